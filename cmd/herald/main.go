@@ -5,11 +5,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -17,17 +22,23 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/btouchard/herald/internal/approval"
 	"github.com/btouchard/herald/internal/auth"
 	"github.com/btouchard/herald/internal/config"
 	"github.com/btouchard/herald/internal/executor"
-	_ "github.com/btouchard/herald/internal/executor/claude" // registers claude-code executor
+	_ "github.com/btouchard/herald/internal/executor/aider"    // registers aider executor
+	_ "github.com/btouchard/herald/internal/executor/claude"   // registers claude-code executor
+	_ "github.com/btouchard/herald/internal/executor/external" // registers external executor
 	heraldmcp "github.com/btouchard/herald/internal/mcp"
 	authmw "github.com/btouchard/herald/internal/mcp/middleware"
 	"github.com/btouchard/herald/internal/notify"
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/scheduler"
 	"github.com/btouchard/herald/internal/store"
 	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
 	"github.com/btouchard/herald/internal/tunnel"
+	"github.com/btouchard/herald/internal/workspace"
 )
 
 var version = "dev"
@@ -49,6 +60,10 @@ func main() {
 		cmdHealth(os.Args[2:])
 	case "rotate-secret":
 		cmdRotateSecret(os.Args[2:])
+	case "approve":
+		cmdDecide("approve", os.Args[2:])
+	case "reject":
+		cmdDecide("reject", os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
 		printUsage()
@@ -63,6 +78,8 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "  check           Validate configuration\n")
 	fmt.Fprintf(os.Stderr, "  health          Check if the server is running\n")
 	fmt.Fprintf(os.Stderr, "  rotate-secret   Generate a new client secret (invalidates sessions)\n")
+	fmt.Fprintf(os.Stderr, "  approve         Approve a task awaiting approval\n")
+	fmt.Fprintf(os.Stderr, "  reject          Reject a task awaiting approval\n")
 	fmt.Fprintf(os.Stderr, "  version         Print version\n")
 }
 
@@ -145,6 +162,63 @@ func cmdRotateSecret(args []string) {
 	fmt.Println("Secret rotated. Restart Herald to apply. All existing sessions will be invalidated.")
 }
 
+// cmdDecide approves or rejects a task awaiting approval through the
+// local server, signing the decision with the client secret.
+func cmdDecide(decision string, args []string) {
+	fs := flag.NewFlagSet(decision, flag.ExitOnError)
+	configPath := fs.String("config", "", "path to config file")
+	fs.Usage = func() {
+		if decision == "reject" {
+			fmt.Fprintf(os.Stderr, "Usage: herald reject [flags] <task-id> [reason]\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Usage: herald approve [flags] <task-id>\n")
+		}
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args) // ExitOnError handles errors
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	taskID := fs.Arg(0)
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ensureClientSecret(cfg, *configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	form := approval.NewSigner(cfg.Auth.ClientSecret, "").Values(task.ApprovalCLI, taskID, time.Minute)
+	form.Set("decision", decision)
+	if decision == "reject" {
+		form.Set("reason", strings.Join(fs.Args()[1:], " "))
+	}
+
+	endpoint := fmt.Sprintf("http://%s/approvals/%s", net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port)), url.PathEscape(taskID))
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.PostForm(endpoint, form)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "error: %s\n", strings.TrimSpace(string(body)))
+		os.Exit(1)
+	}
+	outcome := "approved"
+	if decision == "reject" {
+		outcome = "rejected"
+	}
+	fmt.Printf("task %s %s\n", taskID, outcome)
+}
+
 // ensureClientSecret loads the client secret into cfg.Auth.ClientSecret.
 // Priority: env var HERALD_CLIENT_SECRET > config file value > auto-generated file.
 func ensureClientSecret(cfg *config.Config, configPath string) error {
@@ -283,15 +357,12 @@ func run(ctx context.Context, cfg *config.Config) error {
 	if executorName == "" {
 		executorName = "claude-code"
 	}
-	factory, ok := executor.Get(executorName)
+	executorType, executorCfg := executorFactoryConfig(executorName, cfg.Execution)
+	factory, ok := executor.Get(executorType)
 	if !ok {
-		return fmt.Errorf("unknown executor %q (available: %v)", executorName, executor.Available())
+		return fmt.Errorf("unknown executor %q (available: %v)", executorType, executor.Available())
 	}
-	exec, err := factory(map[string]any{
-		"claude_path": cfg.Execution.ClaudePath,
-		"work_dir":    cfg.Execution.WorkDir,
-		"env":         cfg.Execution.Env,
-	})
+	exec, err := factory(executorCfg)
 	if err != nil {
 		return fmt.Errorf("creating executor %q: %w", executorName, err)
 	}
@@ -299,19 +370,45 @@ func run(ctx context.Context, cfg *config.Config) error {
 
 	// --- Task Manager ---
 	tm := task.NewManager(exec, cfg.Execution.MaxConcurrent, cfg.Execution.MaxTimeout)
+	tm.SetStore(db)
+	tm.SetInterruptedPolicy(cfg.Execution.InterruptedPolicy)
+	tm.SetWorkspace(workspace.NewManager(pm, cfg.Execution.WorkDir))
+	tm.SetBudgets(taskBudget(cfg.Execution.Budget), projectBudgets(cfg.Projects))
+	tm.SetBudgetPolicy(cfg.Execution.OnBudgetExhausted)
+	tm.SetApprovalPolicy(taskApproval(cfg.Execution.Approval), projectApprovals(cfg.Projects))
+	tm.SetPausedHoldSlots(cfg.Execution.PausedTasksHoldSlots)
+	tm.SetPreemptPriority(task.Priority(cfg.Execution.PreemptPriority))
+	tm.SetTimeoutWarning(cfg.Execution.TimeoutWarning)
+	if cfg.Execution.Permissions.Forward {
+		// Spawned processes reach the approval endpoint on the local listener.
+		permissionURL := "http://" + net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port)) + "/permissions/mcp"
+		tm.SetPermissionPrompts(permissionURL, cfg.Execution.Permissions.Timeout)
+	}
+
+	// --- Scheduler ---
+	templates := template.NewRegistry(cfg.Templates, cfg.Projects)
+	sched := scheduler.New(tm, pm, templates, cfg.Execution)
+	sched.SetStore(db)
 
 	// --- MCP Server ---
+	subscriptions := heraldmcp.NewSubscriptions()
 	mcpServer := heraldmcp.NewServer(&heraldmcp.Deps{
-		Projects:     pm,
-		Tasks:        tm,
-		Store:        db,
-		Execution:    cfg.Execution,
-		Capabilities: exec.Capabilities(),
-		Version:      version,
+		Projects:      pm,
+		Tasks:         tm,
+		Store:         db,
+		Events:        db,
+		Transcripts:   db,
+		Execution:     cfg.Execution,
+		Capabilities:  exec.Capabilities(),
+		Templates:     templates,
+		Scheduler:     sched,
+		Subscriptions: subscriptions,
+		Version:       version,
 	})
 
 	// --- Push Notifications ---
 	mcpNotifier := notify.NewMCPNotifier(mcpServer, 3*time.Second)
+	mcpNotifier.SetSubscriptions(subscriptions)
 	hub := notify.NewHub(mcpNotifier)
 	tm.SetNotifyFunc(func(e task.TaskEvent) {
 		hub.Notify(notify.Event{
@@ -319,10 +416,24 @@ func run(ctx context.Context, cfg *config.Config) error {
 			TaskID:       e.TaskID,
 			Project:      e.Project,
 			Message:      e.Message,
+			Progress:     e.Progress,
 			MCPSessionID: e.MCPSessionID,
 		})
 	})
 
+	// Schedule events go through the same hub.
+	sched.SetNotifyFunc(func(e scheduler.Event) {
+		hub.Notify(notify.Event{
+			Type:    e.Type,
+			TaskID:  e.TaskID,
+			Project: e.Project,
+			Message: e.Message,
+		})
+	})
+	if err := sched.Load(cfg.Schedules); err != nil {
+		return fmt.Errorf("loading schedules: %w", err)
+	}
+
 	mcpHTTP := server.NewStreamableHTTPServer(mcpServer)
 
 	// --- Optional Tunnel (ngrok) ---
@@ -356,6 +467,11 @@ func run(ctx context.Context, cfg *config.Config) error {
 	oauth := auth.NewOAuthServerWithStore(cfg.Auth, cfg.Server.PublicURL, authStore)
 	go oauth.StartCleanupLoop(ctx.Done())
 
+	// --- Approval links (signed with the client secret) ---
+	signer := approval.NewSigner(cfg.Auth.ClientSecret, cfg.Server.PublicURL)
+	tm.SetApprovalLinkFunc(signer.Link)
+	approvals := approval.NewHandler(tm, signer)
+
 	// --- HTTP Router ---
 	r := chi.NewRouter()
 	r.Use(authmw.SecurityHeaders)
@@ -377,7 +493,22 @@ func run(ctx context.Context, cfg *config.Config) error {
 	r.Group(func(r chi.Router) {
 		r.Use(authmw.RateLimit(cfg.RateLimit))
 		r.Use(authmw.BearerAuth(oauth, resourceMetadataURL))
-		r.Handle("/mcp", mcpHTTP)
+		r.Handle("/mcp", subscriptions.Middleware(mcpHTTP))
+	})
+
+	// Approval endpoint for the permission requests of spawned Claude Code
+	// processes (task token required, see task.Manager.SetPermissionPrompts)
+	r.Group(func(r chi.Router) {
+		r.Use(authmw.RateLimit(cfg.RateLimit))
+		r.Handle("/permissions/mcp", heraldmcp.NewPermissionHandler(tm, version))
+	})
+
+	// Approval page and decisions for tasks awaiting approval (signed link or
+	// herald approve/reject required, IP rate limited against brute force)
+	r.Group(func(r chi.Router) {
+		r.Use(oauthLimiter)
+		r.Get("/approvals/{taskID}", approvals.HandlePage)
+		r.Post("/approvals/{taskID}", approvals.HandleDecision)
 	})
 
 	// Favicon (embedded SVG — overrides parent domain favicon for Custom Connector icon)
@@ -400,10 +531,15 @@ func run(ctx context.Context, cfg *config.Config) error {
 
 	errCh := make(chan error, 2)
 
-	// Start local server
+	// Start local server. The listener is bound before any task runs so that
+	// the approval links and permission endpoint of restored tasks resolve.
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("local server: %w", err)
+	}
 	go func() {
 		slog.Info("starting local server", "addr", addr)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- fmt.Errorf("local server: %w", err)
 		}
 	}()
@@ -425,6 +561,21 @@ func run(ctx context.Context, cfg *config.Config) error {
 		}()
 	}
 
+	// Restore persisted tasks once notifications, approval links and the
+	// listener are in place so requeued tasks report their progress like
+	// any other task.
+	if err := tm.Restore(restoreRequestBuilder(pm, cfg.Execution)); err != nil {
+		if tunnelSrv != nil {
+			_ = tunnelSrv.Close()
+		}
+		_ = srv.Close()
+		return fmt.Errorf("restoring tasks: %w", err)
+	}
+
+	// Schedules fire after the restore so that missed runs queue behind the
+	// restored tasks.
+	go sched.Run(ctx)
+
 	// Print banner after all servers are started
 	printBanner(cfg, tunnelURL)
 	slog.Info("herald is ready", "local", addr, "tunnel", tunnelURL)
@@ -462,6 +613,104 @@ func run(ctx context.Context, cfg *config.Config) error {
 	return nil
 }
 
+// restoreRequestBuilder rebuilds the executor request of a task requeued
+// after a restart from the current project configuration.
+func restoreRequestBuilder(pm *project.Manager, exec config.ExecutionConfig) task.RequestBuilder {
+	return func(snap task.TaskSnapshot) (executor.Request, int, error) {
+		proj, err := pm.Get(snap.Project)
+		if err != nil {
+			return executor.Request{}, 0, err
+		}
+		model := snap.Model
+		if model == "" {
+			model = exec.Model
+		}
+		return executor.Request{
+			TaskID:         snap.ID,
+			Prompt:         snap.Prompt,
+			ProjectPath:    proj.Path,
+			SessionID:      snap.SessionID,
+			Model:          model,
+			AllowedTools:   proj.AllowedTools,
+			TimeoutMinutes: snap.TimeoutMinutes,
+			DryRun:         snap.DryRun,
+		}, proj.MaxConcurrentTasks, nil
+	}
+}
+
+// executorFactoryConfig returns the registered executor running the backend
+// name, and its factory configuration. Backends declared in
+// execution.executors run with the executor of their type, built-in ones
+// with the executor of their name.
+func executorFactoryConfig(name string, exec config.ExecutionConfig) (string, map[string]any) {
+	backend, ok := exec.Executors[name]
+	if !ok {
+		return name, map[string]any{
+			"claude_path": exec.ClaudePath,
+			"work_dir":    exec.WorkDir,
+			"env":         exec.Env,
+		}
+	}
+
+	env := maps.Clone(exec.Env)
+	if env == nil {
+		env = make(map[string]string)
+	}
+	maps.Copy(env, backend.Env)
+	cfg := maps.Clone(backend.Options)
+	if cfg == nil {
+		cfg = make(map[string]any)
+	}
+	cfg["name"] = name
+	cfg["command"] = backend.Command
+	cfg["args"] = backend.Args
+	cfg["env"] = env
+	cfg["work_dir"] = exec.WorkDir
+
+	typ := backend.Type
+	if typ == "" {
+		typ = "external"
+	}
+	return typ, cfg
+}
+
+// taskBudget converts a configured budget for the task manager.
+func taskBudget(b config.BudgetConfig) task.Budget {
+	return task.Budget{DailyUSD: b.DailyUSD, MonthlyUSD: b.MonthlyUSD}
+}
+
+// projectBudgets returns the budgets of the projects that have one, by name.
+func projectBudgets(projects map[string]config.Project) map[string]task.Budget {
+	budgets := make(map[string]task.Budget)
+	for name, p := range projects {
+		if p.Budget.DailyUSD > 0 || p.Budget.MonthlyUSD > 0 {
+			budgets[name] = taskBudget(p.Budget)
+		}
+	}
+	return budgets
+}
+
+// taskApproval converts a configured approval policy for the task manager.
+func taskApproval(a config.ApprovalConfig) task.ApprovalPolicy {
+	p := task.ApprovalPolicy{Required: a.Required, MaxPromptSize: a.MaxPromptSize}
+	for _, priority := range a.Priorities {
+		p.Priorities = append(p.Priorities, task.Priority(priority))
+	}
+	return p
+}
+
+// projectApprovals returns the approval policies of the projects that have
+// one, by name.
+func projectApprovals(projects map[string]config.Project) map[string]task.ApprovalPolicy {
+	policies := make(map[string]task.ApprovalPolicy)
+	for name, p := range projects {
+		if p.Approval.Required || len(p.Approval.Priorities) > 0 || p.Approval.MaxPromptSize > 0 {
+			policies[name] = taskApproval(p.Approval)
+		}
+	}
+	return policies
+}
+
 // Herald favicon — yellow-green tilted rounded square with dark "H".
 const faviconSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 512 512">
 <g transform="rotate(-3 256 256)">
@@ -0,0 +1,163 @@
+package approval
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// Tasks is the part of the task manager the approval endpoints use.
+// Defined at the consumer side per Go convention; satisfied by
+// *task.Manager.
+type Tasks interface {
+	Get(id string) (*task.Task, error)
+	Approve(id, by string) error
+	Reject(id, by, reason string) error
+}
+
+// Handler serves the approval page of a task and records the decision.
+// Every request carries the fields signed by a Signer.
+type Handler struct {
+	tasks  Tasks
+	signer *Signer
+}
+
+// NewHandler creates the approval endpoints.
+func NewHandler(tasks Tasks, signer *Signer) *Handler {
+	return &Handler{tasks: tasks, signer: signer}
+}
+
+// HandlePage shows what a task would run, with buttons to approve or
+// reject it. It changes nothing, so that fetching a link alone does not
+// approve a task.
+// GET /approvals/{taskID}
+func (h *Handler) HandlePage(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "taskID")
+	q := r.URL.Query()
+	if err := h.signer.Verify(id, q); err != nil {
+		slog.Warn("approval page with invalid signature", "task_id", id, "error", err)
+		http.Error(w, "invalid or expired approval link", http.StatusForbidden)
+		return
+	}
+	t, err := h.tasks.Get(id)
+	if err != nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+
+	snap := t.Snapshot()
+	render(w, http.StatusOK, pageData{
+		Task:     snap,
+		Awaiting: snap.Status == task.StatusAwaitingApproval,
+		Action:   "/approvals/" + url.PathEscape(id),
+		Via:      q.Get("via"),
+		Expires:  q.Get("expires"),
+		Sig:      q.Get("sig"),
+	})
+}
+
+// HandleDecision approves or rejects a task.
+// POST /approvals/{taskID} with decision=approve|reject, an optional
+// reason and the signed fields.
+func (h *Handler) HandleDecision(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "taskID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "malformed form data", http.StatusBadRequest)
+		return
+	}
+	if err := h.signer.Verify(id, r.PostForm); err != nil {
+		slog.Warn("approval decision with invalid signature", "task_id", id, "error", err)
+		http.Error(w, "invalid or expired approval link", http.StatusForbidden)
+		return
+	}
+	via := r.PostForm.Get("via")
+
+	var err error
+	var outcome string
+	switch r.PostForm.Get("decision") {
+	case "approve":
+		err = h.tasks.Approve(id, via)
+		outcome = "approved"
+	case "reject":
+		err = h.tasks.Reject(id, via, strings.TrimSpace(r.PostForm.Get("reason")))
+		outcome = "rejected"
+	default:
+		http.Error(w, "decision must be \"approve\" or \"reject\"", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	t, err := h.tasks.Get(id)
+	if err != nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	render(w, http.StatusOK, pageData{Task: t.Snapshot(), Outcome: outcome})
+}
+
+// pageData fills the approval page.
+type pageData struct {
+	Task     task.TaskSnapshot
+	Awaiting bool
+	Outcome  string // set once a decision was made
+
+	Action, Via, Expires, Sig string
+}
+
+func render(w http.ResponseWriter, status int, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'")
+	w.WriteHeader(status)
+	if err := page.Execute(w, data); err != nil {
+		slog.Warn("failed to render approval page", "error", err)
+	}
+}
+
+var page = template.Must(template.New("approval").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Herald — {{.Task.ID}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #0a0a0f; }
+pre { white-space: pre-wrap; background: #f4f4f5; padding: 1rem; border-radius: .5rem; }
+button { font-size: 1rem; padding: .5rem 1.25rem; margin-right: .5rem; }
+textarea { width: 100%; }
+</style>
+</head>
+<body>
+<h1>{{.Task.ID}}</h1>
+<p><strong>Project:</strong> {{.Task.Project}} · <strong>Priority:</strong> {{.Task.Priority}} · <strong>Status:</strong> {{.Task.Status}}</p>
+{{if .Outcome}}
+<p>Task {{.Outcome}}.</p>
+{{else}}
+{{if .Task.ApprovalReason}}<p><strong>Needs approval because:</strong> {{.Task.ApprovalReason}}</p>{{end}}
+{{if .Task.Context}}<p><strong>Context:</strong> {{.Task.Context}}</p>{{end}}
+<h2>Prompt</h2>
+<pre>{{.Task.Prompt}}</pre>
+{{if .Awaiting}}
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="via" value="{{.Via}}">
+<input type="hidden" name="expires" value="{{.Expires}}">
+<input type="hidden" name="sig" value="{{.Sig}}">
+<p><label>Reason (optional, for a rejection)<br><textarea name="reason" rows="2"></textarea></label></p>
+<button type="submit" name="decision" value="approve">Approve</button>
+<button type="submit" name="decision" value="reject">Reject</button>
+</form>
+{{else}}
+<p>This task is not awaiting approval.</p>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
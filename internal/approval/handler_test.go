@@ -0,0 +1,123 @@
+package approval
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/task"
+)
+
+type instantExecutor struct{}
+
+func (instantExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "instant"}
+}
+
+func (instantExecutor) Execute(context.Context, executor.Request, executor.ProgressFunc) (*executor.Result, error) {
+	return &executor.Result{Output: "done"}, nil
+}
+
+// newAwaitingTask returns a router serving the approval endpoints and a
+// task awaiting approval.
+func newAwaitingTask(t *testing.T, prompt string) (http.Handler, *Signer, *task.Task) {
+	t.Helper()
+	tm := task.NewManager(instantExecutor{}, 3, 2*time.Hour)
+	tm.SetApprovalPolicy(task.ApprovalPolicy{Required: true}, nil)
+	tk := tm.Create("billing", prompt, "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	signer := NewSigner("secret", "https://herald.example.com")
+	h := NewHandler(tm, signer)
+	r := chi.NewRouter()
+	r.Get("/approvals/{taskID}", h.HandlePage)
+	r.Post("/approvals/{taskID}", h.HandleDecision)
+	return r, signer, tk
+}
+
+func postDecision(h http.Handler, id string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/approvals/"+id, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Page_ShowsEscapedPromptWithoutApproving(t *testing.T) {
+	t.Parallel()
+	h, signer, tk := newAwaitingTask(t, "<script>alert(1)</script>")
+
+	u, err := url.Parse(signer.Link(tk.ID))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, body, "<script>")
+	assert.Contains(t, body, `value="approve"`)
+	assert.Equal(t, task.StatusAwaitingApproval, tk.Snapshot().Status)
+}
+
+func TestHandler_Page_WhenSignatureInvalid_Forbidden(t *testing.T) {
+	t.Parallel()
+	h, _, tk := newAwaitingTask(t, "rotate keys")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/approvals/"+tk.ID+"?via=link&expires=9999999999&sig=forged", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandler_Decision_Approve_StartsTask(t *testing.T) {
+	t.Parallel()
+	h, signer, tk := newAwaitingTask(t, "rotate keys")
+
+	form := signer.Values(task.ApprovalCLI, tk.ID, time.Minute)
+	form.Set("decision", "approve")
+	rec := postDecision(h, tk.ID, form)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	<-tk.Done()
+	snap := tk.Snapshot()
+	assert.Equal(t, task.StatusCompleted, snap.Status)
+	assert.Equal(t, task.ApprovalCLI, snap.ApprovedBy)
+
+	rec = postDecision(h, tk.ID, form)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestHandler_Decision_Reject_CancelsTask(t *testing.T) {
+	t.Parallel()
+	h, signer, tk := newAwaitingTask(t, "rotate keys")
+
+	form := signer.Values(task.ApprovalLink, tk.ID, time.Minute)
+	form.Set("decision", "reject")
+	form.Set("reason", "wrong project")
+	rec := postDecision(h, tk.ID, form)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	snap := tk.Snapshot()
+	assert.Equal(t, task.StatusCancelled, snap.Status)
+	assert.Equal(t, "rejected via link: wrong project", snap.Error)
+}
+
+func TestHandler_Decision_WhenSignedForAnotherTask_Forbidden(t *testing.T) {
+	t.Parallel()
+	h, signer, tk := newAwaitingTask(t, "rotate keys")
+
+	form := signer.Values(task.ApprovalLink, "herald-ffffffff", time.Minute)
+	form.Set("decision", "approve")
+	rec := postDecision(h, tk.ID, form)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, task.StatusAwaitingApproval, tk.Snapshot().Status)
+}
@@ -0,0 +1,87 @@
+// Package approval lets tasks awaiting approval be approved or rejected
+// outside Claude Chat: from a signed link opened in a browser, or with the
+// herald approve and herald reject commands on the workstation.
+package approval
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// LinkTTL is how long an approval link stays valid.
+const LinkTTL = 24 * time.Hour
+
+// Signer signs approval decisions with a key derived from the client
+// secret, so that only someone who was given a link, or who can read the
+// secret on the workstation, can approve a task.
+type Signer struct {
+	key       []byte
+	publicURL string
+	now       func() time.Time
+}
+
+// NewSigner creates a Signer. publicURL is where approval links point to;
+// when empty, Link returns "".
+func NewSigner(clientSecret, publicURL string) *Signer {
+	key := sha256.Sum256([]byte(clientSecret))
+	return &Signer{
+		key:       key[:],
+		publicURL: strings.TrimRight(publicURL, "/"),
+		now:       time.Now,
+	}
+}
+
+// Link returns a link that opens the approval page of a task, valid for
+// LinkTTL, or "" when there is no public URL.
+func (s *Signer) Link(taskID string) string {
+	if s.publicURL == "" {
+		return ""
+	}
+	return s.publicURL + "/approvals/" + url.PathEscape(taskID) + "?" + s.Values(task.ApprovalLink, taskID, LinkTTL).Encode()
+}
+
+// Values returns the signed form fields that authorize a decision on
+// taskID through via for ttl.
+func (s *Signer) Values(via, taskID string, ttl time.Duration) url.Values {
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	return url.Values{
+		"via":     {via},
+		"expires": {expires},
+		"sig":     {s.sign(via, taskID, expires)},
+	}
+}
+
+// Verify checks the signed fields of a decision on taskID.
+func (s *Signer) Verify(taskID string, v url.Values) error {
+	via, expires, sig := v.Get("via"), v.Get("expires"), v.Get("sig")
+	if via != task.ApprovalLink && via != task.ApprovalCLI {
+		return fmt.Errorf("unknown approval channel %q", via)
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry")
+	}
+	want := s.sign(via, taskID, expires)
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return fmt.Errorf("invalid signature")
+	}
+	if s.now().Unix() > exp {
+		return fmt.Errorf("link expired")
+	}
+	return nil
+}
+
+// sign computes the hex HMAC-SHA256 of a decision.
+func (s *Signer) sign(via, taskID, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("approval\n" + via + "\n" + taskID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
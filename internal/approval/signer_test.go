@@ -0,0 +1,53 @@
+package approval
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+func TestSigner_Link_VerifiesForItsTaskOnly(t *testing.T) {
+	t.Parallel()
+	s := NewSigner("secret", "https://herald.example.com/")
+
+	link := s.Link("herald-a1b2c3d4")
+	require.True(t, strings.HasPrefix(link, "https://herald.example.com/approvals/herald-a1b2c3d4?"))
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+
+	assert.NoError(t, s.Verify("herald-a1b2c3d4", u.Query()))
+	assert.ErrorContains(t, s.Verify("herald-ffffffff", u.Query()), "invalid signature")
+	assert.ErrorContains(t, NewSigner("other", "").Verify("herald-a1b2c3d4", u.Query()), "invalid signature")
+}
+
+func TestSigner_Verify_WhenChannelChanged_Rejects(t *testing.T) {
+	t.Parallel()
+	s := NewSigner("secret", "")
+
+	v := s.Values(task.ApprovalLink, "herald-a1b2c3d4", time.Minute)
+	v.Set("via", task.ApprovalCLI)
+	assert.ErrorContains(t, s.Verify("herald-a1b2c3d4", v), "invalid signature")
+
+	v.Set("via", task.ApprovalChat)
+	assert.ErrorContains(t, s.Verify("herald-a1b2c3d4", v), "unknown approval channel")
+}
+
+func TestSigner_Verify_WhenExpired_Rejects(t *testing.T) {
+	t.Parallel()
+	s := NewSigner("secret", "")
+	v := s.Values(task.ApprovalCLI, "herald-a1b2c3d4", time.Minute)
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	assert.ErrorContains(t, s.Verify("herald-a1b2c3d4", v), "expired")
+}
+
+func TestSigner_Link_WhenNoPublicURL_ReturnsEmpty(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, NewSigner("secret", "").Link("herald-a1b2c3d4"))
+}
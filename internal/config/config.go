@@ -10,6 +10,8 @@ type Config struct {
 	Execution     ExecutionConfig     `yaml:"execution"`
 	Notifications NotificationsConfig `yaml:"notifications"`
 	Projects      map[string]Project  `yaml:"projects"`
+	Templates     map[string]Template `yaml:"templates"`
+	Schedules     map[string]Schedule `yaml:"schedules"`
 	RateLimit     RateLimitConfig     `yaml:"rate_limit"`
 	Tunnel        TunnelConfig        `yaml:"tunnel"`
 }
@@ -36,7 +38,8 @@ type DatabaseConfig struct {
 }
 
 type ExecutionConfig struct {
-	// Executor selects the CLI backend. Defaults to "claude-code".
+	// Executor selects the CLI backend: a built-in one or one of
+	// Executors. Defaults to "claude-code".
 	Executor       string            `yaml:"executor"`
 	ClaudePath     string            `yaml:"claude_path"`
 	Model          string            `yaml:"model"`
@@ -47,6 +50,86 @@ type ExecutionConfig struct {
 	MaxPromptSize  int               `yaml:"max_prompt_size"`
 	MaxOutputSize  int               `yaml:"max_output_size"`
 	Env            map[string]string `yaml:"env"`
+
+	// InterruptedPolicy decides what happens on startup to tasks that were
+	// queued or running when Herald stopped: "fail" (default) or "requeue".
+	InterruptedPolicy string `yaml:"interrupted_policy"`
+
+	// Budget caps what all tasks together may spend.
+	Budget BudgetConfig `yaml:"budget"`
+	// OnBudgetExhausted decides what happens to new tasks once a budget is
+	// spent: "reject" (default) or "queue" until the budget resets.
+	OnBudgetExhausted string `yaml:"on_budget_exhausted"`
+
+	// PausedTasksHoldSlots counts paused tasks against max_concurrent and
+	// max_concurrent_tasks. By default a paused task frees its slot for
+	// queued tasks.
+	PausedTasksHoldSlots bool `yaml:"paused_tasks_hold_slots"`
+	// PreemptPriority lets tasks of this priority or higher preempt running
+	// tasks of lower priority when no slot is free: the lowest one is
+	// paused until the preempting task ends. Empty (default) disables
+	// preemption.
+	PreemptPriority string `yaml:"preempt_priority"`
+	// TimeoutWarning notifies running tasks this long before their timeout,
+	// so that extend_task can give them more time. Zero (default) disables
+	// the warning.
+	TimeoutWarning time.Duration `yaml:"timeout_warning"`
+
+	// Permissions forwards the permission requests of tasks to Claude Chat.
+	Permissions PermissionsConfig `yaml:"permissions"`
+
+	// Approval holds matching tasks until someone approves them.
+	Approval ApprovalConfig `yaml:"approval"`
+	// ApproveFromChat lets the approve_task tool approve tasks. When false,
+	// tasks are approved only from the workstation or an approval link, so
+	// a compromised conversation cannot approve its own tasks; approve_task
+	// can still reject them.
+	ApproveFromChat bool `yaml:"approve_from_chat"`
+
+	// Executors declares backends by name, which Executor can select.
+	Executors map[string]ExecutorConfig `yaml:"executors"`
+}
+
+// ExecutorConfig declares a backend: an external binary speaking Herald's
+// executor protocol by default, or an executor of another Type.
+type ExecutorConfig struct {
+	// Type is the executor running the backend. Defaults to "external".
+	Type    string            `yaml:"type"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"`
+	// Options are passed as is to the executor of Type.
+	Options map[string]any `yaml:"options"`
+}
+
+// ApprovalConfig decides which tasks wait in awaiting_approval after
+// start_task until someone approves them.
+type ApprovalConfig struct {
+	// Required holds every task.
+	Required bool `yaml:"required"`
+	// Priorities holds tasks of these priorities, e.g. ["urgent"].
+	Priorities []string `yaml:"priorities"`
+	// MaxPromptSize holds tasks whose prompt is longer, in bytes. Zero
+	// means no limit.
+	MaxPromptSize int `yaml:"max_prompt_size"`
+}
+
+// PermissionsConfig controls how tool uses outside a project's
+// allowed_tools are approved.
+type PermissionsConfig struct {
+	// Forward registers Herald as the permission prompt tool of the tasks
+	// it spawns, so that each request waits for approval from Claude Chat.
+	// When false, such tool uses are refused.
+	Forward bool `yaml:"forward"`
+	// Timeout denies a request left unanswered for that long.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// BudgetConfig caps the cost of tasks in USD over the current day and the
+// current month, in the server's time zone. Zero means no cap.
+type BudgetConfig struct {
+	DailyUSD   float64 `yaml:"daily_usd"`
+	MonthlyUSD float64 `yaml:"monthly_usd"`
 }
 
 type NotificationsConfig struct {
@@ -62,6 +145,22 @@ type Project struct {
 	AllowedTools       []string  `yaml:"allowed_tools"`
 	MaxConcurrentTasks int       `yaml:"max_concurrent_tasks"`
 	Git                GitConfig `yaml:"git"`
+
+	// Retry is the retry policy for the project's failed tasks; start_task's
+	// retry argument overrides it per task.
+	Retry RetryConfig `yaml:"retry"`
+
+	// Budget caps what the project's tasks may spend, on top of the global
+	// execution.budget.
+	Budget BudgetConfig `yaml:"budget"`
+
+	// Approval holds the project's matching tasks until someone approves
+	// them, on top of the global execution.approval.
+	Approval ApprovalConfig `yaml:"approval"`
+
+	// Templates adds project-specific task templates. A template with the
+	// same name as a global one replaces it for this project.
+	Templates map[string]Template `yaml:"templates"`
 }
 
 type GitConfig struct {
@@ -69,6 +168,67 @@ type GitConfig struct {
 	AutoStash    bool   `yaml:"auto_stash"`
 	AutoCommit   bool   `yaml:"auto_commit"`
 	BranchPrefix string `yaml:"branch_prefix"`
+
+	// Worktree runs each task in its own git worktree on the task branch
+	// instead of the project checkout.
+	Worktree bool `yaml:"worktree"`
+	// WorktreeCleanup decides what happens to a task worktree once the task
+	// is terminal: "keep" (default), "on_success" or "always".
+	WorktreeCleanup string `yaml:"worktree_cleanup"`
+}
+
+// RetryConfig runs a failed task again, up to MaxAttempts attempts in
+// total. A failure is retried when it matches one of the failure classes:
+// an exit code in ExitCodes, a timeout with OnTimeout, or stderr matching
+// one of StderrPatterns (regular expressions). Without any class, every
+// failure but a timeout is retried.
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	Backoff        time.Duration `yaml:"backoff"`     // delay before the first retry, doubled after each one (default 30s)
+	MaxBackoff     time.Duration `yaml:"max_backoff"` // cap on the delay
+	ExitCodes      []int         `yaml:"exit_codes"`
+	OnTimeout      bool          `yaml:"on_timeout"`
+	StderrPatterns []string      `yaml:"stderr_patterns"`
+	ResumeSession  bool          `yaml:"resume_session"` // retry in the failed attempt's session
+}
+
+// Template is a named task preset selected with start_task's template
+// argument. Prompt is a skeleton with {{variable}} placeholders; the
+// caller's prompt fills {{prompt}}, or is appended when the skeleton has no
+// such placeholder. The other fields are defaults the caller can override.
+type Template struct {
+	Description  string            `yaml:"description"`
+	Prompt       string            `yaml:"prompt"`
+	Variables    map[string]string `yaml:"variables"` // default variable values
+	Model        string            `yaml:"model"`
+	Timeout      time.Duration     `yaml:"timeout"`
+	AllowedTools []string          `yaml:"allowed_tools"`
+	Priority     string            `yaml:"priority"`
+	DryRun       bool              `yaml:"dry_run"`
+}
+
+// Schedule is a recurring task Herald starts on its own, keyed by name in
+// the schedules section. Cron is a five-field cron expression evaluated in
+// Timezone (default: local time). The task is described like a start_task
+// call: a Prompt, a Template with Variables, or both.
+type Schedule struct {
+	Cron      string            `yaml:"cron"`
+	Timezone  string            `yaml:"timezone"`
+	Project   string            `yaml:"project"`
+	Prompt    string            `yaml:"prompt"`
+	Template  string            `yaml:"template"`
+	Variables map[string]string `yaml:"variables"`
+	Priority  string            `yaml:"priority"`
+	Model     string            `yaml:"model"`
+	Timeout   time.Duration     `yaml:"timeout"`
+	GitBranch string            `yaml:"git_branch"`
+	DryRun    bool              `yaml:"dry_run"`
+
+	// MissedRun decides what happens to a run missed while Herald was
+	// stopped: "skip" (default) or "run_once".
+	MissedRun string `yaml:"missed_run"`
+	// Jitter delays each run by a random duration up to this value.
+	Jitter time.Duration `yaml:"jitter"`
 }
 
 type RateLimitConfig struct {
@@ -106,15 +266,21 @@ func Defaults() *Config {
 			RetentionDays: 90,
 		},
 		Execution: ExecutionConfig{
-			Executor:       "claude-code",
-			ClaudePath:     "claude",
-			Model:          "claude-sonnet-4-5-20250929",
-			DefaultTimeout: 30 * time.Minute,
-			MaxTimeout:     2 * time.Hour,
-			WorkDir:        "~/.config/herald/work",
-			MaxConcurrent:  3,
-			MaxPromptSize:  102400,  // 100KB
-			MaxOutputSize:  1048576, // 1MB
+			Executor:          "claude-code",
+			ClaudePath:        "claude",
+			Model:             "claude-sonnet-4-5-20250929",
+			DefaultTimeout:    30 * time.Minute,
+			MaxTimeout:        2 * time.Hour,
+			WorkDir:           "~/.config/herald/work",
+			MaxConcurrent:     3,
+			MaxPromptSize:     102400,  // 100KB
+			MaxOutputSize:     1048576, // 1MB
+			InterruptedPolicy: "fail",
+			Permissions: PermissionsConfig{
+				Forward: true,
+				Timeout: 10 * time.Minute,
+			},
+			ApproveFromChat: true,
 			Env: map[string]string{
 				"CLAUDE_CODE_ENTRYPOINT":          "herald",
 				"CLAUDE_CODE_DISABLE_AUTO_UPDATE": "1",
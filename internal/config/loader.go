@@ -6,8 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/btouchard/herald/internal/cron"
+	"github.com/btouchard/herald/internal/retry"
 )
 
 // searchPaths returns the ordered list of config file locations to try.
@@ -120,8 +124,147 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("execution.max_concurrent must be at least 1")
 	}
 
+	switch cfg.Execution.InterruptedPolicy {
+	case "", "fail", "requeue":
+	default:
+		return fmt.Errorf("execution.interrupted_policy must be \"fail\" or \"requeue\", got %q", cfg.Execution.InterruptedPolicy)
+	}
+
+	switch cfg.Execution.OnBudgetExhausted {
+	case "", "reject", "queue":
+	default:
+		return fmt.Errorf("execution.on_budget_exhausted must be \"reject\" or \"queue\", got %q", cfg.Execution.OnBudgetExhausted)
+	}
+	if err := validateBudget(cfg.Execution.Budget); err != nil {
+		return fmt.Errorf("execution.budget: %w", err)
+	}
+	switch cfg.Execution.PreemptPriority {
+	case "", "normal", "high", "urgent":
+	default:
+		return fmt.Errorf("execution.preempt_priority must be \"normal\", \"high\" or \"urgent\", got %q", cfg.Execution.PreemptPriority)
+	}
+	if cfg.Execution.TimeoutWarning < 0 {
+		return fmt.Errorf("execution.timeout_warning must not be negative")
+	}
+	if cfg.Execution.Permissions.Timeout < 0 {
+		return fmt.Errorf("execution.permissions.timeout must not be negative")
+	}
+	if err := validateApproval(cfg.Execution.Approval); err != nil {
+		return fmt.Errorf("execution.approval: %w", err)
+	}
+	for name, e := range cfg.Execution.Executors {
+		if (e.Type == "" || e.Type == "external") && e.Command == "" {
+			return fmt.Errorf("execution.executors.%s: command is required", name)
+		}
+	}
+
+	for name, p := range cfg.Projects {
+		switch p.Git.WorktreeCleanup {
+		case "", "keep", "on_success", "always":
+		default:
+			return fmt.Errorf("project %s: git.worktree_cleanup must be \"keep\", \"on_success\" or \"always\", got %q", name, p.Git.WorktreeCleanup)
+		}
+		if err := validateRetry(p.Retry); err != nil {
+			return fmt.Errorf("project %s: retry: %w", name, err)
+		}
+		if err := validateBudget(p.Budget); err != nil {
+			return fmt.Errorf("project %s: budget: %w", name, err)
+		}
+		if err := validateApproval(p.Approval); err != nil {
+			return fmt.Errorf("project %s: approval: %w", name, err)
+		}
+		for tname, t := range p.Templates {
+			if err := validateTemplate(t); err != nil {
+				return fmt.Errorf("project %s: template %s: %w", name, tname, err)
+			}
+		}
+	}
+
+	for name, t := range cfg.Templates {
+		if err := validateTemplate(t); err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+
+	for name, sc := range cfg.Schedules {
+		if err := validateSchedule(sc); err != nil {
+			return fmt.Errorf("schedule %s: %w", name, err)
+		}
+	}
+
 	cfg.Database.Path = ExpandHome(cfg.Database.Path)
 	cfg.Execution.WorkDir = ExpandHome(cfg.Execution.WorkDir)
 
 	return nil
 }
+
+func validateTemplate(t Template) error {
+	switch t.Priority {
+	case "", "low", "normal", "high", "urgent":
+	default:
+		return fmt.Errorf("priority must be \"low\", \"normal\", \"high\" or \"urgent\", got %q", t.Priority)
+	}
+	if t.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+func validateRetry(r RetryConfig) error {
+	p := retry.Policy{
+		MaxAttempts:    r.MaxAttempts,
+		Backoff:        r.Backoff,
+		MaxBackoff:     r.MaxBackoff,
+		StderrPatterns: r.StderrPatterns,
+	}
+	return p.Validate()
+}
+
+func validateBudget(b BudgetConfig) error {
+	if b.DailyUSD < 0 || b.MonthlyUSD < 0 {
+		return fmt.Errorf("daily_usd and monthly_usd must not be negative")
+	}
+	return nil
+}
+
+func validateApproval(a ApprovalConfig) error {
+	for _, p := range a.Priorities {
+		switch p {
+		case "low", "normal", "high", "urgent":
+		default:
+			return fmt.Errorf("priorities must be \"low\", \"normal\", \"high\" or \"urgent\", got %q", p)
+		}
+	}
+	if a.MaxPromptSize < 0 {
+		return fmt.Errorf("max_prompt_size must not be negative")
+	}
+	return nil
+}
+
+func validateSchedule(s Schedule) error {
+	if _, err := cron.Parse(s.Cron); err != nil {
+		return err
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+		}
+	}
+	if strings.TrimSpace(s.Prompt) == "" && s.Template == "" {
+		return fmt.Errorf("prompt or template is required")
+	}
+	switch s.Priority {
+	case "", "low", "normal", "high", "urgent":
+	default:
+		return fmt.Errorf("priority must be \"low\", \"normal\", \"high\" or \"urgent\", got %q", s.Priority)
+	}
+	switch s.MissedRun {
+	case "", "skip", "run_once":
+	default:
+		return fmt.Errorf("missed_run must be \"skip\" or \"run_once\", got %q", s.MissedRun)
+	}
+	if s.Timeout < 0 || s.Jitter < 0 {
+		return fmt.Errorf("timeout and jitter must not be negative")
+	}
+	return nil
+}
@@ -178,6 +178,381 @@ execution:
 	assert.Contains(t, err.Error(), "max_concurrent")
 }
 
+func TestLoadFromFile_RejectsUnknownInterruptedPolicy(t *testing.T) {
+	t.Parallel()
+
+	content := `
+execution:
+  interrupted_policy: retry
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	_, err := LoadFromFile(tmpFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interrupted_policy")
+}
+
+func TestLoadFromFile_RejectsUnknownWorktreeCleanup(t *testing.T) {
+	t.Parallel()
+
+	content := `
+projects:
+  app:
+    path: /tmp/app
+    git:
+      worktree: true
+      worktree_cleanup: sometimes
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	_, err := LoadFromFile(tmpFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worktree_cleanup")
+}
+
+func TestLoadFromFile_ParsesTemplates(t *testing.T) {
+	t.Parallel()
+
+	content := `
+templates:
+  review:
+    description: "Code review"
+    prompt: "Review {{scope}} for {{focus}}.\n\n{{prompt}}"
+    variables:
+      focus: "correctness"
+    model: "claude-opus-4-6"
+    timeout: 20m
+    allowed_tools: ["Read", "Grep"]
+    priority: high
+    dry_run: true
+projects:
+  app:
+    path: /tmp/app
+    templates:
+      test:
+        prompt: "Write tests for {{prompt}}"
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+
+	review := cfg.Templates["review"]
+	assert.Equal(t, "Code review", review.Description)
+	assert.Equal(t, "Review {{scope}} for {{focus}}.\n\n{{prompt}}", review.Prompt)
+	assert.Equal(t, map[string]string{"focus": "correctness"}, review.Variables)
+	assert.Equal(t, "claude-opus-4-6", review.Model)
+	assert.Equal(t, 20*time.Minute, review.Timeout)
+	assert.Equal(t, []string{"Read", "Grep"}, review.AllowedTools)
+	assert.Equal(t, "high", review.Priority)
+	assert.True(t, review.DryRun)
+	assert.Equal(t, "Write tests for {{prompt}}", cfg.Projects["app"].Templates["test"].Prompt)
+}
+
+func TestLoadFromFile_RejectsTemplateWithUnknownPriority(t *testing.T) {
+	t.Parallel()
+
+	content := `
+templates:
+  fix:
+    prompt: "Fix {{prompt}}"
+    priority: asap
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	_, err := LoadFromFile(tmpFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template fix")
+}
+
+func TestLoadFromFile_ParsesSchedules(t *testing.T) {
+	t.Parallel()
+
+	content := `
+schedules:
+  nightly-tests:
+    cron: "0 2 * * *"
+    timezone: Europe/Paris
+    project: app
+    prompt: "Run the test suite and fix flaky tests"
+    git_branch: herald/nightly-tests
+    priority: low
+    timeout: 45m
+    missed_run: run_once
+    jitter: 10m
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+
+	sc := cfg.Schedules["nightly-tests"]
+	assert.Equal(t, "0 2 * * *", sc.Cron)
+	assert.Equal(t, "Europe/Paris", sc.Timezone)
+	assert.Equal(t, "app", sc.Project)
+	assert.Equal(t, "herald/nightly-tests", sc.GitBranch)
+	assert.Equal(t, 45*time.Minute, sc.Timeout)
+	assert.Equal(t, "run_once", sc.MissedRun)
+	assert.Equal(t, 10*time.Minute, sc.Jitter)
+}
+
+func TestLoadFromFile_RejectsInvalidSchedule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		schedule string
+		wantErr  string
+	}{
+		{"bad cron", `cron: "0 25 * * *"`, "hour 25 out of range"},
+		{"no prompt", `cron: "@daily"`, "prompt or template is required"},
+		{"bad timezone", "cron: \"@daily\"\n    prompt: x\n    timezone: Mars/Olympus", "invalid timezone"},
+		{"bad missed run", "cron: \"@daily\"\n    prompt: x\n    missed_run: all", "missed_run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := "schedules:\n  nightly:\n    " + tt.schedule + "\n"
+			tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+			require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+			_, err := LoadFromFile(tmpFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "schedule nightly")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromFile_ParsesProjectRetry(t *testing.T) {
+	t.Parallel()
+
+	content := `
+projects:
+  my-api:
+    path: /tmp
+    retry:
+      max_attempts: 3
+      backoff: 1m
+      exit_codes: [1]
+      on_timeout: true
+      stderr_patterns: ["overloaded", "ECONNRESET"]
+      resume_session: true
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+
+	r := cfg.Projects["my-api"].Retry
+	assert.Equal(t, 3, r.MaxAttempts)
+	assert.Equal(t, time.Minute, r.Backoff)
+	assert.Equal(t, []int{1}, r.ExitCodes)
+	assert.True(t, r.OnTimeout)
+	assert.Equal(t, []string{"overloaded", "ECONNRESET"}, r.StderrPatterns)
+	assert.True(t, r.ResumeSession)
+}
+
+func TestLoadFromFile_RejectsInvalidRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		retry   string
+		wantErr string
+	}{
+		{"too many attempts", "max_attempts: 50", "max_attempts"},
+		{"negative backoff", "max_attempts: 2\n      backoff: -1s", "must not be negative"},
+		{"bad pattern", "max_attempts: 2\n      stderr_patterns: [\"(\"]", "invalid stderr pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content := "projects:\n  my-api:\n    path: /tmp\n    retry:\n      " + tt.retry + "\n"
+			tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+			require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+			_, err := LoadFromFile(tmpFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "project my-api: retry")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromFile_ParsesBudgets(t *testing.T) {
+	t.Parallel()
+
+	content := `
+execution:
+  budget:
+    daily_usd: 50
+    monthly_usd: 500
+  on_budget_exhausted: queue
+projects:
+  my-api:
+    path: /tmp
+    budget:
+      daily_usd: 10
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 50, cfg.Execution.Budget.DailyUSD, 0.001)
+	assert.InDelta(t, 500, cfg.Execution.Budget.MonthlyUSD, 0.001)
+	assert.Equal(t, "queue", cfg.Execution.OnBudgetExhausted)
+	assert.InDelta(t, 10, cfg.Projects["my-api"].Budget.DailyUSD, 0.001)
+	assert.Zero(t, cfg.Projects["my-api"].Budget.MonthlyUSD)
+}
+
+func TestLoadFromFile_RejectsInvalidBudget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"negative global", "execution:\n  budget:\n    daily_usd: -1\n", "execution.budget"},
+		{"negative project", "projects:\n  my-api:\n    path: /tmp\n    budget:\n      monthly_usd: -5\n", "project my-api: budget"},
+		{"bad policy", "execution:\n  on_budget_exhausted: wait\n", "on_budget_exhausted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+			require.NoError(t, os.WriteFile(tmpFile, []byte(tt.content), 0600))
+
+			_, err := LoadFromFile(tmpFile)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadFromFile_PreemptPriority(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  preempt_priority: urgent\n"), 0600))
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	assert.Equal(t, "urgent", cfg.Execution.PreemptPriority)
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  preempt_priority: low\n"), 0600))
+	_, err = LoadFromFile(tmpFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preempt_priority")
+}
+
+func TestLoadFromFile_TimeoutWarning(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  timeout_warning: 5m\n"), 0600))
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Execution.TimeoutWarning)
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  timeout_warning: -1m\n"), 0600))
+	_, err = LoadFromFile(tmpFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout_warning")
+}
+
+func TestLoadFromFile_ParsesPermissions(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  permissions:\n    timeout: 2m\n"), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	assert.True(t, cfg.Execution.Permissions.Forward, "forwarding stays on unless disabled")
+	assert.Equal(t, 2*time.Minute, cfg.Execution.Permissions.Timeout)
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  permissions:\n    forward: false\n"), 0600))
+	cfg, err = LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	assert.False(t, cfg.Execution.Permissions.Forward)
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  permissions:\n    timeout: -1m\n"), 0600))
+	_, err = LoadFromFile(tmpFile)
+	assert.ErrorContains(t, err, "execution.permissions.timeout")
+}
+
+func TestLoadFromFile_ParsesApproval(t *testing.T) {
+	t.Parallel()
+
+	content := `
+execution:
+  approval:
+    priorities: [urgent]
+    max_prompt_size: 4096
+projects:
+  billing:
+    path: /tmp/billing
+    approval:
+      required: true
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, cfg.Execution.Approval.Priorities)
+	assert.Equal(t, 4096, cfg.Execution.Approval.MaxPromptSize)
+	assert.True(t, cfg.Execution.ApproveFromChat, "chat approvals stay on unless disabled")
+	assert.True(t, cfg.Projects["billing"].Approval.Required)
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  approval:\n    priorities: [critical]\n"), 0600))
+	_, err = LoadFromFile(tmpFile)
+	assert.ErrorContains(t, err, "execution.approval")
+}
+
+func TestLoadFromFile_ParsesExecutors(t *testing.T) {
+	t.Parallel()
+
+	content := `
+execution:
+  executor: my-agent
+  executors:
+    my-agent:
+      command: /usr/local/bin/my-agent
+      args: [--json]
+      env:
+        MY_AGENT_MODE: fast
+      options:
+        depth: 3
+`
+	tmpFile := filepath.Join(t.TempDir(), "herald.yaml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0600))
+
+	cfg, err := LoadFromFile(tmpFile)
+	require.NoError(t, err)
+	backend := cfg.Execution.Executors["my-agent"]
+	assert.Equal(t, "/usr/local/bin/my-agent", backend.Command)
+	assert.Equal(t, []string{"--json"}, backend.Args)
+	assert.Equal(t, "fast", backend.Env["MY_AGENT_MODE"])
+	assert.Equal(t, 3, backend.Options["depth"])
+
+	require.NoError(t, os.WriteFile(tmpFile, []byte("execution:\n  executors:\n    broken:\n      args: [--json]\n"), 0600))
+	_, err = LoadFromFile(tmpFile)
+	assert.ErrorContains(t, err, "execution.executors.broken: command is required")
+}
+
 func TestLoadFromFile_RejectsPortZero(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,88 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Next(t *testing.T) {
+	t.Parallel()
+
+	// Friday 2026-10-16 14:07:30 UTC
+	from := time.Date(2026, 10, 16, 14, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 14, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 14, 15, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon", time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * fri", time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC)}, // the 13th or a Friday
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0,30 14 * * *", time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Next(from))
+		})
+	}
+}
+
+func TestExpr_Next_WhenNeverMatches_ReturnsZero(t *testing.T) {
+	t.Parallel()
+
+	e, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, e.Next(time.Now()).IsZero())
+}
+
+func TestExpr_Next_KeepsLocation(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	e, err := Parse("0 2 * * *")
+	require.NoError(t, err)
+
+	next := e.Next(time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2026, 10, 17, 2, 0, 0, 0, loc), next)
+}
+
+func TestParse_WhenInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"", "must have 5 fields"},
+		{"* * * *", "must have 5 fields"},
+		{"60 * * * *", "minute 60 out of range"},
+		{"* 24 * * *", "hour 24 out of range"},
+		{"* * 0 * *", "day of month 0 out of range"},
+		{"* * * foo *", `invalid value "foo" in month field`},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "invalid range"},
+		{"@fortnightly", "unknown cron macro"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Package aider runs tasks with Aider (https://aider.chat) in its
+// non-interactive mode: the prompt is sent as a message file, Aider
+// answers it, applies its edits and exits.
+package aider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/git"
+)
+
+func init() {
+	executor.Register("aider", func(cfg map[string]any) (executor.Executor, error) {
+		aiderPath, _ := cfg["command"].(string)
+		if aiderPath == "" {
+			aiderPath = "aider"
+		}
+		workDir, _ := cfg["work_dir"].(string)
+		env, _ := cfg["env"].(map[string]string)
+		autoCommits, _ := cfg["auto_commits"].(bool)
+		return &Executor{
+			AiderPath:   aiderPath,
+			WorkDir:     workDir,
+			Env:         env,
+			Args:        stringList(cfg["args"]),
+			AutoCommits: autoCommits,
+			Files:       stringList(cfg["files"]),
+			ReadFiles:   stringList(cfg["read"]),
+		}, nil
+	})
+}
+
+// Executor runs tasks via the Aider CLI.
+type Executor struct {
+	AiderPath string
+	WorkDir   string
+	Env       map[string]string
+	// Args are passed to Aider before Herald's own flags, e.g. an
+	// --edit-format or the API base of a local model.
+	Args []string
+
+	// AutoCommits lets Aider commit each edit it applies. Off by default:
+	// the changes are left to the project's git workflow.
+	AutoCommits bool
+	// Files are added to the chat for Aider to edit, relative to the
+	// project. Aider picks files from its repository map otherwise.
+	Files []string
+	// ReadFiles are added to the chat read-only, e.g. conventions.
+	ReadFiles []string
+}
+
+// Capabilities returns the feature set supported by Aider. Each task is a
+// new chat, edits are not restricted to a tool list, and the chat cannot
+// be added to while Aider answers.
+func (e *Executor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{
+		SupportsModel:     true,
+		SupportsDryRun:    true,
+		SupportsStreaming: true,
+		Name:              "aider",
+		Version:           "1.0.0",
+	}
+}
+
+func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	promptPath, err := executor.WritePromptFile(e.WorkDir, req.TaskID, req.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	defer executor.CleanupPromptFile(e.WorkDir, req.TaskID)
+
+	cmd := exec.CommandContext(ctx, e.AiderPath, e.args(req, promptPath)...) //nolint:gosec // AiderPath from trusted config
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// Kill the entire process group so child processes are terminated too
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 5 * time.Second
+
+	if req.ProjectPath != "" {
+		cmd.Dir = req.ProjectPath
+		excludeAiderFiles(ctx, req)
+	}
+
+	// Environment
+	cmd.Env = os.Environ()
+	for k, v := range e.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	for k, v := range req.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("opening stdout: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting aider: %w", err)
+	}
+
+	slog.Info("aider started",
+		"task_id", req.TaskID,
+		"pid", cmd.Process.Pid)
+
+	if onProgress != nil {
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
+	}
+
+	p := newParser(req.Prompt, onProgress)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // 10MB max line
+	for scanner.Scan() {
+		p.line(scanner.Text(), time.Now())
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Warn("aider output scanner error", "task_id", req.TaskID, "error", err)
+	}
+
+	waitErr := cmd.Wait()
+	result := p.result(time.Since(start), waitErr != nil, time.Now())
+	result.Stderr = tail(stderr.Bytes(), maxStderrSize)
+
+	if waitErr != nil {
+		if exitErr, ok := errors.AsType[*exec.ExitError](waitErr); ok {
+			result.ExitCode = exitErr.ExitCode()
+			slog.Warn("aider exited with error",
+				"task_id", req.TaskID,
+				"exit_code", result.ExitCode,
+				"duration", result.Duration)
+			return result, fmt.Errorf("aider exited with code %d: %w", result.ExitCode, waitErr)
+		}
+		return result, fmt.Errorf("waiting for aider: %w", waitErr)
+	}
+
+	slog.Info("aider completed",
+		"task_id", req.TaskID,
+		"duration", result.Duration,
+		"cost_usd", result.CostUSD,
+		"turns", result.Turns)
+
+	return result, nil
+}
+
+// excludeAiderFiles keeps the files Aider writes to the project out of git
+// through the repository's info/exclude: they would count, and be
+// committed, as changes of the task.
+func excludeAiderFiles(ctx context.Context, req executor.Request) {
+	ops := git.NewOps(req.ProjectPath)
+	if !ops.IsGitRepo(ctx) {
+		return
+	}
+	if err := ops.Exclude(ctx, ".aider*"); err != nil {
+		slog.Warn("cannot keep aider files out of git", "task_id", req.TaskID, "error", err)
+	}
+}
+
+// args returns the command line answering req with the message in
+// promptPath, then exiting.
+func (e *Executor) args(req executor.Request, promptPath string) []string {
+	taskDir := executor.TaskDir(e.WorkDir, req.TaskID)
+	args := append([]string{}, e.Args...)
+	args = append(args,
+		"--message-file", promptPath,
+		"--yes-always",
+		"--no-pretty",
+		"--no-stream",
+		"--no-check-update",
+		"--no-show-model-warnings",
+		// Answers come without a user to confirm them: never offer to
+		// run shell commands, which --yes-always would run unrestricted.
+		"--no-suggest-shell-commands",
+		// Leave the project's .gitignore alone, Aider would add .aider*
+		// to it. The .aider* files it still writes to the project, e.g.
+		// its tags cache, are ignored by excludeAiderFiles instead.
+		"--no-gitignore",
+		"--input-history-file", filepath.Join(taskDir, "aider.input.history"),
+		"--chat-history-file", filepath.Join(taskDir, "aider.chat.history.md"),
+	)
+
+	if e.AutoCommits {
+		args = append(args, "--auto-commits")
+	} else {
+		args = append(args, "--no-auto-commits")
+	}
+
+	if req.Model != "" {
+		args = append(args, "--model", req.Model)
+	}
+
+	if req.DryRun {
+		args = append(args, "--dry-run")
+	}
+
+	for _, f := range e.ReadFiles {
+		args = append(args, "--read", f)
+	}
+	return append(args, e.Files...)
+}
+
+// maxStderrSize bounds the stderr kept on a Result.
+const maxStderrSize = 4096
+
+// tail returns the last max bytes of data.
+func tail(data []byte, max int) string {
+	if len(data) > max {
+		data = data[len(data)-max:]
+	}
+	return string(data)
+}
+
+// stringList returns the strings of a config value, a YAML list or a
+// []string.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package aider
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// newFakeAider returns an executor running testdata/fake-aider.sh, and the
+// directory it records its arguments and message into.
+func newFakeAider(t *testing.T) (*Executor, string) {
+	t.Helper()
+	script, err := filepath.Abs("testdata/fake-aider.sh")
+	require.NoError(t, err)
+	record := t.TempDir()
+	return &Executor{
+		AiderPath: script,
+		WorkDir:   t.TempDir(),
+		Env:       map[string]string{"FAKE_AIDER_RECORD": record},
+	}, record
+}
+
+// recordedArgs returns the arguments the fake aider was run with.
+func recordedArgs(t *testing.T, record string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(record, "args")) //nolint:gosec // test file
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestCapabilities_ReturnsExpectedValues(t *testing.T) {
+	t.Parallel()
+
+	caps := (&Executor{}).Capabilities()
+
+	assert.Equal(t, "aider", caps.Name)
+	assert.True(t, caps.SupportsModel)
+	assert.True(t, caps.SupportsDryRun)
+	assert.True(t, caps.SupportsStreaming)
+	assert.False(t, caps.SupportsSession)
+	assert.False(t, caps.SupportsToolList)
+	assert.False(t, caps.SupportsMessages)
+	assert.False(t, caps.SupportsPermissionPrompts)
+}
+
+func TestFactory_CreatesExecutorWithOptions(t *testing.T) {
+	t.Parallel()
+
+	factory, ok := executor.Get("aider")
+	require.True(t, ok, "aider should be registered")
+
+	exec, err := factory(map[string]any{
+		"command":      "/opt/aider/bin/aider",
+		"work_dir":     "/tmp/herald",
+		"args":         []string{"--edit-format", "whole"},
+		"auto_commits": true,
+		"files":        []any{"main.go", "go.mod"},
+		"read":         []any{"CONVENTIONS.md"},
+	})
+	require.NoError(t, err)
+
+	e := exec.(*Executor)
+	assert.Equal(t, "/opt/aider/bin/aider", e.AiderPath)
+	assert.Equal(t, "/tmp/herald", e.WorkDir)
+	assert.Equal(t, []string{"--edit-format", "whole"}, e.Args)
+	assert.True(t, e.AutoCommits)
+	assert.Equal(t, []string{"main.go", "go.mod"}, e.Files)
+	assert.Equal(t, []string{"CONVENTIONS.md"}, e.ReadFiles)
+
+	exec, err = factory(map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "aider", exec.(*Executor).AiderPath)
+}
+
+func TestExecute_SendsPromptAsMessageFile(t *testing.T) {
+	t.Parallel()
+	e, record := newFakeAider(t)
+
+	_, err := e.Execute(context.Background(), executor.Request{
+		TaskID:      "herald-aider01",
+		Prompt:      "Reject expired tokens",
+		ProjectPath: t.TempDir(),
+		Model:       "ollama_chat/qwen2.5-coder",
+	}, nil)
+	require.NoError(t, err)
+
+	message, err := os.ReadFile(filepath.Join(record, "message")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "Reject expired tokens", string(message))
+
+	args := recordedArgs(t, record)
+	assert.Contains(t, args, "--yes-always")
+	assert.Contains(t, args, "--no-suggest-shell-commands")
+	assert.Contains(t, args, "--no-auto-commits")
+	assert.Contains(t, args, "--no-gitignore")
+	assert.Subset(t, args, []string{"--model", "ollama_chat/qwen2.5-coder"})
+	assert.NotContains(t, args, "--dry-run")
+
+	_, err = os.Stat(executor.TaskDir(e.WorkDir, "herald-aider01"))
+	assert.True(t, os.IsNotExist(err), "the message file is removed once aider exits")
+}
+
+func TestExecute_WhenOptionsSet_PassesFlagsAndFiles(t *testing.T) {
+	t.Parallel()
+	e, record := newFakeAider(t)
+	e.Args = []string{"--edit-format", "whole"}
+	e.AutoCommits = true
+	e.Files = []string{"auth/login.go"}
+	e.ReadFiles = []string{"CONVENTIONS.md"}
+
+	_, err := e.Execute(context.Background(), executor.Request{
+		TaskID:      "herald-aider02",
+		Prompt:      "plan the fix",
+		ProjectPath: t.TempDir(),
+		DryRun:      true,
+	}, nil)
+	require.NoError(t, err)
+
+	args := recordedArgs(t, record)
+	assert.Equal(t, []string{"--edit-format", "whole"}, args[:2], "configured args come first")
+	assert.Contains(t, args, "--auto-commits")
+	assert.NotContains(t, args, "--no-auto-commits")
+	assert.Contains(t, args, "--dry-run")
+	assert.NotContains(t, args, "--model")
+	assert.Subset(t, args, []string{"--read", "CONVENTIONS.md"})
+	assert.Equal(t, "auth/login.go", args[len(args)-1], "files to edit come last")
+}
+
+func TestExecute_WhenGitProject_KeepsAiderFilesOutOfGit(t *testing.T) {
+	t.Parallel()
+	e, _ := newFakeAider(t)
+	project := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...) //nolint:gosec // test helper
+		cmd.Dir = project
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v failed: %s", args, out)
+		return string(out)
+	}
+	git("init", "-b", "main")
+
+	for range 2 {
+		_, err := e.Execute(context.Background(), executor.Request{
+			TaskID:      "herald-aider06",
+			Prompt:      "Reject expired tokens",
+			ProjectPath: project,
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	_, err := os.Stat(filepath.Join(project, ".aider.tags.cache.v4", "cache.db"))
+	require.NoError(t, err, "the fake writes its cache to the project")
+	assert.Empty(t, git("status", "--porcelain"), "the cache is ignored, .gitignore untouched")
+	exclude, err := os.ReadFile(filepath.Join(project, ".git", "info", "exclude")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(exclude), ".aider*\n"))
+}
+
+func TestExecute_ParsesOutputIntoProgressAndResult(t *testing.T) {
+	t.Parallel()
+	e, _ := newFakeAider(t)
+
+	var events []executor.ProgressEvent
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID:      "herald-aider03",
+		Prompt:      "Reject expired tokens",
+		ProjectPath: t.TempDir(),
+	}, func(ev executor.ProgressEvent) { events = append(events, ev) })
+	require.NoError(t, err)
+
+	require.NotEmpty(t, events)
+	assert.Equal(t, executor.ProgressStarted, events[0].Kind)
+	assert.Positive(t, events[0].PID)
+	assert.Contains(t, events, executor.ProgressEvent{Kind: executor.ProgressToolStarted, Tool: "Edit", ToolUseID: "edit-1", Input: "auth/login.go"})
+	assert.Contains(t, events, executor.ProgressEvent{Kind: executor.ProgressUsage, CostUSD: 0.02, InputTokens: 4200, OutputTokens: 312})
+
+	assert.True(t, strings.HasPrefix(result.Output, "I'll reject expired tokens in the login handler."))
+	assert.Contains(t, result.Output, "<<<<<<< SEARCH")
+	assert.NotContains(t, result.Output, "Aider v0.86.1")
+	assert.InDelta(t, 0.02, result.CostUSD, 0.0001)
+	assert.Equal(t, 1, result.Turns)
+	assert.Equal(t, "some diagnostics\n", result.Stderr)
+	assert.Empty(t, result.SessionID)
+	assert.Equal(t, executor.EntryResult, result.Transcript[len(result.Transcript)-1].Kind)
+}
+
+func TestExecute_WhenAiderFails_ReturnsErrorWithExitCode(t *testing.T) {
+	t.Parallel()
+	e, _ := newFakeAider(t)
+
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID:      "herald-aider04",
+		Prompt:      "fix it",
+		ProjectPath: t.TempDir(),
+		Env:         map[string]string{"FAKE_AIDER_EXIT": "2"},
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "aider exited with code 2")
+	require.NotNil(t, result)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.True(t, result.Transcript[len(result.Transcript)-1].IsError)
+}
+
+func TestExecute_WhenContextCancelled_ReturnsError(t *testing.T) {
+	t.Parallel()
+	e, _ := newFakeAider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := e.Execute(ctx, executor.Request{
+		TaskID:      "herald-aider05",
+		Prompt:      "slow",
+		ProjectPath: t.TempDir(),
+		Env:         map[string]string{"FAKE_AIDER_SLEEP": "30"},
+	}, nil)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
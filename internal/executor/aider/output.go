@@ -0,0 +1,201 @@
+package aider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Lines of Aider's output (with --no-pretty) that are not the model's
+// answer.
+var (
+	// e.g. "Aider v0.86.1", "Main model: gpt-4o with diff edit format"
+	headerLine = regexp.MustCompile(`^(?:Aider v\S+|(?:Main|Weak|Editor) model: .+|Model: .+|Git repo: .+|Repo-map: .+|Added .+ to the chat\.?)$`)
+	// e.g. "Use /help <question> for help, run \"aider --help\" to see cmd line args"
+	noiseLine = regexp.MustCompile(`^(?:Use /help|https://aider\.chat/)`)
+	// e.g. "Applied edit to internal/auth/login.go"
+	appliedLine = regexp.MustCompile(`^Applied edit to (.+)$`)
+	// e.g. "Commit 1a2b3c4 fix: reject expired tokens"
+	commitLine = regexp.MustCompile(`^Commit [0-9a-f]{7,} .+$`)
+	// e.g. "Tokens: 4.2k sent, 1.1k cache write, 312 received. Cost: $0.02 message, $0.04 session."
+	tokensLine = regexp.MustCompile(`^Tokens: (\S+) sent,(?:.*,)? (\S+) received\.`)
+	costPart   = regexp.MustCompile(`Cost: \$([\d.]+) message, \$([\d.]+) session`)
+	// e.g. "litellm.APIConnectionError: ...", "The LLM did not conform to the edit format."
+	warningLine = regexp.MustCompile(`^(?:Warning|Error)\b|^litellm\.\w+|SEARCH/REPLACE blocks? failed to match|did not conform to the edit format`)
+)
+
+// maxProgressText bounds the answers reported as progress.
+const maxProgressText = 200
+
+// parser turns Aider's output, line by line, into progress events and a
+// result. The lines of the model's answer are gathered until a line of
+// Aider's own ends it.
+type parser struct {
+	onProgress executor.ProgressFunc
+
+	answer     []string // lines of the answer being read
+	output     []string // answers read
+	transcript []executor.TranscriptEntry
+
+	edits        int
+	turns        int
+	costUSD      float64
+	inputTokens  int
+	outputTokens int
+}
+
+func newParser(prompt string, onProgress executor.ProgressFunc) *parser {
+	return &parser{
+		onProgress: onProgress,
+		transcript: []executor.TranscriptEntry{{Kind: executor.EntryUser, Text: prompt, At: time.Now()}},
+	}
+}
+
+func (p *parser) line(s string, at time.Time) {
+	s = strings.TrimRight(s, " \t\r")
+
+	switch {
+	case s == "":
+		if len(p.answer) > 0 {
+			p.answer = append(p.answer, s)
+		}
+
+	case noiseLine.MatchString(s):
+
+	case headerLine.MatchString(s), commitLine.MatchString(s):
+		p.endAnswer(at)
+		p.report(executor.ProgressEvent{Kind: executor.ProgressStatus, Text: s})
+
+	case appliedLine.MatchString(s):
+		p.endAnswer(at)
+		p.edit(appliedLine.FindStringSubmatch(s)[1], at)
+
+	case tokensLine.MatchString(s):
+		p.endAnswer(at)
+		p.usage(s)
+
+	case warningLine.MatchString(s):
+		p.endAnswer(at)
+		p.report(executor.ProgressEvent{Kind: executor.ProgressWarning, Text: s})
+
+	default:
+		p.answer = append(p.answer, s)
+	}
+}
+
+// endAnswer records the answer being read, if any.
+func (p *parser) endAnswer(at time.Time) {
+	text := strings.TrimSpace(strings.Join(p.answer, "\n"))
+	p.answer = nil
+	if text == "" {
+		return
+	}
+	p.output = append(p.output, text)
+	p.transcript = append(p.transcript, executor.TranscriptEntry{Kind: executor.EntryAssistant, Text: text, At: at})
+	p.report(executor.ProgressEvent{Kind: executor.ProgressText, Text: truncate(text, maxProgressText)})
+}
+
+// edit records an edit applied to path, as an Edit tool call for the
+// transcript to tell which files the task changed.
+func (p *parser) edit(path string, at time.Time) {
+	p.edits++
+	id := fmt.Sprintf("edit-%d", p.edits)
+	input, _ := json.Marshal(map[string]string{"file_path": path})
+	p.transcript = append(p.transcript,
+		executor.TranscriptEntry{Kind: executor.EntryToolUse, Tool: "Edit", ToolUseID: id, Input: string(input), At: at},
+		executor.TranscriptEntry{Kind: executor.EntryToolResult, Tool: "Edit", ToolUseID: id, Text: "Applied edit to " + path, At: at},
+	)
+	p.report(executor.ProgressEvent{Kind: executor.ProgressToolStarted, Tool: "Edit", ToolUseID: id, Input: path})
+	p.report(executor.ProgressEvent{Kind: executor.ProgressToolFinished, Tool: "Edit", ToolUseID: id})
+}
+
+// usage records the tokens and cost Aider reports after each answer. The
+// cost is missing for models Aider knows no price of, e.g. local ones.
+func (p *parser) usage(s string) {
+	p.turns++
+	m := tokensLine.FindStringSubmatch(s)
+	in, out := parseTokens(m[1]), parseTokens(m[2])
+	p.inputTokens += in
+	p.outputTokens += out
+	if c := costPart.FindStringSubmatch(s); c != nil {
+		if cost, err := strconv.ParseFloat(c[2], 64); err == nil {
+			p.costUSD = cost
+		}
+	}
+
+	// The usage belongs to the last answer.
+	for i := len(p.transcript) - 1; i >= 0; i-- {
+		e := &p.transcript[i]
+		if e.Kind == executor.EntryAssistant {
+			if e.InputTokens == 0 && e.OutputTokens == 0 {
+				e.InputTokens, e.OutputTokens = in, out
+			}
+			break
+		}
+	}
+
+	p.report(executor.ProgressEvent{
+		Kind:         executor.ProgressUsage,
+		CostUSD:      p.costUSD,
+		InputTokens:  p.inputTokens,
+		OutputTokens: p.outputTokens,
+	})
+}
+
+func (p *parser) report(e executor.ProgressEvent) {
+	if p.onProgress != nil {
+		p.onProgress(e)
+	}
+}
+
+// result returns the result of the execution, once Aider has exited.
+func (p *parser) result(d time.Duration, failed bool, at time.Time) *executor.Result {
+	p.endAnswer(at)
+	output := strings.Join(p.output, "\n\n")
+	p.transcript = append(p.transcript, executor.TranscriptEntry{
+		Kind:     executor.EntryResult,
+		Text:     truncate(output, maxProgressText),
+		IsError:  failed,
+		Duration: d,
+		At:       at,
+	})
+	return &executor.Result{
+		Output:     output,
+		CostUSD:    p.costUSD,
+		Turns:      p.turns,
+		Duration:   d,
+		Transcript: p.transcript,
+	}
+}
+
+// parseTokens parses a token count as Aider prints it, e.g. "312", "4.2k"
+// or "1.1M".
+func parseTokens(s string) int {
+	s = strings.ReplaceAll(s, ",", "")
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		mult, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1e6, strings.TrimSuffix(s, "M")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(math.Round(n * mult))
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "..."
+}
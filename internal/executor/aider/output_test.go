@@ -0,0 +1,123 @@
+package aider
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+func parseLines(t *testing.T, output string) ([]executor.ProgressEvent, *executor.Result) {
+	t.Helper()
+	var events []executor.ProgressEvent
+	p := newParser("prompt", func(e executor.ProgressEvent) { events = append(events, e) })
+	for _, line := range strings.Split(output, "\n") {
+		p.line(line, time.Now())
+	}
+	return events, p.result(time.Second, false, time.Now())
+}
+
+func TestParser_WhenSeveralAnswers_ReportsEachAndSumsTokens(t *testing.T) {
+	t.Parallel()
+
+	events, result := parseLines(t, `Aider v0.86.1
+Main model: gpt-4o with diff edit format
+
+First answer.
+Tokens: 1.5k sent, 200 received. Cost: $0.01 message, $0.01 session.
+Applied edit to a.go
+
+Second answer,
+on two lines.
+Tokens: 2k sent, 1.2k received. Cost: $0.03 message, $0.04 session.
+Applied edit to b.go
+Applied edit to a.go
+`)
+
+	assert.Equal(t, "First answer.\n\nSecond answer,\non two lines.", result.Output)
+	assert.Equal(t, 2, result.Turns)
+	assert.InDelta(t, 0.04, result.CostUSD, 0.0001)
+
+	var kinds []executor.ProgressKind
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []executor.ProgressKind{
+		executor.ProgressStatus, executor.ProgressStatus,
+		executor.ProgressText, executor.ProgressUsage,
+		executor.ProgressToolStarted, executor.ProgressToolFinished,
+		executor.ProgressText, executor.ProgressUsage,
+		executor.ProgressToolStarted, executor.ProgressToolFinished,
+		executor.ProgressToolStarted, executor.ProgressToolFinished,
+	}, kinds)
+	assert.Equal(t, "Aider v0.86.1", events[0].Text)
+	last := events[7]
+	assert.Equal(t, 3500, last.InputTokens)
+	assert.Equal(t, 1400, last.OutputTokens)
+
+	// The usage of an answer is on its transcript entry.
+	var answers []executor.TranscriptEntry
+	for _, e := range result.Transcript {
+		if e.Kind == executor.EntryAssistant {
+			answers = append(answers, e)
+		}
+	}
+	require.Len(t, answers, 2)
+	assert.Equal(t, 2000, answers[1].InputTokens)
+	assert.Equal(t, 1200, answers[1].OutputTokens)
+}
+
+func TestParser_WhenEditsApplied_RecordsThemAsEditToolCalls(t *testing.T) {
+	t.Parallel()
+
+	_, result := parseLines(t, "Done.\nApplied edit to internal/auth/login.go\n")
+
+	var uses []executor.TranscriptEntry
+	for _, e := range result.Transcript {
+		if e.Kind == executor.EntryToolUse {
+			uses = append(uses, e)
+		}
+	}
+	require.Len(t, uses, 1)
+	assert.Equal(t, "Edit", uses[0].Tool)
+	assert.JSONEq(t, `{"file_path":"internal/auth/login.go"}`, uses[0].Input)
+}
+
+func TestParser_WhenLocalModel_HasNoCost(t *testing.T) {
+	t.Parallel()
+
+	events, result := parseLines(t, "Answer.\nTokens: 812 sent, 95 received.\n")
+
+	assert.Zero(t, result.CostUSD)
+	assert.Equal(t, 1, result.Turns)
+	assert.Contains(t, events, executor.ProgressEvent{Kind: executor.ProgressUsage, InputTokens: 812, OutputTokens: 95})
+}
+
+func TestParser_WhenErrors_ReportsWarnings(t *testing.T) {
+	t.Parallel()
+
+	events, result := parseLines(t, `litellm.APIConnectionError: OllamaException - connection refused
+https://aider.chat/docs/llms/ollama.html
+# 1 SEARCH/REPLACE block failed to match!
+`)
+
+	assert.Equal(t, []executor.ProgressEvent{
+		{Kind: executor.ProgressWarning, Text: "litellm.APIConnectionError: OllamaException - connection refused"},
+		{Kind: executor.ProgressWarning, Text: "# 1 SEARCH/REPLACE block failed to match!"},
+	}, events)
+	assert.Empty(t, result.Output)
+}
+
+func TestParseTokens(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 312, parseTokens("312"))
+	assert.Equal(t, 4200, parseTokens("4.2k"))
+	assert.Equal(t, 1100000, parseTokens("1.1M"))
+	assert.Equal(t, 1234, parseTokens("1,234"))
+	assert.Zero(t, parseTokens("many"))
+}
@@ -0,0 +1,58 @@
+#!/bin/sh
+# A fake aider for tests: it records its arguments and message file, then
+# prints what aider --no-pretty prints while answering a message.
+#
+#   FAKE_AIDER_RECORD   directory to record args and message into
+#   FAKE_AIDER_EXIT     exit with this code after answering
+#   FAKE_AIDER_SLEEP    sleep this many seconds before answering
+#
+# Like aider, it writes its tags cache to the project.
+
+message_file=""
+prev=""
+for arg in "$@"; do
+	if [ "$prev" = "--message-file" ]; then
+		message_file="$arg"
+	fi
+	prev="$arg"
+done
+
+if [ -n "$FAKE_AIDER_RECORD" ]; then
+	printf '%s\n' "$@" > "$FAKE_AIDER_RECORD/args"
+	cat "$message_file" > "$FAKE_AIDER_RECORD/message"
+fi
+
+mkdir -p .aider.tags.cache.v4 && echo tags > .aider.tags.cache.v4/cache.db
+
+if [ -n "$FAKE_AIDER_SLEEP" ]; then
+	sleep "$FAKE_AIDER_SLEEP"
+fi
+
+cat <<'OUTPUT'
+Aider v0.86.1
+Main model: ollama_chat/qwen2.5-coder with diff edit format
+Git repo: .git with 12 files
+Repo-map: using 1024 tokens, auto refresh
+Use /help <question> for help, run "aider --help" to see cmd line args
+
+I'll reject expired tokens in the login handler.
+
+auth/login.go
+```go
+<<<<<<< SEARCH
+	return nil
+=======
+	if token.Expired() {
+		return ErrExpired
+	}
+	return nil
+>>>>>>> REPLACE
+```
+
+Tokens: 4.2k sent, 1.1k cache write, 312 received. Cost: $0.02 message, $0.02 session.
+Applied edit to auth/login.go
+Commit 1a2b3c4 fix: reject expired tokens
+OUTPUT
+
+echo "some diagnostics" >&2
+exit "${FAKE_AIDER_EXIT:-0}"
@@ -42,21 +42,21 @@ type Executor struct {
 // Capabilities returns the feature set supported by Claude Code.
 func (e *Executor) Capabilities() executor.Capabilities {
 	return executor.Capabilities{
-		SupportsSession:  true,
-		SupportsModel:    true,
-		SupportsToolList: true,
-		SupportsDryRun:   true,
-		SupportsStreaming: true,
-		Name:             "claude-code",
-		Version:          "1.0.0",
+		SupportsSession:           true,
+		SupportsModel:             true,
+		SupportsToolList:          true,
+		SupportsDryRun:            true,
+		SupportsStreaming:         true,
+		SupportsMessages:          true,
+		SupportsPermissionPrompts: true,
+		Name:                      "claude-code",
+		Version:                   "1.0.0",
 	}
 }
 
 func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
-	// Write prompt to file (avoids CLI arg length limits)
-	promptPath, err := executor.WritePromptFile(e.WorkDir, req.TaskID, req.Prompt)
-	if err != nil {
-		return nil, fmt.Errorf("writing prompt: %w", err)
+	if err := os.MkdirAll(executor.TaskDir(e.WorkDir, req.TaskID), 0750); err != nil {
+		return nil, fmt.Errorf("creating task directory: %w", err)
 	}
 	defer executor.CleanupPromptFile(e.WorkDir, req.TaskID)
 
@@ -65,6 +65,7 @@ func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress
 		"-p",
 		"--verbose",
 		"--output-format", "stream-json",
+		"--input-format", "stream-json",
 	}
 
 	if req.Model != "" {
@@ -79,6 +80,16 @@ func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress
 		args = append(args, "--allowedTools", tool)
 	}
 
+	if req.Permissions != nil {
+		mcpConfig, err := writePermissionConfig(e.WorkDir, req.TaskID, req.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("writing permission prompt config: %w", err)
+		}
+		args = append(args,
+			"--mcp-config", mcpConfig,
+			"--permission-prompt-tool", permissionPromptTool)
+	}
+
 	cmd := exec.CommandContext(ctx, e.ClaudePath, args...) //nolint:gosec // ClaudePath from trusted config
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	cmd.Cancel = func() error {
@@ -99,24 +110,36 @@ func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress
 		cmd.Env = append(cmd.Env, k+"="+v)
 	}
 
-	// Pipe prompt via stdin
-	promptFile, err := os.Open(promptPath) //nolint:gosec // path built internally by WritePromptFile
+	// The prompt and the follow-up messages are user turns written to
+	// stdin in stream-json (avoids CLI arg length limits). The pipe belongs
+	// to this Herald process: once reattached after a restart, the task
+	// cannot receive messages any more and send_message rejects it.
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("opening stdin: %w", err)
+	}
+
+	// Spool stdout (stream-json) and stderr to files rather than pipes so
+	// Claude Code survives a Herald restart and can be reattached to.
+	stdout, err := os.OpenFile(spoolPath(e.WorkDir, req.TaskID), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // path built internally
 	if err != nil {
-		return nil, fmt.Errorf("opening prompt file: %w", err)
+		return nil, fmt.Errorf("creating stream spool: %w", err)
 	}
-	defer func() { _ = promptFile.Close() }()
-	cmd.Stdin = promptFile
+	defer func() { _ = stdout.Close() }()
+	cmd.Stdout = stdout
 
-	// Capture stdout (stream-json) and stderr
-	stdout, err := cmd.StdoutPipe()
+	stderr, err := os.OpenFile(stderrPath(e.WorkDir, req.TaskID), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // path built internally
 	if err != nil {
-		return nil, fmt.Errorf("creating stdout pipe: %w", err)
+		return nil, fmt.Errorf("creating stderr spool: %w", err)
 	}
+	defer func() { _ = stderr.Close() }()
+	cmd.Stderr = stderr
 
-	stderr, err := cmd.StderrPipe()
+	spool, err := os.Open(spoolPath(e.WorkDir, req.TaskID)) //nolint:gosec // path built internally
 	if err != nil {
-		return nil, fmt.Errorf("creating stderr pipe: %w", err)
+		return nil, fmt.Errorf("opening stream spool: %w", err)
 	}
+	defer func() { _ = spool.Close() }()
 
 	// Start the process
 	start := time.Now()
@@ -129,27 +152,30 @@ func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress
 		"pid", cmd.Process.Pid)
 
 	if onProgress != nil {
-		onProgress("started", fmt.Sprintf("PID %d", cmd.Process.Pid))
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
 	}
 
-	// Parse stream-json output in background goroutines.
-	// All pipe readers must finish before cmd.Wait() which closes the pipes.
+	// Follow the spool in the background until the process has exited
+	// and everything it wrote has been parsed.
 	var wg sync.WaitGroup
 	result := &executor.Result{}
+	exited := make(chan struct{})
+	conv := newConversation(req.TaskID, stdin)
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		parseStream(req.TaskID, stdout, result, onProgress)
+		conv.run(req.Prompt, req.Messages, exited, onProgress)
 	}()
 	go func() {
 		defer wg.Done()
-		captureStderr(req.TaskID, stderr)
+		parseEvents(req.TaskID, &followReader{f: spool, done: exited}, result, onProgress, conv.close)
 	}()
 
-	// Drain all pipes first, then reap the process
-	wg.Wait()
 	waitErr := cmd.Wait()
+	close(exited)
+	wg.Wait()
 	result.Duration = time.Since(start)
+	result.Stderr = e.logStderr(req.TaskID)
 
 	if waitErr != nil {
 		if exitErr, ok := errors.AsType[*exec.ExitError](waitErr); ok {
@@ -172,7 +198,25 @@ func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress
 	return result, nil
 }
 
-func parseStream(taskID string, r io.Reader, result *executor.Result, onProgress executor.ProgressFunc) {
+// parseStream accumulates stream-json events into result, transcript
+// included, and returns the final "result" event, or nil if the stream
+// ended without one. The cost estimated from the usage of each message is
+// reported with ProgressUsage events.
+func parseStream(taskID string, r io.Reader, result *executor.Result, onProgress executor.ProgressFunc) *StreamEvent {
+	return parseEvents(taskID, r, result, onProgress, nil)
+}
+
+// parseEvents is parseStream, calling onResult (when not nil) at each
+// "result" event, that is each time Claude Code has answered a user turn.
+func parseEvents(taskID string, r io.Reader, result *executor.Result, onProgress executor.ProgressFunc, onResult func()) *StreamEvent {
+	var final *StreamEvent
+	// Claude Code repeats the usage of a message on every event of the
+	// message, so the estimated cost is kept per message ID.
+	costs := make(map[string]float64)
+	usages := make(map[string]Usage)
+	var estimated float64
+	var total Usage
+	tr := newTranscriber()
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024) // 10MB max line
 
@@ -185,12 +229,33 @@ func parseStream(taskID string, r io.Reader, result *executor.Result, onProgress
 		if event == nil {
 			continue
 		}
+		seen := len(tr.entries)
+		tr.add(event, time.Now())
 
 		switch event.Type {
 		case "system":
 			if event.Subtype == "init" && event.SessionID != "" {
 				result.SessionID = event.SessionID
 				slog.Debug("session initialized", "task_id", taskID, "session_id", event.SessionID)
+				if onProgress != nil {
+					onProgress(executor.ProgressEvent{Kind: executor.ProgressSession, SessionID: event.SessionID})
+				}
+			}
+
+		case "user":
+			if onProgress == nil {
+				continue
+			}
+			for _, e := range tr.entries[seen:] {
+				if e.Kind == executor.EntryToolResult && e.Tool != "" {
+					onProgress(executor.ProgressEvent{
+						Kind:      executor.ProgressToolFinished,
+						Tool:      e.Tool,
+						ToolUseID: e.ToolUseID,
+						IsError:   e.IsError,
+						Duration:  e.Duration,
+					})
+				}
 			}
 
 		case "assistant":
@@ -199,34 +264,80 @@ func parseStream(taskID string, r io.Reader, result *executor.Result, onProgress
 				result.Output += output
 			}
 			if onProgress != nil {
-				if progress := ExtractProgress(event); progress != "" {
-					onProgress("progress", progress)
+				for _, progress := range ExtractProgress(event) {
+					onProgress(progress)
+				}
+			}
+			if event.Message != nil && event.Message.Usage != nil {
+				id := event.Message.ID
+				if id == "" {
+					id = fmt.Sprintf("#%d", len(costs))
+				}
+				estimated -= costs[id]
+				costs[id] = estimateCost(event.Message.Model, event.Message.Usage)
+				estimated += costs[id]
+				total = total.add(usages[id], -1).add(*event.Message.Usage, 1)
+				usages[id] = *event.Message.Usage
+				if onProgress != nil {
+					onProgress(executor.ProgressEvent{
+						Kind:         executor.ProgressUsage,
+						CostUSD:      estimated,
+						InputTokens:  total.inputTokens(),
+						OutputTokens: total.OutputTokens,
+					})
 				}
 			}
 
 		case "result":
+			final = event
 			result.CostUSD = event.CostUSD
 			result.Turns = event.NumTurns
 			if event.Duration > 0 {
 				result.Duration = time.Duration(event.Duration) * time.Millisecond
 			}
+			if onResult != nil {
+				onResult()
+			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		slog.Warn("stream scanner error", "task_id", taskID, "error", err)
+		if onProgress != nil {
+			onProgress(executor.ProgressEvent{Kind: executor.ProgressWarning, Text: fmt.Sprintf("reading claude output: %s", err)})
+		}
 	}
+	result.Transcript = tr.entries
+	return final
 }
 
-func captureStderr(taskID string, r io.Reader) {
+// logStderr logs whatever the process wrote to its stderr spool and returns
+// its last maxStderrSize bytes.
+func (e *Executor) logStderr(taskID string) string {
+	f, err := os.Open(stderrPath(e.WorkDir, taskID)) //nolint:gosec // path built internally
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+	return captureStderr(taskID, f)
+}
+
+// maxStderrSize bounds the stderr kept on a Result.
+const maxStderrSize = 4096
+
+func captureStderr(taskID string, r io.Reader) string {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		slog.Debug("stderr read error", "task_id", taskID, "error", err)
-		return
+		return ""
 	}
 	if len(data) > 0 {
 		slog.Debug("claude stderr", "task_id", taskID, "stderr", truncateStr(string(data), 500))
 	}
+	if len(data) > maxStderrSize {
+		data = data[len(data)-maxStderrSize:]
+	}
+	return string(data)
 }
 
 func truncateStr(s string, max int) string {
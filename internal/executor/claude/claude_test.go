@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,6 +36,8 @@ func TestCapabilities_ReturnsExpectedValues(t *testing.T) {
 	assert.True(t, caps.SupportsToolList)
 	assert.True(t, caps.SupportsDryRun)
 	assert.True(t, caps.SupportsStreaming)
+	assert.True(t, caps.SupportsMessages)
+	assert.True(t, caps.SupportsPermissionPrompts)
 }
 
 func TestRegistration_ClaudeCodeIsAvailable(t *testing.T) {
@@ -107,7 +110,11 @@ func TestParseStream_WhenMixedValidAndInvalid_ProcessesValidLines(t *testing.T)
 
 	result := &executor.Result{}
 	var progressCalls int
-	onProgress := func(_, _ string) { progressCalls++ }
+	onProgress := func(e executor.ProgressEvent) {
+		if e.Kind == executor.ProgressText {
+			progressCalls++
+		}
+	}
 
 	parseStream("test-task", strings.NewReader(stream), result, onProgress)
 
@@ -130,7 +137,7 @@ func TestParseStream_WhenMultipleAssistantEvents_AccumulatesOutput(t *testing.T)
 
 	result := &executor.Result{}
 	var progressMsgs []string
-	onProgress := func(_, msg string) { progressMsgs = append(progressMsgs, msg) }
+	onProgress := func(e executor.ProgressEvent) { progressMsgs = append(progressMsgs, e.String()) }
 
 	parseStream("test-task", strings.NewReader(stream), result, onProgress)
 
@@ -139,6 +146,61 @@ func TestParseStream_WhenMultipleAssistantEvents_AccumulatesOutput(t *testing.T)
 	assert.Contains(t, progressMsgs, "Using tool: Read")
 }
 
+func TestParseStream_WhenAssistantUsage_ReportsEstimatedCost(t *testing.T) {
+	t.Parallel()
+
+	// Claude Code repeats a message's usage on each of its content blocks.
+	stream := strings.Join([]string{
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Reading."}],"usage":{"input_tokens":1000,"output_tokens":100}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"tool_use","name":"Read"}],"usage":{"input_tokens":1000,"output_tokens":200}}}`,
+		`{"type":"assistant","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Done."}],"usage":{"input_tokens":0,"cache_read_input_tokens":10000,"output_tokens":1000}}}`,
+		`{"type":"result","subtype":"success","cost_usd":0.05,"num_turns":2}`,
+	}, "\n")
+
+	result := &executor.Result{}
+	var costs []float64
+	var tokens []int
+	onProgress := func(e executor.ProgressEvent) {
+		if e.Kind == executor.ProgressUsage {
+			costs = append(costs, e.CostUSD)
+			tokens = append(tokens, e.InputTokens+e.OutputTokens)
+		}
+	}
+
+	parseStream("test-task", strings.NewReader(stream), result, onProgress)
+
+	// msg_1: 1000 in + 200 out; msg_2: 10000 cached in + 1000 out.
+	require.Len(t, costs, 3)
+	assert.InDelta(t, 0.0045, costs[0], 1e-9)
+	assert.InDelta(t, 0.006, costs[1], 1e-9)
+	assert.InDelta(t, 0.024, costs[2], 1e-9)
+	assert.Equal(t, []int{1100, 1200, 12200}, tokens)
+	assert.InDelta(t, 0.05, result.CostUSD, 0.0001, "the final result has the exact cost")
+}
+
+func TestEstimateCost_UsesModelPrices(t *testing.T) {
+	t.Parallel()
+
+	usage := &Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000}
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"claude-sonnet-4-5-20250929", 18},
+		{"claude-opus-4-6", 30},
+		{"claude-opus-4-1-20250805", 90},
+		{"claude-haiku-4-5", 6},
+		{"some-future-model", 18},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, estimateCost(tt.model, usage), 0.0001, tt.model)
+	}
+
+	cached := &Usage{CacheCreationInputTokens: 1_000_000, CacheReadInputTokens: 1_000_000}
+	assert.InDelta(t, 3*1.25+3*0.1, estimateCost("claude-sonnet-4-5", cached), 0.0001)
+	assert.Zero(t, estimateCost("claude-sonnet-4-5", nil))
+}
+
 func TestParseStream_WhenSystemNotInit_IgnoresSessionID(t *testing.T) {
 	t.Parallel()
 
@@ -198,6 +260,14 @@ func TestCaptureStderr_WhenDataPresent_DoesNotPanic(t *testing.T) {
 	})
 }
 
+func TestCaptureStderr_WhenLarge_KeepsTheEnd(t *testing.T) {
+	t.Parallel()
+	data := strings.Repeat("x", maxStderrSize) + "overloaded_error"
+	got := captureStderr("test-task", strings.NewReader(data))
+	assert.Len(t, got, maxStderrSize)
+	assert.True(t, strings.HasSuffix(got, "overloaded_error"))
+}
+
 func TestCaptureStderr_WhenEmpty_DoesNotPanic(t *testing.T) {
 	t.Parallel()
 
@@ -233,8 +303,8 @@ STREAM
 	}
 
 	var progressMsgs []string
-	onProgress := func(eventType, msg string) {
-		progressMsgs = append(progressMsgs, eventType+":"+msg)
+	onProgress := func(e executor.ProgressEvent) {
+		progressMsgs = append(progressMsgs, string(e.Kind)+":"+e.String())
 	}
 
 	result, err := exec.Execute(context.Background(), req, onProgress)
@@ -247,6 +317,108 @@ STREAM
 	assert.Equal(t, 0, result.ExitCode)
 }
 
+func TestExecute_WhenMessagesSent_WritesThemAsUserTurns(t *testing.T) {
+	t.Parallel()
+
+	// The mock reads the prompt and one message, answers, then waits for
+	// stdin to be closed as Claude Code does.
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "conversation_claude.sh")
+	inputPath := filepath.Join(tmpDir, "input.jsonl")
+	script := `#!/bin/sh
+echo "$@" > "` + tmpDir + `/args"
+read -r prompt
+read -r message
+printf '%s\n%s\n' "$prompt" "$message" > "` + inputPath + `"
+echo '{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Kept the old names."}]}}'
+echo '{"type":"result","subtype":"success","cost_usd":0.05,"num_turns":2}'
+cat > /dev/null
+`
+	writeTestScript(t, scriptPath, script)
+
+	exec := &Executor{
+		ClaudePath: scriptPath,
+		WorkDir:    tmpDir,
+	}
+
+	messages := make(chan string, 1)
+	messages <- "keep the old names as aliases"
+	req := executor.Request{
+		TaskID:      "herald-msg01",
+		Prompt:      "rename the handlers",
+		ProjectPath: tmpDir,
+		Messages:    messages,
+	}
+
+	var mu sync.Mutex
+	var progressMsgs []string
+	onProgress := func(e executor.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		progressMsgs = append(progressMsgs, string(e.Kind)+":"+e.String())
+	}
+
+	result, err := exec.Execute(context.Background(), req, onProgress)
+	require.NoError(t, err)
+	assert.Contains(t, result.Output, "Kept the old names.")
+
+	args, err := os.ReadFile(filepath.Join(tmpDir, "args")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "--input-format stream-json")
+
+	input, err := os.ReadFile(inputPath) //nolint:gosec // test file
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(input)), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"rename the handlers"}]}}`, lines[0])
+	assert.JSONEq(t, `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"keep the old names as aliases"}]}}`, lines[1])
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, progressMsgs, "status:message delivered: keep the old names as aliases")
+}
+
+func TestExecute_WhenPermissionsForwarded_RegistersApprovalTool(t *testing.T) {
+	t.Parallel()
+
+	// The mock copies its MCP config aside: the task directory is removed
+	// once Execute returns.
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "permissions_claude.sh")
+	script := `#!/bin/sh
+echo "$@" > "` + tmpDir + `/args"
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--mcp-config" ]; then cp "$2" "` + tmpDir + `/mcp.json"; fi
+  shift
+done
+echo '{"type":"result","subtype":"success","cost_usd":0.01,"num_turns":1}'
+`
+	writeTestScript(t, scriptPath, script)
+
+	exec := &Executor{
+		ClaudePath: scriptPath,
+		WorkDir:    tmpDir,
+	}
+
+	req := executor.Request{
+		TaskID:      "herald-perm01",
+		Prompt:      "clean up",
+		ProjectPath: tmpDir,
+		Permissions: &executor.PermissionPrompt{URL: "http://127.0.0.1:8420/permissions/mcp", Token: "tok123"},
+	}
+
+	_, err := exec.Execute(context.Background(), req, nil)
+	require.NoError(t, err)
+
+	args, err := os.ReadFile(filepath.Join(tmpDir, "args")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "--permission-prompt-tool mcp__herald__approval_prompt")
+
+	mcpConfig, err := os.ReadFile(filepath.Join(tmpDir, "mcp.json")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"mcpServers":{"herald":{"type":"http","url":"http://127.0.0.1:8420/permissions/mcp","headers":{"Authorization":"Bearer tok123"}}}}`, string(mcpConfig))
+}
+
 func TestExecute_WhenCommandFails_ReturnsErrorWithExitCode(t *testing.T) {
 	t.Parallel()
 
@@ -415,6 +587,7 @@ echo '{"type":"result","subtype":"success","cost_usd":0.01,"num_turns":1}'
 	result, err := exec.Execute(context.Background(), req, nil)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
+	assert.Equal(t, "debug info\n", result.Stderr)
 }
 
 func TestExecute_WhenProgressFuncProvided_ReceivesStartedEvent(t *testing.T) {
@@ -439,15 +612,15 @@ echo '{"type":"result","subtype":"success","cost_usd":0.01,"num_turns":1}'
 		ProjectPath: tmpDir,
 	}
 
-	var events []string
-	onProgress := func(eventType, msg string) {
-		events = append(events, eventType)
+	var events []executor.ProgressKind
+	onProgress := func(e executor.ProgressEvent) {
+		events = append(events, e.Kind)
 	}
 
 	result, err := exec.Execute(context.Background(), req, onProgress)
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Contains(t, events, "started")
+	assert.Contains(t, events, executor.ProgressStarted)
 }
 
 func TestExecute_WhenModelProvided_PassesModelFlag(t *testing.T) {
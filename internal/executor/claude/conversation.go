@@ -0,0 +1,101 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// errConversationClosed is returned when a message arrives after Claude
+// Code has answered and its stdin was closed.
+var errConversationClosed = errors.New("conversation closed")
+
+// conversation writes the user turns of a task to Claude Code's stream-json
+// input: the prompt first, then the messages sent while the task runs.
+// Stdin is closed at the first result event; Claude Code still answers the
+// messages it has read by then, then exits.
+type conversation struct {
+	taskID string
+
+	mu     sync.Mutex
+	stdin  io.WriteCloser
+	closed bool
+}
+
+func newConversation(taskID string, stdin io.WriteCloser) *conversation {
+	return &conversation{taskID: taskID, stdin: stdin}
+}
+
+// run sends the prompt, then each message as it arrives, until the process
+// has exited.
+func (c *conversation) run(prompt string, messages <-chan string, exited <-chan struct{}, onProgress executor.ProgressFunc) {
+	if err := c.send(prompt); err != nil {
+		slog.Warn("failed to write prompt to claude", "task_id", c.taskID, "error", err)
+		return
+	}
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			if err := c.send(msg); err != nil {
+				slog.Warn("message not delivered", "task_id", c.taskID, "error", err)
+				if onProgress != nil {
+					onProgress(executor.ProgressEvent{
+						Kind: executor.ProgressWarning,
+						Text: "message not delivered, the task is finishing: " + truncateStr(msg, 100),
+					})
+				}
+				continue
+			}
+			slog.Info("message delivered", "task_id", c.taskID)
+			if onProgress != nil {
+				onProgress(executor.ProgressEvent{Kind: executor.ProgressStatus, Text: "message delivered: " + truncateStr(msg, 100)})
+			}
+		case <-exited:
+			return
+		}
+	}
+}
+
+// send writes text as a user turn.
+func (c *conversation) send(text string) error {
+	line, err := json.Marshal(StreamEvent{
+		Type: "user",
+		Message: &StreamMessage{
+			Role:    "user",
+			Content: []ContentBlock{{Type: "text", Text: text}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding user turn: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errConversationClosed
+	}
+	if _, err := c.stdin.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing user turn: %w", err)
+	}
+	return nil
+}
+
+// close ends the input so Claude Code exits once it has answered.
+func (c *conversation) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		_ = c.stdin.Close()
+	}
+}
@@ -0,0 +1,81 @@
+package claude
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// bufferCloser is an in-memory stdin that records whether it was closed.
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestConversation_Send_WritesUserTurnPerLine(t *testing.T) {
+	t.Parallel()
+
+	stdin := &bufferCloser{}
+	c := newConversation("herald-conv01", stdin)
+
+	require.NoError(t, c.send("first"))
+	require.NoError(t, c.send("second\nline"))
+
+	lines := bytes.Split(bytes.TrimSpace(stdin.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2, "newlines in messages are escaped")
+	assert.JSONEq(t, `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"second\nline"}]}}`, string(lines[1]))
+}
+
+func TestConversation_Send_WhenClosed_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	stdin := &bufferCloser{}
+	c := newConversation("herald-conv02", stdin)
+	c.close()
+	c.close()
+
+	assert.True(t, stdin.closed)
+	assert.ErrorIs(t, c.send("too late"), errConversationClosed)
+	assert.Zero(t, stdin.Len())
+}
+
+func TestConversation_Run_WhenClosed_ReportsMessageNotDelivered(t *testing.T) {
+	t.Parallel()
+
+	stdin := &bufferCloser{}
+	c := newConversation("herald-conv03", stdin)
+
+	messages := make(chan string, 1)
+	exited := make(chan struct{})
+	var progress []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.run("prompt", messages, exited, func(e executor.ProgressEvent) {
+			progress = append(progress, string(e.Kind)+":"+e.String())
+			close(exited)
+		})
+	}()
+
+	// Wait for the prompt before closing, as a result event would.
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return stdin.Len() > 0
+	}, time.Second, time.Millisecond)
+	c.close()
+	messages <- "fix the tests too"
+	<-done
+
+	assert.Equal(t, []string{"warning:message not delivered, the task is finishing: fix the tests too"}, progress)
+}
@@ -0,0 +1,50 @@
+package claude
+
+import "strings"
+
+// modelPrice is the list price of a model family in USD per million tokens.
+type modelPrice struct {
+	family string
+	input  float64
+	output float64
+}
+
+// modelPrices is matched in order against the model name, so specific
+// versions come before their family.
+var modelPrices = []modelPrice{
+	{"opus-4-5", 5, 25},
+	{"opus-4-6", 5, 25},
+	{"opus", 15, 75},
+	{"sonnet", 3, 15},
+	{"haiku", 1, 5},
+}
+
+// defaultPrice applies to models missing from modelPrices.
+var defaultPrice = modelPrice{family: "sonnet", input: 3, output: 15}
+
+// Cache writes cost 1.25 times the input price, cache reads a tenth of it.
+const (
+	cacheWriteFactor = 1.25
+	cacheReadFactor  = 0.1
+)
+
+// estimateCost returns the approximate cost in USD of an assistant message.
+// Claude Code only reports the exact cost in its final result event, so the
+// estimate lets cost budgets be enforced while a task runs.
+func estimateCost(model string, u *Usage) float64 {
+	if u == nil {
+		return 0
+	}
+	price := defaultPrice
+	for _, p := range modelPrices {
+		if strings.Contains(model, p.family) {
+			price = p
+			break
+		}
+	}
+
+	input := float64(u.InputTokens) +
+		float64(u.CacheCreationInputTokens)*cacheWriteFactor +
+		float64(u.CacheReadInputTokens)*cacheReadFactor
+	return (input*price.input + float64(u.OutputTokens)*price.output) / 1e6
+}
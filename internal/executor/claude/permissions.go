@@ -0,0 +1,45 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// permissionServer is the name Herald's approval endpoint is registered
+// under in the MCP config handed to Claude Code.
+const permissionServer = "herald"
+
+// permissionPromptTool is the --permission-prompt-tool value: Claude Code
+// names MCP tools mcp__<server>__<tool>.
+const permissionPromptTool = "mcp__" + permissionServer + "__" + executor.PermissionToolName
+
+// writePermissionConfig writes the MCP config that points Claude Code at
+// Herald's approval endpoint and returns its path. The file holds the
+// task's token, so it is only readable by its owner.
+func writePermissionConfig(workDir, taskID string, p *executor.PermissionPrompt) (string, error) {
+	cfg := map[string]any{
+		"mcpServers": map[string]any{
+			permissionServer: map[string]any{
+				"type": "http",
+				"url":  p.URL,
+				"headers": map[string]string{
+					"Authorization": "Bearer " + p.Token,
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("encoding mcp config: %w", err)
+	}
+
+	path := filepath.Join(executor.TaskDir(workDir, taskID), "permissions.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("writing mcp config: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,155 @@
+package claude
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+const (
+	spoolFileName  = "stream.jsonl"
+	stderrFileName = "stderr.log"
+
+	// spoolPollInterval is how often a follower checks for new spool data
+	// and whether a reattached process is still alive.
+	spoolPollInterval = 200 * time.Millisecond
+
+	// orphanKillGrace is how long a reattached process gets after SIGTERM
+	// before its process group is killed.
+	orphanKillGrace = 10 * time.Second
+)
+
+// spoolPath returns the file Claude Code writes its stream-json output to.
+// Claude Code writes straight to this file rather than to a pipe so that it
+// keeps running, and its output stays readable, if Herald dies.
+func spoolPath(workDir, taskID string) string {
+	return filepath.Join(executor.TaskDir(workDir, taskID), spoolFileName)
+}
+
+func stderrPath(workDir, taskID string) string {
+	return filepath.Join(executor.TaskDir(workDir, taskID), stderrFileName)
+}
+
+// followReader reads a file that is still being written. At EOF it waits
+// for more data until done is closed, then drains what remains and
+// reports io.EOF.
+type followReader struct {
+	f    *os.File
+	done <-chan struct{}
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.f.Read(p)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+
+		select {
+		case <-r.done:
+			// The writer is gone; one last read catches anything written
+			// between the previous read and its exit.
+			n, err = r.f.Read(p)
+			if n > 0 {
+				return n, nil
+			}
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, err
+		case <-time.After(spoolPollInterval):
+		}
+	}
+}
+
+// Reattach resumes monitoring a Claude Code process started by a previous
+// Herald instance. If the process is alive, its spool file is tailed until
+// it exits; otherwise the task is finalized from the spool contents.
+// Cancelling ctx terminates the process group. The stdin of the process
+// went away with the previous instance, so no message reaches it.
+func (e *Executor) Reattach(ctx context.Context, req executor.ReattachRequest, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	path := spoolPath(e.WorkDir, req.TaskID)
+	f, err := os.Open(path) //nolint:gosec // path built internally from work dir and task ID
+	if err != nil {
+		return nil, fmt.Errorf("%w: opening spool: %s", executor.ErrNotReattachable, err)
+	}
+	defer func() { _ = f.Close() }()
+	defer executor.CleanupPromptFile(e.WorkDir, req.TaskID)
+
+	alive := executor.ProcessGroupAlive(req.PID)
+	slog.Info("reattaching to claude code",
+		"task_id", req.TaskID,
+		"pid", req.PID,
+		"alive", alive)
+
+	exited := make(chan struct{})
+	if alive {
+		go watchOrphan(ctx, req.PID, exited)
+	} else {
+		close(exited)
+	}
+
+	start := time.Now()
+	result := &executor.Result{}
+	final := parseStream(req.TaskID, &followReader{f: f, done: exited}, result, onProgress)
+	if result.Duration == 0 {
+		result.Duration = time.Since(start)
+	}
+
+	result.Stderr = e.logStderr(req.TaskID)
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	switch {
+	case final == nil:
+		result.ExitCode = -1
+		return result, fmt.Errorf("claude process %d exited without reporting a result", req.PID)
+	case final.IsError:
+		result.ExitCode = 1
+		return result, fmt.Errorf("claude reported an error result (%s)", final.Subtype)
+	}
+
+	slog.Info("reattached claude code completed",
+		"task_id", req.TaskID,
+		"cost_usd", result.CostUSD,
+		"turns", result.Turns)
+
+	return result, nil
+}
+
+// watchOrphan polls a process Herald is not the parent of (so it cannot
+// Wait on it) and closes exited once it is gone. When ctx is cancelled the
+// process group gets SIGTERM, then SIGKILL after a grace period.
+func watchOrphan(ctx context.Context, pid int, exited chan<- struct{}) {
+	defer close(exited)
+
+	ticker := time.NewTicker(spoolPollInterval)
+	defer ticker.Stop()
+
+	var killDeadline <-chan time.Time
+	stopping := ctx.Done()
+	for {
+		select {
+		case <-stopping:
+			_ = syscall.Kill(-pid, syscall.SIGTERM)
+			killDeadline = time.After(orphanKillGrace)
+			stopping = nil
+		case <-killDeadline:
+			_ = syscall.Kill(-pid, syscall.SIGKILL)
+			killDeadline = nil
+		case <-ticker.C:
+			if !executor.ProcessGroupAlive(pid) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package claude
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+func writeSpool(t *testing.T, workDir, taskID, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(executor.TaskDir(workDir, taskID), 0750))
+	require.NoError(t, os.WriteFile(spoolPath(workDir, taskID), []byte(content), 0600))
+}
+
+func TestReattach_WhenSpoolMissing_ReturnsErrNotReattachable(t *testing.T) {
+	t.Parallel()
+
+	exec := &Executor{WorkDir: t.TempDir()}
+
+	_, err := exec.Reattach(context.Background(), executor.ReattachRequest{TaskID: "herald-gone01", PID: 0}, nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, executor.ErrNotReattachable))
+}
+
+func TestReattach_WhenProcessExited_ParsesSpool(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	writeSpool(t, workDir, "herald-done01", `{"type":"system","subtype":"init","session_id":"ses_orphan"}
+{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Finished while Herald was down."}]}}
+{"type":"result","subtype":"success","cost_usd":0.4,"duration_ms":9000,"num_turns":3}
+`)
+
+	exec := &Executor{WorkDir: workDir}
+	result, err := exec.Reattach(context.Background(), executor.ReattachRequest{TaskID: "herald-done01", PID: 0}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ses_orphan", result.SessionID)
+	assert.Equal(t, "Finished while Herald was down.", result.Output)
+	assert.InDelta(t, 0.4, result.CostUSD, 0.001)
+	assert.Equal(t, 3, result.Turns)
+
+	_, statErr := os.Stat(executor.TaskDir(workDir, "herald-done01"))
+	assert.True(t, os.IsNotExist(statErr), "task directory should be cleaned up")
+}
+
+func TestReattach_WhenSpoolHasNoResult_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	writeSpool(t, workDir, "herald-died01", `{"type":"system","subtype":"init","session_id":"ses_died"}
+`)
+
+	exec := &Executor{WorkDir: workDir}
+	result, err := exec.Reattach(context.Background(), executor.ReattachRequest{TaskID: "herald-died01", PID: 0}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "without reporting a result")
+	assert.Equal(t, "ses_died", result.SessionID)
+}
+
+func TestReattach_WhenResultIsError_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	writeSpool(t, workDir, "herald-err01", `{"type":"result","subtype":"error_max_turns","is_error":true,"num_turns":50}
+`)
+
+	exec := &Executor{WorkDir: workDir}
+	_, err := exec.Reattach(context.Background(), executor.ReattachRequest{TaskID: "herald-err01", PID: 0}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error_max_turns")
+}
+
+func TestReattach_WhenProcessAlive_FollowsSpoolUntilExit(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	taskID := "herald-live01"
+	writeSpool(t, workDir, taskID, `{"type":"system","subtype":"init","session_id":"ses_live"}
+`)
+
+	// Simulate a Claude Code process left behind by a previous Herald:
+	// its own process group, appending to the spool after a delay.
+	script := filepath.Join(t.TempDir(), "orphan.sh")
+	writeTestScript(t, script, `#!/bin/sh
+sleep 0.5
+echo '{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"late output"}]}}' >> "$1"
+echo '{"type":"result","subtype":"success","cost_usd":0.1,"num_turns":1}' >> "$1"
+`)
+	cmd := exec.Command(script, spoolPath(workDir, taskID)) //nolint:gosec // test script
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+	// Reap the child so it does not linger as a zombie; a real orphan is
+	// reaped by init.
+	go func() { _ = cmd.Wait() }()
+
+	var progress []string
+	onProgress := func(e executor.ProgressEvent) { progress = append(progress, e.String()) }
+
+	e := &Executor{WorkDir: workDir}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := e.Reattach(ctx, executor.ReattachRequest{TaskID: taskID, PID: cmd.Process.Pid}, onProgress)
+	require.NoError(t, err)
+	assert.Equal(t, "ses_live", result.SessionID)
+	assert.Equal(t, "late output", result.Output)
+	assert.Contains(t, progress, "late output")
+}
+
+func TestReattach_WhenContextCancelled_KillsProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	workDir := t.TempDir()
+	taskID := "herald-kill01"
+	writeSpool(t, workDir, taskID, "")
+
+	script := filepath.Join(t.TempDir(), "orphan.sh")
+	writeTestScript(t, script, "#!/bin/sh\nsleep 60\n")
+	cmd := exec.Command(script) //nolint:gosec // test script
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, cmd.Start())
+	waited := make(chan struct{})
+	go func() { _ = cmd.Wait(); close(waited) }()
+
+	e := &Executor{WorkDir: workDir}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	_, err := e.Reattach(ctx, executor.ReattachRequest{TaskID: taskID, PID: cmd.Process.Pid}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case <-waited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("orphaned process was not terminated")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+
+	"github.com/btouchard/herald/internal/executor"
 )
 
 // StreamEvent represents a single line from Claude Code's stream-json output.
@@ -16,20 +18,58 @@ type StreamEvent struct {
 	CostUSD   float64        `json:"cost_usd,omitempty"`
 	Duration  int64          `json:"duration_ms,omitempty"`
 	NumTurns  int            `json:"num_turns,omitempty"`
+	IsError   bool           `json:"is_error,omitempty"`
+	Result    string         `json:"result,omitempty"`
 }
 
-// StreamMessage wraps the assistant's message in a stream event.
+// StreamMessage wraps the assistant's message, or the tool results sent
+// back to it, in a stream event.
 type StreamMessage struct {
+	ID      string         `json:"id,omitempty"`
 	Role    string         `json:"role"`
+	Model   string         `json:"model,omitempty"`
 	Content []ContentBlock `json:"content"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// Usage is the token count of an assistant message.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// inputTokens returns the input tokens of u, cached ones included.
+func (u Usage) inputTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// add returns u plus sign times o.
+func (u Usage) add(o Usage, sign int) Usage {
+	return Usage{
+		InputTokens:              u.InputTokens + sign*o.InputTokens,
+		OutputTokens:             u.OutputTokens + sign*o.OutputTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens + sign*o.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens + sign*o.CacheReadInputTokens,
+	}
 }
 
-// ContentBlock is a piece of content in an assistant message.
+// ContentBlock is a piece of content in a message: text, thinking, a tool
+// call or its result.
 type ContentBlock struct {
-	Type  string          `json:"type"`
-	Text  string          `json:"text,omitempty"`
-	Name  string          `json:"name,omitempty"`
-	Input json.RawMessage `json:"input,omitempty"`
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	Thinking string          `json:"thinking,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
+
+	// Set on tool_result blocks. Content is a string or a list of
+	// content blocks.
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
 }
 
 // ParseStreamLine parses a single line of stream-json output.
@@ -51,22 +91,53 @@ func ParseStreamLine(line []byte) (*StreamEvent, error) {
 	return &event, nil
 }
 
-// ExtractProgress returns a human-readable progress hint from an event.
-func ExtractProgress(event *StreamEvent) string {
+// ExtractProgress returns the progress events of an assistant event: its
+// text, its tool calls and the todo lists it writes.
+func ExtractProgress(event *StreamEvent) []executor.ProgressEvent {
 	if event.Message == nil {
-		return ""
+		return nil
 	}
 
+	var events []executor.ProgressEvent
 	for _, block := range event.Message.Content {
 		switch block.Type {
 		case "text":
-			return truncateStr(block.Text, 200)
+			events = append(events, executor.ProgressEvent{Kind: executor.ProgressText, Text: truncateStr(block.Text, 200)})
 		case "tool_use":
-			return fmt.Sprintf("Using tool: %s", block.Name)
+			if todos, ok := parseTodos(block); ok {
+				events = append(events, executor.ProgressEvent{Kind: executor.ProgressTodos, Todos: todos})
+				continue
+			}
+			events = append(events, executor.ProgressEvent{
+				Kind:      executor.ProgressToolStarted,
+				Tool:      block.Name,
+				ToolUseID: block.ID,
+				Input:     executor.SummarizeInput(block.Input),
+			})
 		}
 	}
+	return events
+}
 
-	return ""
+// parseTodos returns the todo list written by a TodoWrite tool call.
+func parseTodos(block ContentBlock) ([]executor.Todo, bool) {
+	if block.Name != "TodoWrite" {
+		return nil, false
+	}
+	var input struct {
+		Todos []struct {
+			Content string `json:"content"`
+			Status  string `json:"status"`
+		} `json:"todos"`
+	}
+	if err := json.Unmarshal(block.Input, &input); err != nil {
+		return nil, false
+	}
+	todos := make([]executor.Todo, 0, len(input.Todos))
+	for _, t := range input.Todos {
+		todos = append(todos, executor.Todo{Content: t.Content, Status: t.Status})
+	}
+	return todos, true
 }
 
 // ExtractOutput collects all text content from an event.
@@ -1,6 +1,7 @@
 package claude
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -141,7 +142,9 @@ func TestExtractProgress_WhenTextContent_ReturnsTruncated(t *testing.T) {
 	}
 
 	progress := ExtractProgress(event)
-	assert.Equal(t, "I'll fix this bug now.", progress)
+	require.Len(t, progress, 1)
+	assert.Equal(t, executor.ProgressText, progress[0].Kind)
+	assert.Equal(t, "I'll fix this bug now.", progress[0].Text)
 }
 
 func TestExtractProgress_WhenLongText_Truncates(t *testing.T) {
@@ -157,8 +160,9 @@ func TestExtractProgress_WhenLongText_Truncates(t *testing.T) {
 	}
 
 	progress := ExtractProgress(event)
-	assert.Len(t, progress, 203) // 200 + "..."
-	assert.True(t, strings.HasSuffix(progress, "..."))
+	require.Len(t, progress, 1)
+	assert.Len(t, progress[0].Text, 203) // 200 + "..."
+	assert.True(t, strings.HasSuffix(progress[0].Text, "..."))
 }
 
 func TestExtractProgress_WhenToolUse_ReturnsToolName(t *testing.T) {
@@ -173,7 +177,50 @@ func TestExtractProgress_WhenToolUse_ReturnsToolName(t *testing.T) {
 	}
 
 	progress := ExtractProgress(event)
-	assert.Equal(t, "Using tool: Edit", progress)
+	require.Len(t, progress, 1)
+	assert.Equal(t, "Using tool: Edit", progress[0].String())
+}
+
+func TestExtractProgress_WhenToolUseWithInput_SummarizesIt(t *testing.T) {
+	t.Parallel()
+
+	event := &StreamEvent{
+		Message: &StreamMessage{
+			Content: []ContentBlock{
+				{Type: "text", Text: "Running the tests."},
+				{Type: "tool_use", ID: "toolu_1", Name: "Bash", Input: json.RawMessage(`{"command":"go test ./...","description":"Run tests"}`)},
+			},
+		},
+	}
+
+	progress := ExtractProgress(event)
+	require.Len(t, progress, 2)
+	assert.Equal(t, executor.ProgressToolStarted, progress[1].Kind)
+	assert.Equal(t, "toolu_1", progress[1].ToolUseID)
+	assert.Equal(t, "Using tool: Bash — go test ./...", progress[1].String())
+}
+
+func TestExtractProgress_WhenTodoWrite_ReportsTodos(t *testing.T) {
+	t.Parallel()
+
+	event := &StreamEvent{
+		Message: &StreamMessage{
+			Content: []ContentBlock{
+				{Type: "tool_use", Name: "TodoWrite", Input: json.RawMessage(`{"todos":[` +
+					`{"content":"Fix the handler","status":"completed","activeForm":"Fixing the handler"},` +
+					`{"content":"Run tests","status":"in_progress","activeForm":"Running tests"}]}`)},
+			},
+		},
+	}
+
+	progress := ExtractProgress(event)
+	require.Len(t, progress, 1)
+	assert.Equal(t, executor.ProgressTodos, progress[0].Kind)
+	assert.Equal(t, []executor.Todo{
+		{Content: "Fix the handler", Status: executor.TodoCompleted},
+		{Content: "Run tests", Status: executor.TodoInProgress},
+	}, progress[0].Todos)
+	assert.Equal(t, "Todos: 1/2 done — Run tests", progress[0].String())
 }
 
 func TestExtractProgress_WhenNoMessage_ReturnsEmpty(t *testing.T) {
@@ -290,8 +337,8 @@ func TestParseStream_FullConversation(t *testing.T) {
 
 	result := &executor.Result{}
 	var progressMessages []string
-	onProgress := func(eventType, message string) {
-		progressMessages = append(progressMessages, eventType+":"+message)
+	onProgress := func(e executor.ProgressEvent) {
+		progressMessages = append(progressMessages, string(e.Kind)+":"+e.String())
 	}
 
 	parseStream("test-task", strings.NewReader(stream), result, onProgress)
@@ -301,8 +348,30 @@ func TestParseStream_FullConversation(t *testing.T) {
 	assert.Equal(t, 3, result.Turns)
 	assert.Contains(t, result.Output, "I'll fix the bug.")
 	assert.Contains(t, result.Output, "Done, the fix is applied.")
-	assert.Contains(t, progressMessages, "progress:I'll fix the bug.")
-	assert.Contains(t, progressMessages, "progress:Using tool: Edit")
+	assert.Contains(t, progressMessages, "session:session ses_test123 initialized")
+	assert.Contains(t, progressMessages, "text:I'll fix the bug.")
+	assert.Contains(t, progressMessages, "tool_started:Using tool: Edit")
+}
+
+func TestParseStream_WhenToolResult_ReportsToolFinished(t *testing.T) {
+	t.Parallel()
+
+	stream := strings.Join([]string{
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go vet ./..."}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"exit status 1","is_error":true}]}}`,
+	}, "\n")
+
+	var events []executor.ProgressEvent
+	parseStream("test-task", strings.NewReader(stream), &executor.Result{}, func(e executor.ProgressEvent) {
+		events = append(events, e)
+	})
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "Using tool: Bash — go vet ./...", events[0].String())
+	assert.Equal(t, executor.ProgressToolFinished, events[1].Kind)
+	assert.Equal(t, "Bash", events[1].Tool)
+	assert.Equal(t, "toolu_1", events[1].ToolUseID)
+	assert.True(t, events[1].IsError)
 }
 
 func TestParseStream_WhenMalformedLines_SkipsThem(t *testing.T) {
@@ -0,0 +1,135 @@
+package claude
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Bounds on what the transcript keeps of tool calls: inputs may hold whole
+// files (Write) and results whole command outputs.
+const (
+	maxToolInputSize  = 8192
+	maxToolResultSize = 2000
+)
+
+// transcriber builds the transcript of an execution from its stream-json
+// events.
+type transcriber struct {
+	entries []executor.TranscriptEntry
+	tools   map[string]int // tool_use entry of each tool call ID
+	turns   map[string]int // first entry of each assistant message ID
+}
+
+func newTranscriber() *transcriber {
+	return &transcriber{tools: make(map[string]int), turns: make(map[string]int)}
+}
+
+// add records the content of event, received at.
+func (tr *transcriber) add(event *StreamEvent, at time.Time) {
+	switch event.Type {
+	case "assistant":
+		tr.addAssistant(event.Message, at)
+	case "user":
+		tr.addUser(event.Message, at)
+	case "result":
+		tr.entries = append(tr.entries, executor.TranscriptEntry{
+			Kind:     executor.EntryResult,
+			Text:     event.Result,
+			IsError:  event.IsError,
+			Duration: time.Duration(event.Duration) * time.Millisecond,
+			At:       at,
+		})
+	}
+}
+
+func (tr *transcriber) addAssistant(msg *StreamMessage, at time.Time) {
+	if msg == nil {
+		return
+	}
+	first := len(tr.entries)
+	for _, block := range msg.Content {
+		e := executor.TranscriptEntry{At: at}
+		switch block.Type {
+		case "text":
+			e.Kind, e.Text = executor.EntryAssistant, block.Text
+		case "thinking":
+			e.Kind, e.Text = executor.EntryThinking, block.Thinking
+		case "tool_use":
+			e.Kind, e.Tool, e.ToolUseID = executor.EntryToolUse, block.Name, block.ID
+			e.Input = truncateStr(string(block.Input), maxToolInputSize)
+			if block.ID != "" {
+				tr.tools[block.ID] = len(tr.entries)
+			}
+		default:
+			continue
+		}
+		tr.entries = append(tr.entries, e)
+	}
+	if msg.Usage == nil || len(tr.entries) == first {
+		return
+	}
+
+	// Claude Code sends each content block of a message as an event of
+	// its own, repeating the usage so far: it goes on the message's first
+	// entry, updated as the message grows.
+	turn, ok := tr.turns[msg.ID]
+	if !ok || msg.ID == "" {
+		turn = first
+		if msg.ID != "" {
+			tr.turns[msg.ID] = turn
+		}
+	}
+	tr.entries[turn].InputTokens = msg.Usage.inputTokens()
+	tr.entries[turn].OutputTokens = msg.Usage.OutputTokens
+}
+
+func (tr *transcriber) addUser(msg *StreamMessage, at time.Time) {
+	if msg == nil {
+		return
+	}
+	for _, block := range msg.Content {
+		switch block.Type {
+		case "text":
+			tr.entries = append(tr.entries, executor.TranscriptEntry{Kind: executor.EntryUser, Text: block.Text, At: at})
+		case "tool_result":
+			e := executor.TranscriptEntry{
+				Kind:      executor.EntryToolResult,
+				ToolUseID: block.ToolUseID,
+				Text:      truncateStr(toolResultText(block.Content), maxToolResultSize),
+				IsError:   block.IsError,
+				At:        at,
+			}
+			if i, ok := tr.tools[block.ToolUseID]; ok {
+				e.Tool = tr.entries[i].Tool
+				e.Duration = at.Sub(tr.entries[i].At)
+			}
+			tr.entries = append(tr.entries, e)
+		}
+	}
+}
+
+// toolResultText returns the text of a tool result, whose content is a
+// string or a list of content blocks.
+func toolResultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return string(raw)
+	}
+	var texts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
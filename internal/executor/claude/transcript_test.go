@@ -0,0 +1,67 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+func TestParseStream_RecordsTranscript(t *testing.T) {
+	t.Parallel()
+
+	stream := strings.Join([]string{
+		`{"type":"system","subtype":"init","session_id":"ses_t1"}`,
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","content":[{"type":"thinking","thinking":"The tests first."}],"usage":{"input_tokens":10,"cache_read_input_tokens":1000,"output_tokens":5}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":10,"cache_read_input_tokens":1000,"output_tokens":42}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"FAIL\tauth","is_error":true}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_2","content":[{"type":"text","text":"line 1"},{"type":"text","text":"line 2"}]}]}}`,
+		`{"type":"assistant","message":{"id":"msg_2","role":"assistant","content":[{"type":"text","text":"Fixed."}]}}`,
+		`{"type":"result","subtype":"success","result":"Fixed.","duration_ms":9000,"num_turns":2}`,
+	}, "\n")
+
+	result := &executor.Result{}
+	parseStream("test-task", strings.NewReader(stream), result, nil)
+
+	entries := result.Transcript
+	require.Len(t, entries, 6)
+
+	assert.Equal(t, executor.EntryThinking, entries[0].Kind)
+	assert.Equal(t, "The tests first.", entries[0].Text)
+	assert.Equal(t, 1010, entries[0].InputTokens, "usage goes on the first entry of the turn")
+	assert.Equal(t, 42, entries[0].OutputTokens, "and is updated as the message grows")
+
+	assert.Equal(t, executor.EntryToolUse, entries[1].Kind)
+	assert.Equal(t, "Bash", entries[1].Tool)
+	assert.JSONEq(t, `{"command":"go test ./..."}`, entries[1].Input)
+	assert.Zero(t, entries[1].OutputTokens)
+
+	assert.Equal(t, executor.EntryToolResult, entries[2].Kind)
+	assert.Equal(t, "Bash", entries[2].Tool, "results are linked to their call")
+	assert.Equal(t, "FAIL\tauth", entries[2].Text)
+	assert.True(t, entries[2].IsError)
+
+	assert.Equal(t, "line 1\nline 2", entries[3].Text)
+	assert.Empty(t, entries[3].Tool)
+
+	assert.Equal(t, executor.EntryAssistant, entries[4].Kind)
+	assert.Equal(t, executor.EntryResult, entries[5].Kind)
+	assert.Equal(t, "Fixed.", entries[5].Text)
+	assert.Equal(t, "9s", entries[5].Duration.String())
+}
+
+func TestParseStream_TruncatesToolResults(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", maxToolResultSize+100)
+	stream := `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"` + long + `"}]}}`
+
+	result := &executor.Result{}
+	parseStream("test-task", strings.NewReader(stream), result, nil)
+
+	require.Len(t, result.Transcript, 1)
+	assert.Len(t, result.Transcript[0].Text, maxToolResultSize+len("..."))
+}
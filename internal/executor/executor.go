@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -13,6 +14,14 @@ type Result struct {
 	Turns     int
 	Duration  time.Duration
 	ExitCode  int
+
+	// Stderr holds the end of what the process wrote to stderr, for
+	// classifying failures (see retry.Policy).
+	Stderr string
+
+	// Transcript is the execution step by step, for executors that record
+	// one.
+	Transcript []TranscriptEntry
 }
 
 // Request holds parameters for a task execution.
@@ -38,21 +47,39 @@ type Request struct {
 	DryRun bool
 
 	Env map[string]string
+
+	// Messages delivers follow-up user messages while the task runs, each
+	// as a new user turn. Requires Capabilities.SupportsMessages.
+	Messages <-chan string
+
+	// Permissions forwards the tool permission requests of the task to
+	// Herald for approval. Requires Capabilities.SupportsPermissionPrompts.
+	Permissions *PermissionPrompt
 }
 
-// ProgressFunc is called during execution to report progress.
-type ProgressFunc func(eventType string, message string)
+// PermissionToolName is the tool of Herald's approval endpoint that
+// answers permission requests.
+const PermissionToolName = "approval_prompt"
+
+// PermissionPrompt locates Herald's approval endpoint, an MCP server over
+// streamable HTTP exposing PermissionToolName.
+type PermissionPrompt struct {
+	URL   string
+	Token string // bearer token identifying the task
+}
 
 // Capabilities describes what features an executor implementation supports.
 // Handlers use this to warn users when a requested feature is unavailable.
 type Capabilities struct {
-	SupportsSession  bool
-	SupportsModel    bool
-	SupportsToolList bool
-	SupportsDryRun   bool
-	SupportsStreaming bool
-	Name             string
-	Version          string
+	SupportsSession           bool
+	SupportsModel             bool
+	SupportsToolList          bool
+	SupportsDryRun            bool
+	SupportsStreaming         bool
+	SupportsMessages          bool
+	SupportsPermissionPrompts bool
+	Name                      string
+	Version                   string
 }
 
 // Executor runs tasks against a CLI backend.
@@ -60,3 +87,21 @@ type Executor interface {
 	Execute(ctx context.Context, req Request, onProgress ProgressFunc) (*Result, error)
 	Capabilities() Capabilities
 }
+
+// ErrNotReattachable is returned by Reattach when nothing is left of a
+// task's process to reattach to (no spool, no live process).
+var ErrNotReattachable = errors.New("task cannot be reattached")
+
+// ReattachRequest identifies a process started by a previous Herald instance.
+type ReattachRequest struct {
+	TaskID string
+	PID    int
+}
+
+// Reattacher is an optional interface for executors whose processes can
+// outlive a Herald restart. Reattach resumes monitoring the process if it
+// is still alive, or finalizes the task from whatever output it left
+// behind, and returns the result as Execute would.
+type Reattacher interface {
+	Reattach(ctx context.Context, req ReattachRequest, onProgress ProgressFunc) (*Result, error)
+}
@@ -0,0 +1,329 @@
+// Package external runs tasks with any binary speaking Herald's JSON-lines
+// executor protocol on its stdin and stdout, so that backends need not be
+// compiled into Herald. See docs/guide/custom-executor.md for the protocol.
+package external
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// handshakeTimeout bounds the capabilities handshake at startup.
+const handshakeTimeout = 10 * time.Second
+
+// maxStderrSize bounds the stderr kept on a Result.
+const maxStderrSize = 4096
+
+func init() {
+	executor.Register("external", func(cfg map[string]any) (executor.Executor, error) {
+		e := &Executor{}
+		e.Name, _ = cfg["name"].(string)
+		e.Command, _ = cfg["command"].(string)
+		e.Args, _ = cfg["args"].([]string)
+		e.Env, _ = cfg["env"].(map[string]string)
+		if e.Command == "" {
+			return nil, errors.New("external executor: command is required")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+		defer cancel()
+		if err := e.Handshake(ctx); err != nil {
+			return nil, err
+		}
+		return e, nil
+	})
+}
+
+// Executor runs tasks with an external binary: a process per execution,
+// started in the project directory.
+type Executor struct {
+	Name    string // backend name in herald.yaml, the default capability name
+	Command string
+	Args    []string
+	Env     map[string]string
+
+	caps executor.Capabilities
+}
+
+// Capabilities returns what the binary declared in the handshake.
+func (e *Executor) Capabilities() executor.Capabilities {
+	return e.caps
+}
+
+// Handshake asks the binary for its capabilities.
+func (e *Executor) Handshake(ctx context.Context) error {
+	cmd := e.command(ctx, "", nil)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("opening stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("opening stdout: %w", err)
+	}
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s: %w", e.Command, err)
+	}
+
+	var reply *Message
+	if err := writeMessage(stdin, Message{Type: TypeCapabilities, Protocol: ProtocolVersion}); err == nil {
+		scanner := newScanner(stdout)
+		for scanner.Scan() {
+			msg, err := parseMessage(scanner.Bytes())
+			if err == nil && msg.Type == TypeCapabilities && msg.Capabilities != nil {
+				reply = msg
+				break
+			}
+		}
+	}
+	_ = stdin.Close()
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+
+	if reply == nil {
+		if waitErr != nil {
+			return fmt.Errorf("%s did not declare its capabilities: %w (stderr: %s)", e.Command, waitErr, stderr.String())
+		}
+		return fmt.Errorf("%s did not declare its capabilities", e.Command)
+	}
+	e.caps = reply.Capabilities.toExecutor()
+	if e.caps.Name == "" {
+		e.caps.Name = e.Name
+	}
+	return nil
+}
+
+// Execute runs the binary for req: it writes an execute line, then each
+// message of req.Messages, and reads progress lines until the result line.
+// Stdin is closed once the result is read, and the binary is expected to
+// exit.
+func (e *Executor) Execute(ctx context.Context, req executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	cmd := e.command(ctx, req.ProjectPath, req.Env)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("opening stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("opening stdout: %w", err)
+	}
+	var stderr tailBuffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", e.Command, err)
+	}
+	slog.Info("external executor started",
+		"task_id", req.TaskID,
+		"executor", e.caps.Name,
+		"pid", cmd.Process.Pid)
+	if onProgress != nil {
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
+	}
+
+	in := &input{w: stdin}
+	if err := in.send(Message{Type: TypeExecute, Protocol: ProtocolVersion, Request: newRequest(req)}); err != nil {
+		slog.Warn("failed to write request to external executor", "task_id", req.TaskID, "error", err)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		in.forward(req.Messages, done, onProgress)
+	}()
+
+	final := readMessages(req.TaskID, stdout, onProgress)
+	in.close()
+	_, _ = io.Copy(io.Discard, stdout) // whatever follows the result
+	waitErr := cmd.Wait()
+	close(done)
+	wg.Wait()
+
+	result := &executor.Result{}
+	if final != nil && final.Result != nil {
+		result = final.Result.toExecutor()
+	}
+	if result.Duration == 0 {
+		result.Duration = time.Since(start)
+	}
+	if result.Stderr == "" {
+		result.Stderr = stderr.String()
+	}
+
+	if exitErr, ok := errors.AsType[*exec.ExitError](waitErr); ok && result.ExitCode == 0 {
+		result.ExitCode = exitErr.ExitCode()
+	}
+	switch {
+	case final == nil && waitErr != nil:
+		return result, fmt.Errorf("%s exited without a result: %w", e.caps.Name, waitErr)
+	case final == nil:
+		return result, fmt.Errorf("%s exited without a result", e.caps.Name)
+	case final.Error != "":
+		return result, errors.New(final.Error)
+	case result.ExitCode != 0:
+		return result, fmt.Errorf("%s exited with code %d", e.caps.Name, result.ExitCode)
+	}
+
+	slog.Info("external executor completed",
+		"task_id", req.TaskID,
+		"executor", e.caps.Name,
+		"duration", result.Duration,
+		"cost_usd", result.CostUSD)
+	return result, nil
+}
+
+// command returns the command running the binary in dir, in its own
+// process group so that pausing and stopping it reach its children.
+func (e *Executor) command(ctx context.Context, dir string, env map[string]string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...) //nolint:gosec // Command from trusted config
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for k, v := range e.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	return cmd
+}
+
+// readMessages reports the progress lines of r and returns its result
+// line, or nil if r ended without one.
+func readMessages(taskID string, r io.Reader, onProgress executor.ProgressFunc) *Message {
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		msg, err := parseMessage(scanner.Bytes())
+		if err != nil {
+			slog.Debug("external executor: malformed line", "task_id", taskID, "error", err)
+			continue
+		}
+		switch msg.Type {
+		case TypeProgress:
+			if msg.Progress == nil || onProgress == nil || msg.Progress.Kind == string(executor.ProgressStarted) {
+				continue
+			}
+			onProgress(msg.Progress.toExecutor())
+		case TypeResult:
+			return msg
+		default:
+			slog.Debug("external executor: unknown message type", "task_id", taskID, "type", msg.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Warn("external executor: reading output", "task_id", taskID, "error", err)
+	}
+	return nil
+}
+
+// input writes the lines of Herald to the binary's stdin.
+type input struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	closed bool
+}
+
+func (in *input) send(msg Message) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.closed {
+		return errors.New("the task is finishing")
+	}
+	return writeMessage(in.w, msg)
+}
+
+func (in *input) close() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if !in.closed {
+		in.closed = true
+		_ = in.w.Close()
+	}
+}
+
+// forward sends each message as a message line until done.
+func (in *input) forward(messages <-chan string, done <-chan struct{}, onProgress executor.ProgressFunc) {
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			event := executor.ProgressEvent{Kind: executor.ProgressStatus, Text: "message delivered"}
+			if err := in.send(Message{Type: TypeMessage, Text: msg}); err != nil {
+				event = executor.ProgressEvent{Kind: executor.ProgressWarning, Text: fmt.Sprintf("message not delivered: %s", err)}
+			}
+			if onProgress != nil {
+				onProgress(event)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func writeMessage(w io.Writer, msg Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+func parseMessage(line []byte) (*Message, error) {
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // 10MB max line
+	return scanner
+}
+
+// tailBuffer keeps the last maxStderrSize bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > maxStderrSize {
+		b.buf = b.buf[len(b.buf)-maxStderrSize:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,176 @@
+package external_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/executor/external"
+	"github.com/btouchard/herald/internal/executor/external/fake"
+)
+
+// The test binary doubles as the fake executor when run by the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("HERALD_FAKE_EXECUTOR") == "1" {
+		os.Exit(fake.Main())
+	}
+	os.Exit(m.Run())
+}
+
+func newFakeExecutor(t *testing.T) executor.Executor {
+	t.Helper()
+	factory, ok := executor.Get("external")
+	require.True(t, ok)
+	e, err := factory(map[string]any{
+		"name":    "my-backend",
+		"command": os.Args[0],
+		"env":     map[string]string{"HERALD_FAKE_EXECUTOR": "1"},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func TestFactory_PerformsCapabilitiesHandshake(t *testing.T) {
+	t.Parallel()
+
+	e := newFakeExecutor(t)
+
+	caps := e.Capabilities()
+	assert.Equal(t, "fake", caps.Name)
+	assert.Equal(t, "1.0.0", caps.Version)
+	assert.True(t, caps.SupportsSession)
+	assert.True(t, caps.SupportsMessages)
+	assert.False(t, caps.SupportsToolList)
+}
+
+func TestFactory_WhenCommandDoesNotSpeakProtocol_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	factory, _ := executor.Get("external")
+	_, err := factory(map[string]any{"name": "broken", "command": "true"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not declare its capabilities")
+
+	_, err = factory(map[string]any{"name": "none"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command is required")
+}
+
+func TestExecute_ReportsProgressAndResult(t *testing.T) {
+	t.Parallel()
+	e := newFakeExecutor(t)
+
+	var events []executor.ProgressEvent
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID:      "herald-ext01",
+		Prompt:      "fix the bug",
+		ProjectPath: t.TempDir(),
+	}, func(ev executor.ProgressEvent) { events = append(events, ev) })
+	require.NoError(t, err)
+
+	assert.Equal(t, "Done: fix the bug", result.Output)
+	assert.Equal(t, "fake-herald-ext01", result.SessionID)
+	assert.InDelta(t, 0.02, result.CostUSD, 0.0001)
+	assert.Equal(t, 1, result.Turns)
+	require.Len(t, result.Transcript, 2)
+	assert.Equal(t, executor.EntryUser, result.Transcript[0].Kind)
+
+	var kinds []executor.ProgressKind
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	assert.Equal(t, []executor.ProgressKind{
+		executor.ProgressStarted,
+		executor.ProgressSession,
+		executor.ProgressText,
+		executor.ProgressTodos,
+		executor.ProgressToolStarted,
+		executor.ProgressToolFinished,
+		executor.ProgressUsage,
+	}, kinds)
+	assert.Positive(t, events[0].PID)
+	assert.Equal(t, "Using tool: Edit — README.md", events[4].String())
+	assert.Equal(t, 5*time.Millisecond, events[5].Duration)
+}
+
+func TestExecute_WhenMessageSent_DeliversIt(t *testing.T) {
+	t.Parallel()
+	e := newFakeExecutor(t)
+
+	messages := make(chan string, 1)
+	messages <- "keep the old names"
+
+	var mu sync.Mutex
+	var events []executor.ProgressEvent
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID:   "herald-ext02",
+		Prompt:   "rename",
+		Env:      map[string]string{"FAKE_WAIT_MESSAGE": "1"},
+		Messages: messages,
+	}, func(ev executor.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Done: rename\nMessage: keep the old names", result.Output)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, events, executor.ProgressEvent{Kind: executor.ProgressStatus, Text: "message delivered"})
+}
+
+func TestExecute_WhenResultHasError_FailsWithIt(t *testing.T) {
+	t.Parallel()
+	e := newFakeExecutor(t)
+
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID: "herald-ext03",
+		Prompt: "deploy",
+		Env:    map[string]string{"FAKE_FAIL": "tests failed"},
+	}, nil)
+	require.EqualError(t, err, "tests failed")
+	assert.Equal(t, "Done: deploy", result.Output)
+}
+
+func TestExecute_WhenExitingWithoutResult_ReturnsExitCode(t *testing.T) {
+	t.Parallel()
+	e := newFakeExecutor(t)
+
+	result, err := e.Execute(context.Background(), executor.Request{
+		TaskID: "herald-ext04",
+		Prompt: "crash",
+		Env:    map[string]string{"FAKE_EXIT": "3"},
+	}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fake exited without a result")
+	assert.Equal(t, 3, result.ExitCode)
+}
+
+func TestExecute_WhenContextCancelled_StopsProcess(t *testing.T) {
+	t.Parallel()
+	e := newFakeExecutor(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := e.Execute(ctx, executor.Request{
+		TaskID: "herald-ext05",
+		Prompt: "slow",
+		Env:    map[string]string{"FAKE_SLEEP": "30s"},
+	}, nil)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestProtocolVersion(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 1, external.ProtocolVersion)
+}
@@ -0,0 +1,122 @@
+// Package fake is a reference implementation of the external executor
+// protocol, used by tests. It does no real work: it reports progress of
+// every kind and echoes its prompt. The Env of a request tunes it:
+//
+//   - FAKE_SLEEP, a duration, delays the result;
+//   - FAKE_WAIT_MESSAGE waits for a message line and appends it to the output;
+//   - FAKE_FAIL fails the task with its value as the error;
+//   - FAKE_EXIT exits with its value as exit code, without a result.
+package fake
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor/external"
+)
+
+// Name is the name the fake declares in the handshake.
+const Name = "fake"
+
+// Main serves the protocol on stdin and stdout, and returns the exit code
+// of the process.
+func Main() int {
+	code, err := Serve(os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fake executor:", err)
+		return 1
+	}
+	return code
+}
+
+// Serve answers the first line read from r, a capabilities or an execute
+// line, on w, and returns the exit code of the process.
+func Serve(r io.Reader, w io.Writer) (int, error) {
+	lines := bufio.NewScanner(r)
+	if !lines.Scan() {
+		return 1, fmt.Errorf("no request: %v", lines.Err())
+	}
+	var msg external.Message
+	if err := json.Unmarshal(lines.Bytes(), &msg); err != nil {
+		return 1, fmt.Errorf("parsing request: %w", err)
+	}
+	out := json.NewEncoder(w)
+
+	switch msg.Type {
+	case external.TypeCapabilities:
+		return 0, out.Encode(external.Message{Type: external.TypeCapabilities, Capabilities: &external.Capabilities{
+			Name:              Name,
+			Version:           "1.0.0",
+			SupportsSession:   true,
+			SupportsModel:     true,
+			SupportsStreaming: true,
+			SupportsMessages:  true,
+		}})
+	case external.TypeExecute:
+		if msg.Request == nil {
+			return 1, fmt.Errorf("execute line without a request")
+		}
+		return execute(msg.Request, lines, out)
+	}
+	return 1, fmt.Errorf("unexpected %q line", msg.Type)
+}
+
+func execute(req *external.Request, lines *bufio.Scanner, out *json.Encoder) (int, error) {
+	start := time.Now()
+	session := req.SessionID
+	if session == "" {
+		session = "fake-" + req.TaskID
+	}
+	progress := []external.Progress{
+		{Kind: "session", SessionID: session},
+		{Kind: "text", Text: "Working on: " + req.Prompt},
+		{Kind: "todos", Todos: []external.Todo{{Content: "Read the prompt", Status: "completed"}, {Content: "Answer it", Status: "in_progress"}}},
+		{Kind: "tool_started", Tool: "Edit", ToolUseID: "fake-1", Input: "README.md"},
+		{Kind: "tool_finished", Tool: "Edit", ToolUseID: "fake-1", DurationMS: 5},
+		{Kind: "usage", CostUSD: 0.01, InputTokens: 100, OutputTokens: 20},
+	}
+	for _, p := range progress {
+		if err := out.Encode(external.Message{Type: external.TypeProgress, Progress: &p}); err != nil {
+			return 1, err
+		}
+	}
+
+	if d, err := time.ParseDuration(req.Env["FAKE_SLEEP"]); err == nil {
+		time.Sleep(d)
+	}
+	if code, err := strconv.Atoi(req.Env["FAKE_EXIT"]); err == nil {
+		return code, nil
+	}
+
+	output := "Done: " + req.Prompt
+	if req.Env["FAKE_WAIT_MESSAGE"] != "" {
+		for lines.Scan() {
+			var msg external.Message
+			if err := json.Unmarshal(lines.Bytes(), &msg); err == nil && msg.Type == external.TypeMessage {
+				output += "\nMessage: " + msg.Text
+				break
+			}
+		}
+	}
+
+	return 0, out.Encode(external.Message{
+		Type:  external.TypeResult,
+		Error: req.Env["FAKE_FAIL"],
+		Result: &external.Result{
+			SessionID:  session,
+			Output:     output,
+			CostUSD:    0.02,
+			Turns:      1,
+			DurationMS: time.Since(start).Milliseconds(),
+			Transcript: []external.TranscriptEntry{
+				{Kind: "user", Text: req.Prompt, At: start},
+				{Kind: "assistant", Text: output, At: time.Now()},
+			},
+		},
+	})
+}
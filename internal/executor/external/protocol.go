@@ -0,0 +1,200 @@
+package external
+
+import (
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// ProtocolVersion is the version of the JSON-lines protocol Herald speaks
+// with external executors, sent with every request.
+const ProtocolVersion = 1
+
+// Message types. Herald writes capabilities, execute and message lines to
+// the executor's stdin; the executor writes capabilities, progress and
+// result lines to its stdout.
+const (
+	TypeCapabilities = "capabilities"
+	TypeExecute      = "execute"
+	TypeMessage      = "message"
+	TypeProgress     = "progress"
+	TypeResult       = "result"
+)
+
+// Message is a line of the protocol, in either direction. Only the fields
+// of its Type are set.
+type Message struct {
+	Type     string `json:"type"`
+	Protocol int    `json:"protocol,omitempty"`
+
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+	Request      *Request      `json:"request,omitempty"`
+	Progress     *Progress     `json:"progress,omitempty"`
+	Result       *Result       `json:"result,omitempty"`
+
+	// Text is the follow-up user message of a message line.
+	Text string `json:"text,omitempty"`
+	// Error fails the task with its result line.
+	Error string `json:"error,omitempty"`
+}
+
+// Capabilities mirrors executor.Capabilities.
+type Capabilities struct {
+	Name                      string `json:"name"`
+	Version                   string `json:"version,omitempty"`
+	SupportsSession           bool   `json:"supports_session,omitempty"`
+	SupportsModel             bool   `json:"supports_model,omitempty"`
+	SupportsToolList          bool   `json:"supports_tool_list,omitempty"`
+	SupportsDryRun            bool   `json:"supports_dry_run,omitempty"`
+	SupportsStreaming         bool   `json:"supports_streaming,omitempty"`
+	SupportsMessages          bool   `json:"supports_messages,omitempty"`
+	SupportsPermissionPrompts bool   `json:"supports_permission_prompts,omitempty"`
+}
+
+// Request mirrors executor.Request. Messages arrive as message lines.
+type Request struct {
+	TaskID         string            `json:"task_id"`
+	Prompt         string            `json:"prompt"`
+	ProjectPath    string            `json:"project_path"`
+	SessionID      string            `json:"session_id,omitempty"`
+	Model          string            `json:"model,omitempty"`
+	AllowedTools   []string          `json:"allowed_tools,omitempty"`
+	TimeoutMinutes int               `json:"timeout_minutes,omitempty"`
+	DryRun         bool              `json:"dry_run,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	Permissions    *Permissions      `json:"permissions,omitempty"`
+}
+
+// Permissions mirrors executor.PermissionPrompt.
+type Permissions struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// Progress mirrors executor.ProgressEvent. Herald reports the PID of the
+// executor itself: started events are ignored.
+type Progress struct {
+	Kind         string  `json:"kind"`
+	SessionID    string  `json:"session_id,omitempty"`
+	Tool         string  `json:"tool,omitempty"`
+	ToolUseID    string  `json:"tool_use_id,omitempty"`
+	Input        string  `json:"input,omitempty"`
+	IsError      bool    `json:"is_error,omitempty"`
+	DurationMS   int64   `json:"duration_ms,omitempty"`
+	Text         string  `json:"text,omitempty"`
+	Todos        []Todo  `json:"todos,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+}
+
+// Todo mirrors executor.Todo.
+type Todo struct {
+	Content string `json:"content"`
+	Status  string `json:"status"`
+}
+
+// Result mirrors executor.Result.
+type Result struct {
+	SessionID  string            `json:"session_id,omitempty"`
+	Output     string            `json:"output"`
+	CostUSD    float64           `json:"cost_usd,omitempty"`
+	Turns      int               `json:"turns,omitempty"`
+	DurationMS int64             `json:"duration_ms,omitempty"`
+	ExitCode   int               `json:"exit_code,omitempty"`
+	Stderr     string            `json:"stderr,omitempty"`
+	Transcript []TranscriptEntry `json:"transcript,omitempty"`
+}
+
+// TranscriptEntry mirrors executor.TranscriptEntry.
+type TranscriptEntry struct {
+	Kind         string    `json:"kind"`
+	Tool         string    `json:"tool,omitempty"`
+	ToolUseID    string    `json:"tool_use_id,omitempty"`
+	Text         string    `json:"text,omitempty"`
+	Input        string    `json:"input,omitempty"`
+	IsError      bool      `json:"is_error,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	At           time.Time `json:"at,omitzero"`
+}
+
+func (c Capabilities) toExecutor() executor.Capabilities {
+	return executor.Capabilities{
+		SupportsSession:           c.SupportsSession,
+		SupportsModel:             c.SupportsModel,
+		SupportsToolList:          c.SupportsToolList,
+		SupportsDryRun:            c.SupportsDryRun,
+		SupportsStreaming:         c.SupportsStreaming,
+		SupportsMessages:          c.SupportsMessages,
+		SupportsPermissionPrompts: c.SupportsPermissionPrompts,
+		Name:                      c.Name,
+		Version:                   c.Version,
+	}
+}
+
+func newRequest(req executor.Request) *Request {
+	r := &Request{
+		TaskID:         req.TaskID,
+		Prompt:         req.Prompt,
+		ProjectPath:    req.ProjectPath,
+		SessionID:      req.SessionID,
+		Model:          req.Model,
+		AllowedTools:   req.AllowedTools,
+		TimeoutMinutes: req.TimeoutMinutes,
+		DryRun:         req.DryRun,
+		Env:            req.Env,
+	}
+	if req.Permissions != nil {
+		r.Permissions = &Permissions{URL: req.Permissions.URL, Token: req.Permissions.Token}
+	}
+	return r
+}
+
+func (p Progress) toExecutor() executor.ProgressEvent {
+	e := executor.ProgressEvent{
+		Kind:         executor.ProgressKind(p.Kind),
+		SessionID:    p.SessionID,
+		Tool:         p.Tool,
+		ToolUseID:    p.ToolUseID,
+		Input:        p.Input,
+		IsError:      p.IsError,
+		Duration:     time.Duration(p.DurationMS) * time.Millisecond,
+		Text:         p.Text,
+		CostUSD:      p.CostUSD,
+		InputTokens:  p.InputTokens,
+		OutputTokens: p.OutputTokens,
+	}
+	for _, t := range p.Todos {
+		e.Todos = append(e.Todos, executor.Todo{Content: t.Content, Status: t.Status})
+	}
+	return e
+}
+
+func (r Result) toExecutor() *executor.Result {
+	result := &executor.Result{
+		SessionID: r.SessionID,
+		Output:    r.Output,
+		CostUSD:   r.CostUSD,
+		Turns:     r.Turns,
+		Duration:  time.Duration(r.DurationMS) * time.Millisecond,
+		ExitCode:  r.ExitCode,
+		Stderr:    r.Stderr,
+	}
+	for _, e := range r.Transcript {
+		result.Transcript = append(result.Transcript, executor.TranscriptEntry{
+			Kind:         e.Kind,
+			Tool:         e.Tool,
+			ToolUseID:    e.ToolUseID,
+			Text:         e.Text,
+			Input:        e.Input,
+			IsError:      e.IsError,
+			InputTokens:  e.InputTokens,
+			OutputTokens: e.OutputTokens,
+			Duration:     time.Duration(e.DurationMS) * time.Millisecond,
+			At:           e.At,
+		})
+	}
+	return result
+}
@@ -27,3 +27,34 @@ func GracefulKill(pid int) {
 		_ = syscall.Kill(-pid, syscall.SIGKILL)
 	}
 }
+
+// ProcessGroupAlive reports whether pid is alive and still leads its own
+// process group, as processes spawned with Setpgid do. The group check
+// guards against a recycled PID belonging to an unrelated process.
+func ProcessGroupAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if err := syscall.Kill(pid, 0); err != nil && err != syscall.EPERM {
+		return false
+	}
+	pgid, err := syscall.Getpgid(pid)
+	return err == nil && pgid == pid
+}
+
+// SuspendGroup stops the process group of pid with SIGSTOP, as spawned
+// with Setpgid. The processes keep their state until ResumeGroup.
+func SuspendGroup(pid int) error {
+	if pid <= 0 {
+		return syscall.ESRCH
+	}
+	return syscall.Kill(-pid, syscall.SIGSTOP)
+}
+
+// ResumeGroup continues the process group of pid stopped by SuspendGroup.
+func ResumeGroup(pid int) error {
+	if pid <= 0 {
+		return syscall.ESRCH
+	}
+	return syscall.Kill(-pid, syscall.SIGCONT)
+}
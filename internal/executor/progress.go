@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProgressKind identifies what a ProgressEvent reports.
+type ProgressKind string
+
+// Kinds of progress events.
+const (
+	ProgressStarted      ProgressKind = "started"       // the process started: PID
+	ProgressSession      ProgressKind = "session"       // the session is initialized: SessionID
+	ProgressToolStarted  ProgressKind = "tool_started"  // a tool call: Tool, ToolUseID, Input
+	ProgressToolFinished ProgressKind = "tool_finished" // its result: Tool, ToolUseID, IsError, Duration
+	ProgressText         ProgressKind = "text"          // text written by the model: Text
+	ProgressTodos        ProgressKind = "todos"         // the todo list was updated: Todos
+	ProgressUsage        ProgressKind = "usage"         // cost and tokens so far: CostUSD, InputTokens, OutputTokens
+	ProgressWarning      ProgressKind = "warning"       // something went wrong without failing the task: Text
+	ProgressStatus       ProgressKind = "status"        // any other status line: Text
+)
+
+// Todo statuses.
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoCompleted  = "completed"
+)
+
+// Todo is an item of the todo list an executor keeps while it works.
+type Todo struct {
+	Content string
+	Status  string // TodoPending, TodoInProgress or TodoCompleted
+}
+
+// ProgressEvent reports what an execution is doing. Only the fields of
+// its Kind are set.
+type ProgressEvent struct {
+	Kind ProgressKind
+
+	PID       int
+	SessionID string
+
+	Tool      string
+	ToolUseID string
+	Input     string // a one-line summary of the tool input, see SummarizeInput
+	IsError   bool
+	Duration  time.Duration
+
+	Text  string
+	Todos []Todo
+
+	// CostUSD is the cost of the execution so far, which lets cost budgets
+	// stop a task while it runs.
+	CostUSD      float64
+	InputTokens  int
+	OutputTokens int
+}
+
+// String describes e in a line, e.g. "Using tool: Bash — go test ./...".
+func (e ProgressEvent) String() string {
+	switch e.Kind {
+	case ProgressStarted:
+		return fmt.Sprintf("PID %d", e.PID)
+	case ProgressSession:
+		return fmt.Sprintf("session %s initialized", e.SessionID)
+	case ProgressToolStarted:
+		if e.Input == "" {
+			return "Using tool: " + e.Tool
+		}
+		return fmt.Sprintf("Using tool: %s — %s", e.Tool, e.Input)
+	case ProgressToolFinished:
+		outcome := "finished"
+		if e.IsError {
+			outcome = "failed"
+		}
+		if e.Duration > 0 {
+			return fmt.Sprintf("%s %s in %s", e.Tool, outcome, e.Duration.Round(100*time.Millisecond))
+		}
+		return e.Tool + " " + outcome
+	case ProgressTodos:
+		done, current := 0, ""
+		for _, todo := range e.Todos {
+			switch todo.Status {
+			case TodoCompleted:
+				done++
+			case TodoInProgress:
+				if current == "" {
+					current = todo.Content
+				}
+			}
+		}
+		s := fmt.Sprintf("Todos: %d/%d done", done, len(e.Todos))
+		if current != "" {
+			s += " — " + current
+		}
+		return s
+	case ProgressUsage:
+		return fmt.Sprintf("$%.4f so far (%d in / %d out tokens)", e.CostUSD, e.InputTokens, e.OutputTokens)
+	}
+	return e.Text
+}
+
+// ProgressFunc is called during execution to report progress. Executors
+// report ProgressStarted with the PID of their process first, which lets
+// Herald pause, reattach and stop it.
+type ProgressFunc func(e ProgressEvent)
+
+// maxInputSummary bounds the tool input summaries of SummarizeInput.
+const maxInputSummary = 120
+
+// summaryFields are the input fields that best describe a tool call, in
+// order of preference.
+var summaryFields = []string{"command", "file_path", "path", "notebook_path", "pattern", "url", "query", "description", "prompt"}
+
+// SummarizeInput summarizes the JSON input of a tool call in a line: the
+// command it runs, the file it touches, the pattern it looks for... or ""
+// when none is found.
+func SummarizeInput(input []byte) string {
+	var fields map[string]any
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	for _, name := range summaryFields {
+		s, ok := fields[name].(string)
+		if !ok || strings.TrimSpace(s) == "" {
+			continue
+		}
+		s = strings.Join(strings.Fields(s), " ")
+		if r := []rune(s); len(r) > maxInputSummary {
+			s = string(r[:maxInputSummary]) + "…"
+		}
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressEvent_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		event  ProgressEvent
+		expect string
+	}{
+		{"started", ProgressEvent{Kind: ProgressStarted, PID: 4242}, "PID 4242"},
+		{"session", ProgressEvent{Kind: ProgressSession, SessionID: "ses_1"}, "session ses_1 initialized"},
+		{"tool without input", ProgressEvent{Kind: ProgressToolStarted, Tool: "Read"}, "Using tool: Read"},
+		{"tool with input", ProgressEvent{Kind: ProgressToolStarted, Tool: "Bash", Input: "go test ./..."}, "Using tool: Bash — go test ./..."},
+		{"tool finished", ProgressEvent{Kind: ProgressToolFinished, Tool: "Bash", Duration: 1234 * time.Millisecond}, "Bash finished in 1.2s"},
+		{"tool failed", ProgressEvent{Kind: ProgressToolFinished, Tool: "Edit", IsError: true}, "Edit failed"},
+		{"todos", ProgressEvent{Kind: ProgressTodos, Todos: []Todo{
+			{Content: "Write tests", Status: TodoCompleted},
+			{Content: "Fix the bug", Status: TodoInProgress},
+			{Content: "Update docs", Status: TodoPending},
+		}}, "Todos: 1/3 done — Fix the bug"},
+		{"usage", ProgressEvent{Kind: ProgressUsage, CostUSD: 0.0123, InputTokens: 1000, OutputTokens: 200}, "$0.0123 so far (1000 in / 200 out tokens)"},
+		{"warning", ProgressEvent{Kind: ProgressWarning, Text: "message not delivered"}, "message not delivered"},
+		{"text", ProgressEvent{Kind: ProgressText, Text: "Reading the code."}, "Reading the code."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, tt.event.String())
+		})
+	}
+}
+
+func TestSummarizeInput(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "go test ./...", SummarizeInput([]byte(`{"command":"go   test\n./...","description":"Run tests"}`)))
+	assert.Equal(t, "/src/main.go", SummarizeInput([]byte(`{"file_path":"/src/main.go","old_string":"a","new_string":"b"}`)))
+	assert.Equal(t, "TODO", SummarizeInput([]byte(`{"pattern":"TODO","path":""}`)))
+	assert.Empty(t, SummarizeInput([]byte(`{"todos":[]}`)))
+	assert.Empty(t, SummarizeInput([]byte(`{"command":`)))
+
+	long := SummarizeInput([]byte(`{"command":"` + strings.Repeat("é", 200) + `"}`))
+	assert.Equal(t, strings.Repeat("é", 120)+"…", long)
+}
@@ -10,7 +10,7 @@ import (
 // Claude Code prompts are piped via stdin from this file to avoid
 // CLI argument length limits (~7000 chars).
 func WritePromptFile(workDir, taskID, prompt string) (string, error) {
-	dir := filepath.Join(workDir, "tasks", taskID)
+	dir := TaskDir(workDir, taskID)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return "", fmt.Errorf("creating prompt directory: %w", err)
 	}
@@ -23,8 +23,13 @@ func WritePromptFile(workDir, taskID, prompt string) (string, error) {
 	return path, nil
 }
 
+// TaskDir returns the per-task working directory under workDir.
+// It holds the prompt file and any executor spool files.
+func TaskDir(workDir, taskID string) string {
+	return filepath.Join(workDir, "tasks", taskID)
+}
+
 // CleanupPromptFile removes the prompt temp directory for a task.
 func CleanupPromptFile(workDir, taskID string) {
-	dir := filepath.Join(workDir, "tasks", taskID)
-	_ = os.RemoveAll(dir)
+	_ = os.RemoveAll(TaskDir(workDir, taskID))
 }
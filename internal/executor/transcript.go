@@ -0,0 +1,33 @@
+package executor
+
+import "time"
+
+// Kinds of transcript entries.
+const (
+	EntryUser       = "user"        // a user turn
+	EntryAssistant  = "assistant"   // text the assistant wrote
+	EntryThinking   = "thinking"    // the assistant's thinking
+	EntryToolUse    = "tool_use"    // a tool call, with its input
+	EntryToolResult = "tool_result" // what a tool call returned
+	EntryResult     = "result"      // the end of the execution
+)
+
+// TranscriptEntry is one step of an execution, in the order it happened.
+type TranscriptEntry struct {
+	Kind      string
+	Tool      string // name of the tool, on tool_use and tool_result entries
+	ToolUseID string // links a tool_result to its tool_use
+	Text      string // text, thinking, the result of a tool (truncated) or of the execution
+	Input     string // JSON input of a tool_use (truncated)
+	IsError   bool   // a tool or the execution failed
+
+	// InputTokens and OutputTokens are the usage of the assistant turn,
+	// on its first entry.
+	InputTokens  int
+	OutputTokens int
+
+	// Duration is how long a tool call took, on its tool_result, or the
+	// execution, on the result.
+	Duration time.Duration
+	At       time.Time
+}
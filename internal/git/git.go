@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -31,6 +34,20 @@ func (g *Ops) CurrentBranch(ctx context.Context) (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
+// HeadCommit returns the full hash of the commit HEAD points to.
+func (g *Ops) HeadCommit(ctx context.Context) (string, error) {
+	return g.ResolveCommit(ctx, "HEAD")
+}
+
+// ResolveCommit returns the full hash of the commit ref points to.
+func (g *Ops) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	out, err := g.run(ctx, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
 // HasCommits returns true if the repository has at least one commit.
 func (g *Ops) HasCommits(ctx context.Context) bool {
 	_, err := g.run(ctx, "rev-parse", "HEAD")
@@ -78,6 +95,59 @@ func (g *Ops) DiffStat(ctx context.Context, fromRef, toRef string) (string, erro
 	return out, nil
 }
 
+// FileStat is what a diff changes in a file.
+type FileStat struct {
+	Path    string
+	Added   int // lines added, 0 for binary files
+	Removed int // lines removed, 0 for binary files
+	Binary  bool
+}
+
+// NumStat returns the lines added and removed per file between fromRef and
+// the working tree, untracked files included as added in full.
+func (g *Ops) NumStat(ctx context.Context, fromRef string) ([]FileStat, error) {
+	out, err := g.run(ctx, "diff", "--numstat", "--no-renames", fromRef)
+	if err != nil {
+		return nil, fmt.Errorf("getting diff numstat: %w", err)
+	}
+
+	var stats []FileStat
+	for _, line := range strings.Split(out, "\n") {
+		added, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		removed, path, ok := strings.Cut(rest, "\t")
+		if !ok {
+			continue
+		}
+		s := FileStat{Path: path, Binary: added == "-"}
+		s.Added, _ = strconv.Atoi(added)
+		s.Removed, _ = strconv.Atoi(removed)
+		stats = append(stats, s)
+	}
+
+	untracked, err := g.UntrackedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range untracked {
+		data, err := os.ReadFile(filepath.Join(g.repoPath, path)) //nolint:gosec // path listed by git in the repository
+		if err != nil {
+			continue // gone meanwhile
+		}
+		s := FileStat{Path: path, Binary: bytes.IndexByte(data, 0) >= 0}
+		if !s.Binary {
+			s.Added = bytes.Count(data, []byte("\n"))
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				s.Added++
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
 // CreateBranch creates and checks out a new branch from the current HEAD.
 func (g *Ops) CreateBranch(ctx context.Context, name string) error {
 	if _, err := g.run(ctx, "checkout", "-b", name); err != nil {
@@ -86,6 +156,26 @@ func (g *Ops) CreateBranch(ctx context.Context, name string) error {
 	return nil
 }
 
+// CommitAll stages every change, including untracked files, and commits
+// it with the given message. It returns the new commit hash, or "" if
+// there was nothing to commit.
+func (g *Ops) CommitAll(ctx context.Context, message string) (string, error) {
+	clean, err := g.IsClean(ctx)
+	if err != nil {
+		return "", err
+	}
+	if clean {
+		return "", nil
+	}
+	if _, err := g.run(ctx, "add", "-A"); err != nil {
+		return "", fmt.Errorf("staging changes: %w", err)
+	}
+	if _, err := g.run(ctx, "commit", "-m", message); err != nil {
+		return "", fmt.Errorf("committing changes: %w", err)
+	}
+	return g.HeadCommit(ctx)
+}
+
 // Checkout switches to the given branch.
 func (g *Ops) Checkout(ctx context.Context, branch string) error {
 	if _, err := g.run(ctx, "checkout", branch); err != nil {
@@ -94,9 +184,14 @@ func (g *Ops) Checkout(ctx context.Context, branch string) error {
 	return nil
 }
 
-// Stash saves uncommitted changes to the stash.
+// Stash saves uncommitted changes, untracked files included, to the stash.
 func (g *Ops) Stash(ctx context.Context) error {
-	if _, err := g.run(ctx, "stash", "push", "-m", "herald: auto-stash before task"); err != nil {
+	return g.StashWithMessage(ctx, "herald: auto-stash before task")
+}
+
+// StashWithMessage is like Stash with a custom stash message.
+func (g *Ops) StashWithMessage(ctx context.Context, message string) error {
+	if _, err := g.run(ctx, "stash", "push", "--include-untracked", "-m", message); err != nil {
 		return fmt.Errorf("stashing changes: %w", err)
 	}
 	return nil
@@ -110,6 +205,156 @@ func (g *Ops) StashPop(ctx context.Context) error {
 	return nil
 }
 
+// BranchExists returns true if a local branch with the given name exists.
+func (g *Ops) BranchExists(ctx context.Context, name string) bool {
+	_, err := g.run(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// DeleteBranch force-deletes a local branch, merged or not.
+func (g *Ops) DeleteBranch(ctx context.Context, name string) error {
+	if _, err := g.run(ctx, "branch", "-D", name); err != nil {
+		return fmt.Errorf("deleting branch %q: %w", name, err)
+	}
+	return nil
+}
+
+// ResetBranch moves a branch that is not checked out to ref.
+func (g *Ops) ResetBranch(ctx context.Context, name, ref string) error {
+	if _, err := g.run(ctx, "branch", "-f", name, ref); err != nil {
+		return fmt.Errorf("resetting branch %q: %w", name, err)
+	}
+	return nil
+}
+
+// ResetHard moves the current branch to ref, discarding uncommitted
+// changes to tracked files.
+func (g *Ops) ResetHard(ctx context.Context, ref string) error {
+	if _, err := g.run(ctx, "reset", "--hard", ref); err != nil {
+		return fmt.Errorf("resetting to %s: %w", ref, err)
+	}
+	return nil
+}
+
+// Discard throws away every uncommitted change, removing untracked files
+// (ignored files are kept).
+func (g *Ops) Discard(ctx context.Context) error {
+	if err := g.ResetHard(ctx, "HEAD"); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, "clean", "-fd"); err != nil {
+		return fmt.Errorf("removing untracked files: %w", err)
+	}
+	return nil
+}
+
+// CountCommits returns the number of commits reachable from to but not
+// from from.
+func (g *Ops) CountCommits(ctx context.Context, from, to string) (int, error) {
+	out, err := g.run(ctx, "rev-list", "--count", from+".."+to)
+	if err != nil {
+		return 0, fmt.Errorf("counting commits %s..%s: %w", from, to, err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("counting commits %s..%s: %w", from, to, err)
+	}
+	return n, nil
+}
+
+// AddWorktree checks out branch in a new worktree at path. The branch is
+// created from base when it does not exist yet.
+func (g *Ops) AddWorktree(ctx context.Context, path, branch, base string) error {
+	args := []string{"worktree", "add"}
+	if g.BranchExists(ctx, branch) {
+		args = append(args, path, branch)
+	} else {
+		args = append(args, "-b", branch, path, base)
+	}
+	if _, err := g.run(ctx, args...); err != nil {
+		return fmt.Errorf("adding worktree for %q: %w", branch, err)
+	}
+	return nil
+}
+
+// RemoveWorktree removes the worktree at path. Without force, git refuses
+// to remove a worktree with uncommitted changes.
+func (g *Ops) RemoveWorktree(ctx context.Context, path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	args = append(args, path)
+	if _, err := g.run(ctx, args...); err != nil {
+		return fmt.Errorf("removing worktree %s: %w", path, err)
+	}
+	return nil
+}
+
+// MergeBase returns the best common ancestor commit of two refs.
+func (g *Ops) MergeBase(ctx context.Context, a, b string) (string, error) {
+	out, err := g.run(ctx, "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("finding merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// UntrackedFiles returns files not tracked by git and not ignored.
+func (g *Ops) UntrackedFiles(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("listing untracked files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// Exclude adds pattern to the repository's info/exclude file, unless it is
+// there already. Git then ignores the matching files in this clone and its
+// worktrees, without a change to any tracked file such as .gitignore.
+func (g *Ops) Exclude(ctx context.Context, pattern string) error {
+	out, err := g.run(ctx, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return fmt.Errorf("locating info/exclude: %w", err)
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.repoPath, path)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path given by git
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading info/exclude: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		pattern = "\n" + pattern
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("creating info directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec // path given by git
+	if err != nil {
+		return fmt.Errorf("opening info/exclude: %w", err)
+	}
+	if _, err := f.WriteString(pattern + "\n"); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing info/exclude: %w", err)
+	}
+	return f.Close()
+}
+
 // IsGitRepo returns true if the path is a git repository.
 func (g *Ops) IsGitRepo(ctx context.Context) bool {
 	_, err := g.run(ctx, "rev-parse", "--git-dir")
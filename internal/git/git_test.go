@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -200,6 +201,34 @@ func TestOps_DiffStat(t *testing.T) {
 	assert.Contains(t, stat, "stat.txt")
 }
 
+func TestOps_NumStat_CountsCommittedUncommittedAndUntracked(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "kept.txt"), []byte("a\nb\nc\n"), 0600))
+	_, err := ops.CommitAll(ctx, "add kept")
+	require.NoError(t, err)
+	base, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "kept.txt"), []byte("a\nB\nc\nd\n"), 0600))
+	_, err = ops.CommitAll(ctx, "edit kept")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "internal", "auth"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "internal", "auth", "new.go"), []byte("package auth\n\nfunc x() {}"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "logo.png"), []byte{0x89, 'P', 0, 1}, 0600))
+
+	stats, err := ops.NumStat(ctx, base)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []FileStat{
+		{Path: "kept.txt", Added: 2, Removed: 1},
+		{Path: "internal/auth/new.go", Added: 3},
+		{Path: "logo.png", Binary: true},
+	}, stats)
+}
+
 func TestOps_CreateBranch_WhenExistingBranches(t *testing.T) {
 	t.Parallel()
 	repo := initTestRepo(t)
@@ -256,3 +285,183 @@ func TestOps_StashAndPop(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, clean, "should be dirty after pop")
 }
+
+func TestOps_Stash_IncludesUntrackedFiles(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("data"), 0600))
+	require.NoError(t, ops.Stash(ctx))
+
+	clean, err := ops.IsClean(ctx)
+	require.NoError(t, err)
+	assert.True(t, clean, "untracked files should be stashed too")
+
+	require.NoError(t, ops.StashPop(ctx))
+	_, err = os.Stat(filepath.Join(repo, "untracked.txt"))
+	assert.NoError(t, err)
+}
+
+func TestOps_AddAndRemoveWorktree(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	require.NoError(t, ops.AddWorktree(ctx, wtPath, "herald/wt-test", "HEAD"))
+	assert.True(t, ops.BranchExists(ctx, "herald/wt-test"))
+
+	wtOps := NewOps(wtPath)
+	branch, err := wtOps.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "herald/wt-test", branch)
+
+	// Main checkout is untouched
+	branch, err = ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+
+	require.NoError(t, ops.RemoveWorktree(ctx, wtPath, false))
+	_, err = os.Stat(wtPath)
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, ops.BranchExists(ctx, "herald/wt-test"), "branch survives worktree removal")
+
+	// Re-adding reuses the existing branch
+	require.NoError(t, ops.AddWorktree(ctx, wtPath, "herald/wt-test", "HEAD"))
+}
+
+func TestOps_RemoveWorktree_WhenDirty_RequiresForce(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	require.NoError(t, ops.AddWorktree(ctx, wtPath, "herald/dirty", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(wtPath, "wip.txt"), []byte("wip"), 0600))
+
+	assert.Error(t, ops.RemoveWorktree(ctx, wtPath, false))
+	require.NoError(t, ops.RemoveWorktree(ctx, wtPath, true))
+}
+
+func TestOps_Exclude_IgnoresFilesOnce(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".cache.db"), []byte("x"), 0600))
+	require.NoError(t, ops.Exclude(ctx, ".cache*"))
+	require.NoError(t, ops.Exclude(ctx, ".cache*"))
+
+	files, err := ops.UntrackedFiles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	clean, err := ops.IsClean(ctx)
+	require.NoError(t, err)
+	assert.True(t, clean, ".gitignore is not touched")
+
+	data, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), ".cache*\n"))
+}
+
+func TestOps_UntrackedFiles(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "new file.txt"), []byte("x"), 0600))
+
+	files, err := ops.UntrackedFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new file.txt"}, files)
+}
+
+func TestOps_CommitAll(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	before, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+
+	hash, err := ops.CommitAll(ctx, "nothing yet")
+	require.NoError(t, err)
+	assert.Empty(t, hash, "clean tree has nothing to commit")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "new.txt"), []byte("x"), 0600))
+	hash, err = ops.CommitAll(ctx, "herald: add new.txt")
+	require.NoError(t, err)
+	assert.NotEmpty(t, hash)
+	assert.NotEqual(t, before, hash)
+
+	clean, err := ops.IsClean(ctx)
+	require.NoError(t, err)
+	assert.True(t, clean)
+
+	log, err := ops.Log(ctx, 1)
+	require.NoError(t, err)
+	assert.Contains(t, log, "herald: add new.txt")
+}
+
+func TestOps_Discard_RemovesChangesAndUntrackedFiles(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("v1"), 0600))
+	_, err := ops.CommitAll(ctx, "add tracked.txt")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "tracked.txt"), []byte("v2"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("x"), 0600))
+	require.NoError(t, ops.Discard(ctx))
+
+	clean, err := ops.IsClean(ctx)
+	require.NoError(t, err)
+	assert.True(t, clean)
+	content, err := os.ReadFile(filepath.Join(repo, "tracked.txt")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+}
+
+func TestOps_ResetAndDeleteBranch(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ops := NewOps(repo)
+	ctx := context.Background()
+
+	base, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ops.CreateBranch(ctx, "feature"))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a"), 0600))
+	_, err = ops.CommitAll(ctx, "add a.txt")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "b.txt"), []byte("b"), 0600))
+	_, err = ops.CommitAll(ctx, "add b.txt")
+	require.NoError(t, err)
+
+	n, err := ops.CountCommits(ctx, base, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.NoError(t, ops.ResetHard(ctx, "HEAD~1"))
+	n, err = ops.CountCommits(ctx, base, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NoError(t, ops.Checkout(ctx, "main"))
+	require.NoError(t, ops.ResetBranch(ctx, "feature", base))
+	n, err = ops.CountCommits(ctx, base, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	require.NoError(t, ops.DeleteBranch(ctx, "feature"))
+	assert.False(t, ops.BranchExists(ctx, "feature"))
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+type permissionTaskKey struct{}
+
+// WithPermissionTask returns a copy of ctx carrying the ID of the task whose
+// permission requests are served.
+func WithPermissionTask(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, permissionTaskKey{}, taskID)
+}
+
+// permissionDecision is the answer Claude Code expects from its permission
+// prompt tool.
+type permissionDecision struct {
+	Behavior     string         `json:"behavior"` // "allow" or "deny"
+	UpdatedInput map[string]any `json:"updatedInput,omitempty"`
+	Message      string         `json:"message,omitempty"`
+}
+
+// ApprovalPrompt returns the handler Claude Code calls as its permission
+// prompt tool. It holds the call until the user approves or denies the tool
+// use, or the request times out.
+func ApprovalPrompt(tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID, _ := ctx.Value(permissionTaskKey{}).(string)
+		if taskID == "" {
+			return mcp.NewToolResultError("no task is associated with this request"), nil
+		}
+
+		args := req.GetArguments()
+		toolName, _ := args["tool_name"].(string)
+		if toolName == "" {
+			return mcp.NewToolResultError("tool_name is required"), nil
+		}
+		input, _ := args["input"].(map[string]any)
+
+		d := tm.RequestPermission(ctx, taskID, toolName, input)
+		answer := permissionDecision{Behavior: "deny", Message: d.Message}
+		if d.Allow {
+			answer = permissionDecision{Behavior: "allow", UpdatedInput: input}
+			if answer.UpdatedInput == nil {
+				answer.UpdatedInput = map[string]any{}
+			}
+		}
+
+		data, err := json.Marshal(answer)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("encoding decision: %s", err)), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// ApprovePermission returns a handler that allows a tool use a running task
+// is waiting for, once or for the rest of the task.
+func ApprovePermission(tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		requestID, _ := args["request_id"].(string)
+		if requestID == "" {
+			return mcp.NewToolResultError("request_id is required"), nil
+		}
+		scope, _ := args["scope"].(string)
+		switch scope {
+		case "":
+			scope = task.PermissionOnce
+		case task.PermissionOnce, task.PermissionTask:
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid scope %q: must be \"once\" or \"task\"", scope)), nil
+		}
+
+		r, err := tm.AnswerPermission(requestID, task.PermissionDecision{Allow: true}, scope)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to approve: %s", err)), nil
+		}
+
+		if scope == task.PermissionTask {
+			return mcp.NewToolResultText(fmt.Sprintf("✅ Approved %s for task %s. Further %s requests of this task are allowed without asking; other %s uses still ask.", r.Summary, r.TaskID, r.Summary, r.Tool)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("✅ Approved %s for task %s, this once.", r.Summary, r.TaskID)), nil
+	}
+}
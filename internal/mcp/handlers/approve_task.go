@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// ApproveTask returns a handler that approves or rejects a task awaiting
+// approval. When fromChat is false, tasks can only be rejected from Chat
+// and are approved from the workstation or an approval link.
+func ApproveTask(tm *task.Manager, fromChat bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, _ := args["task_id"].(string)
+		if taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+		decision, _ := args["decision"].(string)
+		reason, _ := args["reason"].(string)
+
+		switch decision {
+		case "approve":
+			if !fromChat {
+				return mcp.NewToolResultError(fmt.Sprintf("Approving tasks from Chat is disabled. Run herald approve %s on the workstation or open the approval link.", taskID)), nil
+			}
+			if err := tm.Approve(taskID, task.ApprovalChat); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to approve: %s", err)), nil
+			}
+			t, err := tm.Get(taskID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to approve: %s", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("✅ Task %s approved, now %s. Use check_task to follow it.", taskID, t.Snapshot().Status)), nil
+		case "reject":
+			if err := tm.Reject(taskID, task.ApprovalChat, reason); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to reject: %s", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("⛔ Task %s rejected and cancelled.", taskID)), nil
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid decision %q: must be \"approve\" or \"reject\"", decision)), nil
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// startAwaiting starts a task through start_task with an approval policy
+// that holds every task, and returns its ID.
+func startAwaiting(t *testing.T, tm *task.Manager) string {
+	t.Helper()
+	_, pm := newTestDeps()
+	tm.SetApprovalPolicy(task.ApprovalPolicy{Required: true}, nil)
+	start := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := start(context.Background(), makeReq(map[string]any{"prompt": "rotate keys"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Task awaiting approval")
+	assert.Contains(t, text, "Approval: required, every task requires approval")
+
+	tasks := tm.List(task.Filter{Status: string(task.StatusAwaitingApproval)})
+	require.Len(t, tasks, 1)
+	return tasks[0].ID
+}
+
+func TestApproveTask_WhenApproved_StartsTask(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	id := startAwaiting(t, tm)
+
+	check, err := CheckTask(tm, "mock")(context.Background(), makeReq(map[string]any{"task_id": id}))
+	require.NoError(t, err)
+	assert.Contains(t, check.Content[0].(mcp.TextContent).Text, "Status: awaiting_approval")
+
+	result, err := ApproveTask(tm, true)(context.Background(), makeReq(map[string]any{
+		"task_id":  id,
+		"decision": "approve",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "approved")
+
+	tsk, err := tm.Get(id)
+	require.NoError(t, err)
+	<-tsk.Done()
+	assert.Equal(t, task.ApprovalChat, tsk.Snapshot().ApprovedBy)
+}
+
+func TestApproveTask_WhenChatApprovalDisabled_OnlyRejects(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	id := startAwaiting(t, tm)
+	handler := ApproveTask(tm, false)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id":  id,
+		"decision": "approve",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "herald approve "+id)
+
+	result, err = handler(context.Background(), makeReq(map[string]any{
+		"task_id":  id,
+		"decision": "reject",
+		"reason":   "not requested by me",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	tsk, err := tm.Get(id)
+	require.NoError(t, err)
+	snap := tsk.Snapshot()
+	assert.Equal(t, task.StatusCancelled, snap.Status)
+	assert.Equal(t, "rejected via chat: not requested by me", snap.Error)
+}
+
+func TestApproveTask_WhenDecisionInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ApproveTask(tm, true)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": "herald-ffffffff", "decision": "maybe"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"task_id": "herald-ffffffff", "decision": "approve"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not found")
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -10,7 +11,8 @@ import (
 	"github.com/btouchard/herald/internal/task"
 )
 
-// CancelTask returns a handler that cancels a running task.
+// CancelTask returns a handler that cancels a running task and, with
+// revert, undoes its git changes.
 func CancelTask(tm *task.Manager) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
@@ -19,11 +21,39 @@ func CancelTask(tm *task.Manager) server.ToolHandlerFunc {
 		if !ok || taskID == "" {
 			return mcp.NewToolResultError("task_id is required"), nil
 		}
+		revert, _ := args["revert"].(bool)
 
-		if err := tm.Cancel(taskID); err != nil {
+		if !revert {
+			if err := tm.Cancel(taskID); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel task: %s", err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("🚫 Task %s has been cancelled.", taskID)), nil
+		}
+
+		t, err := tm.Get(taskID)
+		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel task: %s", err)), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("🚫 Task %s has been cancelled.", taskID)), nil
+		undone, revertErr := tm.CancelAndRevert(ctx, taskID)
+		if revertErr != nil && t.Snapshot().Status != task.StatusCancelled {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel task: %s", revertErr)), nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "🚫 Task %s has been cancelled.\n", taskID)
+		switch {
+		case len(undone) > 0:
+			b.WriteString("\nReverted:\n")
+			for _, step := range undone {
+				fmt.Fprintf(&b, "- %s\n", step)
+			}
+		case revertErr == nil:
+			b.WriteString("\nNothing to revert: the task had not changed the project.\n")
+		}
+		if revertErr != nil {
+			fmt.Fprintf(&b, "\n⚠️ Revert incomplete: %s\n", revertErr)
+		}
+		return mcp.NewToolResultText(b.String()), nil
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/btouchard/herald/internal/executor"
 	"github.com/btouchard/herald/internal/task"
 )
 
@@ -56,7 +57,9 @@ func CheckTask(tm *task.Manager, executorName string) server.ToolHandlerFunc {
 			outputLines = int(n)
 		}
 
-		text := formatCheckResponse(snap, includeOutput, outputLines, executorName)
+		queuePos, queueLen := tm.QueuePosition(taskID)
+
+		text := formatCheckResponse(snap, includeOutput, outputLines, executorName, queuePos, queueLen)
 		return mcp.NewToolResultText(text), nil
 	}
 }
@@ -91,7 +94,7 @@ func isTerminalStatus(s task.Status) bool {
 	return s == task.StatusCompleted || s == task.StatusFailed || s == task.StatusCancelled || s == task.StatusLinked
 }
 
-func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines int, executorName string) string {
+func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines int, executorName string, queuePos, queueLen int) string {
 	var b strings.Builder
 
 	if snap.Context != "" {
@@ -99,20 +102,74 @@ func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines
 	}
 
 	switch snap.Status {
-	case task.StatusPending, task.StatusQueued:
+	case task.StatusPending:
 		fmt.Fprintf(&b, "Status: %s\n", snap.Status)
 
+	case task.StatusQueued:
+		fmt.Fprintf(&b, "Status: queued\n")
+		if queuePos > 0 {
+			fmt.Fprintf(&b, "Queue position: %d of %d\n", queuePos, queueLen)
+		}
+		fmt.Fprintf(&b, "Priority: %s\n", snap.Priority)
+		if snap.Progress != "" {
+			fmt.Fprintf(&b, "Progress: %s\n", snap.Progress)
+		}
+		b.WriteString("\nThe task starts automatically when a concurrency slot frees up and its budget allows it.")
+
+	case task.StatusAwaitingApproval:
+		fmt.Fprintf(&b, "Status: awaiting_approval\n")
+		fmt.Fprintf(&b, "Reason: %s\n", snap.ApprovalReason)
+		fmt.Fprintf(&b, "Priority: %s\n", snap.Priority)
+		fmt.Fprintf(&b, "\nThe task starts once a human approves it with approve_task, herald approve %s on the workstation, or the approval link. Ask the user; do not approve it on your own.", snap.ID)
+
+	case task.StatusWaiting:
+		fmt.Fprintf(&b, "Status: waiting\n")
+		fmt.Fprintf(&b, "Waiting for: %s\n", strings.Join(snap.DependsOn, ", "))
+		fmt.Fprintf(&b, "On dependency failure: %s\n", snap.OnDependencyFailure)
+		b.WriteString("\nThe task starts automatically when all its dependencies complete.")
+
 	case task.StatusRunning:
 		fmt.Fprintf(&b, "Status: running\n")
 		fmt.Fprintf(&b, "Duration: %s\n", snap.FormatDuration())
+		if snap.TimeLeft > 0 {
+			fmt.Fprintf(&b, "Time left: %s of a %d minute timeout (extend_task changes it)\n", snap.TimeLeft.Round(time.Second), snap.TimeoutMinutes)
+		}
+		if snap.Retry != nil && len(snap.Attempts) > 0 {
+			fmt.Fprintf(&b, "Attempt: %d of %d\n", len(snap.Attempts)+1, snap.Retry.MaxAttempts)
+		}
 		if snap.Progress != "" {
 			fmt.Fprintf(&b, "Progress: %s\n", snap.Progress)
 		}
-		if snap.CostUSD > 0 {
+		writeTodos(&b, snap.Todos)
+		for _, p := range snap.PendingPermissions {
+			fmt.Fprintf(&b, "⏸️ Waiting for approval: %s (request_id %s, denied automatically in %s)\n",
+				p.Summary, p.ID, time.Until(p.Deadline).Round(time.Second))
+		}
+		if snap.CostUSD > 0 && snap.MaxBudgetUSD > 0 {
+			fmt.Fprintf(&b, "Cost so far: ~$%.2f of a $%.2f budget\n", snap.CostUSD, snap.MaxBudgetUSD)
+		} else if snap.CostUSD > 0 {
 			fmt.Fprintf(&b, "Cost so far: ~$%.2f\n", snap.CostUSD)
 		}
+		if len(snap.PendingPermissions) > 0 {
+			b.WriteString("\nAnswer with approve_permission (scope once or task) or deny_permission.\n")
+		}
 		b.WriteString("\nTip: Use wait_seconds=30 on next check_task call to long-poll efficiently. Do not poll faster than every 30 seconds.")
 
+	case task.StatusPaused:
+		fmt.Fprintf(&b, "Status: paused\n")
+		fmt.Fprintf(&b, "Paused for: %s\n", time.Since(snap.PausedAt).Round(time.Second))
+		if snap.TimeLeft > 0 {
+			fmt.Fprintf(&b, "Time left once resumed: %s\n", snap.TimeLeft.Round(time.Second))
+		}
+		if snap.Progress != "" {
+			fmt.Fprintf(&b, "Progress: %s\n", snap.Progress)
+		}
+		if n := len(snap.Preemptions); n > 0 && snap.Preemptions[n-1].ResumedAt.IsZero() {
+			fmt.Fprintf(&b, "\nPreempted by %s: it resumes automatically when that task ends. Its timeout does not run while it is paused.", snap.Preemptions[n-1].By)
+		} else {
+			b.WriteString("\nUse resume_task to continue it. Its timeout does not run while it is paused.")
+		}
+
 	case task.StatusCompleted:
 		fmt.Fprintf(&b, "Status: completed\n")
 		fmt.Fprintf(&b, "Duration: %s\n", snap.FormatDuration())
@@ -129,6 +186,7 @@ func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines
 		if snap.SessionID != "" {
 			fmt.Fprintf(&b, "Session ID: %s (use to continue this conversation)\n", snap.SessionID)
 		}
+		writeChanges(&b, snap, "")
 		b.WriteString("\nUse get_result for full output, get_diff for changes.")
 
 	case task.StatusFailed:
@@ -137,10 +195,17 @@ func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines
 		if snap.Error != "" {
 			fmt.Fprintf(&b, "Error: %s\n", snap.Error)
 		}
+		if len(snap.Attempts) > 1 {
+			fmt.Fprintf(&b, "Attempts: %d (use get_logs for each attempt)\n", len(snap.Attempts))
+		}
+		writeChanges(&b, snap, "")
 
 	case task.StatusCancelled:
 		fmt.Fprintf(&b, "Status: cancelled\n")
 		fmt.Fprintf(&b, "Duration: %s\n", snap.FormatDuration())
+		if snap.Error != "" {
+			fmt.Fprintf(&b, "Reason: %s\n", snap.Error)
+		}
 
 	case task.StatusLinked:
 		fmt.Fprintf(&b, "Status: linked (external Claude Code session)\n")
@@ -169,6 +234,29 @@ func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines
 		fmt.Fprintf(&b, "\nUse start_task with session_id %q to resume this session.", snap.SessionID)
 	}
 
+	if len(snap.Warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, w := range snap.Warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+	}
+
+	if snap.WorktreePath != "" {
+		fmt.Fprintf(&b, "\nWorktree: %s (branch %s). Use get_diff or read_file with task_id to inspect it.\n", snap.WorktreePath, snap.GitBranch)
+	}
+
+	if snap.Interrupted {
+		if snap.Status == task.StatusFailed {
+			b.WriteString("\nNote: Herald restarted while this task was running.\n")
+		} else {
+			b.WriteString("\nNote: Herald restarted while this task was active; it was requeued.\n")
+		}
+	}
+
+	if len(snap.Preemptions) > 0 {
+		writePreemptions(&b, snap.Preemptions)
+	}
+
 	if includeOutput && snap.Output != "" {
 		lines := lastNLines(snap.Output, outputLines)
 		fmt.Fprintf(&b, "\n--- Last output ---\n%s", lines)
@@ -177,6 +265,20 @@ func formatCheckResponse(snap task.TaskSnapshot, includeOutput bool, outputLines
 	return b.String()
 }
 
+// writePreemptions lists the times a task was paused for a task of higher
+// priority, which explains why its duration grew.
+func writePreemptions(b *strings.Builder, preemptions []task.Preemption) {
+	fmt.Fprintf(b, "\nPreemptions (%d):\n", len(preemptions))
+	for _, p := range preemptions {
+		if p.ResumedAt.IsZero() {
+			fmt.Fprintf(b, "  - paused for %s at %s, not resumed yet\n", p.By, p.PausedAt.Format("15:04:05"))
+			continue
+		}
+		fmt.Fprintf(b, "  - paused for %s at %s, resumed after %s\n",
+			p.By, p.PausedAt.Format("15:04:05"), p.ResumedAt.Sub(p.PausedAt).Round(time.Second))
+	}
+}
+
 func lastNLines(s string, n int) string {
 	lines := strings.Split(s, "\n")
 	if len(lines) <= n {
@@ -184,3 +286,41 @@ func lastNLines(s string, n int) string {
 	}
 	return strings.Join(lines[len(lines)-n:], "\n")
 }
+
+// maxChangedFiles bounds the files listed with the changes of a task.
+const maxChangedFiles = 20
+
+// writeChanges writes what a dispatched task changed in its project, its
+// first line after prefix, or nothing when it changed no file.
+func writeChanges(b *strings.Builder, snap task.TaskSnapshot, prefix string) {
+	changes := task.FormatChanges(len(snap.FilesModified), snap.LinesAdded, snap.LinesRemoved)
+	if changes == "" || snap.Type == task.TypeLinked {
+		return
+	}
+	fmt.Fprintf(b, "%sChanges: %s\n", prefix, changes)
+	for i, f := range snap.FilesModified {
+		if i == maxChangedFiles {
+			fmt.Fprintf(b, "  … and %d more\n", len(snap.FilesModified)-i)
+			break
+		}
+		fmt.Fprintf(b, "  - %s\n", f)
+	}
+}
+
+// writeTodos writes the todo list the executor keeps for a running task.
+func writeTodos(b *strings.Builder, todos []executor.Todo) {
+	if len(todos) == 0 {
+		return
+	}
+	b.WriteString("Todos:\n")
+	for _, todo := range todos {
+		mark := "[ ]"
+		switch todo.Status {
+		case executor.TodoCompleted:
+			mark = "[x]"
+		case executor.TodoInProgress:
+			mark = "[~]"
+		}
+		fmt.Fprintf(b, "  %s %s\n", mark, todo.Content)
+	}
+}
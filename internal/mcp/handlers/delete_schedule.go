@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/scheduler"
+)
+
+// DeleteSchedule returns a handler that removes a recurring task. Tasks it
+// already started are left alone.
+func DeleteSchedule(sc *scheduler.Scheduler) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		name, _ := args["name"].(string)
+		if name == "" {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+
+		if err := sc.Delete(name); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Cannot delete schedule: %s", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Schedule %s deleted. Tasks it already started are not affected.", name)), nil
+	}
+}
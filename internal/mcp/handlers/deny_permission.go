@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// DenyPermission returns a handler that refuses a tool use a running task
+// is waiting for. The reason is passed on to the task.
+func DenyPermission(tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		requestID, _ := args["request_id"].(string)
+		if requestID == "" {
+			return mcp.NewToolResultError("request_id is required"), nil
+		}
+		reason, _ := args["reason"].(string)
+		if reason == "" {
+			reason = "denied by the user"
+		}
+
+		r, err := tm.AnswerPermission(requestID, task.PermissionDecision{Message: reason}, task.PermissionOnce)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to deny: %s", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("⛔ Denied %s for task %s. The task continues without it.", r.Summary, r.TaskID)), nil
+	}
+}
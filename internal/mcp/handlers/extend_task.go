@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// ExtendTask returns a handler that replaces the timeout of a running task.
+func ExtendTask(tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, ok := args["task_id"].(string)
+		if !ok || taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+		minutes, ok := args["timeout_minutes"].(float64)
+		if !ok {
+			return mcp.NewToolResultError("timeout_minutes is required"), nil
+		}
+
+		left, err := tm.Extend(taskID, int(minutes))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to change timeout: %s", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("⏱️ Task %s now times out after %d minutes: %s left.",
+			taskID, int(minutes), left.Round(time.Second))), nil
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,6 +16,8 @@ import (
 
 // GetDiff returns a handler that shows git diff.
 // Accepts either task_id (to resolve project from task) or project directly.
+// Tasks running in their own worktree are diffed inside it, so uncommitted
+// work shows up before anything is merged.
 func GetDiff(tm *task.Manager, pm *project.Manager) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
@@ -23,7 +26,7 @@ func GetDiff(tm *task.Manager, pm *project.Manager) server.ToolHandlerFunc {
 		projectName, _ := args["project"].(string)
 
 		var proj *project.Project
-		var taskBranch string
+		var taskBranch, worktree string
 		var label string
 
 		switch {
@@ -34,6 +37,7 @@ func GetDiff(tm *task.Manager, pm *project.Manager) server.ToolHandlerFunc {
 			}
 			snap := t.Snapshot()
 			taskBranch = snap.GitBranch
+			worktree = existingDir(snap.WorktreePath)
 			proj, err = pm.Get(snap.Project)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Project not found: %s", err)), nil
@@ -61,31 +65,88 @@ func GetDiff(tm *task.Manager, pm *project.Manager) server.ToolHandlerFunc {
 			return mcp.NewToolResultText(fmt.Sprintf("No changes detected for %s (repository has no commits yet).", label)), nil
 		}
 
-		var diff string
-		var err error
-		if taskBranch != "" {
-			branch, brErr := ops.CurrentBranch(ctx)
-			if brErr != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Failed to get current branch: %s", brErr)), nil
-			}
-			diff, err = ops.Diff(ctx, branch, taskBranch)
-		} else {
-			diff, err = ops.Diff(ctx, "HEAD", "")
+		if worktree != "" {
+			label = fmt.Sprintf("%s (worktree %s, branch %s)", label, worktree, taskBranch)
 		}
+		diff, untracked, err := changes(ctx, ops, taskBranch, worktree)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get diff: %s", err)), nil
 		}
 
-		if strings.TrimSpace(diff) == "" {
+		if strings.TrimSpace(diff) == "" && len(untracked) == 0 {
 			return mcp.NewToolResultText(fmt.Sprintf("No changes detected for %s.", label)), nil
 		}
 
 		var sb strings.Builder
 		fmt.Fprintf(&sb, "Diff for %s\n\n", label)
-		sb.WriteString("```diff\n")
-		sb.WriteString(diff)
-		sb.WriteString("\n```\n")
+		if strings.TrimSpace(diff) != "" {
+			sb.WriteString("```diff\n")
+			sb.WriteString(diff)
+			sb.WriteString("\n```\n")
+		}
+		if len(untracked) > 0 {
+			sb.WriteString("\nUntracked files (not in the diff):\n")
+			for _, f := range untracked {
+				fmt.Fprintf(&sb, "- %s\n", f)
+			}
+		}
 
 		return mcp.NewToolResultText(sb.String()), nil
 	}
 }
+
+// changes returns the diff of a task, taken in its worktree when it has
+// one and against its branch otherwise, or the uncommitted changes of the
+// project when taskBranch is empty. Untracked files are only listed for
+// worktrees.
+func changes(ctx context.Context, ops *git.Ops, taskBranch, worktree string) (string, []string, error) {
+	switch {
+	case worktree != "":
+		return worktreeDiff(ctx, ops, git.NewOps(worktree))
+	case taskBranch != "":
+		branch, err := ops.CurrentBranch(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("getting current branch: %w", err)
+		}
+		diff, err := ops.Diff(ctx, branch, taskBranch)
+		return diff, nil, err
+	default:
+		diff, err := ops.Diff(ctx, "HEAD", "")
+		return diff, nil, err
+	}
+}
+
+// worktreeDiff diffs a task worktree, committed and uncommitted changes
+// alike, against the point where the task branch forked from the branch
+// checked out in the project.
+func worktreeDiff(ctx context.Context, repo, worktree *git.Ops) (string, []string, error) {
+	branch, err := repo.CurrentBranch(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	base, err := worktree.MergeBase(ctx, branch, "HEAD")
+	if err != nil {
+		return "", nil, err
+	}
+	diff, err := worktree.Diff(ctx, base, "")
+	if err != nil {
+		return "", nil, err
+	}
+	untracked, err := worktree.UntrackedFiles(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return diff, untracked, nil
+}
+
+// existingDir returns dir if it exists, or "" otherwise (e.g. a task
+// worktree removed by its cleanup policy).
+func existingDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/git"
 	"github.com/btouchard/herald/internal/project"
 	"github.com/btouchard/herald/internal/task"
 )
@@ -190,6 +191,44 @@ func TestGetDiff_WhenTaskHasProject_UsesTaskProject(t *testing.T) {
 	assert.Contains(t, text, "No changes detected")
 }
 
+// addTaskWorktree creates a worktree for tsk on its own branch, as the
+// workspace manager does before execution.
+func addTaskWorktree(t *testing.T, repoPath string, tsk *task.Task) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), tsk.ID)
+	require.NoError(t, git.NewOps(repoPath).AddWorktree(context.Background(), dir, "herald/"+tsk.ID, "HEAD"))
+	tsk.SetWorktree(dir, "herald/"+tsk.ID)
+	return dir
+}
+
+func TestGetDiff_WhenTaskHasWorktree_ShowsUncommittedWork(t *testing.T) {
+	t.Parallel()
+	repoPath := initGitRepo(t)
+	tm, pm := newDiffTestDeps(repoPath)
+	handler := GetDiff(tm, pm)
+
+	tsk := tm.Create("test-repo", "some task", "", task.PriorityNormal, 30)
+	wt := addTaskWorktree(t, repoPath, tsk)
+	require.NoError(t, os.WriteFile(filepath.Join(wt, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(wt, "new.go"), []byte("package main\n"), 0600))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "worktree")
+	assert.Contains(t, text, "+func main() {}")
+	assert.Contains(t, text, "Untracked files")
+	assert.Contains(t, text, "new.go")
+
+	// The project checkout itself is untouched
+	content, err := os.ReadFile(filepath.Join(repoPath, "main.go")) //nolint:gosec // test reads file it created
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+}
+
 // --- ReadFile handler tests ---
 
 type readFileDeps struct {
@@ -217,7 +256,7 @@ func newReadFileDeps(t *testing.T) readFileDeps {
 
 	me := &mockExecutor{}
 	tm := task.NewManager(me, 3, 2*time.Hour)
-	handler := ReadFile(pm)
+	handler := ReadFile(tm, pm)
 
 	return readFileDeps{tm: tm, pm: pm, handler: handler, root: root}
 }
@@ -330,11 +369,55 @@ func TestReadFile_WhenFileTooLarge_ReturnsError(t *testing.T) {
 	assert.Contains(t, text, "File too large")
 }
 
+func TestReadFile_WhenTaskHasWorktree_ReadsFromWorktree(t *testing.T) {
+	t.Parallel()
+	repoPath := initGitRepo(t)
+	tm, pm := newDiffTestDeps(repoPath)
+	handler := ReadFile(tm, pm)
+
+	tsk := tm.Create("test-repo", "some task", "", task.PriorityNormal, 30)
+	wt := addTaskWorktree(t, repoPath, tsk)
+	require.NoError(t, os.WriteFile(filepath.Join(wt, "main.go"), []byte("package worktree\n"), 0600))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"path":    "main.go",
+	}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "package worktree")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"path":    "../../etc/passwd",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestReadFile_WhenTaskHasNoWorktree_ReadsFromProject(t *testing.T) {
+	t.Parallel()
+	repoPath := initGitRepo(t)
+	tm, pm := newDiffTestDeps(repoPath)
+	handler := ReadFile(tm, pm)
+
+	tsk := tm.Create("test-repo", "some task", "", task.PriorityNormal, 30)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"path":    "main.go",
+	}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "package main")
+}
+
 func TestReadFile_WhenInvalidProject_ReturnsError(t *testing.T) {
 	t.Parallel()
 
 	pm := project.NewManager(map[string]config.Project{})
-	handler := ReadFile(pm)
+	tm := task.NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	handler := ReadFile(tm, pm)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"path":    "file.go",
@@ -469,6 +552,27 @@ func TestGetResult_WhenFormatFull_WithCostAndError_ShowsAll(t *testing.T) {
 	assert.Contains(t, text, "Full output")
 }
 
+func TestGetResult_WhenGitWarnings_ShowsBranchAndWarnings(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := GetResult(tm, "mock")
+
+	tsk := tm.Create("test", "fix", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	tsk.SetGitBranch("herald/" + tsk.ID)
+	tsk.AddWarning("git: could not restore stashed changes, they remain in the stash")
+	tsk.SetStatus(task.StatusCompleted)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "- Branch: herald/"+tsk.ID)
+	assert.Contains(t, text, "- Warning: git: could not restore stashed changes")
+}
+
 func TestGetResult_WhenTaskPending_InformsUserStillPending(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
@@ -582,7 +686,7 @@ func TestListTasks_WhenFailedTaskWithError_ShowsError(t *testing.T) {
 func TestGetLogs_WhenTaskHasSessionAndCost_ShowsAll(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
 	tsk.SetStatus(task.StatusRunning)
@@ -607,7 +711,7 @@ func TestGetLogs_WhenTaskHasSessionAndCost_ShowsAll(t *testing.T) {
 func TestGetLogs_WhenLimitProvided_RespectsIt(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	for range 5 {
 		tm.Create("test", "task", "", task.PriorityNormal, 30)
@@ -708,7 +812,7 @@ func TestCancelTask_WhenAlreadyCompleted_ReturnsError(t *testing.T) {
 func TestStartTask_WhenMissingPrompt_ReturnsError(t *testing.T) {
 	t.Parallel()
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{}))
 	require.NoError(t, err)
@@ -720,7 +824,7 @@ func TestStartTask_WhenMissingPrompt_ReturnsError(t *testing.T) {
 func TestStartTask_WhenDryRun_ShowsDryRunMode(t *testing.T) {
 	t.Parallel()
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt":  "plan the refactoring",
@@ -735,7 +839,7 @@ func TestStartTask_WhenDryRun_ShowsDryRunMode(t *testing.T) {
 func TestStartTask_WhenSessionID_ShowsResuming(t *testing.T) {
 	t.Parallel()
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt":     "continue",
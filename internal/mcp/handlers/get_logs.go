@@ -4,15 +4,24 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/btouchard/herald/internal/store"
 	"github.com/btouchard/herald/internal/task"
 )
 
-// GetLogs returns a handler that shows task activity and events.
-func GetLogs(tm *task.Manager) server.ToolHandlerFunc {
+// EventLog lists the recorded events of tasks. Defined at the consumer
+// side per Go convention; satisfied by store.Store.
+type EventLog interface {
+	ListEvents(f store.EventFilter) ([]store.TaskEvent, error)
+}
+
+// GetLogs returns a handler that shows task activity and events. Without
+// an event log, it shows the state of tasks only.
+func GetLogs(tm *task.Manager, events EventLog) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
 
@@ -22,15 +31,90 @@ func GetLogs(tm *task.Manager) server.ToolHandlerFunc {
 			limit = int(l)
 		}
 
+		if events == nil {
+			if taskID != "" {
+				return getTaskLogs(tm, taskID, nil)
+			}
+			return getRecentActivity(tm, limit)
+		}
+
+		f, err := eventFilter(args, time.Now())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		f.Limit = limit
+
 		if taskID != "" {
-			return getTaskLogs(tm, taskID)
+			f.TaskID = taskID
+			return getTaskLogs(tm, taskID, func(sb *strings.Builder) error {
+				return writeTimeline(sb, events, f)
+			})
+		}
+		if f.Levels == nil {
+			f.Levels = store.LevelsFrom(store.LevelInfo) // progress is too chatty across tasks
+		}
+		return getRecentEvents(tm, events, f)
+	}
+}
+
+// eventFilter reads the event filters of get_logs from args.
+func eventFilter(args map[string]any, now time.Time) (store.EventFilter, error) {
+	var f store.EventFilter
+	f.Project, _ = args["project"].(string)
+
+	if level, _ := args["level"].(string); level != "" {
+		f.Levels = store.LevelsFrom(level)
+		if f.Levels == nil {
+			return f, fmt.Errorf("invalid level %q: must be debug, info, warn or error", level)
+		}
+	}
+
+	if types, _ := args["types"].(string); types != "" {
+		for _, typ := range strings.Split(types, ",") {
+			typ = strings.TrimSpace(typ)
+			if typ == "" {
+				continue
+			}
+			if !strings.HasPrefix(typ, "task.") {
+				typ = "task." + typ
+			}
+			f.Types = append(f.Types, typ)
 		}
+	}
+
+	if since, _ := args["since"].(string); since != "" {
+		t, err := parseSince(since, now)
+		if err != nil {
+			return f, err
+		}
+		f.Since = t
+	}
 
-		return getRecentActivity(tm, limit)
+	if after, ok := args["after"].(float64); ok && after > 0 {
+		f.AfterID = int64(after)
 	}
+	if before, ok := args["before"].(float64); ok && before > 0 {
+		f.BeforeID = int64(before)
+	}
+	return f, nil
 }
 
-func getTaskLogs(tm *task.Manager, taskID string) (*mcp.CallToolResult, error) {
+// parseSince reads an RFC 3339 time, or a duration before now such as
+// "30m" or "2h".
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q: use an RFC 3339 time or a duration such as 30m", s)
+	}
+	return now.Add(-d), nil
+}
+
+// getTaskLogs describes a task, then its event timeline when
+// writeEvents is set, or its last progress otherwise.
+func getTaskLogs(tm *task.Manager, taskID string, writeEvents func(sb *strings.Builder) error) (*mcp.CallToolResult, error) {
 	t, err := tm.Get(taskID)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Task not found: %s", err)), nil
@@ -62,16 +146,117 @@ func getTaskLogs(tm *task.Manager, taskID string) (*mcp.CallToolResult, error) {
 	if snap.Turns > 0 {
 		fmt.Fprintf(&sb, "Turns: %d\n", snap.Turns)
 	}
+	if len(snap.Attempts) > 0 {
+		writeAttempts(&sb, snap)
+	}
+	if len(snap.Preemptions) > 0 {
+		writePreemptions(&sb, snap.Preemptions)
+	}
 	if snap.Error != "" {
 		fmt.Fprintf(&sb, "\nError: %s\n", snap.Error)
 	}
-	if snap.Progress != "" {
+	if writeEvents != nil {
+		if err := writeEvents(&sb); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %s", err)), nil
+		}
+	} else if snap.Progress != "" {
 		fmt.Fprintf(&sb, "\nLast progress: %s\n", snap.Progress)
 	}
 
 	return mcp.NewToolResultText(sb.String()), nil
 }
 
+// writeTimeline lists the events of a task matching f, oldest first.
+func writeTimeline(sb *strings.Builder, events EventLog, f store.EventFilter) error {
+	list, err := events.ListEvents(f)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		sb.WriteString("\nNo events match.\n")
+		return nil
+	}
+
+	fmt.Fprintf(sb, "\nEvents (%d):\n", len(list))
+	for _, e := range list {
+		fmt.Fprintf(sb, "  #%d %s %s %s — %s\n", e.ID, e.CreatedAt.Format("2006-01-02 15:04:05"),
+			levelTag(e.Level), e.EventType, e.Message)
+	}
+	writeCursors(sb, list, f.Limit)
+	return nil
+}
+
+// getRecentEvents lists the latest events matching f across tasks.
+func getRecentEvents(tm *task.Manager, events EventLog, f store.EventFilter) (*mcp.CallToolResult, error) {
+	list, err := events.ListEvents(f)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read events: %s", err)), nil
+	}
+	if len(list) == 0 {
+		return mcp.NewToolResultText("No activity recorded yet."), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📋 Recent activity (%d events)\n\n", len(list))
+	for _, e := range list {
+		fmt.Fprintf(&sb, "#%d %s %s %s", e.ID, e.CreatedAt.Format("2006-01-02 15:04:05"), levelTag(e.Level), e.TaskID)
+		if t, err := tm.Get(e.TaskID); err == nil {
+			fmt.Fprintf(&sb, " (%s)", t.Snapshot().Project)
+		}
+		fmt.Fprintf(&sb, " %s — %s\n", e.EventType, e.Message)
+	}
+	writeCursors(&sb, list, f.Limit)
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// writeCursors tells how to page from list: to older events when it is
+// full, and always to newer ones, so that polling picks up where it left.
+func writeCursors(sb *strings.Builder, list []store.TaskEvent, limit int) {
+	sb.WriteString("\n")
+	if len(list) >= limit {
+		fmt.Fprintf(sb, "Older events: before=%d\n", list[0].ID)
+	}
+	fmt.Fprintf(sb, "Newer events: after=%d\n", list[len(list)-1].ID)
+}
+
+// levelTag formats an event level to line up in lists.
+func levelTag(level string) string {
+	return fmt.Sprintf("%-7s", "["+level+"]")
+}
+
+// writeAttempts lists the attempts of a task with a retry policy.
+func writeAttempts(sb *strings.Builder, snap task.TaskSnapshot) {
+	fmt.Fprintf(sb, "\nAttempts (%d", len(snap.Attempts))
+	if snap.Retry != nil {
+		fmt.Fprintf(sb, " of max %d", snap.Retry.MaxAttempts)
+	}
+	sb.WriteString("):\n")
+
+	for _, a := range snap.Attempts {
+		icon := "❌"
+		switch a.Outcome {
+		case task.AttemptCompleted:
+			icon = "✅"
+		case task.AttemptTimedOut:
+			icon = "⏱️"
+		case task.AttemptCancelled:
+			icon = "🚫"
+		}
+		fmt.Fprintf(sb, "  #%d %s %s — %s → %s", a.Number, icon, a.Outcome,
+			a.StartedAt.Format("15:04:05"), a.EndedAt.Format("15:04:05"))
+		if a.CostUSD > 0 {
+			fmt.Fprintf(sb, " — $%.4f", a.CostUSD)
+		}
+		sb.WriteString("\n")
+		if a.Error != "" {
+			fmt.Fprintf(sb, "     Error: %s\n", a.Error)
+		}
+		if a.RetryOn != "" {
+			fmt.Fprintf(sb, "     Retried on %s\n", a.RetryOn)
+		}
+	}
+}
+
 func getRecentActivity(tm *task.Manager, limit int) (*mcp.CallToolResult, error) {
 	tasks := tm.List(task.Filter{Status: "all", Limit: limit})
 
@@ -84,8 +269,12 @@ func getRecentActivity(tm *task.Manager, limit int) (*mcp.CallToolResult, error)
 
 	for _, t := range tasks {
 		icon := statusIcon(t.Status)
-		fmt.Fprintf(&sb, "%s %s — %s (%s) — %s\n",
+		fmt.Fprintf(&sb, "%s %s — %s (%s) — %s",
 			icon, t.ID, t.Status, t.Project, t.CreatedAt.Format("15:04:05"))
+		if len(t.Attempts) > 1 {
+			fmt.Fprintf(&sb, " — %d attempts", len(t.Attempts))
+		}
+		sb.WriteString("\n")
 	}
 
 	return mcp.NewToolResultText(sb.String()), nil
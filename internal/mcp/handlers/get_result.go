@@ -30,7 +30,7 @@ func GetResult(tm *task.Manager, executorName string) server.ToolHandlerFunc {
 
 		snap := t.Snapshot()
 
-		if snap.Status == task.StatusRunning || snap.Status == task.StatusPending || snap.Status == task.StatusQueued {
+		if snap.Status == task.StatusRunning || snap.Status == task.StatusPaused || snap.Status == task.StatusPending || snap.Status == task.StatusQueued || snap.Status == task.StatusWaiting || snap.Status == task.StatusAwaitingApproval {
 			return mcp.NewToolResultText(
 				fmt.Sprintf("Task %s is still %s. Use check_task to monitor progress.", taskID, snap.Status),
 			), nil
@@ -82,9 +82,17 @@ func formatSummary(snap task.TaskSnapshot, executorName string) (*mcp.CallToolRe
 		fmt.Fprintf(&b, "- Turns: %d\n", snap.Turns)
 	}
 
+	if snap.GitBranch != "" {
+		fmt.Fprintf(&b, "- Branch: %s\n", snap.GitBranch)
+	}
+	writeChanges(&b, snap, "- ")
+
 	if snap.Error != "" {
 		fmt.Fprintf(&b, "- Error: %s\n", snap.Error)
 	}
+	for _, w := range snap.Warnings {
+		fmt.Fprintf(&b, "- Warning: %s\n", w)
+	}
 
 	if snap.Output != "" {
 		summary := truncateSummary(snap.Output, 1000)
@@ -109,11 +117,19 @@ func formatFull(snap task.TaskSnapshot, executorName string) (*mcp.CallToolResul
 	if snap.CostUSD > 0 {
 		fmt.Fprintf(&b, " | Cost: $%.2f", snap.CostUSD)
 	}
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	writeChanges(&b, snap, "")
+	b.WriteString("\n")
 
 	if snap.Error != "" {
 		fmt.Fprintf(&b, "Error: %s\n\n", snap.Error)
 	}
+	for _, w := range snap.Warnings {
+		fmt.Fprintf(&b, "Warning: %s\n", w)
+	}
+	if len(snap.Warnings) > 0 {
+		b.WriteString("\n")
+	}
 
 	if snap.Output != "" {
 		fmt.Fprintf(&b, "--- Full output ---\n%s\n", snap.Output)
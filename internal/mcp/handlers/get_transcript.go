@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// TranscriptLog lists the recorded transcripts of tasks. Defined at the
+// consumer side per Go convention; satisfied by store.Store.
+type TranscriptLog interface {
+	ListTranscript(f store.TranscriptFilter) ([]store.TranscriptEntry, error)
+}
+
+// GetTranscript returns a handler that pages through the transcript of a
+// task: its turns, tool calls with their inputs and results, and timings.
+func GetTranscript(tm *task.Manager, transcripts TranscriptLog) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, _ := args["task_id"].(string)
+		if taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+		t, err := tm.Get(taskID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Task not found: %s", err)), nil
+		}
+
+		f := store.TranscriptFilter{TaskID: taskID, Limit: 50}
+		f.Tool, _ = args["tool"].(string)
+		if a, ok := args["attempt"].(float64); ok && a > 0 {
+			f.Attempt = int(a)
+		}
+		if after, ok := args["after"].(float64); ok && after > 0 {
+			f.AfterSeq = int(after)
+		}
+		if l, ok := args["limit"].(float64); ok && l > 0 {
+			f.Limit = int(l)
+		}
+
+		// One more entry than asked tells whether there is a next page.
+		limit := f.Limit
+		f.Limit++
+		entries, err := transcripts.ListTranscript(f)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read transcript: %s", err)), nil
+		}
+		more := len(entries) > limit
+		if more {
+			entries = entries[:limit]
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "📜 Transcript of task %s", taskID)
+		if f.Tool != "" {
+			fmt.Fprintf(&b, " — %s calls", f.Tool)
+		}
+		b.WriteString("\n\n")
+
+		if len(entries) == 0 {
+			switch {
+			case f.AfterSeq > 0 || f.Tool != "" || f.Attempt > 0:
+				b.WriteString("No entries match.\n")
+			case !t.IsTerminal():
+				b.WriteString("No transcript yet: it is recorded when an attempt ends. Use get_logs to follow a running task.\n")
+			default:
+				b.WriteString("No transcript was recorded for this task.\n")
+			}
+			return mcp.NewToolResultText(b.String()), nil
+		}
+
+		attempt := 0
+		for _, e := range entries {
+			if e.Attempt != attempt {
+				attempt = e.Attempt
+				fmt.Fprintf(&b, "— Attempt %d —\n", attempt)
+			}
+			writeTranscriptEntry(&b, e)
+		}
+
+		if more {
+			fmt.Fprintf(&b, "\nMore entries: after=%d\n", entries[len(entries)-1].Seq)
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
+
+// writeTranscriptEntry writes e as a header line, followed by its text
+// indented.
+func writeTranscriptEntry(b *strings.Builder, e store.TranscriptEntry) {
+	fmt.Fprintf(b, "#%d %s ", e.Seq, e.CreatedAt.Format("15:04:05"))
+	text := e.Text
+	switch e.Kind {
+	case executor.EntryUser:
+		b.WriteString("👤 user")
+	case executor.EntryAssistant:
+		b.WriteString("🤖 assistant")
+	case executor.EntryThinking:
+		b.WriteString("💭 thinking")
+	case executor.EntryToolUse:
+		fmt.Fprintf(b, "🔧 %s", e.Tool)
+		text = e.Input
+	case executor.EntryToolResult:
+		icon := "📄"
+		if e.IsError {
+			icon = "❌"
+		}
+		fmt.Fprintf(b, "%s %s result", icon, e.Tool)
+	case executor.EntryResult:
+		icon := "🏁"
+		if e.IsError {
+			icon = "❌"
+		}
+		fmt.Fprintf(b, "%s result", icon)
+	default:
+		b.WriteString(e.Kind)
+	}
+	if d := e.Duration.Round(100 * time.Millisecond); d > 0 {
+		fmt.Fprintf(b, " (%s)", d)
+	}
+	if e.InputTokens > 0 || e.OutputTokens > 0 {
+		fmt.Fprintf(b, " — %d in / %d out tokens", e.InputTokens, e.OutputTokens)
+	}
+	b.WriteString("\n")
+
+	if text = strings.TrimSpace(text); text != "" {
+		b.WriteString("   ")
+		b.WriteString(strings.ReplaceAll(text, "\n", "\n   "))
+		b.WriteString("\n")
+	}
+}
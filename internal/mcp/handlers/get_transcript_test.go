@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+	"github.com/btouchard/herald/internal/task"
+)
+
+func TestGetTranscript_ShowsToolCallsAndPages(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetTranscript(tm, db)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusFailed)
+	now := time.Now()
+	require.NoError(t, db.AddTranscript([]store.TranscriptEntry{
+		{TaskID: tsk.ID, Attempt: 1, Kind: executor.EntryAssistant, Text: "Running the tests.", InputTokens: 1200, OutputTokens: 40, CreatedAt: now},
+		{TaskID: tsk.ID, Attempt: 1, Kind: executor.EntryToolUse, Tool: "Bash", Input: `{"command":"go test ./..."}`, CreatedAt: now},
+		{TaskID: tsk.ID, Attempt: 1, Kind: executor.EntryToolResult, Tool: "Bash", Text: "FAIL auth\nexit 1", IsError: true, Duration: 7 * time.Second, CreatedAt: now},
+		{TaskID: tsk.ID, Attempt: 1, Kind: executor.EntryToolUse, Tool: "Read", Input: `{"file_path":"auth.go"}`, CreatedAt: now},
+	}))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "— Attempt 1 —")
+	assert.Contains(t, text, "🤖 assistant — 1200 in / 40 out tokens\n   Running the tests.")
+	assert.Contains(t, text, "🔧 Bash\n   {\"command\":\"go test ./...\"}")
+	assert.Contains(t, text, "❌ Bash result (7s)\n   FAIL auth\n   exit 1")
+	assert.NotContains(t, text, "More entries")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID, "tool": "bash"}))
+	require.NoError(t, err)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "bash calls")
+	assert.NotContains(t, text, "Read")
+	assert.NotContains(t, text, "assistant")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID, "limit": float64(2)}))
+	require.NoError(t, err)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "More entries: after=2")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID, "after": float64(3)}))
+	require.NoError(t, err)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "#4 ")
+	assert.NotContains(t, text, "#3 ")
+}
+
+func TestGetTranscript_WhenRunningWithoutTranscript_PointsToGetLogs(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetTranscript(tm, db)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "recorded when an attempt ends")
+}
+
+func TestGetTranscript_WhenTaskNotFound_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetTranscript(tm, db)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": "herald-nonexist"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
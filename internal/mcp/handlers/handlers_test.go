@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -9,6 +11,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+	"github.com/btouchard/herald/internal/store"
 	"github.com/btouchard/herald/internal/task"
 )
 
@@ -81,6 +86,49 @@ func TestCheckTask_WhenCompleted_ShowsCostAndTurns(t *testing.T) {
 	assert.Contains(t, text, "ses_abc")
 }
 
+func TestCheckTask_WhenRunningWithTodos_ShowsThem(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := CheckTask(tm, "mock")
+
+	tsk := tm.Create("test", "do something", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	tsk.SetTodos([]executor.Todo{
+		{Content: "Fix the handler", Status: executor.TodoCompleted},
+		{Content: "Run tests", Status: executor.TodoInProgress},
+		{Content: "Update docs", Status: executor.TodoPending},
+	})
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Todos:\n  [x] Fix the handler\n  [~] Run tests\n  [ ] Update docs\n")
+}
+
+func TestCheckTask_WhenCompleted_ShowsChanges(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := CheckTask(tm, "mock")
+
+	tsk := tm.Create("test", "do something", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	tsk.SetChanges(task.Changes{Files: []string{"internal/auth/auth.go", "README.md"}, Added: 12, Removed: 4})
+	tsk.SetStatus(task.StatusCompleted)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Changes: 2 files changed (+12/-4)")
+	assert.Contains(t, text, "  - internal/auth/auth.go\n")
+	assert.Contains(t, text, "  - README.md\n")
+}
+
 func TestCheckTask_WhenIncludeOutput_ShowsOutput(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
@@ -102,6 +150,53 @@ func TestCheckTask_WhenIncludeOutput_ShowsOutput(t *testing.T) {
 	assert.Contains(t, text, "Last output")
 }
 
+func TestCheckTask_WhenQueued_ShowsQueuePosition(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	tm := task.NewManager(&blockingExecutor{}, 1, 2*time.Hour)
+	handler := CheckTask(tm, "mock")
+	proj, err := pm.Resolve("")
+	require.NoError(t, err)
+
+	running := tm.Create(proj.Name, "first", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), running, executor.Request{TaskID: running.ID}, 0))
+	queued := tm.Create(proj.Name, "second", "", task.PriorityHigh, 30)
+	require.NoError(t, tm.Start(context.Background(), queued, executor.Request{TaskID: queued.ID}, 0))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": queued.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Status: queued")
+	assert.Contains(t, text, "Queue position: 1 of 1")
+	assert.Contains(t, text, "Priority: high")
+
+	_ = tm.Cancel(queued.ID)
+	_ = tm.Cancel(running.ID)
+}
+
+func TestCheckTask_WhenTaskHasWorktree_ShowsIt(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := CheckTask(tm, "mock")
+	proj, err := pm.Resolve("")
+	require.NoError(t, err)
+
+	tsk := tm.Create(proj.Name, "isolated", "", task.PriorityNormal, 30)
+	tsk.SetWorktree("/work/worktrees/"+tsk.ID, "herald/"+tsk.ID)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Worktree: /work/worktrees/"+tsk.ID)
+	assert.Contains(t, text, "branch herald/"+tsk.ID)
+}
+
 // --- CheckTask long-polling tests ---
 
 func TestCheckTask_WhenWaitZero_ReturnsImmediately(t *testing.T) {
@@ -279,6 +374,32 @@ func TestGetResult_WhenTaskCompleted_ReturnsSummary(t *testing.T) {
 	assert.Contains(t, text, "Fixed the auth bug")
 }
 
+func TestGetResult_WhenTaskChangedFiles_ListsThem(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := GetResult(tm, "mock")
+
+	files := make([]string, 25)
+	for i := range files {
+		files[i] = fmt.Sprintf("pkg/file%d.go", i)
+	}
+	tsk := tm.Create("test", "fix the bug", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	tsk.SetChanges(task.Changes{Files: files, Added: 50, Removed: 25})
+	tsk.SetStatus(task.StatusCompleted)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "- Changes: 25 files changed (+50/-25)")
+	assert.Contains(t, text, "  - pkg/file19.go\n")
+	assert.NotContains(t, text, "pkg/file20.go")
+	assert.Contains(t, text, "… and 5 more")
+}
+
 func TestGetResult_WhenMissingTaskID_ReturnsError(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
@@ -394,6 +515,93 @@ func TestCancelTask_WhenTaskNotFound_ReturnsError(t *testing.T) {
 	assert.Contains(t, text, "Failed to cancel")
 }
 
+// revertingWorkspace reports a fixed list of undone steps on Revert.
+type revertingWorkspace struct {
+	undone []string
+}
+
+func (w *revertingWorkspace) Prepare(context.Context, *task.Task, *executor.Request) error {
+	return nil
+}
+
+func (w *revertingWorkspace) Release(context.Context, *task.Task) {}
+
+func (w *revertingWorkspace) Revert(context.Context, *task.Task) ([]string, error) {
+	return w.undone, nil
+}
+
+func TestCancelTask_WhenRevert_ReportsWhatWasUndone(t *testing.T) {
+	t.Parallel()
+	tm := task.NewManager(&blockingExecutor{}, 3, 2*time.Hour)
+	tm.SetWorkspace(&revertingWorkspace{undone: []string{
+		"discarded uncommitted changes",
+		"switched back to main",
+		"deleted branch herald/x (2 commits)",
+	}})
+	handler := CancelTask(tm)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"revert":  true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "has been cancelled")
+	assert.Contains(t, text, "Reverted:\n- discarded uncommitted changes\n- switched back to main\n- deleted branch herald/x (2 commits)")
+}
+
+func TestCancelTask_WhenRevertBeforeStart_ReportsNothingToRevert(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := CancelTask(tm)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"revert":  true,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "has been cancelled")
+	assert.Contains(t, text, "Nothing to revert")
+	assert.Equal(t, task.StatusCancelled, tsk.Snapshot().Status)
+}
+
+// --- ListTemplates tests ---
+
+func TestListTemplates_ShowsVariablesAndDefaults(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	handler := ListTemplates(pm, newTestTemplates())
+
+	result, err := handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "1 template(s) available** for project test")
+	assert.Contains(t, text, "**review**")
+	assert.Contains(t, text, `Variables: scope (required), focus (default "correctness"), prompt (your prompt)`)
+	assert.Contains(t, text, "Defaults: model claude-opus-4-6, timeout 20m, priority high, dry run")
+	assert.Contains(t, text, "Tools: Read, Grep")
+}
+
+func TestListTemplates_WhenNoneConfigured_SaysSo(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	handler := ListTemplates(pm, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No task templates configured")
+}
+
 // --- ListTasks tests ---
 
 func TestListTasks_WhenNoTasks_ReturnsEmptyMessage(t *testing.T) {
@@ -461,12 +669,60 @@ func TestListTasks_WhenLimitSet_RespectsIt(t *testing.T) {
 	assert.Contains(t, text, "2 found")
 }
 
+func TestListTasks_WhenFilterByFile_ReturnsTasksThatTouchedIt(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ListTasks(tm)
+
+	auth := tm.Create("test", "fix login", "", task.PriorityNormal, 30)
+	auth.SetStatus(task.StatusRunning)
+	auth.SetChanges(task.Changes{Files: []string{"internal/auth/token.go"}, Added: 7, Removed: 2})
+	auth.SetStatus(task.StatusCompleted)
+	other := tm.Create("test", "update docs", "", task.PriorityNormal, 30)
+	other.SetStatus(task.StatusRunning)
+	other.SetChanges(task.Changes{Files: []string{"README.md"}, Added: 1})
+	other.SetStatus(task.StatusCompleted)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"file": "internal/auth",
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "1 found")
+	assert.Contains(t, text, auth.ID)
+	assert.Contains(t, text, "| 1 file changed (+7/-2)")
+	assert.NotContains(t, text, other.ID)
+}
+
+func TestListTasks_WhenTasksDependOnEachOther_ShowsDependencyGraph(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ListTasks(tm)
+
+	parent := tm.Create("test", "refactor", "", task.PriorityNormal, 30)
+	migrate := tm.Create("test", "migrate", "", task.PriorityNormal, 30)
+	migrate.DependsOn = []string{parent.ID}
+	require.NoError(t, tm.Start(context.Background(), migrate, executor.Request{TaskID: migrate.ID}, 0))
+	docs := tm.Create("test", "docs", "", task.PriorityNormal, 30)
+	docs.DependsOn = []string{migrate.ID}
+	require.NoError(t, tm.Start(context.Background(), docs, executor.Request{TaskID: docs.ID}, 0))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Depends on: "+parent.ID)
+	assert.Contains(t, text, "Dependency graph")
+	assert.Contains(t, text, "⏳ "+parent.ID+" — pending\n└─ ⛓️ "+migrate.ID+" — waiting\n   └─ ⛓️ "+docs.ID+" — waiting\n")
+}
+
 // --- GetLogs tests ---
 
 func TestGetLogs_WhenTaskExists_ShowsLogs(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
 	tsk.SetStatus(task.StatusRunning)
@@ -485,10 +741,38 @@ func TestGetLogs_WhenTaskExists_ShowsLogs(t *testing.T) {
 	assert.Contains(t, text, "fixing bugs")
 }
 
+func TestGetLogs_WhenTaskRetried_ShowsAttempts(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := GetLogs(tm, nil)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	tsk.Retry = &retry.Policy{MaxAttempts: 3}
+	now := time.Now()
+	tsk.AddAttempt(task.Attempt{
+		Number: 1, Outcome: task.AttemptFailed, ExitCode: 1, Error: "claude exited with code 1",
+		RetryOn: "exit code 1", CostUSD: 0.1, StartedAt: now, EndedAt: now,
+	})
+	tsk.AddAttempt(task.Attempt{Number: 2, Outcome: task.AttemptCompleted, StartedAt: now, EndedAt: now})
+	tsk.SetStatus(task.StatusCompleted)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Attempts (2 of max 3):")
+	assert.Contains(t, text, "#1 ❌ failed")
+	assert.Contains(t, text, "Error: claude exited with code 1")
+	assert.Contains(t, text, "Retried on exit code 1")
+	assert.Contains(t, text, "#2 ✅ completed")
+}
+
 func TestGetLogs_WhenTaskNotFound_ReturnsError(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"task_id": "herald-nonexist",
@@ -502,7 +786,7 @@ func TestGetLogs_WhenTaskNotFound_ReturnsError(t *testing.T) {
 func TestGetLogs_WhenNoTaskID_ShowsRecentActivity(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	tm.Create("test", "task 1", "", task.PriorityNormal, 30)
 	time.Sleep(10 * time.Millisecond) // ensure ordering
@@ -519,7 +803,7 @@ func TestGetLogs_WhenNoTaskID_ShowsRecentActivity(t *testing.T) {
 func TestGetLogs_WhenNoActivity_ReturnsEmpty(t *testing.T) {
 	t.Parallel()
 	tm, _ := newTestDeps()
-	handler := GetLogs(tm)
+	handler := GetLogs(tm, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{}))
 	require.NoError(t, err)
@@ -528,6 +812,136 @@ func TestGetLogs_WhenNoActivity_ReturnsEmpty(t *testing.T) {
 	assert.Contains(t, text, "No activity")
 }
 
+// newEventDeps returns a task manager recording its events to a store,
+// and the store.
+func newEventDeps(t *testing.T) (*task.Manager, *store.SQLiteStore) {
+	t.Helper()
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "herald.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	tm, _ := newTestDeps()
+	tm.SetStore(db)
+	return tm, db
+}
+
+func TestGetLogs_WhenEventsRecorded_ShowsTimeline(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetLogs(tm, db)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+	<-tsk.Done()
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+	}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Events (")
+	assert.Contains(t, text, "[info]  task.created")
+	assert.Contains(t, text, "[info]  task.started")
+	assert.Contains(t, text, "task.completed")
+	assert.Contains(t, text, "Newer events: after=")
+	assert.NotContains(t, text, "Last progress")
+}
+
+func TestGetLogs_WhenFiltered_ShowsMatchingEvents(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetLogs(tm, db)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	for _, e := range []store.TaskEvent{
+		{EventType: "task.tool_use", Level: store.LevelDebug, Message: "Using tool: Bash"},
+		{EventType: "task.warning", Level: store.LevelWarn, Message: "git: stash failed"},
+		{EventType: "task.failed", Level: store.LevelError, Message: "boom"},
+	} {
+		e.TaskID = tsk.ID
+		e.CreatedAt = time.Now()
+		require.NoError(t, db.AddEvent(&e))
+	}
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"level":   "warn",
+	}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Events (2):")
+	assert.Contains(t, text, "git: stash failed")
+	assert.NotContains(t, text, "Using tool")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"types":   "tool_use, task.failed",
+		"limit":   float64(1),
+	}))
+	require.NoError(t, err)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Events (1):")
+	assert.Contains(t, text, "task.failed — boom")
+	assert.Contains(t, text, "Older events: before=")
+}
+
+func TestGetLogs_WhenNoTaskIDWithEvents_ShowsActivityAcrossProjects(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetLogs(tm, db)
+
+	first := tm.Create("test", "task 1", "", task.PriorityNormal, 30)
+	second := tm.Create("other", "task 2", "", task.PriorityNormal, 30)
+	require.NoError(t, db.AddEvent(&store.TaskEvent{TaskID: first.ID, EventType: "task.progress", Level: store.LevelDebug, Message: "chatty", CreatedAt: time.Now()}))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Recent activity (2 events)")
+	assert.Contains(t, text, first.ID+" (test) task.created")
+	assert.Contains(t, text, second.ID+" (other) task.created")
+	assert.NotContains(t, text, "chatty")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{
+		"project": "other",
+	}))
+	require.NoError(t, err)
+	text = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Recent activity (1 events)")
+	assert.Contains(t, text, second.ID)
+}
+
+func TestGetLogs_WhenFilterInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, db := newEventDeps(t)
+	handler := GetLogs(tm, db)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"level": "verbose"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"since": "yesterday"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid since")
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseSince("30m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-30*time.Minute), got)
+
+	got, err = parseSince("2026-03-01T10:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), got)
+
+	_, err = parseSince("-5m", now)
+	assert.Error(t, err)
+}
+
 // --- Helper tests ---
 
 func TestLastNLines(t *testing.T) {
@@ -562,3 +976,26 @@ func TestTruncateSummary(t *testing.T) {
 	result := truncateSummary(long, 50)
 	assert.Len(t, result, 50+len("\n\n[... output truncated, use format='full' for complete output]"))
 }
+
+func TestCheckTask_WhenPreempted_ShowsPreemptions(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := CheckTask(tm, "mock")
+
+	paused := time.Now().Add(-10 * time.Minute)
+	tsk := tm.Create("test", "long refactor", "", task.PriorityLow, 30)
+	tsk.Preemptions = []task.Preemption{
+		{By: "herald-urgent01", PausedAt: paused, ResumedAt: paused.Add(4 * time.Minute)},
+		{By: "herald-urgent02", PausedAt: paused.Add(8 * time.Minute)},
+	}
+	tsk.SetStatus(task.StatusRunning)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Preemptions (2)")
+	assert.Contains(t, text, "paused for herald-urgent01")
+	assert.Contains(t, text, "resumed after 4m0s")
+	assert.Contains(t, text, "herald-urgent02")
+}
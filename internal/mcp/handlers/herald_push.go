@@ -49,6 +49,7 @@ func HeraldPush(tm *task.Manager) server.ToolHandlerFunc {
 		if existing := tm.GetBySessionID(sessionID, task.StatusLinked); existing != nil {
 			existing.SetOutput(summary)
 			existing.SetLinkedFields(projectName, gitBranch, currentTask, turns, filesModified)
+			tm.Save(existing)
 
 			return mcp.NewToolResultText(formatPushResponse(existing.ID, sessionID, projectName, true)), nil
 		}
@@ -9,10 +9,12 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
 )
 
 // ListProjects returns a handler that lists all configured projects.
-func ListProjects(pm *project.Manager) server.ToolHandlerFunc {
+// tm may be nil to leave out what is left of the cost budgets.
+func ListProjects(pm *project.Manager, tm *task.Manager) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		projects := pm.All()
 
@@ -22,6 +24,11 @@ func ListProjects(pm *project.Manager) server.ToolHandlerFunc {
 
 		var b strings.Builder
 		fmt.Fprintf(&b, "**%d project(s) configured**\n\n", len(projects))
+		if tm != nil {
+			if _, global := tm.Budgets(""); global.Capped() {
+				fmt.Fprintf(&b, "Global budget: %s\n\n", describeBudget(global))
+			}
+		}
 
 		for _, p := range projects {
 			defaultMark := ""
@@ -50,9 +57,27 @@ func ListProjects(pm *project.Manager) server.ToolHandlerFunc {
 			if len(p.AllowedTools) > 0 {
 				fmt.Fprintf(&b, "  Tools: %s\n", strings.Join(p.AllowedTools, ", "))
 			}
+			if tm != nil {
+				if budget, _ := tm.Budgets(p.Name); budget.Capped() {
+					fmt.Fprintf(&b, "  Budget: %s\n", describeBudget(budget))
+				}
+			}
 			b.WriteString("\n")
 		}
 
 		return mcp.NewToolResultText(b.String()), nil
 	}
 }
+
+// describeBudget summarizes what is left of a budget, e.g. "$4.20 of $10.00
+// left today, $80.00 of $100.00 left this month".
+func describeBudget(s task.BudgetStatus) string {
+	var parts []string
+	if s.DailyUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f left today", max(0, s.DailyUSD-s.SpentToday), s.DailyUSD))
+	}
+	if s.MonthlyUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f left this month", max(0, s.MonthlyUSD-s.SpentThisMonth), s.MonthlyUSD))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -10,13 +11,14 @@ import (
 
 	"github.com/btouchard/herald/internal/config"
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
 )
 
 func TestListProjects_WhenNoProjects_ReturnsMessage(t *testing.T) {
 	t.Parallel()
 
 	pm := project.NewManager(map[string]config.Project{})
-	handler := ListProjects(pm)
+	handler := ListProjects(pm, nil)
 
 	result, err := handler(context.Background(), mcp.CallToolRequest{})
 	require.NoError(t, err)
@@ -39,7 +41,7 @@ func TestListProjects_WhenProjectsExist_ReturnsFormatted(t *testing.T) {
 		},
 	})
 
-	handler := ListProjects(pm)
+	handler := ListProjects(pm, nil)
 
 	result, err := handler(context.Background(), mcp.CallToolRequest{})
 	require.NoError(t, err)
@@ -53,3 +55,22 @@ func TestListProjects_WhenProjectsExist_ReturnsFormatted(t *testing.T) {
 	assert.Contains(t, text, "Read, Write")
 	assert.Contains(t, text, "2 task(s)")
 }
+
+func TestListProjects_WhenBudgets_ShowsWhatIsLeft(t *testing.T) {
+	t.Parallel()
+
+	pm := project.NewManager(map[string]config.Project{
+		"my-api": {Path: "/tmp"},
+		"docs":   {Path: "/tmp"},
+	})
+	tm := newSpentManager(t, "my-api", 4.25)
+	tm.SetBudgets(task.Budget{MonthlyUSD: 500}, map[string]task.Budget{"my-api": {DailyUSD: 10, MonthlyUSD: 100}})
+
+	result, err := ListProjects(pm, tm)(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Global budget: $495.75 of $500.00 left this month")
+	assert.Contains(t, text, "  Budget: $5.75 of $10.00 left today, $95.75 of $100.00 left this month")
+	assert.Equal(t, 1, strings.Count(text, "  Budget:"), "docs has no budget of its own")
+}
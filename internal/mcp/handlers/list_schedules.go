@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/scheduler"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// ListSchedules returns a handler that lists recurring tasks with their
+// next and last runs.
+func ListSchedules(sc *scheduler.Scheduler, tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		projectName, _ := args["project"].(string)
+
+		schedules := sc.List(projectName)
+		if len(schedules) == 0 {
+			return mcp.NewToolResultText("No schedules. Use schedule_task or the schedules section of herald.yaml to add one."), nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "🗓️ Schedules (%d found)\n\n", len(schedules))
+		for _, s := range schedules {
+			fmt.Fprintf(&b, "**%s** — %s", s.Name, s.Cron)
+			if s.Timezone != "" {
+				fmt.Fprintf(&b, " (%s)", s.Timezone)
+			}
+			if s.Source == scheduler.SourceConfig {
+				b.WriteString(" [herald.yaml]")
+			}
+			b.WriteString("\n")
+
+			fmt.Fprintf(&b, "  Project: %s", s.Project)
+			if s.Template != "" {
+				fmt.Fprintf(&b, " | Template: %s", s.Template)
+			}
+			b.WriteString("\n")
+			fmt.Fprintf(&b, "  Next run: %s\n", formatScheduleTime(s.NextRunAt))
+
+			switch {
+			case s.LastRunAt.IsZero():
+				b.WriteString("  Last run: never\n")
+			case s.LastTaskID == "":
+				fmt.Fprintf(&b, "  Last run: %s — task not started\n", formatScheduleTime(s.LastRunAt))
+			default:
+				fmt.Fprintf(&b, "  Last run: %s — %s", formatScheduleTime(s.LastRunAt), s.LastTaskID)
+				if t, err := tm.Get(s.LastTaskID); err == nil {
+					status := t.Snapshot().Status
+					fmt.Fprintf(&b, " %s %s", statusIcon(status), status)
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString("\n")
+		}
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
@@ -29,6 +29,9 @@ func ListTasks(tm *task.Manager) server.ToolHandlerFunc {
 		if limit, ok := args["limit"].(float64); ok && limit > 0 {
 			filter.Limit = int(limit)
 		}
+		if file, ok := args["file"].(string); ok {
+			filter.File = file
+		}
 
 		tasks := tm.List(filter)
 
@@ -47,7 +50,11 @@ func ListTasks(tm *task.Manager) server.ToolHandlerFunc {
 			}
 			sb.WriteString(fmt.Sprintf("  Project: %s | Priority: %s\n", t.Project, t.Priority))
 
-			if t.Status == task.StatusRunning {
+			if len(t.DependsOn) > 0 {
+				sb.WriteString(fmt.Sprintf("  Depends on: %s\n", strings.Join(t.DependsOn, ", ")))
+			}
+
+			if t.Status == task.StatusRunning || t.Status == task.StatusPaused {
 				sb.WriteString(fmt.Sprintf("  Duration: %s", t.FormatDuration()))
 				if t.Progress != "" {
 					sb.WriteString(fmt.Sprintf(" | Progress: %s", t.Progress))
@@ -56,7 +63,11 @@ func ListTasks(tm *task.Manager) server.ToolHandlerFunc {
 			}
 
 			if t.Status == task.StatusCompleted || t.Status == task.StatusFailed {
-				sb.WriteString(fmt.Sprintf("  Duration: %s | Cost: $%.2f\n", t.FormatDuration(), t.CostUSD))
+				sb.WriteString(fmt.Sprintf("  Duration: %s | Cost: $%.2f", t.FormatDuration(), t.CostUSD))
+				if changes := task.FormatChanges(len(t.FilesModified), t.LinesAdded, t.LinesRemoved); changes != "" {
+					sb.WriteString(" | " + changes)
+				}
+				sb.WriteString("\n")
 			}
 
 			if t.Status == task.StatusLinked {
@@ -77,18 +88,80 @@ func ListTasks(tm *task.Manager) server.ToolHandlerFunc {
 			sb.WriteString("\n")
 		}
 
+		writeDependencyGraph(&sb, tasks)
+
 		return mcp.NewToolResultText(sb.String()), nil
 	}
 }
 
+// writeDependencyGraph draws the dependencies between the listed tasks as
+// trees, each task under the tasks it depends on. Nothing is written when
+// no listed task depends on another listed task.
+func writeDependencyGraph(sb *strings.Builder, tasks []task.TaskSnapshot) {
+	byID := make(map[string]task.TaskSnapshot, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	children := make(map[string][]string)
+	hasParent := make(map[string]bool)
+	for i := len(tasks) - 1; i >= 0; i-- { // oldest first
+		t := tasks[i]
+		for _, dep := range t.DependsOn {
+			if _, ok := byID[dep]; ok {
+				children[dep] = append(children[dep], t.ID)
+				hasParent[t.ID] = true
+			}
+		}
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	sb.WriteString("🌳 Dependency graph\n\n")
+	for i := len(tasks) - 1; i >= 0; i-- {
+		id := tasks[i].ID
+		if hasParent[id] || len(children[id]) == 0 {
+			continue
+		}
+		writeDependencyNode(sb, byID, children, id, "", "")
+	}
+}
+
+func writeDependencyNode(sb *strings.Builder, byID map[string]task.TaskSnapshot, children map[string][]string, id, prefix, branch string) {
+	t := byID[id]
+	fmt.Fprintf(sb, "%s%s%s %s — %s\n", prefix, branch, statusIcon(t.Status), t.ID, t.Status)
+
+	switch branch {
+	case "├─ ":
+		prefix += "│  "
+	case "└─ ":
+		prefix += "   "
+	}
+	kids := children[id]
+	for i, child := range kids {
+		next := "├─ "
+		if i == len(kids)-1 {
+			next = "└─ "
+		}
+		writeDependencyNode(sb, byID, children, child, prefix, next)
+	}
+}
+
 func statusIcon(s task.Status) string {
 	switch s {
 	case task.StatusPending:
 		return "⏳"
+	case task.StatusAwaitingApproval:
+		return "✋"
 	case task.StatusQueued:
 		return "📥"
+	case task.StatusWaiting:
+		return "⛓️"
 	case task.StatusRunning:
 		return "🔄"
+	case task.StatusPaused:
+		return "⏸️"
 	case task.StatusCompleted:
 		return "✅"
 	case task.StatusFailed:
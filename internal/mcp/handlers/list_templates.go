@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/template"
+)
+
+// ListTemplates returns a handler that lists the task templates available
+// to a project, with their variables and defaults. templates may be nil.
+func ListTemplates(pm *project.Manager, templates *template.Registry) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		// Without a project, fall back on the default one if there is one.
+		projectName, _ := args["project"].(string)
+		scope := ""
+		if proj, err := pm.Resolve(projectName); err == nil {
+			scope = proj.Name
+		} else if projectName != "" {
+			return mcp.NewToolResultError(fmt.Sprintf("Project error: %s", err)), nil
+		}
+
+		var list []*template.Template
+		if templates != nil {
+			list = templates.List(scope)
+		}
+		if len(list) == 0 {
+			return mcp.NewToolResultText("No task templates configured. Add templates to your herald.yaml configuration."), nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "**%d template(s) available**", len(list))
+		if scope != "" {
+			fmt.Fprintf(&b, " for project %s", scope)
+		}
+		b.WriteString("\n\n")
+
+		for _, t := range list {
+			fmt.Fprintf(&b, "**%s**", t.Name)
+			if t.Project != "" {
+				b.WriteString(" (project template)")
+			}
+			b.WriteString("\n")
+			if t.Description != "" {
+				fmt.Fprintf(&b, "  %s\n", t.Description)
+			}
+			if vars := describeVariables(t); len(vars) > 0 {
+				fmt.Fprintf(&b, "  Variables: %s\n", strings.Join(vars, ", "))
+			}
+			if defaults := describeDefaults(t); len(defaults) > 0 {
+				fmt.Fprintf(&b, "  Defaults: %s\n", strings.Join(defaults, ", "))
+			}
+			if len(t.AllowedTools) > 0 {
+				fmt.Fprintf(&b, "  Tools: %s\n", strings.Join(t.AllowedTools, ", "))
+			}
+			b.WriteString("\n")
+		}
+
+		b.WriteString("Use start_task with template=<name> and the variables object for the template's variables; your prompt fills {{prompt}}.")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
+
+func describeVariables(t *template.Template) []string {
+	var vars []string
+	for _, name := range t.Variables() {
+		def, hasDefault := t.Defaults[name]
+		switch {
+		case name == template.VarPrompt:
+			vars = append(vars, "prompt (your prompt)")
+		case name == template.VarProject:
+			vars = append(vars, "project (project name)")
+		case hasDefault:
+			vars = append(vars, fmt.Sprintf("%s (default %q)", name, def))
+		default:
+			vars = append(vars, name+" (required)")
+		}
+	}
+	return vars
+}
+
+func describeDefaults(t *template.Template) []string {
+	var defaults []string
+	if t.Model != "" {
+		defaults = append(defaults, "model "+t.Model)
+	}
+	if t.Timeout > 0 {
+		defaults = append(defaults, fmt.Sprintf("timeout %dm", int(t.Timeout.Minutes())))
+	}
+	if t.Priority != "" {
+		defaults = append(defaults, "priority "+t.Priority)
+	}
+	if t.DryRun {
+		defaults = append(defaults, "dry run")
+	}
+	return defaults
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// PauseTask returns a handler that suspends a running task. holdSlots
+// tells whether paused tasks keep their concurrency slot.
+func PauseTask(tm *task.Manager, holdSlots bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, ok := args["task_id"].(string)
+		if !ok || taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+
+		if err := tm.Pause(taskID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to pause task: %s", err)), nil
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "⏸️ Task %s paused.\n\n", taskID)
+		b.WriteString("Its timeout is stopped while it is paused.\n")
+		if holdSlots {
+			b.WriteString("It keeps its concurrency slot.\n")
+		} else {
+			b.WriteString("Its concurrency slot is free for queued tasks.\n")
+		}
+		b.WriteString("\nUse resume_task to continue it, or cancel_task to stop it.")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
+
+// ResumeTask returns a handler that continues a paused task.
+func ResumeTask(tm *task.Manager) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, ok := args["task_id"].(string)
+		if !ok || taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+
+		if err := tm.Resume(taskID); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to resume task: %s", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("▶️ Task %s resumed — use check_task to follow its progress.", taskID)), nil
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// sleepingExecutor runs `sleep` in its own process group until cancelled.
+type sleepingExecutor struct{}
+
+func (sleepingExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "sleeping"}
+}
+
+func (sleepingExecutor) Execute(ctx context.Context, _ executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	cmd := exec.CommandContext(ctx, "sleep", "30")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
+	err := cmd.Wait()
+	return &executor.Result{}, err
+}
+
+func TestPauseTask_WhenRunning_PausesUntilResumed(t *testing.T) {
+	t.Parallel()
+
+	tm := task.NewManager(sleepingExecutor{}, 3, 2*time.Hour)
+	tsk := tm.Create("test", "long build", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+	t.Cleanup(func() { _ = tm.Cancel(tsk.ID) })
+	require.Eventually(t, func() bool { return tsk.Snapshot().PID > 0 }, 5*time.Second, 5*time.Millisecond)
+
+	result, err := PauseTask(tm, false)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "paused")
+	assert.Contains(t, text, "slot is free")
+	assert.Equal(t, task.StatusPaused, tsk.Snapshot().Status)
+
+	result, err = ResumeTask(tm)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "resumed")
+	assert.Equal(t, task.StatusRunning, tsk.Snapshot().Status)
+}
+
+func TestPauseTask_WhenNotRunning_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+
+	tsk := tm.Create("test", "prompt", "", task.PriorityNormal, 30)
+
+	result, err := PauseTask(tm, false)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "only running tasks can be paused")
+
+	result, err = ResumeTask(tm)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not paused")
+}
+
+func TestPauseTask_WithoutTaskID_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+
+	result, err := PauseTask(tm, false)(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = ResumeTask(tm)(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestExtendTask_WhenRunning_ReplacesTimeout(t *testing.T) {
+	t.Parallel()
+
+	tm := task.NewManager(sleepingExecutor{}, 3, 2*time.Hour)
+	tsk := tm.Create("test", "long build", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+	t.Cleanup(func() { _ = tm.Cancel(tsk.ID) })
+	require.Eventually(t, func() bool { return tsk.Snapshot().PID > 0 }, 5*time.Second, 5*time.Millisecond)
+
+	result, err := ExtendTask(tm)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID, "timeout_minutes": float64(45)}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "now times out after 45 minutes")
+	assert.Equal(t, 45, tsk.Snapshot().TimeoutMinutes)
+
+	check, err := CheckTask(tm, "sleeping")(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	assert.Contains(t, check.Content[0].(mcp.TextContent).Text, "of a 45 minute timeout")
+
+	result, err = ExtendTask(tm)(context.Background(), makeReq(map[string]any{"task_id": tsk.ID, "timeout_minutes": float64(500)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "max timeout")
+}
+
+func TestExtendTask_WithoutTimeout_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+
+	result, err := ExtendTask(tm)(context.Background(), makeReq(map[string]any{"task_id": "herald-00000000"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "timeout_minutes is required")
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+// askPermission makes tsk request a Bash permission in the background and
+// returns the pending request and the channel its decision arrives on.
+func askPermission(t *testing.T, tm *task.Manager, tsk *task.Task, command string) (task.PermissionRequest, <-chan task.PermissionDecision) {
+	t.Helper()
+	decided := make(chan task.PermissionDecision, 1)
+	go func() {
+		decided <- tm.RequestPermission(context.Background(), tsk.ID, "Bash", map[string]any{"command": command})
+	}()
+
+	var r task.PermissionRequest
+	require.Eventually(t, func() bool {
+		pending := tsk.Snapshot().PendingPermissions
+		if len(pending) == 0 {
+			return false
+		}
+		r = pending[0]
+		return true
+	}, 5*time.Second, time.Millisecond)
+	return r, decided
+}
+
+func TestApprovePermission_WhenScopeTask_AllowsSameUseAgain(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ApprovePermission(tm)
+
+	tsk := tm.Create("test", "clean up", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	r, decided := askPermission(t, tm, tsk, "rm -rf build")
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"request_id": r.ID,
+		"scope":      "task",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Approved Bash(rm -rf build) for task "+tsk.ID+". Further Bash(rm -rf build) requests")
+	assert.True(t, (<-decided).Allow)
+
+	assert.True(t, tm.RequestPermission(context.Background(), tsk.ID, "Bash", map[string]any{"command": "rm -rf build"}).Allow)
+}
+
+func TestApprovePermission_WhenRequestUnknownOrScopeInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ApprovePermission(tm)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"request_id": "perm-missing"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not found or already answered")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"request_id": "perm-missing", "scope": "forever"}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid scope")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "request_id is required")
+}
+
+func TestDenyPermission_PassesReasonToTask(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := DenyPermission(tm)
+
+	tsk := tm.Create("test", "clean up", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	r, decided := askPermission(t, tm, tsk, "rm -rf build")
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"request_id": r.ID,
+		"reason":     "use make clean",
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Denied Bash(rm -rf build)")
+	assert.Equal(t, task.PermissionDecision{Message: "use make clean"}, <-decided)
+}
+
+func TestApprovalPrompt_ReturnsClaudeCodeDecision(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := ApprovalPrompt(tm)
+
+	tsk := tm.Create("test", "clean up", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	ctx := WithPermissionTask(context.Background(), tsk.ID)
+
+	answer := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := handler(ctx, makeReq(map[string]any{
+			"tool_name": "Bash",
+			"input":     map[string]any{"command": "rm -rf build"},
+		}))
+		answer <- result
+	}()
+
+	var r task.PermissionRequest
+	require.Eventually(t, func() bool {
+		pending := tsk.Snapshot().PendingPermissions
+		if len(pending) == 0 {
+			return false
+		}
+		r = pending[0]
+		return true
+	}, 5*time.Second, time.Millisecond)
+	_, err := tm.AnswerPermission(r.ID, task.PermissionDecision{Allow: true}, task.PermissionOnce)
+	require.NoError(t, err)
+
+	result := <-answer
+	assert.JSONEq(t, `{"behavior":"allow","updatedInput":{"command":"rm -rf build"}}`, result.Content[0].(mcp.TextContent).Text)
+
+	// Denied: Claude Code is told why.
+	result, err = handler(WithPermissionTask(context.Background(), "herald-missing"), makeReq(map[string]any{"tool_name": "Bash"}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"behavior":"deny","message":"task \"herald-missing\" not found"}`, result.Content[0].(mcp.TextContent).Text)
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"tool_name": "Bash"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "calls without a task token are refused")
+}
+
+func TestCheckTask_WhenPermissionPending_ShowsRequest(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := CheckTask(tm, "claude-code")
+
+	tsk := tm.Create("test", "clean up", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+	r, decided := askPermission(t, tm, tsk, "rm -rf build")
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"task_id": tsk.ID}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Progress: waiting for approval: Bash(rm -rf build)")
+	assert.Contains(t, text, "Waiting for approval: Bash(rm -rf build) (request_id "+r.ID+", denied automatically in ")
+	assert.Contains(t, text, "approve_permission")
+
+	_, err = tm.AnswerPermission(r.ID, task.PermissionDecision{Message: "no"}, task.PermissionOnce)
+	require.NoError(t, err)
+	<-decided
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/template"
+)
+
+// TemplatePrompt returns a handler for the MCP prompt publishing the task
+// template name. It yields the start_task call running the template with
+// the prompt's arguments. scope is the project defining the template, ""
+// for a global template, which then takes a project argument.
+func TemplatePrompt(pm *project.Manager, templates *template.Registry, name, scope string) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := req.Params.Arguments
+
+		projectName := scope
+		if projectName == "" {
+			projectName = args[template.VarProject]
+		}
+		proj, err := pm.Resolve(projectName)
+		if err != nil {
+			return nil, fmt.Errorf("project error: %w", err)
+		}
+		tmpl, err := templates.Get(proj.Name, name)
+		if err != nil {
+			return nil, err
+		}
+
+		call := map[string]any{
+			"project":  proj.Name,
+			"template": tmpl.Name,
+		}
+		vars := make(map[string]string)
+		for _, v := range tmpl.Variables() {
+			if v == template.VarPrompt || v == template.VarProject || args[v] == "" {
+				continue
+			}
+			vars[v] = args[v]
+		}
+		if len(vars) > 0 {
+			call["variables"] = vars
+		}
+		if p := strings.TrimSpace(args[template.VarPrompt]); p != "" {
+			call["prompt"] = p
+		}
+
+		// Render now so that a missing variable is reported here rather
+		// than by start_task.
+		rendered, err := tmpl.Render(templateRenderVars(vars, args[template.VarPrompt], proj.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		writeStartTaskCall(&b, call)
+		if defaults := describeDefaults(tmpl); len(defaults) > 0 {
+			fmt.Fprintf(&b, "\nTemplate defaults: %s.\n", strings.Join(defaults, ", "))
+		}
+		writeRenderedPrompt(&b, rendered)
+
+		return mcp.NewGetPromptResult(tmpl.Description,
+			[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String()))},
+		), nil
+	}
+}
+
+// WorkflowPrompt returns a handler for a built-in workflow prompt. start_task
+// does not know workflows, so the call it yields carries the rendered prompt
+// and the workflow's settings.
+func WorkflowPrompt(pm *project.Manager, wf *template.Template) server.PromptHandlerFunc {
+	return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := req.Params.Arguments
+
+		proj, err := pm.Resolve(args[template.VarProject])
+		if err != nil {
+			return nil, fmt.Errorf("project error: %w", err)
+		}
+
+		vars := make(map[string]string)
+		for _, v := range wf.Variables() {
+			if args[v] != "" {
+				vars[v] = args[v]
+			}
+		}
+		rendered, err := wf.Render(templateRenderVars(vars, args[template.VarPrompt], proj.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		call := map[string]any{
+			"project": proj.Name,
+			"prompt":  rendered,
+		}
+		if wf.Priority != "" {
+			call["priority"] = wf.Priority
+		}
+		if wf.DryRun {
+			call["dry_run"] = true
+		}
+
+		var b strings.Builder
+		writeStartTaskCall(&b, call)
+
+		return mcp.NewGetPromptResult(wf.Description,
+			[]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String()))},
+		), nil
+	}
+}
+
+func templateRenderVars(vars map[string]string, prompt, projectName string) map[string]string {
+	out := make(map[string]string, len(vars)+2)
+	for k, v := range vars {
+		out[k] = v
+	}
+	out[template.VarPrompt] = strings.TrimSpace(prompt)
+	out[template.VarProject] = projectName
+	return out
+}
+
+func writeStartTaskCall(b *strings.Builder, call map[string]any) {
+	// Marshalling a map of strings, bools and string maps cannot fail.
+	data, _ := json.MarshalIndent(call, "", "  ")
+	b.WriteString("Start a Herald task by calling start_task with these arguments:\n\n")
+	fmt.Fprintf(b, "```json\n%s\n```\n", data)
+}
+
+func writeRenderedPrompt(b *strings.Builder, rendered string) {
+	if rendered == "" {
+		return
+	}
+	b.WriteString("\nClaude Code will receive this prompt:\n\n")
+	for _, line := range strings.Split(rendered, "\n") {
+		fmt.Fprintf(b, "> %s\n", line)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/template"
+)
+
+func makePromptReq(args map[string]string) mcp.GetPromptRequest {
+	req := mcp.GetPromptRequest{}
+	req.Params.Arguments = args
+	return req
+}
+
+func TestTemplatePrompt_YieldsStartTaskCall(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	handler := TemplatePrompt(pm, newTestTemplates(), "review", "")
+
+	result, err := handler(context.Background(), makePromptReq(map[string]string{
+		"scope":  "internal/auth",
+		"prompt": "Check token rotation.",
+	}))
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	assert.Contains(t, text, `"project": "test"`)
+	assert.Contains(t, text, `"template": "review"`)
+	assert.Contains(t, text, `"scope": "internal/auth"`)
+	assert.Contains(t, text, `"prompt": "Check token rotation."`)
+	assert.NotContains(t, text, `"focus"`, "defaults are left to start_task")
+	assert.Contains(t, text, "Template defaults: model claude-opus-4-6, timeout 20m, priority high, dry run.")
+	assert.Contains(t, text, "> Review internal/auth for correctness.")
+}
+
+func TestTemplatePrompt_WhenVariableMissing_ReturnsError(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	handler := TemplatePrompt(pm, newTestTemplates(), "review", "")
+
+	_, err := handler(context.Background(), makePromptReq(nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing value for scope")
+}
+
+func TestTemplatePrompt_WhenUnknownProject_ReturnsError(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	handler := TemplatePrompt(pm, newTestTemplates(), "review", "")
+
+	_, err := handler(context.Background(), makePromptReq(map[string]string{"project": "nope", "scope": "x"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project error")
+}
+
+func TestWorkflowPrompt_RendersPromptIntoCall(t *testing.T) {
+	t.Parallel()
+	_, pm := newTestDeps()
+	wf := &template.Template{
+		Name:     "review-branch",
+		Prompt:   "Review {{branch}} against {{base}}.",
+		Defaults: map[string]string{"base": "main"},
+		DryRun:   true,
+	}
+	handler := WorkflowPrompt(pm, wf)
+
+	result, err := handler(context.Background(), makePromptReq(map[string]string{
+		"branch": "feature/login",
+		"prompt": "Mind the session cookie.",
+	}))
+	require.NoError(t, err)
+
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	assert.Contains(t, text, `"project": "test"`)
+	assert.Contains(t, text, `"prompt": "Review feature/login against main.\n\nMind the session cookie."`)
+	assert.Contains(t, text, `"dry_run": true`)
+	assert.NotContains(t, text, `"template"`)
+}
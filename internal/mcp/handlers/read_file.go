@@ -11,50 +11,47 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
 )
 
 const maxFileSize = 1024 * 1024 // 1MB
 
 // ReadFile returns a handler that reads a file from a project with path traversal prevention.
-func ReadFile(pm *project.Manager) server.ToolHandlerFunc {
+// With task_id, the file is read from the task's worktree when it has one,
+// so results can be inspected before they are merged.
+func ReadFile(tm *task.Manager, pm *project.Manager) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := req.GetArguments()
 
 		projectName, _ := args["project"].(string)
+		taskID, _ := args["task_id"].(string)
 		filePath, ok := args["path"].(string)
 		if !ok || filePath == "" {
 			return mcp.NewToolResultError("path is required"), nil
 		}
 
-		proj, err := pm.Resolve(projectName)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Project error: %s", err)), nil
-		}
-
-		safePath, err := SafePath(proj.Path, filePath)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Access denied: %s", err)), nil
-		}
-
-		info, err := os.Stat(safePath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return mcp.NewToolResultError(fmt.Sprintf("File not found: %s", filePath)), nil
+		var root string
+		if taskID != "" {
+			t, err := tm.Get(taskID)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Task not found: %s", err)), nil
 			}
-			return mcp.NewToolResultError(fmt.Sprintf("Cannot access file: %s", err)), nil
+			snap := t.Snapshot()
+			projectName = snap.Project
+			root = existingDir(snap.WorktreePath)
 		}
 
-		if info.IsDir() {
-			return mcp.NewToolResultError(fmt.Sprintf("%s is a directory, not a file", filePath)), nil
+		proj, err := pm.Resolve(projectName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Project error: %s", err)), nil
 		}
-
-		if info.Size() > maxFileSize {
-			return mcp.NewToolResultError(fmt.Sprintf("File too large (%d bytes, max %d)", info.Size(), maxFileSize)), nil
+		if root == "" {
+			root = proj.Path
 		}
 
-		content, err := os.ReadFile(safePath) //nolint:gosec // path validated by safePath above
+		content, err := readSafeFile(root, filePath)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to read file: %s", err)), nil
+			return mcp.NewToolResultError(capitalize(err.Error())), nil
 		}
 
 		var sb strings.Builder
@@ -67,6 +64,46 @@ func ReadFile(pm *project.Manager) server.ToolHandlerFunc {
 	}
 }
 
+// readSafeFile reads a file of a project, resolved through SafePath, up to
+// maxFileSize.
+func readSafeFile(root, filePath string) ([]byte, error) {
+	safePath, err := SafePath(root, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	info, err := os.Stat(safePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file not found: %s", filePath)
+		}
+		return nil, fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, not a file", filePath)
+	}
+
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("file too large (%d bytes, max %d)", info.Size(), maxFileSize)
+	}
+
+	content, err := os.ReadFile(safePath) //nolint:gosec // path validated by safePath above
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, nil
+}
+
+// capitalize upper-cases the first letter of an error message shown as a
+// tool result.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 // SafePath validates that the requested path stays within the project root.
 // This prevents path traversal attacks (e.g., ../../etc/passwd) and symlink escapes.
 func SafePath(projectRoot, requestedPath string) (string, error) {
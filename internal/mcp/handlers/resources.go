@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/git"
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// Resource URI templates. Every task resource URI starts with
+// TaskURIPrefix + the task ID.
+const (
+	TaskURIPrefix = "herald://tasks/"
+
+	TaskURITemplate        = "herald://tasks/{id}"
+	TaskOutputURITemplate  = "herald://tasks/{id}/output"
+	TaskDiffURITemplate    = "herald://tasks/{id}/diff"
+	ProjectFileURITemplate = "herald://projects/{name}/files/{+path}"
+)
+
+// TaskResource returns a handler that reads a task as JSON, the document
+// get_result returns with format=json.
+func TaskResource(tm *task.Manager) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		snap, err := resourceTask(tm, req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding task: %w", err)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}}, nil
+	}
+}
+
+// TaskOutputResource returns a handler that reads a task's output as
+// reported so far.
+func TaskOutputResource(tm *task.Manager) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		snap, err := resourceTask(tm, req)
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "text/plain",
+			Text:     snap.Output,
+		}}, nil
+	}
+}
+
+// TaskDiffResource returns a handler that reads a task's changes as a
+// unified diff, computed like get_diff with a task_id. Untracked files of
+// a task worktree are not part of it.
+func TaskDiffResource(tm *task.Manager, pm *project.Manager) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		snap, err := resourceTask(tm, req)
+		if err != nil {
+			return nil, err
+		}
+		proj, err := pm.Get(snap.Project)
+		if err != nil {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+
+		ops := git.NewOps(proj.Path)
+		if !ops.IsGitRepo(ctx) {
+			return nil, fmt.Errorf("project %q is not a git repository", proj.Name)
+		}
+		var diff string
+		if ops.HasCommits(ctx) {
+			diff, _, err = changes(ctx, ops, snap.GitBranch, existingDir(snap.WorktreePath))
+			if err != nil {
+				return nil, fmt.Errorf("getting diff: %w", err)
+			}
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: "text/x-diff",
+			Text:     diff,
+		}}, nil
+	}
+}
+
+// ProjectFileResource returns a handler that reads a project file, with
+// the same path checks and size limit as read_file. Files that are not
+// valid UTF-8 are returned base64-encoded.
+func ProjectFileResource(pm *project.Manager) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		proj, err := pm.Get(uriVar(req, "name"))
+		if err != nil {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+		filePath := uriVar(req, "path")
+		if filePath == "" {
+			return nil, fmt.Errorf("path is required")
+		}
+
+		content, err := readSafeFile(proj.Path, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		mimeType := mime.TypeByExtension(filepath.Ext(filePath))
+		if utf8.Valid(content) {
+			if mimeType == "" {
+				mimeType = "text/plain"
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      req.Params.URI,
+				MIMEType: mimeType,
+				Text:     string(content),
+			}}, nil
+		}
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		return []mcp.ResourceContents{mcp.BlobResourceContents{
+			URI:      req.Params.URI,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(content),
+		}}, nil
+	}
+}
+
+func resourceTask(tm *task.Manager, req mcp.ReadResourceRequest) (task.TaskSnapshot, error) {
+	id := uriVar(req, "id")
+	t, err := tm.Get(id)
+	if err != nil {
+		return task.TaskSnapshot{}, fmt.Errorf("task not found: %w", err)
+	}
+	return t.Snapshot(), nil
+}
+
+// uriVar returns a variable matched from the resource URI template.
+func uriVar(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, "/")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/task"
+)
+
+func makeResourceReq(uri string, args map[string]any) mcp.ReadResourceRequest {
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	req.Params.Arguments = args
+	return req
+}
+
+func TestTaskResource_ReturnsTaskAsJSON(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	tsk := tm.Create("test", "Fix the login", "", task.PriorityHigh, 30)
+
+	contents, err := TaskResource(tm)(context.Background(),
+		makeResourceReq("herald://tasks/"+tsk.ID, map[string]any{"id": []string{tsk.ID}}))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "application/json", text.MIMEType)
+	var snap task.TaskSnapshot
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &snap))
+	assert.Equal(t, tsk.ID, snap.ID)
+	assert.Equal(t, "Fix the login", snap.Prompt)
+}
+
+func TestTaskOutputResource_WhenTaskNotFound_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+
+	_, err := TaskOutputResource(tm)(context.Background(),
+		makeResourceReq("herald://tasks/nope/output", map[string]any{"id": []string{"nope"}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task not found")
+}
+
+func TestTaskDiffResource_WhenTaskHasWorktree_ReturnsDiff(t *testing.T) {
+	t.Parallel()
+	repoPath := initGitRepo(t)
+	tm, pm := newDiffTestDeps(repoPath)
+
+	tsk := tm.Create("test-repo", "some task", "", task.PriorityNormal, 30)
+	wt := addTaskWorktree(t, repoPath, tsk)
+	require.NoError(t, os.WriteFile(filepath.Join(wt, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0600))
+
+	contents, err := TaskDiffResource(tm, pm)(context.Background(),
+		makeResourceReq("herald://tasks/"+tsk.ID+"/diff", map[string]any{"id": []string{tsk.ID}}))
+	require.NoError(t, err)
+
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "text/x-diff", text.MIMEType)
+	assert.Contains(t, text.Text, "+func main() {}")
+}
+
+func TestProjectFileResource_ReadsFile(t *testing.T) {
+	t.Parallel()
+	deps := newReadFileDeps(t)
+
+	contents, err := ProjectFileResource(deps.pm)(context.Background(),
+		makeResourceReq("herald://projects/test/files/src/handler.go",
+			map[string]any{"name": []string{"test"}, "path": []string{"src/handler.go"}}))
+	require.NoError(t, err)
+
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "package src\n", text.Text)
+}
+
+func TestProjectFileResource_WhenPathTraversal_ReturnsError(t *testing.T) {
+	t.Parallel()
+	deps := newReadFileDeps(t)
+
+	_, err := ProjectFileResource(deps.pm)(context.Background(),
+		makeResourceReq("herald://projects/test/files/../../etc/passwd",
+			map[string]any{"name": []string{"test"}, "path": []string{"../../etc/passwd"}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestProjectFileResource_WhenBinary_ReturnsBlob(t *testing.T) {
+	t.Parallel()
+	deps := newReadFileDeps(t)
+	require.NoError(t, os.WriteFile(filepath.Join(deps.root, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0xff, 0xfe}, 0600))
+
+	contents, err := ProjectFileResource(deps.pm)(context.Background(),
+		makeResourceReq("herald://projects/test/files/logo.png",
+			map[string]any{"name": []string{"test"}, "path": []string{"logo.png"}}))
+	require.NoError(t, err)
+
+	blob := contents[0].(mcp.BlobResourceContents)
+	assert.Equal(t, "image/png", blob.MIMEType)
+	assert.NotEmpty(t, blob.Blob)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/scheduler"
+)
+
+// ScheduleTask returns a handler that creates or replaces a recurring task.
+func ScheduleTask(sc *scheduler.Scheduler) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		name, _ := args["name"].(string)
+		if name == "" {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+		cronExpr, _ := args["cron"].(string)
+		if cronExpr == "" {
+			return mcp.NewToolResultError("cron is required"), nil
+		}
+
+		s := scheduler.Schedule{Name: name, Cron: cronExpr}
+		s.Prompt, _ = args["prompt"].(string)
+		s.Template, _ = args["template"].(string)
+		if s.Prompt == "" && s.Template == "" {
+			return mcp.NewToolResultError("prompt is required unless a template is given"), nil
+		}
+		s.Project, _ = args["project"].(string)
+		s.Timezone, _ = args["timezone"].(string)
+		s.Priority, _ = args["priority"].(string)
+		s.Model, _ = args["model"].(string)
+		s.GitBranch, _ = args["git_branch"].(string)
+		s.DryRun, _ = args["dry_run"].(bool)
+		s.MissedRun, _ = args["missed_run"].(string)
+		if raw, ok := args["variables"].(map[string]any); ok {
+			s.Variables = make(map[string]string, len(raw))
+			for k, v := range raw {
+				s.Variables[k] = fmt.Sprint(v)
+			}
+		}
+		if t, ok := args["timeout_minutes"].(float64); ok && t > 0 {
+			s.TimeoutMinutes = int(t)
+		}
+		if j, ok := args["jitter_minutes"].(float64); ok && j > 0 {
+			s.Jitter = time.Duration(j * float64(time.Minute))
+		}
+
+		saved, err := sc.Add(s)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Cannot schedule task: %s", err)), nil
+		}
+
+		var b strings.Builder
+		b.WriteString("Schedule saved\n\n")
+		writeSchedule(&b, saved)
+		b.WriteString("\nEach run starts a normal task; its notifications are broadcast to connected clients. Use list_schedules to see the last run, delete_schedule to stop it.")
+		return mcp.NewToolResultText(b.String()), nil
+	}
+}
+
+// writeSchedule describes a schedule as a bullet list.
+func writeSchedule(b *strings.Builder, s scheduler.Schedule) {
+	fmt.Fprintf(b, "- Name: %s\n", s.Name)
+	fmt.Fprintf(b, "- Cron: %s", s.Cron)
+	if s.Timezone != "" {
+		fmt.Fprintf(b, " (%s)", s.Timezone)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(b, "- Project: %s\n", s.Project)
+	if s.Template != "" {
+		fmt.Fprintf(b, "- Template: %s\n", s.Template)
+	}
+	if s.Prompt != "" {
+		prompt := strings.Join(strings.Fields(s.Prompt), " ")
+		if len(prompt) > 120 {
+			prompt = prompt[:120] + "..."
+		}
+		fmt.Fprintf(b, "- Prompt: %s\n", prompt)
+	}
+	if s.GitBranch != "" {
+		fmt.Fprintf(b, "- Branch: %s\n", s.GitBranch)
+	}
+	if s.DryRun {
+		b.WriteString("- Mode: dry run (plan only)\n")
+	}
+	fmt.Fprintf(b, "- Missed runs: %s\n", s.MissedRun)
+	if s.Jitter > 0 {
+		fmt.Fprintf(b, "- Jitter: up to %s\n", s.Jitter)
+	}
+	fmt.Fprintf(b, "- Next run: %s\n", formatScheduleTime(s.NextRunAt))
+}
+
+func formatScheduleTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04 MST")
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/scheduler"
+	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
+)
+
+func newTestScheduler() (*scheduler.Scheduler, *task.Manager) {
+	tm, pm := newTestDeps()
+	templates := template.NewRegistry(map[string]config.Template{
+		"test": {Prompt: "Run the tests in {{scope}}."},
+	}, nil)
+	return scheduler.New(tm, pm, templates, config.ExecutionConfig{}), tm
+}
+
+func TestScheduleTask_WhenValid_SavesSchedule(t *testing.T) {
+	t.Parallel()
+	sc, _ := newTestScheduler()
+	handler := ScheduleTask(sc)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"name":       "nightly-tests",
+		"cron":       "0 2 * * *",
+		"timezone":   "Europe/Paris",
+		"template":   "test",
+		"variables":  map[string]any{"scope": "internal/auth"},
+		"missed_run": "run_once",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Schedule saved")
+	assert.Contains(t, text, "- Cron: 0 2 * * * (Europe/Paris)")
+	assert.Contains(t, text, "- Template: test")
+	assert.Contains(t, text, "- Missed runs: run_once")
+	assert.Contains(t, text, "- Next run: ")
+	assert.NotContains(t, text, "- Next run: never")
+
+	list := sc.List("")
+	require.Len(t, list, 1)
+	assert.Equal(t, "internal/auth", list[0].Variables["scope"])
+}
+
+func TestScheduleTask_WhenInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"missing name", map[string]any{"cron": "@daily", "prompt": "x"}, "name is required"},
+		{"missing cron", map[string]any{"name": "n", "prompt": "x"}, "cron is required"},
+		{"missing prompt", map[string]any{"name": "n", "cron": "@daily"}, "prompt is required"},
+		{"bad cron", map[string]any{"name": "n", "cron": "61 * * * *", "prompt": "x"}, "minute 61 out of range"},
+		{"bad priority", map[string]any{"name": "n", "cron": "@daily", "prompt": "x", "priority": "asap"}, "priority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sc, _ := newTestScheduler()
+			result, err := ScheduleTask(sc)(context.Background(), makeReq(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.wantErr)
+		})
+	}
+}
+
+func TestListSchedules_ShowsNextAndLastRun(t *testing.T) {
+	t.Parallel()
+	sc, tm := newTestScheduler()
+
+	handler := ListSchedules(sc, tm)
+	result, err := handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No schedules")
+
+	_, err = sc.Add(scheduler.Schedule{Name: "weekly-deps", Cron: "0 9 * * mon", Prompt: "update dependencies"})
+	require.NoError(t, err)
+	require.NoError(t, sc.Load(map[string]config.Schedule{
+		"nightly-tests": {Cron: "0 2 * * *", Template: "test", Variables: map[string]string{"scope": "."}},
+	}))
+
+	result, err = handler(context.Background(), makeReq(map[string]any{}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Schedules (2 found)")
+	assert.Contains(t, text, "**nightly-tests** — 0 2 * * * [herald.yaml]")
+	assert.Contains(t, text, "**weekly-deps** — 0 9 * * mon\n")
+	assert.Contains(t, text, "Template: test")
+	assert.Contains(t, text, "Last run: never")
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"project": "other"}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No schedules")
+}
+
+func TestDeleteSchedule_RemovesSchedule(t *testing.T) {
+	t.Parallel()
+	sc, _ := newTestScheduler()
+	_, err := sc.Add(scheduler.Schedule{Name: "nightly-tests", Cron: "@daily", Prompt: "x"})
+	require.NoError(t, err)
+
+	handler := DeleteSchedule(sc)
+	result, err := handler(context.Background(), makeReq(map[string]any{"name": "nightly-tests"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Schedule nightly-tests deleted")
+	assert.Empty(t, sc.List(""))
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"name": "nightly-tests"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "not found")
+}
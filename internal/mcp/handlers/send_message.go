@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// SendMessage returns a handler that delivers follow-up instructions to a
+// running task. maxPromptSize limits message length in bytes (0 = no limit).
+func SendMessage(tm *task.Manager, caps executor.Capabilities, maxPromptSize int) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		taskID, ok := args["task_id"].(string)
+		if !ok || taskID == "" {
+			return mcp.NewToolResultError("task_id is required"), nil
+		}
+		message, ok := args["message"].(string)
+		if !ok || message == "" {
+			return mcp.NewToolResultError("message is required"), nil
+		}
+		if maxPromptSize > 0 && len(message) > maxPromptSize {
+			return mcp.NewToolResultError(fmt.Sprintf("message too large: %d bytes (max %d)", len(message), maxPromptSize)), nil
+		}
+
+		if !caps.SupportsMessages {
+			return mcp.NewToolResultError(fmt.Sprintf("The %s executor cannot receive messages while a task runs. Cancel the task and start a new one with the updated instructions.", caps.Name)), nil
+		}
+
+		if err := tm.SendMessage(taskID, message); err != nil {
+			if errors.Is(err, task.ErrReattached) {
+				return mcp.NewToolResultError(fmt.Sprintf("Task %s was reattached after a Herald restart and can no longer receive messages: its input belonged to the previous Herald process. Let it finish, or cancel it and start a new one with the updated instructions.", taskID)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to send message: %s", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("✉️ Message sent to %s. It is delivered as a new user turn once the executor has read the previous ones — use check_task to follow its progress.", taskID)), nil
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// listeningExecutor completes with the first message it receives.
+type listeningExecutor struct{}
+
+func (l *listeningExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "listening", SupportsMessages: true}
+}
+
+func (l *listeningExecutor) Execute(ctx context.Context, req executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	select {
+	case msg := <-req.Messages:
+		return &executor.Result{Output: "got: " + msg}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestSendMessage_WhenTaskRunning_DeliversMessage(t *testing.T) {
+	t.Parallel()
+
+	exec := &listeningExecutor{}
+	tm := task.NewManager(exec, 3, 2*time.Hour)
+	handler := SendMessage(tm, exec.Capabilities(), 102400)
+
+	tsk := tm.Create("test", "rename the handlers", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"message": "keep the old names",
+	}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Message sent to "+tsk.ID)
+
+	select {
+	case <-tsk.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("task did not finish")
+	}
+	assert.Equal(t, "got: keep the old names", tsk.Snapshot().Output)
+}
+
+func TestSendMessage_WhenExecutorUnsupported_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := SendMessage(tm, testCaps, 102400)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+	tsk.SetStatus(task.StatusRunning)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"message": "hello",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "executor cannot receive messages")
+}
+
+func TestSendMessage_WhenTaskNotRunning_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	exec := &listeningExecutor{}
+	tm := task.NewManager(exec, 3, 2*time.Hour)
+	handler := SendMessage(tm, exec.Capabilities(), 102400)
+
+	tsk := tm.Create("test", "work", "", task.PriorityNormal, 30)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"task_id": tsk.ID,
+		"message": "hello",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "messages can only be sent to running tasks")
+}
+
+// reattachingListener reattaches to processes that run until release is
+// closed.
+type reattachingListener struct {
+	listeningExecutor
+	reattached chan struct{}
+	release    chan struct{}
+}
+
+func (r *reattachingListener) Reattach(_ context.Context, _ executor.ReattachRequest, _ executor.ProgressFunc) (*executor.Result, error) {
+	r.reattached <- struct{}{}
+	<-r.release
+	return &executor.Result{Output: "finished after restart"}, nil
+}
+
+func TestSendMessage_WhenTaskReattached_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "herald.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00001", Type: "dispatched", Project: "test", Prompt: "was running",
+		Status: "running", Priority: "normal", PID: 4242, TimeoutMinutes: 30,
+		CreatedAt: now, StartedAt: now,
+	}))
+
+	exec := &reattachingListener{reattached: make(chan struct{}, 1), release: make(chan struct{})}
+	tm := task.NewManager(exec, 3, 2*time.Hour)
+	tm.SetStore(db)
+	require.NoError(t, tm.Restore(func(task.TaskSnapshot) (executor.Request, int, error) {
+		return executor.Request{}, 0, nil
+	}))
+	<-exec.reattached
+	defer close(exec.release)
+
+	result, err := SendMessage(tm, exec.Capabilities(), 102400)(context.Background(), makeReq(map[string]any{
+		"task_id": "herald-run00001",
+		"message": "keep the old names",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Task herald-run00001 was reattached after a Herald restart and can no longer receive messages")
+}
+
+func TestSendMessage_WhenArgumentsInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, _ := newTestDeps()
+	handler := SendMessage(tm, executor.Capabilities{SupportsMessages: true}, 10)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"missing task_id", map[string]any{"message": "hello"}, "task_id is required"},
+		{"missing message", map[string]any{"task_id": "herald-x"}, "message is required"},
+		{"message too large", map[string]any{"task_id": "herald-x", "message": "far more than ten bytes"}, "message too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := handler(context.Background(), makeReq(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.want)
+		})
+	}
+}
@@ -12,7 +12,9 @@ import (
 
 	"github.com/btouchard/herald/internal/executor"
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/retry"
 	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
 )
 
 // DurationEstimator provides average task duration for a project.
@@ -27,7 +29,8 @@ type DurationEstimator interface {
 // defaultModel is used when no model is specified per task.
 // caps describes the active executor's feature set (used to emit warnings).
 // estimator may be nil to skip duration estimation.
-func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout time.Duration, maxPromptSize int, defaultModel string, caps executor.Capabilities, estimator DurationEstimator) server.ToolHandlerFunc {
+// templates may be nil when no task templates are configured.
+func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout time.Duration, maxPromptSize int, defaultModel string, caps executor.Capabilities, estimator DurationEstimator, templates *template.Registry) server.ToolHandlerFunc {
 	defaultMinutes := int(defaultTimeout.Minutes())
 	if defaultMinutes <= 0 {
 		defaultMinutes = 30
@@ -41,14 +44,11 @@ func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout
 		args := req.GetArguments()
 
 		prompt, _ := args["prompt"].(string)
-		if prompt == "" {
+		templateName, _ := args["template"].(string)
+		if prompt == "" && templateName == "" {
 			return mcp.NewToolResultError("prompt is required"), nil
 		}
 
-		if maxPromptSize > 0 && len(prompt) > maxPromptSize {
-			return mcp.NewToolResultError(fmt.Sprintf("prompt too large: %d bytes (max %d)", len(prompt), maxPromptSize)), nil
-		}
-
 		context, _ := args["context"].(string)
 
 		projectName, _ := args["project"].(string)
@@ -57,12 +57,41 @@ func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout
 			return mcp.NewToolResultError(fmt.Sprintf("Project error: %s", err)), nil
 		}
 
+		// Template defaults sit between the caller's arguments and the
+		// global defaults.
+		tmpl := &template.Template{}
+		if templateName != "" {
+			if templates == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Template error: template %q not found (no templates configured)", templateName)), nil
+			}
+			if tmpl, err = templates.Get(proj.Name, templateName); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Template error: %s", err)), nil
+			}
+			prompt, err = tmpl.Render(templateVars(args, prompt, proj.Name))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Template error: %s", err)), nil
+			}
+			if strings.TrimSpace(prompt) == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("prompt is required: template %q renders an empty prompt", templateName)), nil
+			}
+		}
+
+		if maxPromptSize > 0 && len(prompt) > maxPromptSize {
+			return mcp.NewToolResultError(fmt.Sprintf("prompt too large: %d bytes (max %d)", len(prompt), maxPromptSize)), nil
+		}
+
 		priority := task.PriorityNormal
+		if tmpl.Priority != "" {
+			priority = task.Priority(tmpl.Priority)
+		}
 		if p, ok := args["priority"].(string); ok && p != "" {
 			priority = task.Priority(p)
 		}
 
 		timeoutMinutes := defaultMinutes
+		if tmpl.Timeout > 0 {
+			timeoutMinutes = int(tmpl.Timeout.Minutes())
+		}
 		if t, ok := args["timeout_minutes"].(float64); ok && t > 0 {
 			timeoutMinutes = int(t)
 		}
@@ -75,19 +104,74 @@ func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout
 
 		sessionID, _ := args["session_id"].(string)
 		gitBranch, _ := args["git_branch"].(string)
-		dryRun, _ := args["dry_run"].(bool)
+		dryRun := tmpl.DryRun
+		if d, ok := args["dry_run"].(bool); ok {
+			dryRun = d
+		}
 
 		model := defaultModel
+		if tmpl.Model != "" {
+			model = tmpl.Model
+		}
 		if m, ok := args["model"].(string); ok && m != "" {
 			model = m
 		}
 
+		allowedTools := tmpl.ToolsFor(proj.AllowedTools)
+
+		var dependsOn []string
+		if raw, ok := args["depends_on"].([]any); ok {
+			for _, v := range raw {
+				if id, ok := v.(string); ok && id != "" {
+					dependsOn = append(dependsOn, id)
+				}
+			}
+		}
+		onDependencyFailure := task.DependencyCancel
+		if p, ok := args["on_dependency_failure"].(string); ok && p != "" {
+			if p != task.DependencyCancel && p != task.DependencyFail {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid on_dependency_failure %q: must be cancel or fail", p)), nil
+			}
+			onDependencyFailure = p
+		}
+		inheritBranch, _ := args["inherit_branch"].(bool)
+		inheritSession, _ := args["inherit_session"].(bool)
+		if (inheritBranch || inheritSession) && len(dependsOn) == 0 {
+			return mcp.NewToolResultError("inherit_branch and inherit_session require depends_on"), nil
+		}
+
+		retries, err := retryPolicy(args, proj.Retry)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid retry policy: %s", err)), nil
+		}
+
+		maxBudget, _ := args["max_budget_usd"].(float64)
+		if maxBudget < 0 {
+			return mcp.NewToolResultError("max_budget_usd must not be negative"), nil
+		}
+
+		// A spent budget rejects the task, unless tasks over budget are
+		// queued until it resets.
+		budgetErr := tm.CheckBudget(proj.Name)
+		if budgetErr != nil && !tm.QueuesOverBudget() {
+			return mcp.NewToolResultError(fmt.Sprintf("Cannot start task: %s", budgetErr)), nil
+		}
+
 		// Create the task
 		t := tm.Create(proj.Name, prompt, context, priority, timeoutMinutes)
 		t.GitBranch = gitBranch
+		t.SessionID = sessionID
 		t.DryRun = dryRun
 		t.Model = model
-		t.AllowedTools = proj.AllowedTools
+		t.AllowedTools = allowedTools
+		t.Retry = retries
+		t.MaxBudgetUSD = maxBudget
+		if len(dependsOn) > 0 {
+			t.DependsOn = dependsOn
+			t.OnDependencyFailure = onDependencyFailure
+			t.InheritBranch = inheritBranch
+			t.InheritSession = inheritSession
+		}
 
 		// Capture MCP session for push notifications
 		if sess := server.ClientSessionFromContext(ctx); sess != nil {
@@ -101,29 +185,90 @@ func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout
 			ProjectPath:    proj.Path,
 			SessionID:      sessionID,
 			Model:          model,
-			AllowedTools:   proj.AllowedTools,
+			AllowedTools:   allowedTools,
 			TimeoutMinutes: timeoutMinutes,
 			DryRun:         dryRun,
 		}
 
-		// Start execution (enforces global + per-project concurrency limits)
+		// Start execution, or queue it when concurrency limits are reached
 		if err := tm.Start(ctx, t, execReq, proj.MaxConcurrentTasks); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Cannot start task: %s", err)), nil
 		}
 
 		// Build response
 		var b strings.Builder
-		fmt.Fprintf(&b, "Task started\n\n")
+		queuePos, queueLen := tm.QueuePosition(t.ID)
+		started := t.Snapshot()
+		waiting := started.Status == task.StatusWaiting
+		awaiting := started.Status == task.StatusAwaitingApproval
+		switch {
+		case awaiting:
+			fmt.Fprintf(&b, "Task awaiting approval\n\n")
+		case waiting:
+			fmt.Fprintf(&b, "Task waiting\n\n")
+		case queuePos > 0:
+			fmt.Fprintf(&b, "Task queued\n\n")
+		default:
+			fmt.Fprintf(&b, "Task started\n\n")
+		}
 		fmt.Fprintf(&b, "- ID: %s\n", t.ID)
 		fmt.Fprintf(&b, "- Project: %s\n", proj.Name)
 		fmt.Fprintf(&b, "- Model: %s\n", model)
 		fmt.Fprintf(&b, "- Priority: %s\n", string(priority))
+		if tmpl.Name != "" {
+			fmt.Fprintf(&b, "- Template: %s\n", tmpl.Name)
+		}
+		if awaiting {
+			fmt.Fprintf(&b, "- Approval: required, %s\n", started.ApprovalReason)
+		}
+		if waiting {
+			fmt.Fprintf(&b, "- Waiting for: %s (starts automatically when they complete, %ss if one fails)\n",
+				strings.Join(dependsOn, ", "), onDependencyFailure)
+		} else if awaiting && len(dependsOn) > 0 {
+			fmt.Fprintf(&b, "- Depends on: %s (checked once the task is approved)\n", strings.Join(dependsOn, ", "))
+		} else if len(dependsOn) > 0 {
+			fmt.Fprintf(&b, "- Depends on: %s (already completed)\n", strings.Join(dependsOn, ", "))
+		}
+		if inheritBranch || inheritSession {
+			var inherited []string
+			if inheritBranch {
+				inherited = append(inherited, "branch")
+			}
+			if inheritSession {
+				inherited = append(inherited, "session")
+			}
+			fmt.Fprintf(&b, "- Inherits: %s of %s\n", strings.Join(inherited, " and "), dependsOn[0])
+		}
+		if retries != nil {
+			fmt.Fprintf(&b, "- Retry: %s\n", describeRetry(retries))
+		}
+		if maxBudget > 0 {
+			fmt.Fprintf(&b, "- Budget: $%.2f (stopped once reached)\n", maxBudget)
+		}
+		switch {
+		case queuePos > 0 && budgetErr != nil:
+			fmt.Fprintf(&b, "- Queue position: %d of %d (%s, starts automatically when the budget resets)\n", queuePos, queueLen, budgetErr)
+		case queuePos > 0:
+			fmt.Fprintf(&b, "- Queue position: %d of %d (concurrency limit reached, starts automatically when a slot frees up)\n", queuePos, queueLen)
+		}
 		if dryRun {
 			b.WriteString("- Mode: dry run (plan only)\n")
 		}
 		if sessionID != "" {
 			fmt.Fprintf(&b, "- Resuming session: %s\n", sessionID)
 		}
+		taskBranch := started.GitBranch // may be inherited from a dependency
+		if taskBranch == "" && (proj.Git.Worktree || proj.Git.AutoBranch) {
+			taskBranch = proj.Git.BranchPrefix + t.ID
+		}
+		switch {
+		case inheritBranch && gitBranch == "" && (waiting || awaiting):
+			fmt.Fprintf(&b, "- Branch: the branch of %s, once it completes\n", dependsOn[0])
+		case proj.Git.Worktree:
+			fmt.Fprintf(&b, "- Workspace: dedicated git worktree on branch %s\n", taskBranch)
+		case taskBranch != "":
+			fmt.Fprintf(&b, "- Branch: %s (checked out when the task starts, original branch restored afterwards)\n", taskBranch)
+		}
 		fmt.Fprintf(&b, "- Executor: %s\n", caps.Name)
 
 		// Capability warnings
@@ -159,12 +304,108 @@ func StartTask(tm *task.Manager, pm *project.Manager, defaultTimeout, maxTimeout
 			}
 		}
 
+		if awaiting {
+			fmt.Fprintf(&b, "\nIMPORTANT: This task does not start until a human approves it. Ask the user whether to approve it; do not approve it on your own. It can be approved with approve_task, with herald approve %s on the workstation, or from the approval link sent in the notification.", t.ID)
+			return mcp.NewToolResultText(b.String()), nil
+		}
+
 		fmt.Fprintf(&b, "\nIMPORTANT: Use check_task with task_id=%q and wait_seconds=30. This long-polls server-side and returns instantly on status change — no need to poll repeatedly. Do NOT call check_task more than once every 30 seconds.", t.ID)
 
 		return mcp.NewToolResultText(b.String()), nil
 	}
 }
 
+// templateVars collects the variables a template is rendered with: the
+// caller's variables argument plus the built-ins, which take precedence.
+func templateVars(args map[string]any, prompt, projectName string) map[string]string {
+	vars := make(map[string]string)
+	if raw, ok := args["variables"].(map[string]any); ok {
+		for k, v := range raw {
+			vars[k] = fmt.Sprint(v)
+		}
+	}
+	vars[template.VarPrompt] = prompt
+	vars[template.VarProject] = projectName
+	return vars
+}
+
+// retryPolicy applies start_task's retry argument over the project's
+// retry policy. It returns nil when failed attempts are not retried.
+func retryPolicy(args map[string]any, base *retry.Policy) (*retry.Policy, error) {
+	raw, ok := args["retry"].(map[string]any)
+	if !ok {
+		return base, nil
+	}
+
+	var p retry.Policy
+	if base != nil {
+		p = *base
+	}
+	if v, ok := raw["max_attempts"].(float64); ok {
+		p.MaxAttempts = int(v)
+	}
+	if v, ok := raw["backoff_seconds"].(float64); ok {
+		p.Backoff = time.Duration(v * float64(time.Second))
+	}
+	if v, ok := raw["max_backoff_seconds"].(float64); ok {
+		p.MaxBackoff = time.Duration(v * float64(time.Second))
+	}
+	if v, ok := raw["exit_codes"].([]any); ok {
+		p.ExitCodes = nil
+		for _, c := range v {
+			if code, ok := c.(float64); ok {
+				p.ExitCodes = append(p.ExitCodes, int(code))
+			}
+		}
+	}
+	if v, ok := raw["on_timeout"].(bool); ok {
+		p.OnTimeout = v
+	}
+	if v, ok := raw["stderr_patterns"].([]any); ok {
+		p.StderrPatterns = nil
+		for _, pattern := range v {
+			if s, ok := pattern.(string); ok && s != "" {
+				p.StderrPatterns = append(p.StderrPatterns, s)
+			}
+		}
+	}
+	if v, ok := raw["resume_session"].(bool); ok {
+		p.ResumeSession = v
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if p.MaxAttempts <= 1 {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// describeRetry summarizes a retry policy on one line.
+func describeRetry(p *retry.Policy) string {
+	var classes []string
+	for _, code := range p.ExitCodes {
+		classes = append(classes, fmt.Sprintf("exit code %d", code))
+	}
+	if p.OnTimeout {
+		classes = append(classes, "timeout")
+	}
+	for _, pattern := range p.StderrPatterns {
+		classes = append(classes, fmt.Sprintf("stderr /%s/", pattern))
+	}
+	on := "any failure but a timeout"
+	if len(classes) > 0 {
+		on = strings.Join(classes, ", ")
+	}
+
+	s := fmt.Sprintf("up to %d attempts on %s", p.MaxAttempts, on)
+	if p.ResumeSession {
+		s += ", resuming the failed attempt's session"
+	}
+	return s
+}
+
 // formatEstimate returns a human-readable duration like "3m" or "45s".
 func formatEstimate(d time.Duration) string {
 	if d < time.Minute {
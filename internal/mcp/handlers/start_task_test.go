@@ -9,20 +9,23 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/btouchard/herald/internal/config"
 	"github.com/btouchard/herald/internal/executor"
 	"github.com/btouchard/herald/internal/project"
 	"github.com/btouchard/herald/internal/task"
-	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/template"
 )
 
-type mockExecutor struct{}
+type mockExecutor struct {
+	cost float64
+}
 
 func (m *mockExecutor) Capabilities() executor.Capabilities {
 	return executor.Capabilities{Name: "mock"}
 }
 
 func (m *mockExecutor) Execute(_ context.Context, _ executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
-	return &executor.Result{Output: "done"}, nil
+	return &executor.Result{Output: "done", CostUSD: m.cost}, nil
 }
 
 type mockEstimator struct {
@@ -35,6 +38,25 @@ func (m *mockEstimator) GetAverageTaskDuration(_ string) (time.Duration, int, er
 	return m.avgDuration, m.count, m.err
 }
 
+// newSpentManager returns a task manager where a task of project already
+// cost usd.
+func newSpentManager(t *testing.T, project string, usd float64) *task.Manager {
+	t.Helper()
+	tm := task.NewManager(&mockExecutor{cost: usd}, 3, 2*time.Hour)
+	spent := tm.Create(project, "earlier work", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), spent, executor.Request{TaskID: spent.ID}, 0))
+	<-spent.Done()
+	return tm
+}
+
+// newSpentDeps is newTestDeps with a task of the test project that
+// already cost usd.
+func newSpentDeps(t *testing.T, usd float64) (*task.Manager, *project.Manager) {
+	t.Helper()
+	_, pm := newTestDeps()
+	return newSpentManager(t, "test", usd), pm
+}
+
 func newTestDeps() (*task.Manager, *project.Manager) {
 	pm := project.NewManager(map[string]config.Project{
 		"test": {
@@ -48,12 +70,12 @@ func newTestDeps() (*task.Manager, *project.Manager) {
 }
 
 var testCaps = executor.Capabilities{
-	SupportsSession:  true,
-	SupportsModel:    true,
-	SupportsToolList: true,
-	SupportsDryRun:   true,
+	SupportsSession:   true,
+	SupportsModel:     true,
+	SupportsToolList:  true,
+	SupportsDryRun:    true,
 	SupportsStreaming: true,
-	Name:             "mock",
+	Name:              "mock",
 }
 
 func makeReq(args map[string]any) mcp.CallToolRequest {
@@ -68,7 +90,7 @@ func TestStartTask_WhenNormalTimeout_AcceptsIt(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt":          "do something",
@@ -89,7 +111,7 @@ func TestStartTask_WhenTimeoutExceedsMax_ClampsToMax(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil) // max = 120 min
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil) // max = 120 min
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt":          "do something",
@@ -121,7 +143,7 @@ func TestStartTask_WhenTimeoutZeroOrNegative_UsesDefault(t *testing.T) {
 			t.Parallel()
 
 			tm, pm := newTestDeps()
-			handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+			handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 			result, err := handler(context.Background(), makeReq(map[string]any{
 				"prompt":          "do something",
@@ -143,7 +165,7 @@ func TestStartTask_WhenNoTimeoutProvided_UsesDefault(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -162,7 +184,7 @@ func TestStartTask_WhenPromptTooLarge_RejectsWithError(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 100, "claude-sonnet-4-5-20250929", testCaps, nil) // max 100 bytes
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 100, "claude-sonnet-4-5-20250929", testCaps, nil, nil) // max 100 bytes
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": string(make([]byte, 200)), // 200 bytes > 100 limit
@@ -178,7 +200,7 @@ func TestStartTask_WhenEstimatorHasHistory_IncludesEstimate(t *testing.T) {
 
 	tm, pm := newTestDeps()
 	est := &mockEstimator{avgDuration: 3 * time.Minute, count: 12}
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, est)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, est, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -196,7 +218,7 @@ func TestStartTask_WhenEstimatorNoHistory_ShowsUnknown(t *testing.T) {
 
 	tm, pm := newTestDeps()
 	est := &mockEstimator{avgDuration: 0, count: 0}
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, est)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, est, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -211,7 +233,7 @@ func TestStartTask_WhenNilEstimator_SkipsEstimate(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -227,7 +249,7 @@ func TestStartTask_WhenExplicitModel_UsesIt(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -248,7 +270,7 @@ func TestStartTask_WhenNoModel_UsesConfigDefault(t *testing.T) {
 	t.Parallel()
 
 	tm, pm := newTestDeps()
-	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
 
 	result, err := handler(context.Background(), makeReq(map[string]any{
 		"prompt": "do something",
@@ -262,3 +284,311 @@ func TestStartTask_WhenNoModel_UsesConfigDefault(t *testing.T) {
 	require.Len(t, tasks, 1)
 	assert.Equal(t, "claude-sonnet-4-5-20250929", tasks[0].Model)
 }
+
+// blockingExecutor runs until its context is cancelled.
+type blockingExecutor struct{}
+
+func (b *blockingExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "blocking"}
+}
+
+func (b *blockingExecutor) Execute(ctx context.Context, _ executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestStartTask_WhenConcurrencyLimitReached_QueuesTask(t *testing.T) {
+	t.Parallel()
+
+	_, pm := newTestDeps()
+	tm := task.NewManager(&blockingExecutor{}, 1, 2*time.Hour)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	first, err := handler(context.Background(), makeReq(map[string]any{"prompt": "first"}))
+	require.NoError(t, err)
+	assert.Contains(t, first.Content[0].(mcp.TextContent).Text, "Task started")
+
+	second, err := handler(context.Background(), makeReq(map[string]any{"prompt": "second"}))
+	require.NoError(t, err)
+	assert.False(t, second.IsError)
+
+	text := second.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Task queued")
+	assert.Contains(t, text, "Queue position: 1 of 1")
+
+	for _, snap := range tm.List(task.Filter{}) {
+		_ = tm.Cancel(snap.ID)
+	}
+}
+
+func newTestTemplates() *template.Registry {
+	return template.NewRegistry(map[string]config.Template{
+		"review": {
+			Prompt:       "Review {{scope}} for {{focus}}.\n\n{{prompt}}",
+			Variables:    map[string]string{"focus": "correctness"},
+			Model:        "claude-opus-4-6",
+			Timeout:      20 * time.Minute,
+			AllowedTools: []string{"Read", "Grep"},
+			Priority:     "high",
+			DryRun:       true,
+		},
+	}, nil)
+}
+
+func TestStartTask_WhenTemplate_MergesPromptAndDefaults(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, newTestTemplates())
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":    "Pay attention to token rotation.",
+		"template":  "review",
+		"variables": map[string]any{"scope": "internal/auth"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "- Template: review")
+
+	tasks := tm.List(task.Filter{})
+	require.Len(t, tasks, 1)
+	snap := tasks[0]
+	assert.Equal(t, "Review internal/auth for correctness.\n\nPay attention to token rotation.", snap.Prompt)
+	assert.Equal(t, "claude-opus-4-6", snap.Model)
+	assert.Equal(t, 20, snap.TimeoutMinutes)
+	assert.Equal(t, task.PriorityHigh, snap.Priority)
+	assert.True(t, snap.DryRun)
+
+	tk, err := tm.Get(snap.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Read", "Grep"}, tk.AllowedTools)
+}
+
+func TestStartTask_WhenTemplateAndExplicitArgs_ArgsWin(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, newTestTemplates())
+
+	_, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":          "p",
+		"template":        "review",
+		"variables":       map[string]any{"scope": "cmd", "focus": "security"},
+		"model":           "claude-sonnet-4-5-20250929",
+		"timeout_minutes": float64(5),
+		"priority":        "low",
+		"dry_run":         false,
+	}))
+	require.NoError(t, err)
+
+	tasks := tm.List(task.Filter{})
+	require.Len(t, tasks, 1)
+	snap := tasks[0]
+	assert.Equal(t, "Review cmd for security.\n\np", snap.Prompt)
+	assert.Equal(t, "claude-sonnet-4-5-20250929", snap.Model)
+	assert.Equal(t, 5, snap.TimeoutMinutes)
+	assert.Equal(t, task.PriorityLow, snap.Priority)
+	assert.False(t, snap.DryRun)
+}
+
+func TestStartTask_WhenTemplateVariableMissing_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, newTestTemplates())
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":   "p",
+		"template": "review",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "missing value for scope")
+	assert.Empty(t, tm.List(task.Filter{}), "no task is created")
+}
+
+func TestStartTask_WhenTemplateUnknown_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, newTestTemplates())
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":   "p",
+		"template": "deploy",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "available: review")
+}
+
+func TestStartTask_WhenTemplateWithoutPrompt_StartsTask(t *testing.T) {
+	t.Parallel()
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, newTestTemplates())
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"template":  "review",
+		"variables": map[string]any{"scope": "cmd"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	tasks := tm.List(task.Filter{})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Review cmd for correctness.", tasks[0].Prompt)
+}
+
+func TestStartTask_WhenDependsOnPendingTask_Waits(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	parent := tm.Create("test", "refactor", "", task.PriorityNormal, 30)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":                "run the migration",
+		"depends_on":            []any{parent.ID},
+		"on_dependency_failure": "fail",
+		"inherit_branch":        true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Task waiting")
+	assert.Contains(t, text, "Waiting for: "+parent.ID)
+	assert.Contains(t, text, "fails if one fails")
+	assert.Contains(t, text, "Inherits: branch of "+parent.ID)
+
+	tasks := tm.List(task.Filter{Status: string(task.StatusWaiting)})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, []string{parent.ID}, tasks[0].DependsOn)
+	assert.Equal(t, task.DependencyFail, tasks[0].OnDependencyFailure)
+	assert.True(t, tasks[0].InheritBranch)
+}
+
+func TestStartTask_WhenDependencyUnknown_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":     "run the migration",
+		"depends_on": []any{"herald-missing0"},
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `dependency "herald-missing0" not found`)
+}
+
+func TestStartTask_WhenDependencyPolicyInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	parent := tm.Create("test", "refactor", "", task.PriorityNormal, 30)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt":                "run the migration",
+		"depends_on":            []any{parent.ID},
+		"on_dependency_failure": "ignore",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "on_dependency_failure")
+}
+
+func TestStartTask_WhenRetry_OverlaysProjectPolicy(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt": "run the flaky suite",
+		"retry": map[string]any{
+			"max_attempts":    float64(3),
+			"backoff_seconds": float64(10),
+			"exit_codes":      []any{float64(1)},
+			"on_timeout":      true,
+			"stderr_patterns": []any{"overloaded"},
+			"resume_session":  true,
+		},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "- Retry: up to 3 attempts on exit code 1, timeout, stderr /overloaded/, resuming the failed attempt's session")
+
+	tasks := tm.List(task.Filter{})
+	require.Len(t, tasks, 1)
+	require.NotNil(t, tasks[0].Retry)
+	assert.Equal(t, 10*time.Second, tasks[0].Retry.Backoff)
+}
+
+func TestStartTask_WhenRetryInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{
+		"prompt": "run the flaky suite",
+		"retry":  map[string]any{"max_attempts": float64(3), "stderr_patterns": []any{"("}},
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Invalid retry policy")
+	assert.Empty(t, tm.List(task.Filter{}))
+}
+
+func TestStartTask_WhenBudgetSpent_RejectsWithoutCreatingTask(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newSpentDeps(t, 1.5)
+	tm.SetBudgets(task.Budget{}, map[string]task.Budget{"test": {DailyUSD: 1}})
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"prompt": "more work"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "budget exhausted: project test daily budget of $1.00 is spent")
+	assert.Len(t, tm.List(task.Filter{}), 1)
+}
+
+func TestStartTask_WhenBudgetSpentAndQueued_ExplainsWhy(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newSpentDeps(t, 1)
+	tm.SetBudgets(task.Budget{DailyUSD: 1}, nil)
+	tm.SetBudgetPolicy(task.BudgetQueue)
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"prompt": "more work"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Task queued")
+	assert.Contains(t, text, "- Queue position: 1 of 1 (budget exhausted: global daily budget of $1.00 is spent ($1.00 today), starts automatically when the budget resets)")
+}
+
+func TestStartTask_WhenMaxBudget_SetsTaskCap(t *testing.T) {
+	t.Parallel()
+
+	tm, pm := newTestDeps()
+	handler := StartTask(tm, pm, 30*time.Minute, 2*time.Hour, 102400, "claude-sonnet-4-5-20250929", testCaps, nil, nil)
+
+	result, err := handler(context.Background(), makeReq(map[string]any{"prompt": "work", "max_budget_usd": 2.5}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "- Budget: $2.50 (stopped once reached)")
+
+	tasks := tm.List(task.Filter{})
+	require.Len(t, tasks, 1)
+	assert.InDelta(t, 2.5, tasks[0].MaxBudgetUSD, 0.001)
+
+	result, err = handler(context.Background(), makeReq(map[string]any{"prompt": "work", "max_budget_usd": -1.0}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "max_budget_usd must not be negative")
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/mcp/handlers"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// NewPermissionHandler returns the approval endpoint: an MCP server over
+// streamable HTTP that Claude Code processes spawned by Herald call as
+// their permission prompt tool. Each process authenticates with the bearer
+// token issued to its task, which also tells whose request it is.
+func NewPermissionHandler(tm *task.Manager, version string) http.Handler {
+	s := server.NewMCPServer(
+		"Herald permissions",
+		version,
+		server.WithToolCapabilities(false),
+	)
+
+	s.AddTool(
+		mcp.NewTool(executor.PermissionToolName,
+			mcp.WithDescription("Ask the Herald user to approve a tool use. Returns a JSON permission decision."),
+			mcp.WithString("tool_name",
+				mcp.Required(),
+				mcp.Description("Name of the tool to run"),
+			),
+			mcp.WithObject("input",
+				mcp.Description("Input of the tool use"),
+			),
+			mcp.WithString("tool_use_id",
+				mcp.Description("ID of the tool use"),
+			),
+		),
+		handlers.ApprovalPrompt(tm),
+	)
+
+	return permissionAuth(tm, server.NewStreamableHTTPServer(s))
+}
+
+// permissionAuth rejects requests without the token of a running task and
+// passes the task ID on to the handlers.
+func permissionAuth(tm *task.Manager, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "missing task token", http.StatusUnauthorized)
+			return
+		}
+		taskID, ok := tm.PermissionTask(token)
+		if !ok {
+			http.Error(w, "invalid or expired task token", http.StatusUnauthorized)
+			return
+		}
+		// Approval can take longer than the server's write timeout.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		next.ServeHTTP(w, r.WithContext(handlers.WithPermissionTask(r.Context(), taskID)))
+	})
+}
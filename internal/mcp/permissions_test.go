@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// promptingExecutor supports permission prompts and hands its request to
+// the test, then runs until cancelled.
+type promptingExecutor struct {
+	reqs chan executor.Request
+}
+
+func (p *promptingExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "prompting", SupportsPermissionPrompts: true}
+}
+
+func (p *promptingExecutor) Execute(ctx context.Context, req executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	p.reqs <- req
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func postPermissionRPC(t *testing.T, h http.Handler, token, sessionID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/permissions/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if sessionID != "" {
+		req.Header.Set(server.HeaderKeySessionID, sessionID)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPermissionHandler_WhenTokenMissingOrUnknown_Rejects(t *testing.T) {
+	t.Parallel()
+	tm := task.NewManager(&promptingExecutor{}, 3, 2*time.Hour)
+	h := NewPermissionHandler(tm, "test")
+
+	rec := postPermissionRPC(t, h, "", "", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = postPermissionRPC(t, h, "forged", "", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestPermissionHandler_WhenTaskAsks_WaitsForApproval(t *testing.T) {
+	t.Parallel()
+	exec := &promptingExecutor{reqs: make(chan executor.Request, 1)}
+	tm := task.NewManager(exec, 3, 2*time.Hour)
+	tm.SetPermissionPrompts("http://127.0.0.1:8420/permissions/mcp", time.Minute)
+	h := NewPermissionHandler(tm, "test")
+
+	tsk := tm.Create("proj", "clean up", "", task.PriorityNormal, 30)
+	require.NoError(t, tm.Start(context.Background(), tsk, executor.Request{TaskID: tsk.ID}, 0))
+	token := (<-exec.reqs).Permissions.Token
+
+	rec := postPermissionRPC(t, h, token, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"claude-code","version":"1"}}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	sessionID := rec.Header().Get(server.HeaderKeySessionID)
+
+	called := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		called <- postPermissionRPC(t, h, token, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"approval_prompt","arguments":{"tool_name":"Bash","input":{"command":"rm -rf build"}}}}`)
+	}()
+
+	var r task.PermissionRequest
+	require.Eventually(t, func() bool {
+		pending := tsk.Snapshot().PendingPermissions
+		if len(pending) == 0 {
+			return false
+		}
+		r = pending[0]
+		return true
+	}, 5*time.Second, time.Millisecond)
+	_, err := tm.AnswerPermission(r.ID, task.PermissionDecision{Message: "use make clean"}, task.PermissionOnce)
+	require.NoError(t, err)
+
+	rec = <-called
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `{\"behavior\":\"deny\",\"message\":\"use make clean\"}`)
+
+	require.NoError(t, tm.Cancel(tsk.ID))
+}
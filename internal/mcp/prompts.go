@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/mcp/handlers"
+	"github.com/btouchard/herald/internal/template"
+)
+
+// workflows are the built-in prompts for common tasks. A global template
+// with the same name replaces the workflow.
+var workflows = []*template.Template{
+	{
+		Name:        "review-branch",
+		Description: "Review the changes of a branch without modifying anything",
+		Prompt:      "Review the changes on {{branch}} compared to {{base}}. Focus on {{focus}}. Report the issues you find by severity, with file and line, and do not modify anything.",
+		Defaults: map[string]string{
+			"branch": "the current branch",
+			"base":   "the default branch",
+			"focus":  "correctness, security and missing tests",
+		},
+		DryRun: true,
+	},
+	{
+		Name:        "fix-failing-tests",
+		Description: "Run the tests and fix the failures",
+		Prompt:      "Run {{command}} and fix the failing tests in {{scope}}. Fix the code under test rather than the tests unless a test is clearly wrong, and run the tests again until they pass.",
+		Defaults: map[string]string{
+			"command": "the project's test suite",
+			"scope":   "the project",
+		},
+	},
+	{
+		Name:        "write-release-notes",
+		Description: "Write release notes from the git history",
+		Prompt:      "Write the release notes for {{version}} from the git history since {{since}}. Group the user-facing changes under Added, Changed and Fixed, skip internal-only commits, and add them to the changelog following its existing format.",
+		Defaults: map[string]string{
+			"version": "the next release",
+			"since":   "the latest tag",
+		},
+	},
+}
+
+// registerPrompts publishes the task templates and the built-in workflows
+// as MCP prompts. A project's own templates are named "<project>:<name>".
+func registerPrompts(s *server.MCPServer, deps *Deps) {
+	taken := make(map[string]bool)
+	if deps.Templates != nil {
+		for _, t := range deps.Templates.All() {
+			name := t.Name
+			if t.Project != "" {
+				name = t.Project + ":" + t.Name
+			}
+			taken[name] = true
+			s.AddPrompt(newTemplatePrompt(name, t, t.Project == ""),
+				handlers.TemplatePrompt(deps.Projects, deps.Templates, t.Name, t.Project))
+		}
+	}
+
+	for _, wf := range workflows {
+		if taken[wf.Name] {
+			continue
+		}
+		s.AddPrompt(newTemplatePrompt(wf.Name, wf, true), handlers.WorkflowPrompt(deps.Projects, wf))
+	}
+}
+
+// newTemplatePrompt declares a prompt taking t's variables as arguments.
+func newTemplatePrompt(name string, t *template.Template, withProject bool) mcp.Prompt {
+	description := t.Description
+	if description == "" {
+		description = fmt.Sprintf("Start a task from the %s template", t.Name)
+	}
+	opts := []mcp.PromptOption{mcp.WithPromptDescription(description)}
+
+	if withProject {
+		opts = append(opts, mcp.WithArgument(template.VarProject,
+			mcp.ArgumentDescription("Project name. If omitted, uses default project."),
+		))
+	}
+	for _, v := range t.Variables() {
+		if v == template.VarPrompt || v == template.VarProject {
+			continue
+		}
+		if def, ok := t.Defaults[v]; ok {
+			opts = append(opts, mcp.WithArgument(v,
+				mcp.ArgumentDescription(fmt.Sprintf("Defaults to %q.", def)),
+			))
+		} else {
+			opts = append(opts, mcp.WithArgument(v, mcp.RequiredArgument()))
+		}
+	}
+	opts = append(opts, mcp.WithArgument(template.VarPrompt,
+		mcp.ArgumentDescription("Instructions for the task, added to the template's prompt."),
+	))
+
+	return mcp.NewPrompt(name, opts...)
+}
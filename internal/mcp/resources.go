@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/mcp/handlers"
+)
+
+func registerResources(s *server.MCPServer, deps *Deps) {
+	// herald://tasks/{id} — Task status and result
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(handlers.TaskURITemplate, "Task",
+			mcp.WithTemplateDescription("A Herald task as JSON: status, progress, cost, git branch and output. Subscribe to be notified of every state change."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		handlers.TaskResource(deps.Tasks),
+	)
+
+	// herald://tasks/{id}/output — Task output
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(handlers.TaskOutputURITemplate, "Task output",
+			mcp.WithTemplateDescription("The output of a Herald task, as reported so far."),
+			mcp.WithTemplateMIMEType("text/plain"),
+		),
+		handlers.TaskOutputResource(deps.Tasks),
+	)
+
+	// herald://tasks/{id}/diff — Task changes
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(handlers.TaskDiffURITemplate, "Task diff",
+			mcp.WithTemplateDescription("The changes made by a Herald task, as a unified diff (same as get_diff with task_id)."),
+			mcp.WithTemplateMIMEType("text/x-diff"),
+		),
+		handlers.TaskDiffResource(deps.Tasks, deps.Projects),
+	)
+
+	// herald://projects/{name}/files/{path} — Project file
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(handlers.ProjectFileURITemplate, "Project file",
+			mcp.WithTemplateDescription("A file of a configured project, by path relative to the project root (same checks as read_file)."),
+		),
+		handlers.ProjectFileResource(deps.Projects),
+	)
+}
@@ -7,17 +7,24 @@ import (
 	"github.com/btouchard/herald/internal/executor"
 	"github.com/btouchard/herald/internal/mcp/handlers"
 	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/scheduler"
 	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
 )
 
 // Deps holds shared dependencies injected into MCP handlers.
 type Deps struct {
-	Projects     *project.Manager
-	Tasks        *task.Manager
-	Store        handlers.DurationEstimator
-	Execution    config.ExecutionConfig
-	Capabilities executor.Capabilities
-	Version      string
+	Projects      *project.Manager
+	Tasks         *task.Manager
+	Store         handlers.DurationEstimator
+	Events        handlers.EventLog
+	Transcripts   handlers.TranscriptLog
+	Execution     config.ExecutionConfig
+	Capabilities  executor.Capabilities
+	Templates     *template.Registry
+	Scheduler     *scheduler.Scheduler
+	Subscriptions *Subscriptions // optional, follows the registered sessions
+	Version       string
 }
 
 // serverInstructions are returned to the client during MCP initialize.
@@ -32,17 +39,25 @@ IMPORTANT — prompt efficiency:
 - Think of yourself as a product manager giving clear requirements, not a developer writing code.
 - If the user provides code or file content, summarize the intent instead of forwarding it verbatim.`
 
-// NewServer creates and configures the MCP server with all tools registered.
+// NewServer creates and configures the MCP server with all tools, prompts
+// and resources registered. Resource subscriptions are handled by
+// Subscriptions.Middleware in front of the server's HTTP transport.
 func NewServer(deps *Deps) *server.MCPServer {
-	s := server.NewMCPServer(
-		"Herald",
-		deps.Version,
+	opts := []server.ServerOption{
 		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(false),
+		server.WithResourceCapabilities(true, false),
 		server.WithLogging(),
 		server.WithInstructions(serverInstructions),
-	)
+	}
+	if deps.Subscriptions != nil {
+		opts = append(opts, server.WithHooks(deps.Subscriptions.Hooks()))
+	}
+	s := server.NewMCPServer("Herald", deps.Version, opts...)
 
 	registerTools(s, deps)
+	registerPrompts(s, deps)
+	registerResources(s, deps)
 
 	return s
 }
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/btouchard/herald/internal/mcp/handlers"
+)
+
+// Subscription methods, missing from mcp-go's method constants.
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// maxRequestBody bounds the MCP requests Middleware reads: well above
+// prompts of the largest sensible execution.max_prompt_size.
+const maxRequestBody = 10 << 20 // 10 MB
+
+// Subscriptions tracks the resources MCP sessions subscribed to. mcp-go
+// advertises resources/subscribe but does not implement it, so Middleware
+// answers subscribe and unsubscribe requests before they reach the server.
+// Only sessions registered with the MCP server can subscribe; Hooks keeps
+// that set in step with the server.
+type Subscriptions struct {
+	mu       sync.Mutex
+	live     map[string]struct{}            // sessions registered with the server
+	sessions map[string]map[string]struct{} // session ID → subscribed URIs
+}
+
+// NewSubscriptions creates an empty Subscriptions.
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		live:     make(map[string]struct{}),
+		sessions: make(map[string]map[string]struct{}),
+	}
+}
+
+// Hooks returns the mcp-go hooks that record the sessions the server
+// registers and drop a session's subscriptions when the transport
+// unregisters it.
+func (s *Subscriptions) Hooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(_ context.Context, session server.ClientSession) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.live[session.SessionID()] = struct{}{}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.Drop(session.SessionID())
+	})
+	return hooks
+}
+
+// Subscribe records that a session wants updates for uri. It reports
+// false, recording nothing, when the session is not registered with the
+// MCP server.
+func (s *Subscriptions) Subscribe(sessionID, uri string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.live[sessionID]; !ok {
+		return false
+	}
+	if s.sessions[sessionID] == nil {
+		s.sessions[sessionID] = make(map[string]struct{})
+	}
+	s.sessions[sessionID][uri] = struct{}{}
+	return true
+}
+
+// Unsubscribe removes a subscription of a session.
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions[sessionID], uri)
+	if len(s.sessions[sessionID]) == 0 {
+		delete(s.sessions, sessionID)
+	}
+}
+
+// Drop forgets a session and removes every subscription of it.
+func (s *Subscriptions) Drop(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.live, sessionID)
+	delete(s.sessions, sessionID)
+}
+
+// TaskSubscriptions returns, per session, the subscribed URIs of the
+// resources describing a task.
+func (s *Subscriptions) TaskSubscriptions(taskID string) map[string][]string {
+	base := handlers.TaskURIPrefix + taskID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string][]string)
+	for sessionID, uris := range s.sessions {
+		for uri := range uris {
+			if uri == base || strings.HasPrefix(uri, base+"/") {
+				result[sessionID] = append(result[sessionID], uri)
+			}
+		}
+	}
+	return result
+}
+
+// Middleware answers resources/subscribe and resources/unsubscribe for the
+// session of the request and forgets a session when it is terminated: mcp-go
+// does not unregister sessions on DELETE. Everything else is passed on to next.
+func (s *Subscriptions) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+
+		switch r.Method {
+		case http.MethodDelete:
+			if sessionID != "" {
+				s.Drop(sessionID)
+			}
+			next.ServeHTTP(w, r)
+			return
+		case http.MethodPost:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		if err != nil {
+			if _, ok := errors.AsType[*http.MaxBytesError](err); ok {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				URI string `json:"uri"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(body, &msg) != nil || len(msg.ID) == 0 ||
+			(msg.Method != methodResourcesSubscribe && msg.Method != methodResourcesUnsubscribe) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": msg.ID}
+		switch {
+		case sessionID == "":
+			resp["error"] = map[string]any{"code": mcp.INVALID_REQUEST, "message": "subscriptions require a session"}
+		case msg.Params.URI == "":
+			resp["error"] = map[string]any{"code": mcp.INVALID_PARAMS, "message": "uri is required"}
+		case msg.Method == methodResourcesSubscribe:
+			if !s.Subscribe(sessionID, msg.Params.URI) {
+				resp["error"] = map[string]any{"code": mcp.INVALID_REQUEST, "message": "unknown session"}
+				break
+			}
+			slog.Debug("resource subscribed", "session_id", sessionID, "uri", msg.Params.URI)
+			resp["result"] = map[string]any{}
+		default:
+			s.Unsubscribe(sessionID, msg.Params.URI)
+			slog.Debug("resource unsubscribed", "session_id", sessionID, "uri", msg.Params.URI)
+			resp["result"] = map[string]any{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if sessionID != "" {
+			w.Header().Set(server.HeaderKeySessionID, sessionID)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postRPC(t *testing.T, h http.Handler, sessionID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(server.HeaderKeySessionID, sessionID)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+type fakeSession struct{ id string }
+
+func (f fakeSession) Initialize()                                         {}
+func (f fakeSession) Initialized() bool                                   { return true }
+func (f fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (f fakeSession) SessionID() string                                   { return f.id }
+
+// register reports sessions to subs as the MCP server would.
+func register(subs *Subscriptions, ids ...string) {
+	hooks := subs.Hooks()
+	for _, id := range ids {
+		hooks.RegisterSession(context.Background(), fakeSession{id})
+	}
+}
+
+func TestSubscriptions_Middleware_AnswersSubscribe(t *testing.T) {
+	t.Parallel()
+	subs := NewSubscriptions()
+	register(subs, "s1", "s2")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("subscribe must not reach the MCP server")
+	})
+	h := subs.Middleware(next)
+
+	rec := postRPC(t, h, "s1", `{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"herald://tasks/herald-1/diff"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":{}}`, rec.Body.String())
+
+	postRPC(t, h, "s2", `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"herald://tasks/herald-10"}}`)
+	assert.Equal(t, map[string][]string{"s1": {"herald://tasks/herald-1/diff"}}, subs.TaskSubscriptions("herald-1"))
+
+	postRPC(t, h, "s1", `{"jsonrpc":"2.0","id":8,"method":"resources/unsubscribe","params":{"uri":"herald://tasks/herald-1/diff"}}`)
+	assert.Empty(t, subs.TaskSubscriptions("herald-1"))
+}
+
+func TestSubscriptions_Middleware_WhenNoSession_ReturnsError(t *testing.T) {
+	t.Parallel()
+	h := NewSubscriptions().Middleware(http.NotFoundHandler())
+
+	rec := postRPC(t, h, "", `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"herald://tasks/x"}}`)
+	assert.Contains(t, rec.Body.String(), "subscriptions require a session")
+}
+
+func TestSubscriptions_Middleware_WhenBodyTooLarge_Rejects(t *testing.T) {
+	t.Parallel()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("an oversized request must not reach the MCP server")
+	})
+	h := NewSubscriptions().Middleware(next)
+
+	rec := postRPC(t, h, "s1", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"x":"`+strings.Repeat("a", maxRequestBody)+`"}}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestSubscriptions_Middleware_PassesOtherRequestsThrough(t *testing.T) {
+	t.Parallel()
+	subs := NewSubscriptions()
+	register(subs, "s1")
+	require.True(t, subs.Subscribe("s1", "herald://tasks/x"))
+
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = r.Method + " " + string(body)
+	})
+	h := subs.Middleware(next)
+
+	postRPC(t, h, "s1", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, `POST {"jsonrpc":"2.0","id":1,"method":"tools/list"}`, got)
+
+	req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Header.Set(server.HeaderKeySessionID, "s1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "DELETE ", got)
+	assert.Empty(t, subs.TaskSubscriptions("x"))
+}
+
+func TestSubscriptions_Middleware_WhenSessionUnknown_ReturnsError(t *testing.T) {
+	t.Parallel()
+	subs := NewSubscriptions()
+	h := subs.Middleware(http.NotFoundHandler())
+
+	rec := postRPC(t, h, "made-up", `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"herald://tasks/x"}}`)
+	assert.Contains(t, rec.Body.String(), "unknown session")
+	assert.Empty(t, subs.TaskSubscriptions("x"))
+}
+
+func TestSubscriptions_Hooks_FollowServerSessions(t *testing.T) {
+	t.Parallel()
+	subs := NewSubscriptions()
+	s := server.NewMCPServer("test", "1", server.WithResourceCapabilities(true, false), server.WithHooks(subs.Hooks()))
+	h := subs.Middleware(server.NewStreamableHTTPServer(s))
+
+	rec := postRPC(t, h, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	sessionID := rec.Header().Get(server.HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	rec = postRPC(t, h, sessionID, `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"herald://tasks/x"}}`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, rec.Body.String())
+	assert.Equal(t, map[string][]string{sessionID: {"herald://tasks/x"}}, subs.TaskSubscriptions("x"))
+
+	s.UnregisterSession(context.Background(), sessionID)
+	assert.Empty(t, subs.TaskSubscriptions("x"))
+	rec = postRPC(t, h, sessionID, `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"herald://tasks/x"}}`)
+	assert.Contains(t, rec.Body.String(), "unknown session")
+}
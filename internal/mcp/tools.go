@@ -13,16 +13,26 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 		mcp.NewTool("list_projects",
 			mcp.WithDescription("List all configured projects with their Git status and description."),
 		),
-		handlers.ListProjects(deps.Projects),
+		handlers.ListProjects(deps.Projects, deps.Tasks),
+	)
+
+	// list_templates — List task templates usable with start_task
+	s.AddTool(
+		mcp.NewTool("list_templates",
+			mcp.WithDescription("List the task templates available for start_task's template parameter, with their variables and defaults (model, timeout, priority, dry run, tools)."),
+			mcp.WithString("project",
+				mcp.Description("Project name. Includes the project's own templates. If omitted, uses default project."),
+			),
+		),
+		handlers.ListTemplates(deps.Projects, deps.Templates),
 	)
 
 	// start_task — Launch a Claude Code task
 	s.AddTool(
 		mcp.NewTool("start_task",
-			mcp.WithDescription("Start a Claude Code task on a project. Returns immediately with a task ID. The task runs asynchronously — use check_task to monitor progress. Tasks typically take 1-10 minutes. Use check_task with wait_seconds=30 to long-poll efficiently instead of polling rapidly."),
+			mcp.WithDescription("Start a Claude Code task on a project. Returns immediately with a task ID. When concurrency limits are reached, the task is queued by priority and starts automatically. The task runs asynchronously — use check_task to monitor progress. Tasks typically take 1-10 minutes. Use check_task with wait_seconds=30 to long-poll efficiently instead of polling rapidly."),
 			mcp.WithString("prompt",
-				mcp.Required(),
-				mcp.Description("The task instructions for Claude Code. Send concise, functional requirements — describe WHAT to do, not HOW. Do NOT write code, file contents, or documentation here: Claude Code has the full codebase and will implement it. Think clear requirements, not code."),
+				mcp.Description("The task instructions for Claude Code. Required unless a template is given. Send concise, functional requirements — describe WHAT to do, not HOW. Do NOT write code, file contents, or documentation here: Claude Code has the full codebase and will implement it. Think clear requirements, not code."),
 			),
 			mcp.WithString("context",
 				mcp.Description("Human-readable context explaining why this task was launched, for tracking across chat sessions"),
@@ -35,7 +45,11 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 				mcp.Enum("low", "normal", "high", "urgent"),
 			),
 			mcp.WithString("template",
-				mcp.Description("Optional template name to use (e.g., 'review', 'test', 'fix')"),
+				mcp.Description("Optional template name to use (e.g., 'review', 'test', 'fix'). Use list_templates to see the available templates. The template's prompt skeleton wraps your prompt and its defaults (model, timeout, priority, dry_run, tools) apply unless you override them."),
+			),
+			mcp.WithObject("variables",
+				mcp.Description("Values for the template's {{variables}}, e.g. {\"scope\": \"internal/auth\"}"),
+				mcp.AdditionalProperties(map[string]any{"type": "string"}),
 			),
 			mcp.WithString("session_id",
 				mcp.Description("Claude Code session ID to resume (for multi-turn conversations)"),
@@ -44,7 +58,7 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 				mcp.Description("Maximum execution time in minutes (default: 30)"),
 			),
 			mcp.WithString("git_branch",
-				mcp.Description("Git branch to create/use. Auto-generated if not specified."),
+				mcp.Description("Git branch to check out while the task runs (created if missing). Defaults to branch_prefix + task ID when the project has auto_branch."),
 			),
 			mcp.WithBoolean("dry_run",
 				mcp.Description("If true, Claude Code plans but doesn't execute changes"),
@@ -52,8 +66,37 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 			mcp.WithString("model",
 				mcp.Description("Claude model to use for this task. Defaults to config value. Examples: claude-sonnet-4-5-20250929, claude-opus-4-6"),
 			),
+			mcp.WithArray("depends_on",
+				mcp.Description("IDs of tasks that must complete successfully before this one starts. The task waits in the waiting status until then."),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("on_dependency_failure",
+				mcp.Description("What happens to this task when a dependency fails or is cancelled (default: cancel)"),
+				mcp.Enum("cancel", "fail"),
+			),
+			mcp.WithBoolean("inherit_branch",
+				mcp.Description("Run on the git branch of the first dependency, unless git_branch is set"),
+			),
+			mcp.WithBoolean("inherit_session",
+				mcp.Description("Resume the Claude Code session of the first dependency, unless session_id is set"),
+			),
+			mcp.WithObject("retry",
+				mcp.Description("Retry policy for failed attempts, overriding the project's. A failure is retried when it matches exit_codes, on_timeout or stderr_patterns; without any of them, every failure but a timeout is. Only the final outcome is notified."),
+				mcp.Properties(map[string]any{
+					"max_attempts":        map[string]any{"type": "number", "description": "Attempts in total, the first one included (max 10). 0 or 1 disables retries."},
+					"backoff_seconds":     map[string]any{"type": "number", "description": "Delay before the first retry, doubled after each one (default 30)"},
+					"max_backoff_seconds": map[string]any{"type": "number", "description": "Cap on the delay between attempts"},
+					"exit_codes":          map[string]any{"type": "array", "items": map[string]any{"type": "number"}, "description": "Exit codes to retry"},
+					"on_timeout":          map[string]any{"type": "boolean", "description": "Retry attempts that timed out"},
+					"stderr_patterns":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Regular expressions matched against stderr, e.g. 'overloaded'"},
+					"resume_session":      map[string]any{"type": "boolean", "description": "Resume the failed attempt's session so its work is not lost"},
+				}),
+			),
+			mcp.WithNumber("max_budget_usd",
+				mcp.Description("Cost cap for this task in USD, attempts included. The task is stopped gracefully once it reaches it."),
+			),
 		),
-		handlers.StartTask(deps.Tasks, deps.Projects, deps.Execution.DefaultTimeout, deps.Execution.MaxTimeout, deps.Execution.MaxPromptSize, deps.Execution.Model, deps.Capabilities, deps.Store),
+		handlers.StartTask(deps.Tasks, deps.Projects, deps.Execution.DefaultTimeout, deps.Execution.MaxTimeout, deps.Execution.MaxPromptSize, deps.Execution.Model, deps.Capabilities, deps.Store, deps.Templates),
 	)
 
 	// check_task — Check task status
@@ -99,7 +142,7 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 			mcp.WithDescription("List tasks with optional filters."),
 			mcp.WithString("status",
 				mcp.Description("Filter by status"),
-				mcp.Enum("all", "pending", "running", "completed", "failed", "cancelled", "linked"),
+				mcp.Enum("all", "pending", "awaiting_approval", "waiting", "queued", "running", "paused", "completed", "failed", "cancelled", "linked"),
 			),
 			mcp.WithString("project",
 				mcp.Description("Filter by project name"),
@@ -110,6 +153,9 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 			mcp.WithString("since",
 				mcp.Description("ISO 8601 datetime — only tasks after this time"),
 			),
+			mcp.WithString("file",
+				mcp.Description("Only tasks that modified a file whose path contains this, e.g. internal/auth"),
+			),
 		),
 		handlers.ListTasks(deps.Tasks),
 	)
@@ -117,22 +163,129 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 	// cancel_task — Cancel a running task
 	s.AddTool(
 		mcp.NewTool("cancel_task",
-			mcp.WithDescription("Cancel a running or pending task."),
+			mcp.WithDescription("Cancel a running, queued or pending task."),
 			mcp.WithString("task_id",
 				mcp.Required(),
 				mcp.Description("The task ID to cancel"),
 			),
 			mcp.WithBoolean("revert",
-				mcp.Description("If true, git revert changes made by this task"),
+				mcp.Description("If true, undo the task's git changes: discard its uncommitted edits, delete (or reset) the task branch and restore the original branch and stashed changes"),
 			),
 		),
 		handlers.CancelTask(deps.Tasks),
 	)
 
+	// approve_task — Approve or reject a task awaiting approval
+	s.AddTool(
+		mcp.NewTool("approve_task",
+			mcp.WithDescription("Approve or reject a task awaiting approval. Tasks wait in awaiting_approval after start_task when the project, their priority or the size of their prompt requires it. Rejected tasks are cancelled."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The task ID (herald-xxxxxxxx)"),
+			),
+			mcp.WithString("decision",
+				mcp.Required(),
+				mcp.Description("approve starts the task; reject cancels it"),
+				mcp.Enum("approve", "reject"),
+			),
+			mcp.WithString("reason",
+				mcp.Description("Why the task is rejected, recorded as its error"),
+			),
+		),
+		handlers.ApproveTask(deps.Tasks, deps.Execution.ApproveFromChat),
+	)
+
+	// send_message — Send follow-up instructions to a running task
+	s.AddTool(
+		mcp.NewTool("send_message",
+			mcp.WithDescription("Send follow-up instructions to a running task, e.g. to correct course or add a requirement, without cancelling it. The message is delivered as a new user turn. Executors that do not support messages, and tasks reattached after a Herald restart, reject it."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The ID of the running task"),
+			),
+			mcp.WithString("message",
+				mcp.Required(),
+				mcp.Description("The instructions to deliver, written as you would address Claude Code"),
+			),
+		),
+		handlers.SendMessage(deps.Tasks, deps.Capabilities, deps.Execution.MaxPromptSize),
+	)
+
+	// pause_task — Suspend a running task
+	s.AddTool(
+		mcp.NewTool("pause_task",
+			mcp.WithDescription("Pause a running task, e.g. to free the machine for something more urgent. Its process is suspended and its timeout stops until resume_task."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The ID of the running task"),
+			),
+		),
+		handlers.PauseTask(deps.Tasks, deps.Execution.PausedTasksHoldSlots),
+	)
+
+	// resume_task — Continue a paused task
+	s.AddTool(
+		mcp.NewTool("resume_task",
+			mcp.WithDescription("Resume a task paused with pause_task. It continues where it stopped."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The ID of the paused task"),
+			),
+		),
+		handlers.ResumeTask(deps.Tasks),
+	)
+
+	// extend_task — Change the timeout of a running task
+	s.AddTool(
+		mcp.NewTool("extend_task",
+			mcp.WithDescription("Replace the timeout of a running or paused task, e.g. to give a task about to finish more time after a timeout warning. The new timeout counts from when the task started, paused time excluded, and may be shorter than the current one."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The ID of the running or paused task"),
+			),
+			mcp.WithNumber("timeout_minutes",
+				mcp.Required(),
+				mcp.Description("The new timeout in minutes, at most the server's max timeout"),
+			),
+		),
+		handlers.ExtendTask(deps.Tasks),
+	)
+
+	// approve_permission — Allow a tool use a task is waiting for
+	s.AddTool(
+		mcp.NewTool("approve_permission",
+			mcp.WithDescription("Allow a tool use a running task asked permission for, e.g. Bash(rm -rf build). Pending requests are shown by check_task and pushed as notifications."),
+			mcp.WithString("request_id",
+				mcp.Required(),
+				mcp.Description("The permission request ID (perm-...)"),
+			),
+			mcp.WithString("scope",
+				mcp.Description("once allows this tool use only; task also allows the exact same tool use (same command, file or input) for the rest of the task, not other uses of the tool (default: once)"),
+				mcp.Enum("once", "task"),
+			),
+		),
+		handlers.ApprovePermission(deps.Tasks),
+	)
+
+	// deny_permission — Refuse a tool use a task is waiting for
+	s.AddTool(
+		mcp.NewTool("deny_permission",
+			mcp.WithDescription("Refuse a tool use a running task asked permission for. The task is told why and continues without it."),
+			mcp.WithString("request_id",
+				mcp.Required(),
+				mcp.Description("The permission request ID (perm-...)"),
+			),
+			mcp.WithString("reason",
+				mcp.Description("Why the tool use is refused, passed on to the task, e.g. 'do not delete the build directory, clean it with make clean'"),
+			),
+		),
+		handlers.DenyPermission(deps.Tasks),
+	)
+
 	// get_diff — Get Git diff for a task or project
 	s.AddTool(
 		mcp.NewTool("get_diff",
-			mcp.WithDescription("Show Git diff of changes. Use task_id to diff a task's branch against current branch, or project to diff uncommitted changes."),
+			mcp.WithDescription("Show Git diff of changes. Use task_id to diff a task's branch against current branch (including uncommitted work when the task has its own worktree), or project to diff uncommitted changes."),
 			mcp.WithString("task_id",
 				mcp.Description("Task ID — diffs the task branch against the current branch"),
 			),
@@ -150,6 +303,9 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 			mcp.WithString("project",
 				mcp.Description("Project name. If omitted, uses default project."),
 			),
+			mcp.WithString("task_id",
+				mcp.Description("Task ID — reads from the task's worktree if it has one (overrides project)"),
+			),
 			mcp.WithString("path",
 				mcp.Required(),
 				mcp.Description("Relative path within the project"),
@@ -161,7 +317,7 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 				mcp.Description("Stop reading at this line number"),
 			),
 		),
-		handlers.ReadFile(deps.Projects),
+		handlers.ReadFile(deps.Tasks, deps.Projects),
 	)
 
 	// herald_push — Push Claude Code session context to Herald
@@ -199,18 +355,139 @@ func registerTools(s *server.MCPServer, deps *Deps) {
 	// get_logs — Get logs and activity history
 	s.AddTool(
 		mcp.NewTool("get_logs",
-			mcp.WithDescription("Get logs and activity history."),
+			mcp.WithDescription("Get the event timeline of a task, or recent activity across tasks. Events are listed oldest first; page with the before/after cursors printed at the end."),
 			mcp.WithString("task_id",
-				mcp.Description("Specific task ID. If omitted, shows recent activity."),
+				mcp.Description("Specific task ID. If omitted, shows recent activity across tasks."),
+			),
+			mcp.WithString("project",
+				mcp.Description("Only show recent activity of this project (without task_id)"),
 			),
 			mcp.WithString("level",
-				mcp.Description("Minimum log level to show"),
+				mcp.Description("Minimum log level to show (default: debug for a task, info for recent activity)"),
 				mcp.Enum("debug", "info", "warn", "error"),
 			),
+			mcp.WithString("types",
+				mcp.Description("Comma-separated event types to show, e.g. 'tool_use,warning' or 'task.failed'"),
+			),
+			mcp.WithString("since",
+				mcp.Description("Only events since this time: RFC 3339, or a duration ago such as '30m'"),
+			),
+			mcp.WithNumber("after",
+				mcp.Description("Cursor: only events after this event ID, to poll for new ones"),
+			),
+			mcp.WithNumber("before",
+				mcp.Description("Cursor: only events before this event ID, to page back"),
+			),
 			mcp.WithNumber("limit",
 				mcp.Description("Maximum number of log entries to return (default: 20)"),
 			),
 		),
-		handlers.GetLogs(deps.Tasks),
+		handlers.GetLogs(deps.Tasks, deps.Events),
+	)
+
+	// get_transcript — Page through what a task did, step by step
+	s.AddTool(
+		mcp.NewTool("get_transcript",
+			mcp.WithDescription("Get the transcript of a task: the assistant's turns, its tool calls with their inputs and (truncated) results, and how long each took. Use it to see what commands a task actually ran. Transcripts are recorded when an attempt ends."),
+			mcp.WithString("task_id",
+				mcp.Required(),
+				mcp.Description("The task ID"),
+			),
+			mcp.WithString("tool",
+				mcp.Description("Only show the calls and results of this tool, e.g. 'Bash'"),
+			),
+			mcp.WithNumber("attempt",
+				mcp.Description("Only show this attempt of a retried task"),
+			),
+			mcp.WithNumber("after",
+				mcp.Description("Cursor: only entries after this entry number, to read the next page"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of entries to return (default: 50)"),
+			),
+		),
+		handlers.GetTranscript(deps.Tasks, deps.Transcripts),
+	)
+
+	if deps.Scheduler == nil {
+		return
+	}
+
+	// schedule_task — Create or replace a recurring task
+	s.AddTool(
+		mcp.NewTool("schedule_task",
+			mcp.WithDescription("Schedule a recurring Claude Code task with a cron expression, e.g. a nightly test run. Each run starts a normal task, as start_task would. Scheduling again with the same name replaces the schedule."),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Unique schedule name, e.g. 'nightly-tests'"),
+			),
+			mcp.WithString("cron",
+				mcp.Required(),
+				mcp.Description("Five-field cron expression (minute hour day-of-month month day-of-week), e.g. '0 2 * * *' every night at 2:00 or '0 9 * * mon' every Monday at 9:00. @hourly, @daily, @weekly and @monthly are accepted."),
+			),
+			mcp.WithString("prompt",
+				mcp.Description("The task instructions for Claude Code. Required unless a template is given."),
+			),
+			mcp.WithString("project",
+				mcp.Description("Project name from configuration. If omitted, uses default project."),
+			),
+			mcp.WithString("template",
+				mcp.Description("Optional template name to use. Use list_templates to see the available templates."),
+			),
+			mcp.WithObject("variables",
+				mcp.Description("Values for the template's {{variables}}"),
+				mcp.AdditionalProperties(map[string]any{"type": "string"}),
+			),
+			mcp.WithString("timezone",
+				mcp.Description("IANA time zone the cron expression is evaluated in, e.g. 'Europe/Paris'. Defaults to the server's local time."),
+			),
+			mcp.WithString("priority",
+				mcp.Description("Priority of the tasks in the execution queue"),
+				mcp.Enum("low", "normal", "high", "urgent"),
+			),
+			mcp.WithString("model",
+				mcp.Description("Claude model to use. Defaults to config value."),
+			),
+			mcp.WithNumber("timeout_minutes",
+				mcp.Description("Maximum execution time of each run in minutes"),
+			),
+			mcp.WithString("git_branch",
+				mcp.Description("Git branch each run checks out (created if missing)"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("If true, Claude Code plans but doesn't execute changes"),
+			),
+			mcp.WithString("missed_run",
+				mcp.Description("What to do on startup with a run missed while Herald was stopped (default: skip)"),
+				mcp.Enum("skip", "run_once"),
+			),
+			mcp.WithNumber("jitter_minutes",
+				mcp.Description("Delay each run by a random duration up to this many minutes"),
+			),
+		),
+		handlers.ScheduleTask(deps.Scheduler),
+	)
+
+	// list_schedules — List recurring tasks
+	s.AddTool(
+		mcp.NewTool("list_schedules",
+			mcp.WithDescription("List recurring tasks with their cron expression, next run and last run."),
+			mcp.WithString("project",
+				mcp.Description("Filter by project name"),
+			),
+		),
+		handlers.ListSchedules(deps.Scheduler, deps.Tasks),
+	)
+
+	// delete_schedule — Delete a recurring task
+	s.AddTool(
+		mcp.NewTool("delete_schedule",
+			mcp.WithDescription("Delete a recurring task created with schedule_task. Schedules from herald.yaml must be removed there."),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("The schedule name"),
+			),
+		),
+		handlers.DeleteSchedule(deps.Scheduler),
 	)
 }
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/btouchard/herald/internal/executor"
 )
 
 // MCPSender abstracts the mcp-go server notification methods.
@@ -13,10 +15,17 @@ type MCPSender interface {
 	SendNotificationToAllClients(method string, params map[string]any)
 }
 
+// ResourceSubscriptions reports which sessions subscribed to the MCP
+// resources of a task. Defined consumer-side per Go convention.
+type ResourceSubscriptions interface {
+	TaskSubscriptions(taskID string) map[string][]string // session ID → URIs
+}
+
 // MCPNotifier pushes task updates to Claude Chat via MCP notifications.
 type MCPNotifier struct {
 	sender   MCPSender
 	debounce time.Duration
+	subs     ResourceSubscriptions
 
 	mu       sync.Mutex
 	lastSent map[string]time.Time // taskID → last progress notification time
@@ -36,13 +45,22 @@ func NewMCPNotifier(sender MCPSender, debounce time.Duration) *MCPNotifier {
 	}
 }
 
+// SetSubscriptions enables notifications/resources/updated for the task
+// resources sessions subscribed to.
+func (n *MCPNotifier) SetSubscriptions(subs ResourceSubscriptions) {
+	n.subs = subs
+}
+
 // Notify sends an MCP notification for the given event.
 func (n *MCPNotifier) Notify(event Event) {
 	switch event.Type {
 	case "task.progress":
 		n.sendProgress(event)
-	case "task.started":
+		return
+	case "task.queued", "task.waiting", "task.started", "task.paused", "task.resumed":
 		n.sendMessage(event, "info")
+	case "task.permission", "task.awaiting_approval", "task.preempted", "task.timeout_warning":
+		n.sendMessage(event, "warning")
 	case "task.completed":
 		n.clearDebounce(event.TaskID)
 		n.sendMessage(event, "info")
@@ -52,16 +70,30 @@ func (n *MCPNotifier) Notify(event Event) {
 	case "task.cancelled":
 		n.clearDebounce(event.TaskID)
 		n.sendMessage(event, "warning")
+	case "schedule.fired":
+		n.sendMessage(event, "info")
+		return
+	case "schedule.missed":
+		n.sendMessage(event, "warning")
+		return
+	case "schedule.failed":
+		n.sendMessage(event, "error")
+		return
 	default:
 		slog.Debug("mcp notifier: unknown event type", "type", event.Type)
+		return
 	}
+	n.sendResourceUpdates(event)
 }
 
-// sendProgress sends a notifications/progress with debounce.
+// sendProgress sends a notifications/progress with debounce. Todo list
+// updates, which tell how far along the task is, are not debounced.
 func (n *MCPNotifier) sendProgress(event Event) {
+	todos := event.Progress != nil && event.Progress.Kind == executor.ProgressTodos && len(event.Progress.Todos) > 0
+
 	n.mu.Lock()
 	last, ok := n.lastSent[event.TaskID]
-	if ok && time.Since(last) < n.debounce {
+	if ok && time.Since(last) < n.debounce && !todos {
 		n.mu.Unlock()
 		return
 	}
@@ -74,8 +106,19 @@ func (n *MCPNotifier) sendProgress(event Event) {
 		"total":         1,
 		"message":       event.Message,
 	}
+	if todos {
+		done := 0
+		for _, todo := range event.Progress.Todos {
+			if todo.Status == executor.TodoCompleted {
+				done++
+			}
+		}
+		params["progress"] = done
+		params["total"] = len(event.Progress.Todos)
+	}
 
 	n.send(event.MCPSessionID, "notifications/progress", params)
+	n.sendResourceUpdates(event)
 }
 
 // sendMessage sends a notifications/message for terminal/start events.
@@ -94,6 +137,25 @@ func (n *MCPNotifier) sendMessage(event Event, level string) {
 	n.send(event.MCPSessionID, "notifications/message", params)
 }
 
+// sendResourceUpdates sends a notifications/resources/updated for each
+// subscribed resource of the event's task, to the subscribed session only.
+func (n *MCPNotifier) sendResourceUpdates(event Event) {
+	if n.subs == nil {
+		return
+	}
+	for sessionID, uris := range n.subs.TaskSubscriptions(event.TaskID) {
+		for _, uri := range uris {
+			params := map[string]any{"uri": uri}
+			if err := n.sender.SendNotificationToSpecificClient(sessionID, "notifications/resources/updated", params); err != nil {
+				slog.Debug("resource update notification failed",
+					"session_id", sessionID,
+					"uri", uri,
+					"error", err)
+			}
+		}
+	}
+}
+
 // send dispatches to a specific client or broadcasts.
 func (n *MCPNotifier) send(mcpSessionID, method string, params map[string]any) {
 	if mcpSessionID != "" {
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
 )
 
 // mockSender records all MCP notifications sent.
@@ -116,6 +118,95 @@ func TestMCPNotifier_StartedAlwaysSent(t *testing.T) {
 	assert.Equal(t, "notifications/message", msgs[0].method)
 }
 
+func TestMCPNotifier_QueuedAlwaysSent(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.queued", TaskID: "t1", Message: "task queued (position 1 of 1)"})
+
+	msgs := sender.allBroadcast()
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "notifications/message", msgs[0].method)
+	assert.Equal(t, "info", msgs[0].params["level"])
+}
+
+func TestMCPNotifier_PermissionSentAsWarning(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "Using tool: Bash"})
+	n.Notify(Event{Type: "task.permission", TaskID: "t1", Message: "t1 wants to run Bash(rm -rf build)"})
+
+	msgs := sender.allBroadcast()
+	require.Len(t, msgs, 2, "permission requests are not debounced")
+	assert.Equal(t, "notifications/message", msgs[1].method)
+	assert.Equal(t, "warning", msgs[1].params["level"])
+}
+
+func TestMCPNotifier_AwaitingApprovalSentAsWarning(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.awaiting_approval", TaskID: "t1", Message: "t1 awaits approval (urgent priority requires approval)"})
+
+	msgs := sender.allBroadcast()
+	require.NotEmpty(t, msgs)
+	assert.Equal(t, "notifications/message", msgs[0].method)
+	assert.Equal(t, "warning", msgs[0].params["level"])
+}
+
+func TestMCPNotifier_PreemptedSentAsWarning(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.preempted", TaskID: "t1", Message: "task paused to make room for urgent task t2, resumes when it ends"})
+
+	msgs := sender.allBroadcast()
+	require.NotEmpty(t, msgs)
+	assert.Equal(t, "notifications/message", msgs[0].method)
+	assert.Equal(t, "warning", msgs[0].params["level"])
+}
+
+func TestMCPNotifier_TimeoutWarningSentAsWarning(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.timeout_warning", TaskID: "t1", Message: "task times out in 5m0s — use extend_task to give it more time"})
+
+	msgs := sender.allBroadcast()
+	require.NotEmpty(t, msgs)
+	assert.Equal(t, "warning", msgs[0].params["level"])
+}
+
+func TestMCPNotifier_PauseAndResumeSentAsInfo(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.paused", TaskID: "t1", Message: "task paused"})
+	n.Notify(Event{Type: "task.resumed", TaskID: "t1", Message: "task resumed after 5m0s paused"})
+
+	msgs := sender.allBroadcast()
+	var levels []any
+	for _, m := range msgs {
+		if m.method == "notifications/message" {
+			levels = append(levels, m.params["level"])
+		}
+	}
+	assert.Equal(t, []any{"info", "info"}, levels)
+}
+
 func TestMCPNotifier_TargetsSpecificSession(t *testing.T) {
 	t.Parallel()
 
@@ -176,6 +267,29 @@ func TestMCPNotifier_ProgressMethodAndParams(t *testing.T) {
 	assert.Equal(t, "compiling...", msgs[0].params["message"])
 }
 
+func TestMCPNotifier_WhenTodosUpdated_SendsDeterminateProgressPastDebounce(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, 10*time.Second)
+
+	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "compiling..."})
+	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "Todos: 1/3 done — Run tests", Progress: &executor.ProgressEvent{
+		Kind: executor.ProgressTodos,
+		Todos: []executor.Todo{
+			{Content: "Fix the handler", Status: executor.TodoCompleted},
+			{Content: "Run tests", Status: executor.TodoInProgress},
+			{Content: "Update docs", Status: executor.TodoPending},
+		},
+	}})
+
+	msgs := sender.allBroadcast()
+	require.Len(t, msgs, 2)
+	assert.Equal(t, 1, msgs[1].params["progress"])
+	assert.Equal(t, 3, msgs[1].params["total"])
+	assert.Equal(t, "Todos: 1/3 done — Run tests", msgs[1].params["message"])
+}
+
 func TestMCPNotifier_CompletedClearsDebounce(t *testing.T) {
 	t.Parallel()
 
@@ -213,3 +327,57 @@ func TestMCPNotifier_DifferentTasksIndependentDebounce(t *testing.T) {
 	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "t1 step 2"})
 	assert.Equal(t, 2, sender.broadcastCount())
 }
+
+type staticSubscriptions map[string]map[string][]string
+
+func (s staticSubscriptions) TaskSubscriptions(taskID string) map[string][]string {
+	return s[taskID]
+}
+
+func TestMCPNotifier_WhenSubscribed_SendsResourceUpdates(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, time.Hour)
+	n.SetSubscriptions(staticSubscriptions{
+		"t1": {"sess-a": {"herald://tasks/t1", "herald://tasks/t1/output"}},
+	})
+
+	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "step 1"})
+	n.Notify(Event{Type: "task.progress", TaskID: "t1", Message: "step 2"}) // debounced
+	n.Notify(Event{Type: "task.completed", TaskID: "t1", Message: "done"})
+	n.Notify(Event{Type: "task.completed", TaskID: "t2", Message: "done"})
+
+	var uris []string
+	for _, s := range sender.allTargeted() {
+		require.Equal(t, "notifications/resources/updated", s.method)
+		assert.Equal(t, "sess-a", s.sessionID)
+		uris = append(uris, s.params["uri"].(string))
+	}
+	assert.ElementsMatch(t, []string{
+		"herald://tasks/t1", "herald://tasks/t1/output",
+		"herald://tasks/t1", "herald://tasks/t1/output",
+	}, uris)
+}
+
+func TestMCPNotifier_ScheduleEvents_BroadcastWithoutResourceUpdates(t *testing.T) {
+	t.Parallel()
+
+	sender := &mockSender{}
+	n := NewMCPNotifier(sender, time.Hour)
+	n.SetSubscriptions(staticSubscriptions{
+		"":   {"sess-a": {"herald://tasks/t1"}},
+		"t1": {"sess-a": {"herald://tasks/t1"}},
+	})
+
+	n.Notify(Event{Type: "schedule.fired", TaskID: "t1", Message: "nightly started t1"})
+	n.Notify(Event{Type: "schedule.missed", Message: "nightly missed a run"})
+	n.Notify(Event{Type: "schedule.failed", Message: "nightly could not start"})
+
+	assert.Equal(t, 0, sender.targetedCount())
+	msgs := sender.allBroadcast()
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "info", msgs[0].params["level"])
+	assert.Equal(t, "warning", msgs[1].params["level"])
+	assert.Equal(t, "error", msgs[2].params["level"])
+}
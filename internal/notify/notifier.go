@@ -1,12 +1,18 @@
 package notify
 
-// Event represents a task lifecycle notification.
+import "github.com/btouchard/herald/internal/executor"
+
+// Event represents a task lifecycle or schedule notification.
 type Event struct {
-	Type    string // "task.started", "task.progress", "task.completed", "task.failed", "task.cancelled"
+	Type    string // "task.awaiting_approval", "task.queued", "task.waiting", "task.started", "task.progress", "task.permission", "task.timeout_warning", "task.paused", "task.preempted", "task.resumed", "task.completed", "task.failed", "task.cancelled", "schedule.fired", "schedule.missed", "schedule.failed"
 	TaskID  string
 	Project string
 	Message string
 
+	// Progress is what a task.progress event reports, when the executor
+	// reported it.
+	Progress *executor.ProgressEvent
+
 	// MCPSessionID targets a specific MCP client session.
 	// Empty means broadcast to all.
 	MCPSessionID string
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/retry"
 )
 
 // Manager loads and manages configured projects.
@@ -31,18 +32,39 @@ func NewManager(projects map[string]config.Project) *Manager {
 			AllowedTools:       cfg.AllowedTools,
 			MaxConcurrentTasks: cfg.MaxConcurrentTasks,
 			Git: GitConfig{
-				AutoBranch:   cfg.Git.AutoBranch,
-				AutoStash:    cfg.Git.AutoStash,
-				AutoCommit:   cfg.Git.AutoCommit,
-				BranchPrefix: cfg.Git.BranchPrefix,
+				AutoBranch:      cfg.Git.AutoBranch,
+				AutoStash:       cfg.Git.AutoStash,
+				AutoCommit:      cfg.Git.AutoCommit,
+				BranchPrefix:    cfg.Git.BranchPrefix,
+				Worktree:        cfg.Git.Worktree,
+				WorktreeCleanup: cfg.Git.WorktreeCleanup,
 			},
 		}
+		if cfg.Retry.MaxAttempts > 1 {
+			p.Retry = &retry.Policy{
+				MaxAttempts:    cfg.Retry.MaxAttempts,
+				Backoff:        cfg.Retry.Backoff,
+				MaxBackoff:     cfg.Retry.MaxBackoff,
+				ExitCodes:      cfg.Retry.ExitCodes,
+				OnTimeout:      cfg.Retry.OnTimeout,
+				StderrPatterns: cfg.Retry.StderrPatterns,
+				ResumeSession:  cfg.Retry.ResumeSession,
+			}
+			if err := p.Retry.Validate(); err != nil {
+				// Rejected when the config is loaded already.
+				slog.Warn("ignoring invalid retry policy", "project", name, "error", err)
+				p.Retry = nil
+			}
+		}
 		if p.MaxConcurrentTasks < 1 {
 			p.MaxConcurrentTasks = 1
 		}
 		if p.Git.BranchPrefix == "" {
 			p.Git.BranchPrefix = "herald/"
 		}
+		if p.Git.WorktreeCleanup == "" {
+			p.Git.WorktreeCleanup = "keep"
+		}
 		m.projects[name] = p
 	}
 
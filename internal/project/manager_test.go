@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +27,38 @@ func TestNewManager_SetsDefaults(t *testing.T) {
 	assert.Equal(t, "herald/", p.Git.BranchPrefix)
 }
 
+func TestNewManager_MapsRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	pm := NewManager(map[string]config.Project{
+		"flaky": {
+			Path: "/tmp",
+			Retry: config.RetryConfig{
+				MaxAttempts:    3,
+				Backoff:        time.Minute,
+				StderrPatterns: []string{"overloaded"},
+				ResumeSession:  true,
+			},
+		},
+		"once": {
+			Path:  "/tmp",
+			Retry: config.RetryConfig{MaxAttempts: 1},
+		},
+	})
+
+	p, err := pm.Get("flaky")
+	require.NoError(t, err)
+	require.NotNil(t, p.Retry)
+	assert.Equal(t, 3, p.Retry.MaxAttempts)
+	assert.Equal(t, time.Minute, p.Retry.Backoff)
+	assert.Equal(t, []string{"overloaded"}, p.Retry.StderrPatterns)
+	assert.True(t, p.Retry.ResumeSession)
+
+	p, err = pm.Get("once")
+	require.NoError(t, err)
+	assert.Nil(t, p.Retry, "a single attempt needs no policy")
+}
+
 func TestManager_Validate_RejectsNonexistentPath(t *testing.T) {
 	t.Parallel()
 
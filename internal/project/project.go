@@ -1,5 +1,7 @@
 package project
 
+import "github.com/btouchard/herald/internal/retry"
+
 // Project represents a configured project that Herald can operate on.
 type Project struct {
 	Name               string
@@ -9,11 +11,14 @@ type Project struct {
 	AllowedTools       []string
 	MaxConcurrentTasks int
 	Git                GitConfig
+	Retry              *retry.Policy // nil when failed tasks are not retried
 }
 
 type GitConfig struct {
-	AutoBranch   bool
-	AutoStash    bool
-	AutoCommit   bool
-	BranchPrefix string
+	AutoBranch      bool
+	AutoStash       bool
+	AutoCommit      bool
+	BranchPrefix    string
+	Worktree        bool
+	WorktreeCleanup string
 }
@@ -0,0 +1,101 @@
+// Package retry holds the policy deciding whether a failed task attempt is
+// run again. It depends on nothing else in Herald, so that configuration,
+// projects and the task manager can all share it.
+package retry
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// MaxAttempts bounds Policy.MaxAttempts.
+const MaxAttempts = 10
+
+// DefaultBackoff is the delay before the first retry when a policy does not
+// set one.
+const DefaultBackoff = 30 * time.Second
+
+// Policy decides whether a failed attempt is run again. A failure is
+// retryable when it matches one of the failure classes: an exit code in
+// ExitCodes, a timeout with OnTimeout, or stderr matching one of
+// StderrPatterns. A policy without any class retries every failure except
+// timeouts. Cancelled tasks are never retried.
+type Policy struct {
+	MaxAttempts    int           `json:"max_attempts"`          // attempts in total, the first one included
+	Backoff        time.Duration `json:"backoff,omitempty"`     // delay before the first retry, doubled after each one
+	MaxBackoff     time.Duration `json:"max_backoff,omitempty"` // cap on the delay (0 = none)
+	ExitCodes      []int         `json:"exit_codes,omitempty"`
+	OnTimeout      bool          `json:"on_timeout,omitempty"`
+	StderrPatterns []string      `json:"stderr_patterns,omitempty"` // regular expressions
+	ResumeSession  bool          `json:"resume_session,omitempty"`  // retry in the failed attempt's session
+
+	patterns []*regexp.Regexp // StderrPatterns, compiled by Validate
+}
+
+// Validate checks the bounds of the policy and compiles its patterns. It
+// must be called before the policy matches failures, and before the policy
+// is shared: Match only uses the patterns it compiled.
+func (p *Policy) Validate() error {
+	if p.MaxAttempts < 0 || p.MaxAttempts > MaxAttempts {
+		return fmt.Errorf("max_attempts must be between 0 and %d, got %d", MaxAttempts, p.MaxAttempts)
+	}
+	if p.Backoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("backoff and max_backoff must not be negative")
+	}
+	patterns := make([]*regexp.Regexp, 0, len(p.StderrPatterns))
+	for _, pattern := range p.StderrPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid stderr pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	p.patterns = patterns
+	return nil
+}
+
+// Enabled reports whether the policy allows more than one attempt.
+func (p *Policy) Enabled() bool {
+	return p != nil && p.MaxAttempts > 1
+}
+
+// Match returns the failure class an attempt that exited with exitCode and
+// wrote stderr matched, or "" when the failure is not retryable.
+func (p *Policy) Match(exitCode int, stderr string, timedOut bool) string {
+	if timedOut {
+		if p.OnTimeout {
+			return "timeout"
+		}
+		return ""
+	}
+
+	if len(p.ExitCodes) == 0 && len(p.StderrPatterns) == 0 && !p.OnTimeout {
+		return "failure"
+	}
+	if exitCode != 0 && slices.Contains(p.ExitCodes, exitCode) {
+		return fmt.Sprintf("exit code %d", exitCode)
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(stderr) {
+			return fmt.Sprintf("stderr matches %q", re.String())
+		}
+	}
+	return ""
+}
+
+// Delay returns how long to wait before attempt n+1 after attempt n failed.
+func (p *Policy) Delay(n int) time.Duration {
+	d := p.Backoff
+	if d == 0 {
+		d = DefaultBackoff
+	}
+	for i := 1; i < n && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Match(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		exitCode int
+		stderr   string
+		timedOut bool
+		want     string
+	}{
+		{"no class retries failures", Policy{MaxAttempts: 2}, 1, "", false, "failure"},
+		{"no class skips timeouts", Policy{MaxAttempts: 2}, 0, "", true, ""},
+		{"exit code matches", Policy{ExitCodes: []int{1, 143}}, 143, "", false, "exit code 143"},
+		{"exit code differs", Policy{ExitCodes: []int{1}}, 2, "", false, ""},
+		{"timeout", Policy{OnTimeout: true}, 0, "", true, "timeout"},
+		{"timeout only", Policy{OnTimeout: true}, 1, "", false, ""},
+		{"stderr matches", Policy{StderrPatterns: []string{`ECONN(RESET|REFUSED)`}}, 1, "fetch failed: ECONNRESET", false, `stderr matches "ECONN(RESET|REFUSED)"`},
+		{"stderr differs", Policy{StderrPatterns: []string{"overloaded"}}, 1, "bad request", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.NoError(t, tt.policy.Validate())
+			assert.Equal(t, tt.want, tt.policy.Match(tt.exitCode, tt.stderr, tt.timedOut))
+		})
+	}
+}
+
+func TestPolicy_Delay_DoublesUpToMax(t *testing.T) {
+	t.Parallel()
+
+	p := Policy{Backoff: 10 * time.Second, MaxBackoff: time.Minute}
+	assert.Equal(t, 10*time.Second, p.Delay(1))
+	assert.Equal(t, 20*time.Second, p.Delay(2))
+	assert.Equal(t, 40*time.Second, p.Delay(3))
+	assert.Equal(t, time.Minute, p.Delay(4))
+	assert.Equal(t, time.Minute, p.Delay(9))
+
+	assert.Equal(t, DefaultBackoff, (&Policy{}).Delay(1))
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	p := &Policy{MaxAttempts: 3, StderrPatterns: []string{"overload(ed)?"}}
+	require.NoError(t, p.Validate())
+	assert.Len(t, p.patterns, 1, "patterns are compiled once")
+	assert.ErrorContains(t, (&Policy{MaxAttempts: 11}).Validate(), "max_attempts")
+	assert.ErrorContains(t, (&Policy{MaxAttempts: 2, Backoff: -time.Second}).Validate(), "negative")
+	assert.ErrorContains(t, (&Policy{MaxAttempts: 2, StderrPatterns: []string{"("}}).Validate(), "invalid stderr pattern")
+}
@@ -0,0 +1,697 @@
+// Package scheduler starts tasks on cron schedules defined in herald.yaml
+// or through the schedule_task tool.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/cron"
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/store"
+	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
+)
+
+// Missed-run policies, applied on startup to a run that fell while Herald
+// was stopped.
+const (
+	MissedSkip    = "skip"     // skip it and wait for the next run (default)
+	MissedRunOnce = "run_once" // run once right away, however many were missed
+)
+
+// Where a schedule was defined.
+const (
+	SourceConfig = "config" // herald.yaml, read-only through MCP
+	SourceMCP    = "mcp"    // schedule_task
+)
+
+// maxWait bounds how long the loop sleeps, so that clock jumps (e.g. a
+// suspended laptop) are noticed within a minute.
+const maxWait = time.Minute
+
+// Schedule is a recurring task.
+type Schedule struct {
+	Name      string
+	Source    string
+	Cron      string
+	Timezone  string // IANA name, "" for local time
+	Project   string
+	Prompt    string
+	Template  string
+	Variables map[string]string
+
+	Priority       string
+	Model          string
+	TimeoutMinutes int
+	GitBranch      string
+	DryRun         bool
+	MissedRun      string
+	Jitter         time.Duration
+
+	LastRunAt  time.Time
+	LastTaskID string
+	NextRunAt  time.Time // next activation, before jitter
+	CreatedAt  time.Time
+}
+
+// Event reports a schedule firing, or failing to.
+type Event struct {
+	Type     string // "schedule.fired", "schedule.missed", "schedule.failed"
+	Schedule string
+	TaskID   string
+	Project  string
+	Message  string
+}
+
+// Store persists schedules. Defined at the consumer side per Go convention.
+type Store interface {
+	SaveSchedule(s *store.ScheduleRecord) error
+	ListSchedules() ([]store.ScheduleRecord, error)
+	DeleteSchedule(name string) error
+}
+
+// entry is a loaded schedule with its parsed expression.
+type entry struct {
+	schedule Schedule
+	expr     *cron.Expr
+	loc      *time.Location
+	fireAt   time.Time // NextRunAt plus jitter
+}
+
+// Scheduler fires schedules by creating normal tasks through the task
+// manager, like start_task does.
+type Scheduler struct {
+	tasks     *task.Manager
+	projects  *project.Manager
+	templates *template.Registry
+	exec      config.ExecutionConfig
+	store     Store
+	onNotify  func(Event)
+	now       func() time.Time
+	jitter    func(max time.Duration) time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	wake    chan struct{}
+}
+
+// New creates a Scheduler. templates may be nil when no task templates are
+// configured.
+func New(tm *task.Manager, pm *project.Manager, templates *template.Registry, exec config.ExecutionConfig) *Scheduler {
+	return &Scheduler{
+		tasks:     tm,
+		projects:  pm,
+		templates: templates,
+		exec:      exec,
+		now:       time.Now,
+		jitter:    randomJitter,
+		entries:   make(map[string]*entry),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetStore enables persistence of schedules and of their last run.
+func (s *Scheduler) SetStore(st Store) {
+	s.store = st
+}
+
+// SetNotifyFunc sets the callback for schedule events.
+func (s *Scheduler) SetNotifyFunc(fn func(Event)) {
+	s.onNotify = fn
+}
+
+// Load reads the persisted schedules and merges those of herald.yaml into
+// them: configured schedules replace stored ones of the same name, keeping
+// their last run, and stored schedules removed from the configuration are
+// deleted. Runs missed while Herald was stopped are then skipped or run
+// once according to each schedule's missed-run policy.
+func (s *Scheduler) Load(configured map[string]config.Schedule) error {
+	stored := make(map[string]Schedule)
+	if s.store != nil {
+		records, err := s.store.ListSchedules()
+		if err != nil {
+			return fmt.Errorf("loading schedules: %w", err)
+		}
+		for _, r := range records {
+			stored[r.Name] = fromRecord(r)
+		}
+	}
+
+	for name, sc := range stored {
+		if _, ok := configured[name]; ok || sc.Source != SourceConfig {
+			continue
+		}
+		delete(stored, name)
+		if err := s.deleteRecord(name); err != nil {
+			return err
+		}
+		slog.Info("schedule removed from configuration", "schedule", name)
+	}
+	for name, c := range configured {
+		sc := fromConfig(name, c)
+		if prev, ok := stored[name]; ok {
+			sc.LastRunAt, sc.LastTaskID, sc.CreatedAt = prev.LastRunAt, prev.LastTaskID, prev.CreatedAt
+		}
+		stored[name] = sc
+	}
+
+	now := s.now()
+	for name, sc := range stored {
+		if sc.CreatedAt.IsZero() {
+			sc.CreatedAt = now
+		}
+		if sc.Source == SourceConfig {
+			if err := s.validate(&sc); err != nil {
+				return fmt.Errorf("schedule %s: %w", name, err)
+			}
+		}
+		e, err := s.newEntry(sc)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", name, err)
+		}
+		if err := s.saveRecord(e.schedule); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.entries[name] = e
+		s.mu.Unlock()
+		s.catchUp(e, now)
+	}
+
+	slog.Info("schedules loaded", "count", len(stored))
+	return nil
+}
+
+// catchUp applies the missed-run policy when the run following the last
+// one (or the schedule's creation) is already past.
+func (s *Scheduler) catchUp(e *entry, now time.Time) {
+	s.mu.Lock()
+	sc := e.schedule
+	since := sc.LastRunAt
+	if since.IsZero() {
+		since = sc.CreatedAt
+	}
+	missed := e.expr.Next(since.In(e.loc))
+	if missed.IsZero() || !missed.Before(now) {
+		s.mu.Unlock()
+		return
+	}
+	if sc.MissedRun == MissedRunOnce {
+		e.schedule.NextRunAt = missed
+		e.fireAt = now.Add(s.jitter(sc.Jitter))
+	}
+	s.mu.Unlock()
+
+	if sc.MissedRun == MissedRunOnce {
+		slog.Info("schedule missed a run, running it once", "schedule", sc.Name, "missed", missed)
+		return
+	}
+	slog.Info("schedule missed a run, skipping it", "schedule", sc.Name, "missed", missed)
+	s.emit(Event{
+		Type:     "schedule.missed",
+		Schedule: sc.Name,
+		Project:  sc.Project,
+		Message:  fmt.Sprintf("schedule %s missed its run at %s while Herald was stopped; next run at %s", sc.Name, formatTime(missed), formatTime(e.schedule.NextRunAt)),
+	})
+}
+
+// Add creates or replaces a schedule defined through MCP. The project,
+// template and cron expression are checked before it is saved.
+func (s *Scheduler) Add(sc Schedule) (Schedule, error) {
+	if sc.Name == "" || strings.ContainsAny(sc.Name, " \t\n/") {
+		return Schedule{}, fmt.Errorf("invalid schedule name %q: must be non-empty, without spaces or slashes", sc.Name)
+	}
+
+	s.mu.Lock()
+	var prev Schedule
+	e, exists := s.entries[sc.Name]
+	if exists {
+		prev = e.schedule
+	}
+	s.mu.Unlock()
+	if exists && prev.Source == SourceConfig {
+		return Schedule{}, fmt.Errorf("schedule %q is defined in herald.yaml; change it there", sc.Name)
+	}
+
+	sc.Source = SourceMCP
+	if err := s.validate(&sc); err != nil {
+		return Schedule{}, err
+	}
+	sc.CreatedAt = s.now()
+	if exists {
+		sc.CreatedAt = prev.CreatedAt
+		sc.LastRunAt, sc.LastTaskID = prev.LastRunAt, prev.LastTaskID
+	}
+
+	e, err := s.newEntry(sc)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if err = s.saveRecord(e.schedule); err != nil {
+		return Schedule{}, err
+	}
+
+	s.mu.Lock()
+	s.entries[sc.Name] = e
+	s.mu.Unlock()
+	s.notifyLoop()
+
+	slog.Info("schedule saved", "schedule", sc.Name, "cron", sc.Cron, "next_run", e.schedule.NextRunAt)
+	return e.schedule, nil
+}
+
+// Delete removes a schedule defined through MCP.
+func (s *Scheduler) Delete(name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if ok && e.schedule.Source == SourceConfig {
+		s.mu.Unlock()
+		return fmt.Errorf("schedule %q is defined in herald.yaml; remove it there", name)
+	}
+	delete(s.entries, name)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("schedule %q not found", name)
+	}
+	if err := s.deleteRecord(name); err != nil {
+		return err
+	}
+	s.notifyLoop()
+	slog.Info("schedule deleted", "schedule", name)
+	return nil
+}
+
+// List returns the schedules sorted by name, optionally only those of a
+// project.
+func (s *Scheduler) List(projectName string) []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Schedule
+	for _, e := range s.entries {
+		if projectName != "" && e.schedule.Project != projectName {
+			continue
+		}
+		result = append(result, e.schedule)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Run fires schedules as they fall due until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+			s.fireDue(ctx)
+		}
+	}
+}
+
+// untilNext returns how long to sleep before the earliest run, at most
+// maxWait.
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := maxWait
+	now := s.now()
+	for _, e := range s.entries {
+		if e.fireAt.IsZero() {
+			continue
+		}
+		if d := e.fireAt.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// fireDue starts a task for every schedule whose run is due.
+func (s *Scheduler) fireDue(ctx context.Context) {
+	now := s.now()
+
+	s.mu.Lock()
+	var due []Schedule
+	for _, e := range s.entries {
+		if e.fireAt.IsZero() || e.fireAt.After(now) {
+			continue
+		}
+		due = append(due, e.schedule)
+		s.advanceLocked(e, now)
+	}
+	s.mu.Unlock()
+
+	for _, sc := range due {
+		s.fire(ctx, sc)
+	}
+}
+
+// advanceLocked moves e to its run following the current one, skipping
+// the runs already past. Caller must hold s.mu.
+func (s *Scheduler) advanceLocked(e *entry, now time.Time) {
+	next := e.expr.Next(e.schedule.NextRunAt.In(e.loc))
+	if !next.IsZero() && next.Before(now) {
+		next = e.expr.Next(now.In(e.loc))
+	}
+	e.schedule.LastRunAt = e.schedule.NextRunAt
+	e.schedule.NextRunAt = next
+	e.fireAt = time.Time{}
+	if !next.IsZero() {
+		e.fireAt = next.Add(s.jitter(e.schedule.Jitter))
+	}
+}
+
+// fire creates and starts the task of a schedule run, then records it.
+func (s *Scheduler) fire(ctx context.Context, sc Schedule) {
+	t, err := s.startTask(ctx, sc)
+	if err != nil {
+		slog.Error("scheduled task not started", "schedule", sc.Name, "error", err)
+		s.record(sc.Name, "")
+		s.emit(Event{
+			Type:     "schedule.failed",
+			Schedule: sc.Name,
+			Project:  sc.Project,
+			Message:  fmt.Sprintf("schedule %s could not start its task: %s", sc.Name, err),
+		})
+		return
+	}
+
+	s.record(sc.Name, t.ID)
+	slog.Info("scheduled task started", "schedule", sc.Name, "task_id", t.ID)
+	s.emit(Event{
+		Type:     "schedule.fired",
+		Schedule: sc.Name,
+		TaskID:   t.ID,
+		Project:  t.Project,
+		Message:  fmt.Sprintf("schedule %s started task %s", sc.Name, t.ID),
+	})
+}
+
+// startTask creates the task of a schedule run the way start_task does:
+// the schedule's settings win over the template's, which win over the
+// configured defaults.
+func (s *Scheduler) startTask(ctx context.Context, sc Schedule) (*task.Task, error) {
+	proj, err := s.projects.Resolve(sc.Project)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, prompt, err := s.prompt(sc, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := task.PriorityNormal
+	if tmpl.Priority != "" {
+		priority = task.Priority(tmpl.Priority)
+	}
+	if sc.Priority != "" {
+		priority = task.Priority(sc.Priority)
+	}
+
+	timeoutMinutes := int(s.exec.DefaultTimeout.Minutes())
+	if tmpl.Timeout > 0 {
+		timeoutMinutes = int(tmpl.Timeout.Minutes())
+	}
+	if sc.TimeoutMinutes > 0 {
+		timeoutMinutes = sc.TimeoutMinutes
+	}
+	if maxMinutes := int(s.exec.MaxTimeout.Minutes()); maxMinutes > 0 && timeoutMinutes > maxMinutes {
+		timeoutMinutes = maxMinutes
+	}
+	if timeoutMinutes <= 0 {
+		timeoutMinutes = 30
+	}
+
+	model := s.exec.Model
+	if tmpl.Model != "" {
+		model = tmpl.Model
+	}
+	if sc.Model != "" {
+		model = sc.Model
+	}
+	dryRun := tmpl.DryRun || sc.DryRun
+	allowedTools := tmpl.ToolsFor(proj.AllowedTools)
+
+	// As with start_task, a spent budget skips the run unless tasks over
+	// budget are queued until it resets.
+	if err := s.tasks.CheckBudget(proj.Name); err != nil && !s.tasks.QueuesOverBudget() {
+		return nil, err
+	}
+
+	t := s.tasks.Create(proj.Name, prompt, fmt.Sprintf("Scheduled run of %s (%s)", sc.Name, sc.Cron), priority, timeoutMinutes)
+	t.GitBranch = sc.GitBranch
+	t.DryRun = dryRun
+	t.Model = model
+	t.AllowedTools = allowedTools
+	t.Retry = proj.Retry
+
+	err = s.tasks.Start(ctx, t, executor.Request{
+		TaskID:         t.ID,
+		Prompt:         prompt,
+		ProjectPath:    proj.Path,
+		Model:          model,
+		AllowedTools:   allowedTools,
+		TimeoutMinutes: timeoutMinutes,
+		DryRun:         dryRun,
+	}, proj.MaxConcurrentTasks)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// validate checks the priority of a schedule, resolves its project and
+// checks that its prompt renders.
+func (s *Scheduler) validate(sc *Schedule) error {
+	switch task.Priority(sc.Priority) {
+	case "", task.PriorityLow, task.PriorityNormal, task.PriorityHigh, task.PriorityUrgent:
+	default:
+		return fmt.Errorf("priority must be \"low\", \"normal\", \"high\" or \"urgent\", got %q", sc.Priority)
+	}
+	proj, err := s.projects.Resolve(sc.Project)
+	if err != nil {
+		return err
+	}
+	sc.Project = proj.Name
+	_, _, err = s.prompt(*sc, proj)
+	return err
+}
+
+// prompt renders the prompt of a schedule run.
+func (s *Scheduler) prompt(sc Schedule, proj *project.Project) (*template.Template, string, error) {
+	if sc.Template == "" {
+		if strings.TrimSpace(sc.Prompt) == "" {
+			return nil, "", fmt.Errorf("prompt or template is required")
+		}
+		return &template.Template{}, sc.Prompt, nil
+	}
+	if s.templates == nil {
+		return nil, "", fmt.Errorf("template %q not found (no templates configured)", sc.Template)
+	}
+	tmpl, err := s.templates.Get(proj.Name, sc.Template)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vars := make(map[string]string, len(sc.Variables)+2)
+	for k, v := range sc.Variables {
+		vars[k] = v
+	}
+	vars[template.VarPrompt] = sc.Prompt
+	vars[template.VarProject] = proj.Name
+	prompt, err := tmpl.Render(vars)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return nil, "", fmt.Errorf("template %q renders an empty prompt", sc.Template)
+	}
+	return tmpl, prompt, nil
+}
+
+// record stores the last run of a schedule.
+func (s *Scheduler) record(name, taskID string) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+		return // deleted meanwhile
+	}
+	e.schedule.LastTaskID = taskID
+	sc := e.schedule
+	s.mu.Unlock()
+
+	if err := s.saveRecord(sc); err != nil {
+		slog.Warn("failed to persist schedule run", "schedule", name, "error", err)
+	}
+}
+
+// newEntry parses a schedule and computes its next run.
+func (s *Scheduler) newEntry(sc Schedule) (*entry, error) {
+	expr, err := cron.Parse(sc.Cron)
+	if err != nil {
+		return nil, err
+	}
+	loc := time.Local
+	if sc.Timezone != "" {
+		if loc, err = time.LoadLocation(sc.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", sc.Timezone, err)
+		}
+	}
+	switch sc.MissedRun {
+	case "":
+		sc.MissedRun = MissedSkip
+	case MissedSkip, MissedRunOnce:
+	default:
+		return nil, fmt.Errorf("missed_run must be %q or %q, got %q", MissedSkip, MissedRunOnce, sc.MissedRun)
+	}
+	if sc.Jitter < 0 {
+		return nil, fmt.Errorf("jitter must not be negative")
+	}
+
+	sc.NextRunAt = expr.Next(s.now().In(loc))
+	if sc.NextRunAt.IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", sc.Cron)
+	}
+	return &entry{
+		schedule: sc,
+		expr:     expr,
+		loc:      loc,
+		fireAt:   sc.NextRunAt.Add(s.jitter(sc.Jitter)),
+	}, nil
+}
+
+func (s *Scheduler) saveRecord(sc Schedule) error {
+	if s.store == nil {
+		return nil
+	}
+	r := toRecord(sc)
+	if err := s.store.SaveSchedule(&r); err != nil {
+		return fmt.Errorf("saving schedule %s: %w", sc.Name, err)
+	}
+	return nil
+}
+
+func (s *Scheduler) deleteRecord(name string) error {
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.DeleteSchedule(name); err != nil {
+		return fmt.Errorf("deleting schedule %s: %w", name, err)
+	}
+	return nil
+}
+
+// notifyLoop makes Run recompute its next wake-up.
+func (s *Scheduler) notifyLoop() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) emit(e Event) {
+	if s.onNotify != nil {
+		s.onNotify(e)
+	}
+}
+
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+func fromConfig(name string, c config.Schedule) Schedule {
+	return Schedule{
+		Name:           name,
+		Source:         SourceConfig,
+		Cron:           c.Cron,
+		Timezone:       c.Timezone,
+		Project:        c.Project,
+		Prompt:         c.Prompt,
+		Template:       c.Template,
+		Variables:      c.Variables,
+		Priority:       c.Priority,
+		Model:          c.Model,
+		TimeoutMinutes: int(c.Timeout.Minutes()),
+		GitBranch:      c.GitBranch,
+		DryRun:         c.DryRun,
+		MissedRun:      c.MissedRun,
+		Jitter:         c.Jitter,
+	}
+}
+
+func toRecord(sc Schedule) store.ScheduleRecord {
+	return store.ScheduleRecord{
+		Name:           sc.Name,
+		Source:         sc.Source,
+		Cron:           sc.Cron,
+		Timezone:       sc.Timezone,
+		Project:        sc.Project,
+		Prompt:         sc.Prompt,
+		Template:       sc.Template,
+		Variables:      sc.Variables,
+		Priority:       sc.Priority,
+		Model:          sc.Model,
+		TimeoutMinutes: sc.TimeoutMinutes,
+		GitBranch:      sc.GitBranch,
+		DryRun:         sc.DryRun,
+		MissedRun:      sc.MissedRun,
+		JitterSeconds:  int(sc.Jitter.Seconds()),
+		LastRunAt:      sc.LastRunAt,
+		LastTaskID:     sc.LastTaskID,
+		CreatedAt:      sc.CreatedAt,
+	}
+}
+
+func fromRecord(r store.ScheduleRecord) Schedule {
+	return Schedule{
+		Name:           r.Name,
+		Source:         r.Source,
+		Cron:           r.Cron,
+		Timezone:       r.Timezone,
+		Project:        r.Project,
+		Prompt:         r.Prompt,
+		Template:       r.Template,
+		Variables:      r.Variables,
+		Priority:       r.Priority,
+		Model:          r.Model,
+		TimeoutMinutes: r.TimeoutMinutes,
+		GitBranch:      r.GitBranch,
+		DryRun:         r.DryRun,
+		MissedRun:      r.MissedRun,
+		Jitter:         time.Duration(r.JitterSeconds) * time.Second,
+		LastRunAt:      r.LastRunAt,
+		LastTaskID:     r.LastTaskID,
+		CreatedAt:      r.CreatedAt,
+	}
+}
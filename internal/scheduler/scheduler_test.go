@@ -0,0 +1,313 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/store"
+	"github.com/btouchard/herald/internal/task"
+	"github.com/btouchard/herald/internal/template"
+)
+
+type mockExecutor struct {
+	cost float64
+}
+
+func (m *mockExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "mock"}
+}
+
+func (m *mockExecutor) Execute(_ context.Context, _ executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	return &executor.Result{Output: "done", CostUSD: m.cost}, nil
+}
+
+// fakeClock is a settable clock for the scheduler.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+type testScheduler struct {
+	*Scheduler
+	tasks  *task.Manager
+	exec   *mockExecutor
+	clock  *fakeClock
+	db     *store.SQLiteStore
+	events chan Event
+}
+
+func newTestScheduler(t *testing.T, db *store.SQLiteStore, now time.Time) *testScheduler {
+	t.Helper()
+	if db == nil {
+		db = newTestStore(t)
+	}
+
+	pm := project.NewManager(map[string]config.Project{
+		"app": {Path: "/tmp", Default: true},
+	})
+	templates := template.NewRegistry(map[string]config.Template{
+		"test": {Prompt: "Run the tests in {{scope}}. {{prompt}}"},
+	}, nil)
+	exec := &mockExecutor{}
+	tm := task.NewManager(exec, 3, 2*time.Hour)
+
+	clock := &fakeClock{now: now}
+	s := New(tm, pm, templates, config.ExecutionConfig{
+		Model:          "claude-sonnet-4-5-20250929",
+		DefaultTimeout: 30 * time.Minute,
+		MaxTimeout:     2 * time.Hour,
+	})
+	s.now = clock.Now
+	s.jitter = func(time.Duration) time.Duration { return 0 }
+	s.SetStore(db)
+
+	events := make(chan Event, 16)
+	s.SetNotifyFunc(func(e Event) { events <- e })
+
+	return &testScheduler{Scheduler: s, tasks: tm, exec: exec, clock: clock, db: db, events: events}
+}
+
+func newTestStore(t *testing.T) *store.SQLiteStore {
+	t.Helper()
+	db, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "herald.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestScheduler_Add_ComputesNextRunAndPersists(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	s := newTestScheduler(t, nil, now)
+
+	saved, err := s.Add(Schedule{Name: "nightly", Cron: "0 2 * * *", Timezone: "UTC", Prompt: "fix flaky tests"})
+	require.NoError(t, err)
+	assert.Equal(t, "app", saved.Project)
+	assert.Equal(t, SourceMCP, saved.Source)
+	assert.Equal(t, MissedSkip, saved.MissedRun)
+	assert.Equal(t, time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), saved.NextRunAt)
+
+	records, err := s.db.ListSchedules()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "nightly", records[0].Name)
+	assert.Equal(t, "fix flaky tests", records[0].Prompt)
+}
+
+func TestScheduler_Add_WhenInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	s := newTestScheduler(t, nil, time.Now())
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  string
+	}{
+		{"bad name", Schedule{Name: "nightly tests", Cron: "@daily", Prompt: "x"}, "invalid schedule name"},
+		{"bad cron", Schedule{Name: "n", Cron: "every night", Prompt: "x"}, "must have 5 fields"},
+		{"unknown project", Schedule{Name: "n", Cron: "@daily", Prompt: "x", Project: "nope"}, "not found"},
+		{"unknown template", Schedule{Name: "n", Cron: "@daily", Template: "nope"}, "nope"},
+		{"missing variable", Schedule{Name: "n", Cron: "@daily", Template: "test"}, "scope"},
+		{"bad timezone", Schedule{Name: "n", Cron: "@daily", Prompt: "x", Timezone: "Mars/Olympus"}, "invalid timezone"},
+		{"bad missed run", Schedule{Name: "n", Cron: "@daily", Prompt: "x", MissedRun: "all"}, "missed_run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Add(tt.schedule)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+	assert.Empty(t, s.List(""))
+}
+
+func TestScheduler_FireDue_StartsTaskAndRecordsRun(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 16, 1, 59, 0, 0, time.UTC)
+	s := newTestScheduler(t, nil, now)
+
+	_, err := s.Add(Schedule{
+		Name: "nightly", Cron: "0 2 * * *", Timezone: "UTC",
+		Template: "test", Variables: map[string]string{"scope": "internal/auth"},
+		Priority: "low", GitBranch: "herald/nightly",
+	})
+	require.NoError(t, err)
+
+	s.fireDue(context.Background()) // not due yet
+	assert.Empty(t, s.tasks.List(task.Filter{}))
+
+	s.clock.Set(now.Add(time.Minute))
+	s.fireDue(context.Background())
+
+	e := <-s.events
+	assert.Equal(t, "schedule.fired", e.Type)
+	assert.Equal(t, "nightly", e.Schedule)
+
+	tk, err := s.tasks.Get(e.TaskID)
+	require.NoError(t, err)
+	snap := tk.Snapshot()
+	assert.Equal(t, "Run the tests in internal/auth.", snap.Prompt)
+	assert.Equal(t, "Scheduled run of nightly (0 2 * * *)", snap.Context)
+	assert.Equal(t, task.PriorityLow, snap.Priority)
+	assert.Equal(t, "herald/nightly", snap.GitBranch)
+
+	list := s.List("")
+	require.Len(t, list, 1)
+	assert.Equal(t, time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), list[0].LastRunAt)
+	assert.Equal(t, e.TaskID, list[0].LastTaskID)
+	assert.Equal(t, time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), list[0].NextRunAt)
+}
+
+func TestScheduler_FireDue_WhenBudgetSpent_SkipsRun(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 16, 1, 59, 0, 0, time.UTC)
+	s := newTestScheduler(t, nil, now)
+
+	s.exec.cost = 2
+	spent := s.tasks.Create("app", "earlier work", "", task.PriorityNormal, 30)
+	require.NoError(t, s.tasks.Start(context.Background(), spent, executor.Request{TaskID: spent.ID}, 0))
+	<-spent.Done()
+	s.tasks.SetBudgets(task.Budget{DailyUSD: 1}, nil)
+
+	_, err := s.Add(Schedule{Name: "nightly", Cron: "0 2 * * *", Timezone: "UTC", Prompt: "fix flaky tests"})
+	require.NoError(t, err)
+	s.clock.Set(now.Add(time.Minute))
+	s.fireDue(context.Background())
+
+	e := <-s.events
+	assert.Equal(t, "schedule.failed", e.Type)
+	assert.Contains(t, e.Message, "budget exhausted: global daily budget")
+	assert.Len(t, s.tasks.List(task.Filter{}), 1)
+}
+
+func TestScheduler_Load_WhenRunMissed_AppliesPolicy(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	lastRun := time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+	for _, r := range []store.ScheduleRecord{
+		{Name: "catch-up", Source: SourceMCP, Cron: "0 2 * * *", Timezone: "UTC", Project: "app", Prompt: "x", MissedRun: MissedRunOnce, LastRunAt: lastRun, CreatedAt: lastRun},
+		{Name: "skipper", Source: SourceMCP, Cron: "0 2 * * *", Timezone: "UTC", Project: "app", Prompt: "x", MissedRun: MissedSkip, LastRunAt: lastRun, CreatedAt: lastRun},
+	} {
+		require.NoError(t, db.SaveSchedule(&r))
+	}
+
+	// Herald comes back two days later.
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	s := newTestScheduler(t, db, now)
+	require.NoError(t, s.Load(nil))
+
+	e := <-s.events
+	assert.Equal(t, "schedule.missed", e.Type)
+	assert.Equal(t, "skipper", e.Schedule)
+
+	s.fireDue(context.Background())
+	e = <-s.events
+	assert.Equal(t, "schedule.fired", e.Type)
+	assert.Equal(t, "catch-up", e.Schedule)
+	assert.Len(t, s.tasks.List(task.Filter{}), 1, "missed runs are run once, not replayed")
+
+	for _, sc := range s.List("") {
+		assert.Equal(t, time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC), sc.NextRunAt, sc.Name)
+	}
+}
+
+func TestScheduler_Load_SyncsConfiguredSchedules(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	lastRun := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	for _, r := range []store.ScheduleRecord{
+		{Name: "nightly", Source: SourceConfig, Cron: "0 2 * * *", Project: "app", Prompt: "old", LastRunAt: lastRun, LastTaskID: "herald-last0001", CreatedAt: lastRun},
+		{Name: "removed", Source: SourceConfig, Cron: "@daily", Project: "app", Prompt: "x", CreatedAt: lastRun},
+		{Name: "adhoc", Source: SourceMCP, Cron: "@weekly", Project: "app", Prompt: "x", CreatedAt: lastRun},
+	} {
+		require.NoError(t, db.SaveSchedule(&r))
+	}
+
+	s := newTestScheduler(t, db, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, s.Load(map[string]config.Schedule{
+		"nightly": {Cron: "0 3 * * *", Prompt: "new"},
+	}))
+
+	list := s.List("")
+	require.Len(t, list, 2)
+	assert.Equal(t, "adhoc", list[0].Name)
+	assert.Equal(t, "nightly", list[1].Name)
+	assert.Equal(t, "new", list[1].Prompt)
+	assert.Equal(t, "0 3 * * *", list[1].Cron)
+	assert.Equal(t, "herald-last0001", list[1].LastTaskID)
+
+	records, err := db.ListSchedules()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	_, err = s.Add(Schedule{Name: "nightly", Cron: "@daily", Prompt: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "herald.yaml")
+	require.Error(t, s.Delete("nightly"))
+	require.NoError(t, s.Delete("adhoc"))
+}
+
+func TestScheduler_Load_WhenConfiguredScheduleInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+	s := newTestScheduler(t, nil, time.Now())
+
+	err := s.Load(map[string]config.Schedule{
+		"nightly": {Cron: "@daily", Project: "nope", Prompt: "x"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schedule nightly")
+}
+
+func TestScheduler_Run_FiresAndStopsWithContext(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 10, 16, 1, 59, 59, 0, time.UTC)
+	s := newTestScheduler(t, nil, now)
+
+	_, err := s.Add(Schedule{Name: "nightly", Cron: "0 2 * * *", Timezone: "UTC", Prompt: "x"})
+	require.NoError(t, err)
+	s.clock.Set(now.Add(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case e := <-s.events:
+		assert.Equal(t, "schedule.fired", e.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("schedule did not fire")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+}
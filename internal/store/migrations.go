@@ -74,4 +74,106 @@ var migrations = []string{
 
 	// Migration 3: Add context column for human-readable task intent
 	`ALTER TABLE tasks ADD COLUMN context TEXT NOT NULL DEFAULT '';`,
+
+	// Migration 4: Columns needed to rehydrate tasks after a restart
+	`ALTER TABLE tasks ADD COLUMN model TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN files_modified TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN lines_added INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN lines_removed INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN interrupted INTEGER NOT NULL DEFAULT 0;`,
+
+	// Migration 5: Per-task git worktree
+	`ALTER TABLE tasks ADD COLUMN worktree_path TEXT NOT NULL DEFAULT '';`,
+
+	// Migration 6: Git base commit and non-fatal warnings
+	`ALTER TABLE tasks ADD COLUMN base_commit TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN warnings TEXT NOT NULL DEFAULT '';`,
+
+	// Migration 7: Checkout state needed to restore or revert a task's git changes
+	`ALTER TABLE tasks ADD COLUMN original_branch TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN branch_created INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN stashed INTEGER NOT NULL DEFAULT 0;`,
+
+	// Migration 8: Task dependencies
+	`ALTER TABLE tasks ADD COLUMN depends_on TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN on_dependency_failure TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN inherit_branch INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE tasks ADD COLUMN inherit_session INTEGER NOT NULL DEFAULT 0;`,
+
+	// Migration 9: Scheduled tasks
+	`CREATE TABLE IF NOT EXISTS schedules (
+		name TEXT PRIMARY KEY,
+		source TEXT NOT NULL DEFAULT 'mcp',
+		cron TEXT NOT NULL,
+		timezone TEXT NOT NULL DEFAULT '',
+		project TEXT NOT NULL DEFAULT '',
+		prompt TEXT NOT NULL DEFAULT '',
+		template TEXT NOT NULL DEFAULT '',
+		variables TEXT NOT NULL DEFAULT '',
+		priority TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		timeout_minutes INTEGER NOT NULL DEFAULT 0,
+		git_branch TEXT NOT NULL DEFAULT '',
+		dry_run INTEGER NOT NULL DEFAULT 0,
+		missed_run TEXT NOT NULL DEFAULT '',
+		jitter_seconds INTEGER NOT NULL DEFAULT 0,
+		last_run_at TEXT NOT NULL DEFAULT '',
+		last_task_id TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);`,
+
+	// Migration 10: Retry policies and the attempts of retried tasks
+	`ALTER TABLE tasks ADD COLUMN retry_policy TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS task_attempts (
+		task_id TEXT NOT NULL REFERENCES tasks(id),
+		attempt INTEGER NOT NULL,
+		outcome TEXT NOT NULL,
+		exit_code INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		retry_on TEXT NOT NULL DEFAULT '',
+		session_id TEXT NOT NULL DEFAULT '',
+		cost_usd REAL NOT NULL DEFAULT 0,
+		turns INTEGER NOT NULL DEFAULT 0,
+		started_at TEXT NOT NULL,
+		ended_at TEXT NOT NULL,
+		PRIMARY KEY (task_id, attempt)
+	);`,
+
+	// Migration 11: Per-task cost budgets
+	`ALTER TABLE tasks ADD COLUMN max_budget_usd REAL NOT NULL DEFAULT 0;`,
+
+	// Migration 12: Approval gate
+	`ALTER TABLE tasks ADD COLUMN approval_reason TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN approved_by TEXT NOT NULL DEFAULT '';`,
+
+	// Migration 13: Pause and resume
+	`ALTER TABLE tasks ADD COLUMN paused_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE tasks ADD COLUMN paused_ms INTEGER NOT NULL DEFAULT 0;`,
+
+	// Migration 14: Priority preemption
+	`ALTER TABLE tasks ADD COLUMN preemptions TEXT NOT NULL DEFAULT '';`,
+
+	// Migration 15: Leveled task events
+	`ALTER TABLE task_events ADD COLUMN level TEXT NOT NULL DEFAULT 'info';
+
+	CREATE INDEX IF NOT EXISTS idx_task_events_created_at ON task_events(created_at);`,
+
+	// Migration 16: Execution transcripts
+	`CREATE TABLE IF NOT EXISTS task_transcripts (
+		task_id TEXT NOT NULL REFERENCES tasks(id),
+		seq INTEGER NOT NULL,
+		attempt INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		tool TEXT NOT NULL DEFAULT '',
+		tool_use_id TEXT NOT NULL DEFAULT '',
+		text TEXT NOT NULL DEFAULT '',
+		input TEXT NOT NULL DEFAULT '',
+		is_error INTEGER NOT NULL DEFAULT 0,
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (task_id, seq)
+	);`,
 }
@@ -2,10 +2,13 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -13,6 +16,14 @@ import (
 
 const timeFormat = time.RFC3339
 
+// taskColumns is the column list shared by every task SELECT, in scan order.
+const taskColumns = `id, type, project, prompt, context, status, priority, model, session_id, pid,
+		git_branch, worktree_path, base_commit, original_branch, branch_created, stashed,
+		output, progress, error, warnings, cost_usd, turns, files_modified, lines_added,
+		lines_removed, timeout_minutes, dry_run, interrupted,
+		depends_on, on_dependency_failure, inherit_branch, inherit_session, retry_policy,
+		max_budget_usd, approval_reason, approved_by, paused_at, paused_ms, preemptions, created_at, started_at, completed_at`
+
 // SQLiteStore implements Store using modernc.org/sqlite (pure Go, zero CGO).
 type SQLiteStore struct {
 	db *sql.DB
@@ -95,14 +106,15 @@ func (s *SQLiteStore) Close() error {
 // --- Tasks ---
 
 func (s *SQLiteStore) CreateTask(t *TaskRecord) error {
-	_, err := s.db.Exec(`INSERT INTO tasks (id, type, project, prompt, context, status, priority, session_id, pid,
-		git_branch, output, progress, error, cost_usd, turns, timeout_minutes, dry_run,
-		created_at, started_at, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		t.ID, t.Type, t.Project, t.Prompt, t.Context, t.Status, t.Priority, t.SessionID, t.PID,
-		t.GitBranch, t.Output, t.Progress, t.Error, t.CostUSD, t.Turns,
-		t.TimeoutMinutes, boolToInt(t.DryRun),
-		formatTime(t.CreatedAt), formatTime(t.StartedAt), formatTime(t.CompletedAt))
+	_, err := s.db.Exec(`INSERT INTO tasks (`+taskColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Type, t.Project, t.Prompt, t.Context, t.Status, t.Priority, t.Model, t.SessionID, t.PID,
+		t.GitBranch, t.WorktreePath, t.BaseCommit, t.OriginalBranch, boolToInt(t.BranchCreated), boolToInt(t.Stashed),
+		t.Output, t.Progress, t.Error, encodeStrings(t.Warnings), t.CostUSD,
+		t.Turns, encodeStrings(t.FilesModified), t.LinesAdded, t.LinesRemoved,
+		t.TimeoutMinutes, boolToInt(t.DryRun), boolToInt(t.Interrupted),
+		encodeStrings(t.DependsOn), t.OnDependencyFailure, boolToInt(t.InheritBranch), boolToInt(t.InheritSession), t.RetryPolicy,
+		t.MaxBudgetUSD, t.ApprovalReason, t.ApprovedBy, formatTime(t.PausedAt), t.PausedFor.Milliseconds(), t.Preemptions, formatTime(t.CreatedAt), formatTime(t.StartedAt), formatTime(t.CompletedAt))
 	if err != nil {
 		return fmt.Errorf("inserting task: %w", err)
 	}
@@ -110,24 +122,26 @@ func (s *SQLiteStore) CreateTask(t *TaskRecord) error {
 }
 
 func (s *SQLiteStore) GetTask(id string) (*TaskRecord, error) {
-	row := s.db.QueryRow(`SELECT id, type, project, prompt, context, status, priority, session_id, pid,
-		git_branch, output, progress, error, cost_usd, turns, timeout_minutes, dry_run,
-		created_at, started_at, completed_at
-		FROM tasks WHERE id = ?`, id)
+	row := s.db.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id)
 	return scanTask(row)
 }
 
 func (s *SQLiteStore) UpdateTask(t *TaskRecord) error {
 	_, err := s.db.Exec(`UPDATE tasks SET
-		type = ?, status = ?, priority = ?, session_id = ?, pid = ?,
-		git_branch = ?, output = ?, progress = ?, error = ?,
-		cost_usd = ?, turns = ?, timeout_minutes = ?, dry_run = ?,
-		started_at = ?, completed_at = ?
+		type = ?, project = ?, status = ?, priority = ?, model = ?, session_id = ?, pid = ?,
+		git_branch = ?, worktree_path = ?, base_commit = ?, original_branch = ?, branch_created = ?, stashed = ?,
+		output = ?, progress = ?, error = ?, warnings = ?, cost_usd = ?, turns = ?, files_modified = ?, lines_added = ?, lines_removed = ?,
+		timeout_minutes = ?, dry_run = ?, interrupted = ?,
+		depends_on = ?, on_dependency_failure = ?, inherit_branch = ?, inherit_session = ?, retry_policy = ?,
+		max_budget_usd = ?, approval_reason = ?, approved_by = ?, paused_at = ?, paused_ms = ?, preemptions = ?, started_at = ?, completed_at = ?
 		WHERE id = ?`,
-		t.Type, t.Status, t.Priority, t.SessionID, t.PID,
-		t.GitBranch, t.Output, t.Progress, t.Error,
-		t.CostUSD, t.Turns, t.TimeoutMinutes, boolToInt(t.DryRun),
-		formatTime(t.StartedAt), formatTime(t.CompletedAt),
+		t.Type, t.Project, t.Status, t.Priority, t.Model, t.SessionID, t.PID,
+		t.GitBranch, t.WorktreePath, t.BaseCommit, t.OriginalBranch, boolToInt(t.BranchCreated), boolToInt(t.Stashed),
+		t.Output, t.Progress, t.Error, encodeStrings(t.Warnings), t.CostUSD, t.Turns,
+		encodeStrings(t.FilesModified), t.LinesAdded, t.LinesRemoved,
+		t.TimeoutMinutes, boolToInt(t.DryRun), boolToInt(t.Interrupted),
+		encodeStrings(t.DependsOn), t.OnDependencyFailure, boolToInt(t.InheritBranch), boolToInt(t.InheritSession), t.RetryPolicy,
+		t.MaxBudgetUSD, t.ApprovalReason, t.ApprovedBy, formatTime(t.PausedAt), t.PausedFor.Milliseconds(), t.Preemptions, formatTime(t.StartedAt), formatTime(t.CompletedAt),
 		t.ID)
 	if err != nil {
 		return fmt.Errorf("updating task: %w", err)
@@ -136,7 +150,7 @@ func (s *SQLiteStore) UpdateTask(t *TaskRecord) error {
 }
 
 func (s *SQLiteStore) ListTasks(f TaskFilter) ([]TaskRecord, error) {
-	query := "SELECT id, type, project, prompt, context, status, priority, session_id, pid, git_branch, output, progress, error, cost_usd, turns, timeout_minutes, dry_run, created_at, started_at, completed_at FROM tasks WHERE 1=1"
+	query := "SELECT " + taskColumns + " FROM tasks WHERE 1=1"
 	var args []interface{}
 
 	if f.Status != "" && f.Status != "all" {
@@ -167,7 +181,7 @@ func (s *SQLiteStore) ListTasks(f TaskFilter) ([]TaskRecord, error) {
 
 	var tasks []TaskRecord
 	for rows.Next() {
-		t, err := scanTaskRows(rows)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -177,27 +191,147 @@ func (s *SQLiteStore) ListTasks(f TaskFilter) ([]TaskRecord, error) {
 }
 
 func (s *SQLiteStore) GetLinkedTaskBySessionID(sessionID string) (*TaskRecord, error) {
-	row := s.db.QueryRow(`SELECT id, type, project, prompt, context, status, priority, session_id, pid,
-		git_branch, output, progress, error, cost_usd, turns, timeout_minutes, dry_run,
-		created_at, started_at, completed_at
+	row := s.db.QueryRow(`SELECT `+taskColumns+`
 		FROM tasks WHERE session_id = ? AND status = 'linked'
 		ORDER BY created_at DESC LIMIT 1`, sessionID)
 	return scanTask(row)
 }
 
+// --- Task Attempts ---
+
+// AddAttempt records an attempt, replacing one with the same number.
+func (s *SQLiteStore) AddAttempt(a *AttemptRecord) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO task_attempts
+		(task_id, attempt, outcome, exit_code, error, retry_on, session_id, cost_usd, turns, started_at, ended_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.TaskID, a.Number, a.Outcome, a.ExitCode, a.Error, a.RetryOn, a.SessionID, a.CostUSD, a.Turns,
+		formatTime(a.StartedAt), formatTime(a.EndedAt))
+	if err != nil {
+		return fmt.Errorf("adding attempt: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListAttempts(taskID string) ([]AttemptRecord, error) {
+	rows, err := s.db.Query(`SELECT task_id, attempt, outcome, exit_code, error, retry_on, session_id, cost_usd, turns, started_at, ended_at
+		FROM task_attempts WHERE task_id = ? ORDER BY attempt`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("listing attempts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var attempts []AttemptRecord
+	for rows.Next() {
+		var a AttemptRecord
+		var startedAt, endedAt string
+		if err := rows.Scan(&a.TaskID, &a.Number, &a.Outcome, &a.ExitCode, &a.Error, &a.RetryOn, &a.SessionID,
+			&a.CostUSD, &a.Turns, &startedAt, &endedAt); err != nil {
+			return nil, fmt.Errorf("scanning attempt: %w", err)
+		}
+		a.StartedAt = parseTime(startedAt)
+		a.EndedAt = parseTime(endedAt)
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
 // --- Task Events ---
 
 func (s *SQLiteStore) AddEvent(e *TaskEvent) error {
-	_, err := s.db.Exec(`INSERT INTO task_events (task_id, event_type, message, created_at) VALUES (?, ?, ?, ?)`,
-		e.TaskID, e.EventType, e.Message, formatTime(e.CreatedAt))
+	level := e.Level
+	if level == "" {
+		level = LevelInfo
+	}
+	res, err := s.db.Exec(`INSERT INTO task_events (task_id, event_type, level, message, created_at) VALUES (?, ?, ?, ?, ?)`,
+		e.TaskID, e.EventType, level, e.Message, formatTime(e.CreatedAt))
 	if err != nil {
 		return fmt.Errorf("adding event: %w", err)
 	}
+	if id, err := res.LastInsertId(); err == nil {
+		e.ID = id
+	}
 	return nil
 }
 
+// ListEvents returns the events matching f in chronological order: the
+// first ones after f.AfterID, or else the most recent ones.
+func (s *SQLiteStore) ListEvents(f EventFilter) ([]TaskEvent, error) {
+	query := "SELECT e.id, e.task_id, e.event_type, e.level, e.message, e.created_at FROM task_events e"
+	var args []interface{}
+	if f.Project != "" {
+		query += " JOIN tasks t ON t.id = e.task_id WHERE t.project = ?"
+		args = append(args, f.Project)
+	} else {
+		query += " WHERE 1=1"
+	}
+
+	if f.TaskID != "" {
+		query += " AND e.task_id = ?"
+		args = append(args, f.TaskID)
+	}
+	if len(f.Levels) > 0 {
+		query += " AND e.level IN (" + placeholders(len(f.Levels)) + ")"
+		for _, l := range f.Levels {
+			args = append(args, l)
+		}
+	}
+	if len(f.Types) > 0 {
+		query += " AND e.event_type IN (" + placeholders(len(f.Types)) + ")"
+		for _, t := range f.Types {
+			args = append(args, t)
+		}
+	}
+	if !f.Since.IsZero() {
+		query += " AND e.created_at >= ?"
+		args = append(args, formatTime(f.Since))
+	}
+	if f.AfterID > 0 {
+		query += " AND e.id > ?"
+		args = append(args, f.AfterID)
+	}
+	if f.BeforeID > 0 {
+		query += " AND e.id < ?"
+		args = append(args, f.BeforeID)
+	}
+
+	newest := f.AfterID <= 0
+	if newest {
+		query += " ORDER BY e.id DESC"
+	} else {
+		query += " ORDER BY e.id ASC"
+	}
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing events: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []TaskEvent
+	for rows.Next() {
+		var e TaskEvent
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.EventType, &e.Level, &e.Message, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		e.CreatedAt = parseTime(createdAt)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if newest {
+		slices.Reverse(events)
+	}
+	return events, nil
+}
+
 func (s *SQLiteStore) GetEvents(taskID string, limit int) ([]TaskEvent, error) {
-	query := "SELECT id, task_id, event_type, message, created_at FROM task_events WHERE task_id = ? ORDER BY created_at DESC"
+	query := "SELECT id, task_id, event_type, level, message, created_at FROM task_events WHERE task_id = ? ORDER BY created_at DESC"
 	var args []interface{}
 	args = append(args, taskID)
 
@@ -216,7 +350,7 @@ func (s *SQLiteStore) GetEvents(taskID string, limit int) ([]TaskEvent, error) {
 	for rows.Next() {
 		var e TaskEvent
 		var createdAt string
-		if err := rows.Scan(&e.ID, &e.TaskID, &e.EventType, &e.Message, &createdAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.EventType, &e.Level, &e.Message, &createdAt); err != nil {
 			return nil, fmt.Errorf("scanning event: %w", err)
 		}
 		e.CreatedAt = parseTime(createdAt)
@@ -225,6 +359,139 @@ func (s *SQLiteStore) GetEvents(taskID string, limit int) ([]TaskEvent, error) {
 	return events, rows.Err()
 }
 
+// --- Task Transcripts ---
+
+// AddTranscript appends entries to the transcripts of their tasks,
+// numbering them after the entries already stored.
+func (s *SQLiteStore) AddTranscript(entries []TranscriptEntry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("adding transcript: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := range entries {
+		e := &entries[i]
+		err := tx.QueryRow(`INSERT INTO task_transcripts
+			(task_id, seq, attempt, kind, tool, tool_use_id, text, input, is_error, input_tokens, output_tokens, duration_ms, created_at)
+			SELECT ?, COALESCE(MAX(seq), 0) + 1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? FROM task_transcripts WHERE task_id = ?
+			RETURNING seq`,
+			e.TaskID, e.Attempt, e.Kind, e.Tool, e.ToolUseID, e.Text, e.Input, e.IsError,
+			e.InputTokens, e.OutputTokens, e.Duration.Milliseconds(), formatTime(e.CreatedAt), e.TaskID).Scan(&e.Seq)
+		if err != nil {
+			return fmt.Errorf("adding transcript entry: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("adding transcript: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListTranscript(f TranscriptFilter) ([]TranscriptEntry, error) {
+	query := `SELECT task_id, seq, attempt, kind, tool, tool_use_id, text, input, is_error, input_tokens, output_tokens, duration_ms, created_at
+		FROM task_transcripts WHERE task_id = ?`
+	args := []interface{}{f.TaskID}
+	if f.Attempt > 0 {
+		query += " AND attempt = ?"
+		args = append(args, f.Attempt)
+	}
+	if f.Tool != "" {
+		query += " AND tool = ? COLLATE NOCASE"
+		args = append(args, f.Tool)
+	}
+	if f.AfterSeq > 0 {
+		query += " AND seq > ?"
+		args = append(args, f.AfterSeq)
+	}
+	query += " ORDER BY seq"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing transcript: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []TranscriptEntry
+	for rows.Next() {
+		var e TranscriptEntry
+		var durationMs int64
+		var createdAt string
+		if err := rows.Scan(&e.TaskID, &e.Seq, &e.Attempt, &e.Kind, &e.Tool, &e.ToolUseID, &e.Text, &e.Input, &e.IsError,
+			&e.InputTokens, &e.OutputTokens, &durationMs, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning transcript entry: %w", err)
+		}
+		e.Duration = time.Duration(durationMs) * time.Millisecond
+		e.CreatedAt = parseTime(createdAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// --- Schedules ---
+
+const scheduleColumns = `name, source, cron, timezone, project, prompt, template, variables,
+		priority, model, timeout_minutes, git_branch, dry_run, missed_run, jitter_seconds,
+		last_run_at, last_task_id, created_at`
+
+// SaveSchedule inserts a schedule or replaces the one with the same name,
+// keeping its creation time.
+func (s *SQLiteStore) SaveSchedule(r *ScheduleRecord) error {
+	_, err := s.db.Exec(`INSERT INTO schedules (`+scheduleColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+		source = excluded.source, cron = excluded.cron, timezone = excluded.timezone,
+		project = excluded.project, prompt = excluded.prompt, template = excluded.template,
+		variables = excluded.variables, priority = excluded.priority, model = excluded.model,
+		timeout_minutes = excluded.timeout_minutes, git_branch = excluded.git_branch,
+		dry_run = excluded.dry_run, missed_run = excluded.missed_run, jitter_seconds = excluded.jitter_seconds,
+		last_run_at = excluded.last_run_at, last_task_id = excluded.last_task_id`,
+		r.Name, r.Source, r.Cron, r.Timezone, r.Project, r.Prompt, r.Template, encodeStringMap(r.Variables),
+		r.Priority, r.Model, r.TimeoutMinutes, r.GitBranch, boolToInt(r.DryRun), r.MissedRun, r.JitterSeconds,
+		formatTime(r.LastRunAt), r.LastTaskID, formatTime(r.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("saving schedule: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListSchedules() ([]ScheduleRecord, error) {
+	rows, err := s.db.Query(`SELECT ` + scheduleColumns + ` FROM schedules ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("listing schedules: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var schedules []ScheduleRecord
+	for rows.Next() {
+		var r ScheduleRecord
+		var variables, lastRunAt, createdAt string
+		var dryRun int
+		if err := rows.Scan(&r.Name, &r.Source, &r.Cron, &r.Timezone, &r.Project, &r.Prompt, &r.Template, &variables,
+			&r.Priority, &r.Model, &r.TimeoutMinutes, &r.GitBranch, &dryRun, &r.MissedRun, &r.JitterSeconds,
+			&lastRunAt, &r.LastTaskID, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning schedule: %w", err)
+		}
+		r.Variables = decodeStringMap(variables)
+		r.DryRun = dryRun != 0
+		r.LastRunAt = parseTime(lastRunAt)
+		r.CreatedAt = parseTime(createdAt)
+		schedules = append(schedules, r)
+	}
+	return schedules, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteSchedule(name string) error {
+	if _, err := s.db.Exec("DELETE FROM schedules WHERE name = ?", name); err != nil {
+		return fmt.Errorf("deleting schedule: %w", err)
+	}
+	return nil
+}
+
 // --- OAuth Tokens ---
 
 func (s *SQLiteStore) StoreToken(t *TokenRecord) error {
@@ -350,44 +617,47 @@ func (s *SQLiteStore) Cleanup() error {
 
 // --- Helpers ---
 
-func scanTask(row *sql.Row) (*TaskRecord, error) {
-	var t TaskRecord
-	var dryRun int
-	var createdAt, startedAt, completedAt string
-
-	err := row.Scan(&t.ID, &t.Type, &t.Project, &t.Prompt, &t.Context, &t.Status, &t.Priority,
-		&t.SessionID, &t.PID, &t.GitBranch, &t.Output, &t.Progress, &t.Error,
-		&t.CostUSD, &t.Turns, &t.TimeoutMinutes, &dryRun,
-		&createdAt, &startedAt, &completedAt)
-	if err != nil {
-		return nil, fmt.Errorf("scanning task: %w", err)
-	}
-
-	t.DryRun = dryRun != 0
-	t.CreatedAt = parseTime(createdAt)
-	t.StartedAt = parseTime(startedAt)
-	t.CompletedAt = parseTime(completedAt)
+// placeholders returns n comma-separated SQL placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
 
-	return &t, nil
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func scanTaskRows(rows *sql.Rows) (*TaskRecord, error) {
+func scanTask(row rowScanner) (*TaskRecord, error) {
 	var t TaskRecord
-	var dryRun int
-	var createdAt, startedAt, completedAt string
-
-	err := rows.Scan(&t.ID, &t.Type, &t.Project, &t.Prompt, &t.Context, &t.Status, &t.Priority,
-		&t.SessionID, &t.PID, &t.GitBranch, &t.Output, &t.Progress, &t.Error,
-		&t.CostUSD, &t.Turns, &t.TimeoutMinutes, &dryRun,
-		&createdAt, &startedAt, &completedAt)
+	var branchCreated, stashed, dryRun, interrupted, inheritBranch, inheritSession int
+	var warnings, filesModified, dependsOn string
+	var createdAt, startedAt, completedAt, pausedAt string
+	var pausedMS int64
+
+	err := row.Scan(&t.ID, &t.Type, &t.Project, &t.Prompt, &t.Context, &t.Status, &t.Priority, &t.Model,
+		&t.SessionID, &t.PID, &t.GitBranch, &t.WorktreePath, &t.BaseCommit, &t.OriginalBranch, &branchCreated, &stashed,
+		&t.Output, &t.Progress, &t.Error, &warnings, &t.CostUSD, &t.Turns, &filesModified, &t.LinesAdded, &t.LinesRemoved,
+		&t.TimeoutMinutes, &dryRun, &interrupted,
+		&dependsOn, &t.OnDependencyFailure, &inheritBranch, &inheritSession, &t.RetryPolicy,
+		&t.MaxBudgetUSD, &t.ApprovalReason, &t.ApprovedBy, &pausedAt, &pausedMS, &t.Preemptions, &createdAt, &startedAt, &completedAt)
 	if err != nil {
 		return nil, fmt.Errorf("scanning task: %w", err)
 	}
 
+	t.BranchCreated = branchCreated != 0
+	t.Stashed = stashed != 0
 	t.DryRun = dryRun != 0
+	t.Interrupted = interrupted != 0
+	t.Warnings = decodeStrings(warnings)
+	t.FilesModified = decodeStrings(filesModified)
+	t.DependsOn = decodeStrings(dependsOn)
+	t.InheritBranch = inheritBranch != 0
+	t.InheritSession = inheritSession != 0
 	t.CreatedAt = parseTime(createdAt)
 	t.StartedAt = parseTime(startedAt)
 	t.CompletedAt = parseTime(completedAt)
+	t.PausedAt = parseTime(pausedAt)
+	t.PausedFor = time.Duration(pausedMS) * time.Millisecond
 
 	return &t, nil
 }
@@ -413,3 +683,49 @@ func boolToInt(b bool) int {
 	}
 	return 0
 }
+
+// encodeStrings serializes a string list as JSON for a TEXT column.
+func encodeStrings(v []string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeStrings(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var v []string
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// encodeStringMap serializes a string map as JSON for a TEXT column.
+func encodeStringMap(v map[string]string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeStringMap(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	var v map[string]string
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil
+	}
+	return v
+}
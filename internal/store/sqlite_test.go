@@ -13,7 +13,7 @@ import (
 
 func newTestStore(t *testing.T) *SQLiteStore {
 	t.Helper()
-	s, err := NewSQLiteStore(":memory:")
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "herald.db"))
 	require.NoError(t, err)
 	t.Cleanup(func() { _ = s.Close() })
 	return s
@@ -84,6 +84,83 @@ func TestSQLiteStore_UpdateTask(t *testing.T) {
 	assert.Equal(t, 12345, got.PID)
 }
 
+func TestSQLiteStore_UpdateTask_PersistsRehydrationFields(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	task := &TaskRecord{
+		ID:        "herald-rehyd001",
+		Type:      "dispatched",
+		Project:   "proj",
+		Prompt:    "do stuff",
+		Status:    "running",
+		Priority:  "high",
+		CreatedAt: now,
+	}
+	require.NoError(t, s.CreateTask(task))
+
+	task.Status = "failed"
+	task.Model = "claude-opus-4-6"
+	task.FilesModified = []string{"main.go", "internal/auth/token.go"}
+	task.LinesAdded = 127
+	task.LinesRemoved = 23
+	task.Interrupted = true
+	task.WorktreePath = "/work/worktrees/herald-rehyd001"
+	task.BaseCommit = "0123abcd"
+	task.Warnings = []string{"git: stash pop failed"}
+	task.OriginalBranch = "main"
+	task.BranchCreated = true
+	task.Stashed = true
+	require.NoError(t, s.UpdateTask(task))
+
+	got, err := s.GetTask("herald-rehyd001")
+	require.NoError(t, err)
+	assert.Equal(t, "claude-opus-4-6", got.Model)
+	assert.Equal(t, []string{"main.go", "internal/auth/token.go"}, got.FilesModified)
+	assert.Equal(t, 127, got.LinesAdded)
+	assert.Equal(t, 23, got.LinesRemoved)
+	assert.True(t, got.Interrupted)
+	assert.Equal(t, "/work/worktrees/herald-rehyd001", got.WorktreePath)
+	assert.Equal(t, "0123abcd", got.BaseCommit)
+	assert.Equal(t, []string{"git: stash pop failed"}, got.Warnings)
+	assert.Equal(t, "main", got.OriginalBranch)
+	assert.True(t, got.BranchCreated)
+	assert.True(t, got.Stashed)
+
+	listed, err := s.ListTasks(TaskFilter{Status: "failed"})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, got.FilesModified, listed[0].FilesModified)
+}
+
+func TestSQLiteStore_CreateTask_PersistsDependencies(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	task := &TaskRecord{
+		ID:                  "herald-child001",
+		Type:                "dispatched",
+		Project:             "proj",
+		Prompt:              "deploy",
+		Status:              "waiting",
+		Priority:            "normal",
+		DependsOn:           []string{"herald-parent01", "herald-parent02"},
+		OnDependencyFailure: "fail",
+		InheritBranch:       true,
+		InheritSession:      true,
+		CreatedAt:           time.Now().Truncate(time.Second),
+	}
+	require.NoError(t, s.CreateTask(task))
+
+	got, err := s.GetTask("herald-child001")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"herald-parent01", "herald-parent02"}, got.DependsOn)
+	assert.Equal(t, "fail", got.OnDependencyFailure)
+	assert.True(t, got.InheritBranch)
+	assert.True(t, got.InheritSession)
+}
+
 func TestSQLiteStore_ListTasks_FilterByStatus(t *testing.T) {
 	t.Parallel()
 	s := newTestStore(t)
@@ -168,6 +245,105 @@ func TestSQLiteStore_AddAndGetEvents(t *testing.T) {
 	assert.Len(t, limited, 2)
 }
 
+func TestSQLiteStore_Transcript_AppendsAndFilters(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, s.CreateTask(&TaskRecord{ID: "herald-tr001", Project: "api", Prompt: "x", Status: "running", Priority: "normal", CreatedAt: now}))
+
+	first := []TranscriptEntry{
+		{TaskID: "herald-tr001", Attempt: 1, Kind: "assistant", Text: "Running the tests.", InputTokens: 1200, OutputTokens: 40, CreatedAt: now},
+		{TaskID: "herald-tr001", Attempt: 1, Kind: "tool_use", Tool: "Bash", ToolUseID: "toolu_1", Input: `{"command":"go test ./..."}`, CreatedAt: now},
+		{TaskID: "herald-tr001", Attempt: 1, Kind: "tool_result", Tool: "Bash", ToolUseID: "toolu_1", Text: "FAIL", IsError: true, Duration: 7 * time.Second, CreatedAt: now.Add(7 * time.Second)},
+	}
+	require.NoError(t, s.AddTranscript(first))
+	assert.Equal(t, 3, first[2].Seq)
+	second := []TranscriptEntry{{TaskID: "herald-tr001", Attempt: 2, Kind: "tool_use", Tool: "Edit", CreatedAt: now.Add(time.Minute)}}
+	require.NoError(t, s.AddTranscript(second))
+	assert.Equal(t, 4, second[0].Seq, "later attempts go on numbering")
+
+	all, err := s.ListTranscript(TranscriptFilter{TaskID: "herald-tr001"})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, first[2], all[2])
+
+	bash, err := s.ListTranscript(TranscriptFilter{TaskID: "herald-tr001", Tool: "bash"})
+	require.NoError(t, err)
+	assert.Len(t, bash, 2)
+
+	page, err := s.ListTranscript(TranscriptFilter{TaskID: "herald-tr001", AfterSeq: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, 2, page[0].Seq)
+
+	retry, err := s.ListTranscript(TranscriptFilter{TaskID: "herald-tr001", Attempt: 2})
+	require.NoError(t, err)
+	require.Len(t, retry, 1)
+	assert.Equal(t, "Edit", retry[0].Tool)
+}
+
+func TestSQLiteStore_ListEvents_FiltersAndPages(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, s.CreateTask(&TaskRecord{ID: "herald-ev101", Project: "api", Prompt: "x", Status: "running", Priority: "normal", CreatedAt: now}))
+	require.NoError(t, s.CreateTask(&TaskRecord{ID: "herald-ev102", Project: "web", Prompt: "x", Status: "running", Priority: "normal", CreatedAt: now}))
+
+	events := []*TaskEvent{
+		{TaskID: "herald-ev101", EventType: "task.started", Message: "started", CreatedAt: now},
+		{TaskID: "herald-ev101", EventType: "task.tool_use", Level: LevelDebug, Message: "Using tool: Bash", CreatedAt: now.Add(time.Second)},
+		{TaskID: "herald-ev102", EventType: "task.started", Message: "started", CreatedAt: now.Add(2 * time.Second)},
+		{TaskID: "herald-ev101", EventType: "task.failed", Level: LevelError, Message: "exit 1", CreatedAt: now.Add(3 * time.Second)},
+	}
+	for _, e := range events {
+		require.NoError(t, s.AddEvent(e))
+		require.Positive(t, e.ID)
+	}
+
+	got, err := s.ListEvents(EventFilter{TaskID: "herald-ev101"})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "task.started", got[0].EventType, "events are chronological")
+	assert.Equal(t, LevelInfo, got[0].Level, "empty level is stored as info")
+
+	got, err = s.ListEvents(EventFilter{TaskID: "herald-ev101", Levels: LevelsFrom(LevelInfo)})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	got, err = s.ListEvents(EventFilter{Types: []string{"task.started"}, Project: "web"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "herald-ev102", got[0].TaskID)
+
+	latest, err := s.ListEvents(EventFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, events[2].ID, latest[0].ID, "the most recent events, oldest first")
+
+	older, err := s.ListEvents(EventFilter{BeforeID: latest[0].ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, older, 2)
+
+	newer, err := s.ListEvents(EventFilter{AfterID: events[0].ID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, newer, 1)
+	assert.Equal(t, events[1].ID, newer[0].ID)
+
+	got, err = s.ListEvents(EventFilter{Since: now.Add(2 * time.Second)})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestLevelsFrom(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{LevelWarn, LevelError}, LevelsFrom(LevelWarn))
+	assert.Len(t, LevelsFrom(LevelDebug), 4)
+	assert.Nil(t, LevelsFrom(""))
+}
+
 func TestSQLiteStore_StoreAndGetToken(t *testing.T) {
 	t.Parallel()
 	s := newTestStore(t)
@@ -471,3 +647,195 @@ func TestSQLiteStore_ListTasks_IncludesContext(t *testing.T) {
 	require.Len(t, tasks, 1)
 	assert.Equal(t, "working on feature X", tasks[0].Context)
 }
+
+func TestSQLiteStore_Schedules_SaveListDelete(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	created := time.Now().Truncate(time.Second)
+	rec := &ScheduleRecord{
+		Name:           "nightly-tests",
+		Source:         "mcp",
+		Cron:           "0 2 * * *",
+		Timezone:       "Europe/Paris",
+		Project:        "my-api",
+		Template:       "test",
+		Variables:      map[string]string{"scope": "internal/auth"},
+		Priority:       "low",
+		TimeoutMinutes: 45,
+		DryRun:         true,
+		MissedRun:      "run_once",
+		JitterSeconds:  300,
+		CreatedAt:      created,
+	}
+	require.NoError(t, s.SaveSchedule(rec))
+
+	// Recording a run updates the row but keeps its creation time.
+	lastRun := created.Add(time.Hour)
+	rec.LastRunAt = lastRun
+	rec.LastTaskID = "herald-sched001"
+	rec.CreatedAt = lastRun
+	require.NoError(t, s.SaveSchedule(rec))
+	require.NoError(t, s.SaveSchedule(&ScheduleRecord{Name: "adhoc", Source: "mcp", Cron: "@daily", Project: "my-api", Prompt: "x", CreatedAt: created}))
+
+	got, err := s.ListSchedules()
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "adhoc", got[0].Name)
+	assert.Nil(t, got[0].Variables)
+	assert.True(t, got[0].LastRunAt.IsZero())
+
+	n := got[1]
+	assert.Equal(t, "nightly-tests", n.Name)
+	assert.Equal(t, "Europe/Paris", n.Timezone)
+	assert.Equal(t, map[string]string{"scope": "internal/auth"}, n.Variables)
+	assert.Equal(t, 45, n.TimeoutMinutes)
+	assert.True(t, n.DryRun)
+	assert.Equal(t, "run_once", n.MissedRun)
+	assert.Equal(t, 300, n.JitterSeconds)
+	assert.Equal(t, "herald-sched001", n.LastTaskID)
+	assert.True(t, lastRun.Equal(n.LastRunAt))
+	assert.True(t, created.Equal(n.CreatedAt))
+
+	require.NoError(t, s.DeleteSchedule("nightly-tests"))
+	got, err = s.ListSchedules()
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestSQLiteStore_Attempts_AddList(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, s.CreateTask(&TaskRecord{
+		ID:          "herald-retry001",
+		Project:     "my-api",
+		Prompt:      "fix the bug",
+		Status:      "running",
+		Priority:    "normal",
+		RetryPolicy: `{"max_attempts":3}`,
+		CreatedAt:   now,
+	}))
+
+	got, err := s.GetTask("herald-retry001")
+	require.NoError(t, err)
+	assert.Equal(t, `{"max_attempts":3}`, got.RetryPolicy)
+
+	require.NoError(t, s.AddAttempt(&AttemptRecord{
+		TaskID: "herald-retry001", Number: 2, Outcome: "completed", SessionID: "ses_1",
+		CostUSD: 0.25, Turns: 3, StartedAt: now.Add(time.Minute), EndedAt: now.Add(2 * time.Minute),
+	}))
+	require.NoError(t, s.AddAttempt(&AttemptRecord{
+		TaskID: "herald-retry001", Number: 1, Outcome: "failed", ExitCode: 1, Error: "claude exited with code 1",
+		RetryOn: "exit code 1", SessionID: "ses_1", CostUSD: 0.10, Turns: 2, StartedAt: now, EndedAt: now.Add(30 * time.Second),
+	}))
+
+	attempts, err := s.ListAttempts("herald-retry001")
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, 1, attempts[0].Number)
+	assert.Equal(t, "failed", attempts[0].Outcome)
+	assert.Equal(t, 1, attempts[0].ExitCode)
+	assert.Equal(t, "exit code 1", attempts[0].RetryOn)
+	assert.True(t, now.Equal(attempts[0].StartedAt))
+	assert.Equal(t, 2, attempts[1].Number)
+	assert.InDelta(t, 0.25, attempts[1].CostUSD, 0.0001)
+
+	none, err := s.ListAttempts("herald-other000")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestSQLiteStore_MaxBudget_PersistsAcrossUpdate(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	task := &TaskRecord{
+		ID:           "herald-budget01",
+		Project:      "my-api",
+		Prompt:       "fix auth bug",
+		Status:       "pending",
+		Priority:     "normal",
+		MaxBudgetUSD: 2.5,
+		CreatedAt:    time.Now().Truncate(time.Second),
+	}
+	require.NoError(t, s.CreateTask(task))
+
+	got, err := s.GetTask("herald-budget01")
+	require.NoError(t, err)
+	assert.InDelta(t, 2.5, got.MaxBudgetUSD, 0.0001)
+
+	task.MaxBudgetUSD = 4
+	require.NoError(t, s.UpdateTask(task))
+	got, err = s.GetTask("herald-budget01")
+	require.NoError(t, err)
+	assert.InDelta(t, 4, got.MaxBudgetUSD, 0.0001)
+}
+
+func TestSQLiteStore_Approval_PersistsAcrossUpdate(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	task := &TaskRecord{
+		ID:             "herald-approve1",
+		Project:        "my-api",
+		Prompt:         "rotate the signing keys",
+		Status:         "awaiting_approval",
+		Priority:       "urgent",
+		ApprovalReason: "urgent priority requires approval",
+		CreatedAt:      time.Now().Truncate(time.Second),
+	}
+	require.NoError(t, s.CreateTask(task))
+
+	got, err := s.GetTask("herald-approve1")
+	require.NoError(t, err)
+	assert.Equal(t, "urgent priority requires approval", got.ApprovalReason)
+	assert.Empty(t, got.ApprovedBy)
+
+	task.Status = "pending"
+	task.ApprovedBy = "cli"
+	require.NoError(t, s.UpdateTask(task))
+	got, err = s.GetTask("herald-approve1")
+	require.NoError(t, err)
+	assert.Equal(t, "pending", got.Status)
+	assert.Equal(t, "cli", got.ApprovedBy)
+}
+
+func TestSQLiteStore_Pause_PersistsAcrossUpdate(t *testing.T) {
+	t.Parallel()
+	s := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	task := &TaskRecord{
+		ID:        "herald-pause001",
+		Project:   "my-api",
+		Prompt:    "refactor",
+		Status:    "paused",
+		Priority:  "normal",
+		PausedAt:  now,
+		PausedFor: 90 * time.Second,
+		CreatedAt: now,
+	}
+	require.NoError(t, s.CreateTask(task))
+
+	got, err := s.GetTask("herald-pause001")
+	require.NoError(t, err)
+	assert.True(t, now.Equal(got.PausedAt))
+	assert.Equal(t, 90*time.Second, got.PausedFor)
+
+	task.Status = "running"
+	task.PausedAt = time.Time{}
+	task.PausedFor = 2 * time.Minute
+	require.NoError(t, s.UpdateTask(task))
+	got, err = s.GetTask("herald-pause001")
+	require.NoError(t, err)
+	assert.True(t, got.PausedAt.IsZero())
+	assert.Equal(t, 2*time.Minute, got.PausedFor)
+
+	task.Preemptions = `[{"by":"herald-urgent01","paused_at":"2026-03-01T10:00:00Z"}]`
+	require.NoError(t, s.UpdateTask(task))
+	got, err = s.GetTask("herald-pause001")
+	require.NoError(t, err)
+	assert.Equal(t, task.Preemptions, got.Preemptions)
+}
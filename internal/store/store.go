@@ -14,9 +14,18 @@ type Store interface {
 	ListTasks(f TaskFilter) ([]TaskRecord, error)
 	GetLinkedTaskBySessionID(sessionID string) (*TaskRecord, error)
 
+	// Task attempts
+	AddAttempt(a *AttemptRecord) error
+	ListAttempts(taskID string) ([]AttemptRecord, error)
+
+	// Task transcripts
+	AddTranscript(entries []TranscriptEntry) error
+	ListTranscript(f TranscriptFilter) ([]TranscriptEntry, error)
+
 	// Task events
 	AddEvent(e *TaskEvent) error
 	GetEvents(taskID string, limit int) ([]TaskEvent, error)
+	ListEvents(f EventFilter) ([]TaskEvent, error)
 
 	// OAuth tokens
 	StoreToken(t *TokenRecord) error
@@ -27,6 +36,11 @@ type Store interface {
 	StoreAuthCode(c *AuthCodeRecord) error
 	ConsumeAuthCode(codeHash string) (*AuthCodeRecord, error)
 
+	// Schedules
+	SaveSchedule(s *ScheduleRecord) error
+	ListSchedules() ([]ScheduleRecord, error)
+	DeleteSchedule(name string) error
+
 	// Analytics
 	GetAverageTaskDuration(project string) (time.Duration, int, error)
 
@@ -44,19 +58,42 @@ type TaskRecord struct {
 	Context        string
 	Status         string
 	Priority       string
+	Model          string
 	SessionID      string
 	PID            int
 	GitBranch      string
+	WorktreePath   string
+	BaseCommit     string
+	OriginalBranch string // branch the project checkout was on before the task switched it
+	BranchCreated  bool   // the task branch was created for the task
+	Stashed        bool   // local changes were stashed before the task
 	Output         string
 	Progress       string
 	Error          string
+	Warnings       []string
 	CostUSD        float64
 	Turns          int
+	FilesModified  []string
+	LinesAdded     int
+	LinesRemoved   int
 	TimeoutMinutes int
 	DryRun         bool
-	CreatedAt      time.Time
-	StartedAt      time.Time
-	CompletedAt    time.Time
+	Interrupted    bool // task was running or queued when Herald stopped
+
+	DependsOn           []string // tasks that must complete before this one starts
+	OnDependencyFailure string
+	InheritBranch       bool
+	InheritSession      bool
+	RetryPolicy         string        // JSON-encoded retry policy, empty when failures are not retried
+	MaxBudgetUSD        float64       // cost cap of the task, 0 for none
+	ApprovalReason      string        // why the task needs approval before it starts
+	ApprovedBy          string        // how the task was approved, empty until it is
+	PausedAt            time.Time     // when the task was paused, zero unless paused
+	PausedFor           time.Duration // time spent paused before PausedAt
+	Preemptions         string        // JSON-encoded times the task was preempted, empty when it never was
+	CreatedAt           time.Time
+	StartedAt           time.Time
+	CompletedAt         time.Time
 }
 
 // TaskFilter specifies criteria for listing tasks.
@@ -67,15 +104,115 @@ type TaskFilter struct {
 	Since   time.Time
 }
 
+// AttemptRecord represents one execution attempt of a task with a retry
+// policy.
+type AttemptRecord struct {
+	TaskID    string
+	Number    int
+	Outcome   string
+	ExitCode  int
+	Error     string
+	RetryOn   string // failure class that triggered a retry
+	SessionID string
+	CostUSD   float64
+	Turns     int
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
 // TaskEvent represents a timestamped event for audit trail.
 type TaskEvent struct {
 	ID        int64
 	TaskID    string
 	EventType string
+	Level     string // LevelDebug, LevelInfo, LevelWarn or LevelError; LevelInfo when empty
 	Message   string
 	CreatedAt time.Time
 }
 
+// Levels of task events, from the least to the most severe.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var levels = []string{LevelDebug, LevelInfo, LevelWarn, LevelError}
+
+// LevelsFrom returns min and the levels more severe than it, or nil when
+// min is empty or unknown.
+func LevelsFrom(min string) []string {
+	for i, l := range levels {
+		if l == min {
+			return levels[i:]
+		}
+	}
+	return nil
+}
+
+// EventFilter specifies criteria for listing task events. Without AfterID,
+// the most recent events are listed.
+type EventFilter struct {
+	TaskID   string
+	Project  string
+	Levels   []string // any level when empty
+	Types    []string // any type when empty
+	Since    time.Time
+	AfterID  int64 // only events after this one, oldest first
+	BeforeID int64 // only events before this one
+	Limit    int
+}
+
+// TranscriptEntry represents one step of a task execution transcript.
+type TranscriptEntry struct {
+	TaskID       string
+	Seq          int // position in the task's transcript, from 1
+	Attempt      int
+	Kind         string
+	Tool         string
+	ToolUseID    string
+	Text         string
+	Input        string
+	IsError      bool
+	InputTokens  int
+	OutputTokens int
+	Duration     time.Duration
+	CreatedAt    time.Time
+}
+
+// TranscriptFilter specifies criteria for listing transcript entries,
+// oldest first.
+type TranscriptFilter struct {
+	TaskID   string
+	Attempt  int    // any attempt when 0
+	Tool     string // only the calls and results of this tool, any case
+	AfterSeq int    // only entries after this one
+	Limit    int
+}
+
+// ScheduleRecord represents a persisted recurring task schedule.
+type ScheduleRecord struct {
+	Name           string
+	Source         string // "config" for schedules from herald.yaml, "mcp" for schedule_task
+	Cron           string
+	Timezone       string
+	Project        string
+	Prompt         string
+	Template       string
+	Variables      map[string]string
+	Priority       string
+	Model          string
+	TimeoutMinutes int
+	GitBranch      string
+	DryRun         bool
+	MissedRun      string
+	JitterSeconds  int
+	LastRunAt      time.Time
+	LastTaskID     string
+	CreatedAt      time.Time
+}
+
 // TokenRecord represents a persisted OAuth token.
 type TokenRecord struct {
 	TokenHash string
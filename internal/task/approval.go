@@ -0,0 +1,199 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Channels a task can be approved or rejected through.
+const (
+	ApprovalChat = "chat" // the approve_task tool
+	ApprovalCLI  = "cli"  // herald approve on the workstation
+	ApprovalLink = "link" // a signed approval link
+)
+
+// ApprovalPolicy decides which tasks wait for a human to approve them
+// before they start.
+type ApprovalPolicy struct {
+	Required      bool       // every task needs approval
+	Priorities    []Priority // tasks of these priorities need approval
+	MaxPromptSize int        // tasks with a longer prompt, in bytes, need approval; 0 for no limit
+}
+
+// ApprovalLinkFunc returns a link that approves or rejects a task from a
+// browser, or "" when there is none.
+type ApprovalLinkFunc func(taskID string) string
+
+// SetApprovalPolicy sets the global approval policy and the policies of
+// projects, by name. A task needs approval when either applies to it.
+func (m *Manager) SetApprovalPolicy(global ApprovalPolicy, projects map[string]ApprovalPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approvalPolicy = global
+	m.projectApprovals = projects
+}
+
+// SetApprovalLinkFunc sets how approval links are made for the
+// notifications of tasks awaiting approval.
+func (m *Manager) SetApprovalLinkFunc(fn ApprovalLinkFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approvalLink = fn
+}
+
+// ApprovalLink returns the approval link of a task, or "" when approval
+// links are not enabled.
+func (m *Manager) ApprovalLink(taskID string) string {
+	m.mu.RLock()
+	fn := m.approvalLink
+	m.mu.RUnlock()
+	if fn == nil {
+		return ""
+	}
+	return fn(taskID)
+}
+
+// Approve starts a task awaiting approval. by records the channel it was
+// approved through (ApprovalChat, ApprovalCLI or ApprovalLink).
+func (m *Manager) Approve(id, by string) error {
+	w, err := m.takeApproval(id)
+	if err != nil {
+		return err
+	}
+	t := w.task
+
+	t.mu.Lock()
+	t.ApprovedBy = by
+	t.Status = StatusPending
+	t.Progress = ""
+	t.mu.Unlock()
+	m.persist(t)
+
+	slog.Info("task approved", "task_id", id, "via", by)
+	m.record(t, "task.approved", "task approved via "+by)
+
+	if err := m.Start(context.Background(), t, w.req, w.maxPerProject); err != nil {
+		if !t.IsTerminal() {
+			t.SetError(fmt.Sprintf("approved but could not start: %s", err))
+			t.SetStatus(StatusFailed)
+			m.persist(t)
+			m.emit(t, "task.failed", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// Reject cancels a task awaiting approval. by records the channel it was
+// rejected through; reason is optional.
+func (m *Manager) Reject(id, by, reason string) error {
+	w, err := m.takeApproval(id)
+	if err != nil {
+		return err
+	}
+	t := w.task
+
+	msg := "rejected via " + by
+	if reason != "" {
+		msg += ": " + reason
+	}
+	t.SetError(msg)
+	t.SetStatus(StatusCancelled)
+	m.persist(t)
+
+	slog.Info("task rejected", "task_id", id, "via", by, "reason", reason)
+	m.emit(t, "task.cancelled", "task "+msg)
+	m.dispatch()
+	return nil
+}
+
+// takeApproval removes the task id from the ones awaiting approval, so
+// that it is approved or rejected once.
+func (m *Manager) takeApproval(id string) (*waitingTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.approvals[id]
+	if !ok {
+		t, exists := m.tasks[id]
+		if !exists {
+			return nil, fmt.Errorf("task %q not found", id)
+		}
+		t.mu.RLock()
+		status := t.Status
+		t.mu.RUnlock()
+		return nil, fmt.Errorf("task %q is %s, not awaiting approval", id, status)
+	}
+	delete(m.approvals, id)
+	return w, nil
+}
+
+// approvalReason says why t needs approval before it starts, or returns ""
+// when it does not or was approved already.
+func (m *Manager) approvalReason(t *Task) string {
+	m.mu.RLock()
+	global := m.approvalPolicy
+	project, hasProject := m.projectApprovals[t.Project]
+	m.mu.RUnlock()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.ApprovedBy != "" {
+		return ""
+	}
+	if hasProject {
+		if project.Required {
+			return fmt.Sprintf("project %s requires approval", t.Project)
+		}
+		if reason := project.reason(t); reason != "" {
+			return reason
+		}
+	}
+	if global.Required {
+		return "every task requires approval"
+	}
+	return global.reason(t)
+}
+
+// reason applies the priority and prompt size rules of p to t.
+// Caller must hold t.mu.
+func (p ApprovalPolicy) reason(t *Task) string {
+	if slices.Contains(p.Priorities, t.Priority) {
+		return fmt.Sprintf("%s priority requires approval", t.Priority)
+	}
+	if p.MaxPromptSize > 0 && len(t.Prompt) > p.MaxPromptSize {
+		return fmt.Sprintf("prompt of %d bytes is over the %d bytes that run without approval", len(t.Prompt), p.MaxPromptSize)
+	}
+	return ""
+}
+
+// awaitApproval holds t until it is approved or rejected. Dependencies are
+// checked first so that a task that could never start is refused right
+// away.
+func (m *Manager) awaitApproval(t *Task, req executor.Request, maxPerProject int, reason string) error {
+	m.mu.Lock()
+	if err := m.validateDependenciesLocked(t); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.approvals[t.ID] = &waitingTask{task: t, req: req, maxPerProject: maxPerProject}
+	t.mu.Lock()
+	t.Status = StatusAwaitingApproval
+	t.ApprovalReason = reason
+	t.Progress = "awaiting approval: " + reason
+	t.mu.Unlock()
+	m.mu.Unlock()
+
+	m.persist(t)
+	slog.Info("task awaiting approval", "task_id", t.ID, "reason", reason)
+
+	message := fmt.Sprintf("%s awaits approval (%s) — approve or reject it with approve_task, or run herald approve %s on the workstation", t.ID, reason, t.ID)
+	if link := m.ApprovalLink(t.ID); link != "" {
+		message += ", or open " + link
+	}
+	m.emit(t, "task.awaiting_approval", message)
+	return nil
+}
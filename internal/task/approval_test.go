@@ -0,0 +1,167 @@
+package task
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+)
+
+func TestManager_Start_WhenProjectRequiresApproval_HoldsTask(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{}, map[string]ApprovalPolicy{"billing": {Required: true}})
+	m.SetApprovalLinkFunc(func(id string) string { return "https://herald.example.com/approvals/" + id })
+
+	events := make(chan TaskEvent, 8)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	tk := m.Create("billing", "rotate keys", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusAwaitingApproval, snap.Status)
+	assert.Equal(t, "project billing requires approval", snap.ApprovalReason)
+
+	e := <-events
+	assert.Equal(t, "task.awaiting_approval", e.Type)
+	assert.Contains(t, e.Message, "herald approve "+tk.ID)
+	assert.Contains(t, e.Message, "https://herald.example.com/approvals/"+tk.ID)
+
+	select {
+	case <-exec.reqs:
+		t.Fatal("task ran before it was approved")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestManager_Start_WhenPolicyDoesNotApply_RunsRightAway(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{Priorities: []Priority{PriorityUrgent}, MaxPromptSize: 100}, nil)
+
+	tk := m.Create("api", "fix typo", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	<-tk.Done()
+	assert.Equal(t, StatusCompleted, tk.Snapshot().Status)
+}
+
+func TestManager_ApprovalReason_ExplainsWhichRuleApplies(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{Priorities: []Priority{PriorityUrgent}, MaxPromptSize: 10}, nil)
+
+	urgent := m.Create("api", "deploy", "", PriorityUrgent, 30)
+	assert.Equal(t, "urgent priority requires approval", m.approvalReason(urgent))
+
+	long := m.Create("api", strings.Repeat("x", 11), "", PriorityNormal, 30)
+	assert.Equal(t, "prompt of 11 bytes is over the 10 bytes that run without approval", m.approvalReason(long))
+
+	long.ApprovedBy = ApprovalChat
+	assert.Empty(t, m.approvalReason(long))
+}
+
+func TestManager_Approve_StartsTask(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{Required: true}, nil)
+
+	tk := m.Create("api", "migrate", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	require.NoError(t, m.Approve(tk.ID, ApprovalCLI))
+
+	<-tk.Done()
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.Equal(t, ApprovalCLI, snap.ApprovedBy)
+	assert.Equal(t, tk.ID, (<-exec.reqs).TaskID)
+
+	err := m.Approve(tk.ID, ApprovalCLI)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not awaiting approval")
+}
+
+func TestManager_Reject_CancelsTaskAndItsDependents(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{Priorities: []Priority{PriorityUrgent}}, nil)
+	ctx := context.Background()
+
+	parent := m.Create("api", "drop table", "", PriorityUrgent, 30)
+	child := m.Create("api", "reindex", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	require.NoError(t, m.Start(ctx, parent, executor.Request{TaskID: parent.ID}, 0))
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+	assert.Equal(t, StatusWaiting, child.Snapshot().Status)
+
+	require.NoError(t, m.Reject(parent.ID, ApprovalLink, "not today"))
+
+	snap := parent.Snapshot()
+	assert.Equal(t, StatusCancelled, snap.Status)
+	assert.Equal(t, "rejected via link: not today", snap.Error)
+
+	select {
+	case <-child.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("dependent task was not cancelled")
+	}
+	assert.Equal(t, StatusCancelled, child.Snapshot().Status)
+}
+
+func TestManager_Cancel_WhenAwaitingApproval_DropsTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetApprovalPolicy(ApprovalPolicy{Required: true}, nil)
+
+	tk := m.Create("api", "migrate", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	require.NoError(t, m.Cancel(tk.ID))
+
+	assert.Equal(t, StatusCancelled, tk.Snapshot().Status)
+	assert.Error(t, m.Approve(tk.ID, ApprovalChat))
+}
+
+func TestManager_Restore_WhenAwaitingApproval_KeepsWaiting(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-await001", Type: "dispatched", Project: "billing", Prompt: "rotate keys",
+		Status: "awaiting_approval", Priority: "normal", ApprovalReason: "project billing requires approval",
+		CreatedAt: time.Now().Truncate(time.Second),
+	}))
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetApprovalPolicy(ApprovalPolicy{}, map[string]ApprovalPolicy{"billing": {Required: true}})
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	tk, err := m.Get("herald-await001")
+	require.NoError(t, err)
+	assert.Equal(t, StatusAwaitingApproval, tk.Snapshot().Status)
+
+	require.NoError(t, m.Approve(tk.ID, ApprovalLink))
+	<-tk.Done()
+	assert.Equal(t, StatusCompleted, tk.Snapshot().Status)
+	assert.Equal(t, "rotate keys", (<-exec.reqs).Prompt)
+
+	rec, err := db.GetTask(tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "link", rec.ApprovedBy)
+}
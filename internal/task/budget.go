@@ -0,0 +1,252 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Budget caps what tasks may spend in USD over the current day and the
+// current month, in the server's time zone. Cost is charged to the period it
+// is reported in. Zero means no cap.
+type Budget struct {
+	DailyUSD   float64
+	MonthlyUSD float64
+}
+
+// Policies for new tasks once a budget is spent.
+const (
+	BudgetReject = "reject" // fail the task right away
+	BudgetQueue  = "queue"  // queue the task until the budget resets
+)
+
+// ErrBudgetExhausted is returned when a budget a task counts against is
+// already spent.
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// BudgetStatus is a budget and what has been spent against it.
+type BudgetStatus struct {
+	Budget
+	SpentToday     float64
+	SpentThisMonth float64
+}
+
+// Capped reports whether the budget has a daily or a monthly cap.
+func (s BudgetStatus) Capped() bool {
+	return s.DailyUSD > 0 || s.MonthlyUSD > 0
+}
+
+// SetBudgets sets the global budget and the budgets of projects, by name.
+func (m *Manager) SetBudgets(global Budget, projects map[string]Budget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budget = global
+	m.projectBudgets = projects
+}
+
+// SetBudgetPolicy sets what happens to new tasks once a budget is spent
+// (BudgetReject or BudgetQueue).
+func (m *Manager) SetBudgetPolicy(policy string) {
+	m.budgetPolicy = policy
+}
+
+// QueuesOverBudget reports whether tasks over budget are queued until the
+// budget resets rather than rejected.
+func (m *Manager) QueuesOverBudget() bool {
+	return m.budgetPolicy == BudgetQueue
+}
+
+// Budgets returns the budget status of project and the global one.
+func (m *Manager) Budgets(project string) (BudgetStatus, BudgetStatus) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	day, month := budgetKeys(time.Now())
+	proj := BudgetStatus{
+		Budget:         m.projectBudgets[project],
+		SpentToday:     m.spentLocked(project, day),
+		SpentThisMonth: m.spentLocked(project, month),
+	}
+	global := BudgetStatus{
+		Budget:         m.budget,
+		SpentToday:     m.spentLocked("", day),
+		SpentThisMonth: m.spentLocked("", month),
+	}
+	return proj, global
+}
+
+// CheckBudget returns an error wrapping ErrBudgetExhausted when the budget
+// of project, or the global one, is spent.
+func (m *Manager) CheckBudget(project string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if reason := m.budgetExhaustedLocked(project); reason != "" {
+		return fmt.Errorf("%w: %s", ErrBudgetExhausted, reason)
+	}
+	return nil
+}
+
+// budgetExhaustedLocked describes the first spent budget among the ones of
+// project and the global one, or returns "" when none is.
+// Caller must hold m.mu.
+func (m *Manager) budgetExhaustedLocked(project string) string {
+	day, month := budgetKeys(time.Now())
+	scopes := []struct {
+		name    string
+		project string
+		budget  Budget
+	}{
+		{"project " + project, project, m.projectBudgets[project]},
+		{"global", "", m.budget},
+	}
+	for _, sc := range scopes {
+		if sc.budget.DailyUSD > 0 {
+			if spent := m.spentLocked(sc.project, day); spent >= sc.budget.DailyUSD {
+				return fmt.Sprintf("%s daily budget of $%.2f is spent ($%.2f today)", sc.name, sc.budget.DailyUSD, spent)
+			}
+		}
+		if sc.budget.MonthlyUSD > 0 {
+			if spent := m.spentLocked(sc.project, month); spent >= sc.budget.MonthlyUSD {
+				return fmt.Sprintf("%s monthly budget of $%.2f is spent ($%.2f this month)", sc.name, sc.budget.MonthlyUSD, spent)
+			}
+		}
+	}
+	return ""
+}
+
+// budgetKey identifies what the tasks of a project ("" for every project)
+// cost over a day ("2006-01-02") or a month ("2006-01").
+type budgetKey struct {
+	project string
+	period  string
+}
+
+// budgetKeys returns the day and month periods of now.
+func budgetKeys(now time.Time) (day, month string) {
+	return now.Format(time.DateOnly), now.Format("2006-01")
+}
+
+// spentLocked returns what the tasks of project (of every project when
+// empty) were charged over period. Caller must hold m.mu.
+func (m *Manager) spentLocked(project, period string) float64 {
+	return m.spent[budgetKey{project, period}]
+}
+
+// chargeLocked charges usd spent by a task of project to the day and month
+// of at, for the project and globally. Caller must hold m.mu.
+func (m *Manager) chargeLocked(project string, usd float64, at time.Time) {
+	if usd <= 0 {
+		return
+	}
+	day, month := budgetKeys(at)
+	for _, p := range []string{project, ""} {
+		m.spent[budgetKey{p, day}] += usd
+		m.spent[budgetKey{p, month}] += usd
+	}
+}
+
+// setCost records the cost t has reached. What it adds to the previous cost
+// is charged to the current day and month, whenever t started.
+func (m *Manager) setCost(t *Task, cost float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setCostLocked(t, cost)
+}
+
+// setCostLocked is setCost for callers holding m.mu.
+func (m *Manager) setCostLocked(t *Task, cost float64) {
+	t.mu.Lock()
+	added := cost - t.CostUSD
+	t.CostUSD = cost
+	t.mu.Unlock()
+	m.chargeLocked(t.Project, added, time.Now())
+}
+
+// chargeRestoredLocked charges the cost of a task loaded from the store.
+// When it was reported is not persisted, so it goes to the period the task
+// completed in, or started in while it is still active. Caller must hold
+// m.mu.
+func (m *Manager) chargeRestoredLocked(t *Task) {
+	at := t.CompletedAt
+	if at.IsZero() {
+		at = t.StartedAt
+	}
+	if at.IsZero() {
+		return
+	}
+	m.chargeLocked(t.Project, t.CostUSD, at)
+}
+
+// budgetPeriods returns the start of the day and of the month of now.
+func budgetPeriods(now time.Time) (day, month time.Time) {
+	y, mo, d := now.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, now.Location()), time.Date(y, mo, 1, 0, 0, 0, 0, now.Location())
+}
+
+// armBudgetResetLocked dispatches the queue again when the daily budgets
+// reset, for tasks held in the queue over budget. Caller must hold m.mu.
+func (m *Manager) armBudgetResetLocked() {
+	if m.budgetReset != nil {
+		return
+	}
+	day, _ := budgetPeriods(time.Now())
+	m.budgetReset = time.AfterFunc(time.Until(day.AddDate(0, 0, 1)), func() {
+		m.mu.Lock()
+		m.budgetReset = nil
+		m.mu.Unlock()
+		m.dispatch()
+	})
+}
+
+// trackCost records the cost a running task has reached and stops the task
+// gracefully once it goes over its own budget, its project's or the global
+// one.
+func (m *Manager) trackCost(t *Task, cost float64) {
+	t.mu.RLock()
+	maxBudget := t.MaxBudgetUSD
+	t.mu.RUnlock()
+
+	var reason string
+	m.mu.Lock()
+	m.setCostLocked(t, cost)
+	if maxBudget > 0 && cost >= maxBudget {
+		reason = fmt.Sprintf("task budget of $%.2f reached ($%.2f spent)", maxBudget, cost)
+	} else {
+		reason = m.budgetExhaustedLocked(t.Project)
+	}
+	m.mu.Unlock()
+	if reason == "" {
+		return
+	}
+
+	t.mu.Lock()
+	first := t.budgetStop == ""
+	if first {
+		t.budgetStop = reason
+	}
+	pid := t.PID
+	t.mu.Unlock()
+	if !first {
+		return
+	}
+
+	slog.Warn("task over budget, stopping",
+		"task_id", t.ID,
+		"reason", reason,
+		"pid", pid)
+	t.SetProgress("stopping: " + reason)
+	m.record(t, "task.over_budget", "stopping: "+reason)
+	if pid > 0 {
+		go executor.GracefulKill(pid)
+	}
+}
+
+// budgetStopReason returns why t was stopped for going over budget, or "".
+func (t *Task) budgetStopReason() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.budgetStop
+}
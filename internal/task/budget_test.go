@@ -0,0 +1,223 @@
+package task
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+	"github.com/btouchard/herald/internal/store"
+)
+
+// spend records a finished task of project that cost usd, charged at at.
+func spend(m *Manager, project string, usd float64, at time.Time) *Task {
+	tk := m.Create(project, "earlier work", "", PriorityNormal, 30)
+	tk.SetStatus(StatusCompleted)
+	tk.mu.Lock()
+	tk.CostUSD = usd
+	tk.mu.Unlock()
+	m.mu.Lock()
+	m.chargeLocked(project, usd, at)
+	m.mu.Unlock()
+	return tk
+}
+
+func TestManager_Start_WhenProjectBudgetSpent_RejectsTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	m.SetBudgets(Budget{}, map[string]Budget{"proj": {DailyUSD: 10}})
+	spend(m, "proj", 10.5, time.Now())
+
+	var events []string
+	m.SetNotifyFunc(func(e TaskEvent) { events = append(events, e.Type) })
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	err := m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0)
+	require.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.Contains(t, err.Error(), "project proj daily budget of $10.00 is spent ($10.50 today)")
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Contains(t, snap.Error, "budget exhausted")
+	assert.Equal(t, []string{"task.failed"}, events)
+
+	// Other projects are not affected.
+	other := m.Create("other", "refactor", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), other, executor.Request{TaskID: other.ID}, 0))
+	waitDone(t, other)
+	assert.Equal(t, StatusCompleted, other.Snapshot().Status)
+}
+
+func TestManager_Start_WhenGlobalMonthlyBudgetSpent_RejectsTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	m.SetBudgets(Budget{MonthlyUSD: 100}, nil)
+	spend(m, "a", 60, time.Now())
+	spend(m, "b", 40, time.Now())
+
+	err := m.CheckBudget("c")
+	require.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.Contains(t, err.Error(), "global monthly budget of $100.00 is spent ($100.00 this month)")
+}
+
+func TestManager_Start_WhenBudgetSpentAndQueuePolicy_QueuesUntilReset(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	m.SetBudgets(Budget{DailyUSD: 5}, nil)
+	m.SetBudgetPolicy(BudgetQueue)
+	spend(m, "proj", 5, time.Now())
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusQueued, snap.Status)
+	assert.Contains(t, snap.Progress, "task queued until the budget resets: global daily budget")
+
+	// The spend moves to yesterday, as it does at midnight.
+	m.mu.Lock()
+	clear(m.spent)
+	m.chargeLocked("proj", 5, time.Now().AddDate(0, 0, -1))
+	m.mu.Unlock()
+	m.dispatch()
+
+	waitDone(t, tk)
+	assert.Equal(t, StatusCompleted, tk.Snapshot().Status)
+}
+
+func TestManager_Budgets_SumsSpendOfTheDayAndMonth(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	m.SetBudgets(Budget{DailyUSD: 20}, map[string]Budget{"proj": {DailyUSD: 10, MonthlyUSD: 100}})
+
+	day, month := budgetPeriods(time.Now())
+	spend(m, "proj", 2, time.Now())
+	spend(m, "proj", 3, month)
+	spend(m, "proj", 50, month.Add(-time.Second)) // last month
+	spend(m, "other", 4, time.Now())
+
+	wantToday := 2.0
+	if day.Equal(month) {
+		wantToday += 3
+	}
+
+	proj, global := m.Budgets("proj")
+	assert.True(t, proj.Capped())
+	assert.InDelta(t, wantToday, proj.SpentToday, 0.001)
+	assert.InDelta(t, 5, proj.SpentThisMonth, 0.001)
+	assert.InDelta(t, 20, global.DailyUSD, 0.001)
+	assert.InDelta(t, wantToday+4, global.SpentToday, 0.001)
+
+	none, _ := m.Budgets("other")
+	assert.False(t, none.Capped())
+}
+
+func TestManager_TrackCost_ChargesCostToTheDayItIsReported(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{}, 3, 2*time.Hour)
+	tk := m.Create("proj", "overnight work", "", PriorityNormal, 30)
+	tk.SetStatus(StatusRunning)
+	tk.mu.Lock()
+	tk.StartedAt = tk.StartedAt.AddDate(0, 0, -1) // started before midnight
+	tk.mu.Unlock()
+
+	m.trackCost(tk, 1)
+	m.trackCost(tk, 3)
+
+	proj, global := m.Budgets("proj")
+	assert.InDelta(t, 3, proj.SpentToday, 0.001)
+	assert.InDelta(t, 3, global.SpentToday, 0.001)
+	assert.InDelta(t, 3, tk.Snapshot().CostUSD, 0.001)
+}
+
+func TestManager_Restore_ChargesStoredCost(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now()
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-don00001", Type: "dispatched", Project: "proj", Prompt: "done today",
+		Status: "completed", Priority: "normal", CostUSD: 2,
+		CreatedAt: now, StartedAt: now, CompletedAt: now,
+	}))
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-don00002", Type: "dispatched", Project: "proj", Prompt: "done last month",
+		Status: "completed", Priority: "normal", CostUSD: 5,
+		CreatedAt: now.AddDate(0, -1, 0), StartedAt: now.AddDate(0, -1, 0), CompletedAt: now.AddDate(0, -1, 0),
+	}))
+
+	m := NewManager(&scriptedExecutor{}, 3, 2*time.Hour)
+	m.SetStore(db)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	proj, _ := m.Budgets("proj")
+	assert.InDelta(t, 2, proj.SpentToday, 0.001)
+	assert.InDelta(t, 2, proj.SpentThisMonth, 0.001)
+}
+
+func TestManager_ProgressFunc_WhenCostEvent_TracksCostWithEarlierAttempts(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.SetProgress("Using tool: Edit")
+
+	m.progressFunc(tk, 0.5)(executor.ProgressEvent{Kind: executor.ProgressUsage, CostUSD: 0.25})
+
+	snap := tk.Snapshot()
+	assert.InDelta(t, 0.75, snap.CostUSD, 0.0001)
+	assert.Equal(t, "Using tool: Edit", snap.Progress, "usage events are not progress")
+}
+
+// costlyExecutor runs a real process and reports a growing cost until the
+// process is stopped.
+type costlyExecutor struct {
+	costs []float64
+}
+
+func (c *costlyExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "costly"}
+}
+
+func (c *costlyExecutor) Execute(ctx context.Context, _ executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	cmd := exec.CommandContext(ctx, "sleep", "30")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
+	for _, cost := range c.costs {
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressUsage, CostUSD: cost})
+	}
+	err := cmd.Wait()
+	return &executor.Result{CostUSD: c.costs[len(c.costs)-1], Output: "partial"}, err
+}
+
+func TestManager_Run_WhenTaskBudgetReached_StopsGracefully(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&costlyExecutor{costs: []float64{0.40, 1.20}}, 3, 2*time.Hour)
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.MaxBudgetUSD = 1
+	tk.Retry = &retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Equal(t, "stopped over budget: task budget of $1.00 reached ($1.20 spent)", snap.Error)
+	assert.InDelta(t, 1.20, snap.CostUSD, 0.001)
+	require.Len(t, snap.Attempts, 1, "a task stopped over budget is not retried")
+}
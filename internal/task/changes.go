@@ -0,0 +1,152 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Changes sums up what a task changed in its project.
+type Changes struct {
+	Files   []string // paths relative to the project when known
+	Added   int      // lines added
+	Removed int      // lines removed
+}
+
+// ChangeMeasurer is an optional interface for a Workspace that can measure
+// what a task changed in its project, e.g. with git diff --numstat from
+// the commit it started from. ok is false when it cannot, e.g. outside a
+// git repository.
+type ChangeMeasurer interface {
+	Changes(ctx context.Context, t *Task) (c Changes, ok bool)
+}
+
+// FormatChanges describes changes to files, e.g. "4 files changed
+// (+127/-23)", or "" when there are none.
+func FormatChanges(files, added, removed int) string {
+	switch files {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("1 file changed (+%d/-%d)", added, removed)
+	}
+	return fmt.Sprintf("%d files changed (+%d/-%d)", files, added, removed)
+}
+
+// SetChanges records what the task changed in its project.
+func (t *Task) SetChanges(c Changes) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.FilesModified = c.Files
+	t.LinesAdded = c.Added
+	t.LinesRemoved = c.Removed
+}
+
+// addEdits adds the changes seen in a transcript to those of earlier
+// attempts.
+func (t *Task) addEdits(c Changes) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range c.Files {
+		if !slices.Contains(t.edits.Files, f) {
+			t.edits.Files = append(t.edits.Files, f)
+		}
+	}
+	t.edits.Added += c.Added
+	t.edits.Removed += c.Removed
+}
+
+// measureChanges records what t changed in its project: as the workspace
+// measures it, or else as seen in the file edits of its transcripts.
+func (m *Manager) measureChanges(t *Task) {
+	if cm, ok := m.workspace.(ChangeMeasurer); ok {
+		seen := t.warningCount()
+		c, ok := cm.Changes(context.Background(), t)
+		m.recordWarnings(t, seen)
+		if ok {
+			t.SetChanges(c)
+			return
+		}
+	}
+
+	t.mu.RLock()
+	edits := t.edits
+	t.mu.RUnlock()
+	if len(edits.Files) > 0 {
+		t.SetChanges(edits)
+	}
+}
+
+// editChanges returns the changes made by the successful Edit, MultiEdit
+// and Write tool calls of a transcript, with paths relative to root when
+// they are under it. Lines removed by Write are not known.
+func editChanges(transcript []executor.TranscriptEntry, root string) Changes {
+	failed := make(map[string]bool)
+	for _, e := range transcript {
+		if e.Kind == executor.EntryToolResult && e.IsError && e.ToolUseID != "" {
+			failed[e.ToolUseID] = true
+		}
+	}
+
+	var c Changes
+	for _, e := range transcript {
+		if e.Kind != executor.EntryToolUse || failed[e.ToolUseID] {
+			continue
+		}
+		switch e.Tool {
+		case "Edit", "MultiEdit", "Write":
+		default:
+			continue
+		}
+		var input struct {
+			FilePath  string `json:"file_path"`
+			OldString string `json:"old_string"`
+			NewString string `json:"new_string"`
+			Content   string `json:"content"`
+			Edits     []struct {
+				OldString string `json:"old_string"`
+				NewString string `json:"new_string"`
+			} `json:"edits"`
+		}
+		if err := json.Unmarshal([]byte(e.Input), &input); err != nil || input.FilePath == "" {
+			continue // truncated or malformed input
+		}
+
+		switch e.Tool {
+		case "Edit":
+			c.Added += countLines(input.NewString)
+			c.Removed += countLines(input.OldString)
+		case "MultiEdit":
+			for _, edit := range input.Edits {
+				c.Added += countLines(edit.NewString)
+				c.Removed += countLines(edit.OldString)
+			}
+		case "Write":
+			c.Added += countLines(input.Content)
+		}
+
+		path := input.FilePath
+		if rel, err := filepath.Rel(root, path); root != "" && err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		if !slices.Contains(c.Files, path) {
+			c.Files = append(c.Files, path)
+		}
+	}
+	return c
+}
+
+// countLines returns the number of lines of s, a last line without a
+// newline included.
+func countLines(s string) int {
+	n := strings.Count(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,123 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// editExecutor edits files through tool calls, the last of which fails.
+type editExecutor struct{}
+
+func (editExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "edit"}
+}
+
+func (editExecutor) Execute(context.Context, executor.Request, executor.ProgressFunc) (*executor.Result, error) {
+	return &executor.Result{Transcript: []executor.TranscriptEntry{
+		{Kind: executor.EntryToolUse, Tool: "Edit", ToolUseID: "1", Input: `{"file_path":"/projects/proj/internal/auth/auth.go","old_string":"a\nb","new_string":"a\nb\nc\n"}`},
+		{Kind: executor.EntryToolResult, Tool: "Edit", ToolUseID: "1"},
+		{Kind: executor.EntryToolUse, Tool: "Write", ToolUseID: "2", Input: `{"file_path":"/projects/proj/README.md","content":"# proj\n"}`},
+		{Kind: executor.EntryToolUse, Tool: "Read", ToolUseID: "3", Input: `{"file_path":"/projects/proj/go.mod"}`},
+		{Kind: executor.EntryToolUse, Tool: "Edit", ToolUseID: "4", Input: `{"file_path":"/projects/proj/main.go","old_string":"x","new_string":"y"}`},
+		{Kind: executor.EntryToolResult, Tool: "Edit", ToolUseID: "4", IsError: true},
+	}}, nil
+}
+
+// measuringWorkspace measures fixed changes, or none when ok is false.
+type measuringWorkspace struct {
+	fakeWorkspace
+	changes Changes
+	ok      bool
+}
+
+func (w *measuringWorkspace) Changes(context.Context, *Task) (Changes, bool) {
+	return w.changes, w.ok
+}
+
+func TestEditChanges_CountsSuccessfulEdits(t *testing.T) {
+	t.Parallel()
+
+	result, err := editExecutor{}.Execute(context.Background(), executor.Request{}, nil)
+	require.NoError(t, err)
+
+	c := editChanges(result.Transcript, "/projects/proj")
+	assert.Equal(t, []string{"internal/auth/auth.go", "README.md"}, c.Files)
+	assert.Equal(t, 4, c.Added)
+	assert.Equal(t, 2, c.Removed)
+}
+
+func TestEditChanges_WhenMultiEditOutsideRoot_KeepsAbsolutePath(t *testing.T) {
+	t.Parallel()
+
+	c := editChanges([]executor.TranscriptEntry{
+		{Kind: executor.EntryToolUse, Tool: "MultiEdit", Input: `{"file_path":"/etc/hosts","edits":[{"old_string":"a","new_string":"b\nc"},{"old_string":"d\n","new_string":""}]}`},
+		{Kind: executor.EntryToolUse, Tool: "Write", Input: `{"file_path":"/etc/trunc`},
+	}, "/projects/proj")
+	assert.Equal(t, []string{"/etc/hosts"}, c.Files)
+	assert.Equal(t, 2, c.Added)
+	assert.Equal(t, 2, c.Removed)
+}
+
+func TestFormatChanges(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, FormatChanges(0, 0, 0))
+	assert.Equal(t, "1 file changed (+3/-0)", FormatChanges(1, 3, 0))
+	assert.Equal(t, "4 files changed (+127/-23)", FormatChanges(4, 127, 23))
+}
+
+func TestManager_WhenWorkspaceMeasuresChanges_RecordsThem(t *testing.T) {
+	t.Parallel()
+
+	ws := &measuringWorkspace{fakeWorkspace: fakeWorkspace{dir: "/projects/proj"}, ok: true,
+		changes: Changes{Files: []string{"a.go", "b.go"}, Added: 10, Removed: 3}}
+	m := NewManager(editExecutor{}, 3, 2*time.Hour)
+	m.SetWorkspace(ws)
+
+	var events []TaskEvent
+	done := make(chan struct{})
+	m.SetNotifyFunc(func(e TaskEvent) {
+		events = append(events, e)
+		if e.Type == "task.completed" {
+			close(done)
+		}
+	})
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID, ProjectPath: "/projects/proj"}, 0))
+	<-done
+
+	snap := tk.Snapshot()
+	assert.Equal(t, []string{"a.go", "b.go"}, snap.FilesModified)
+	assert.Equal(t, 10, snap.LinesAdded)
+	assert.Equal(t, 3, snap.LinesRemoved)
+	assert.Equal(t, "task completed successfully, 2 files changed (+10/-3)", events[len(events)-1].Message)
+}
+
+func TestManager_WhenWorkspaceCannotMeasureChanges_FallsBackToEdits(t *testing.T) {
+	t.Parallel()
+
+	ws := &measuringWorkspace{fakeWorkspace: fakeWorkspace{dir: "/projects/proj"}}
+	m := NewManager(editExecutor{}, 3, 2*time.Hour)
+	m.SetWorkspace(ws)
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID, ProjectPath: "/projects/proj"}, 0))
+	<-tk.Done()
+
+	snap := tk.Snapshot()
+	assert.Equal(t, []string{"internal/auth/auth.go", "README.md"}, snap.FilesModified)
+	assert.Equal(t, 4, snap.LinesAdded)
+	assert.Equal(t, 2, snap.LinesRemoved)
+
+	tasks := m.List(Filter{File: "internal/auth"})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, tk.ID, tasks[0].ID)
+	assert.Empty(t, m.List(Filter{File: "internal/billing"}))
+}
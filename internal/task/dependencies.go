@@ -0,0 +1,186 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Policies for a task whose dependency fails or is cancelled.
+const (
+	DependencyCancel = "cancel" // cancel the task (default)
+	DependencyFail   = "fail"   // fail the task
+)
+
+// waitingTask is a task held until its dependencies complete, together
+// with everything needed to start it then.
+type waitingTask struct {
+	task          *Task
+	req           executor.Request
+	maxPerProject int
+}
+
+// dependencyState summarizes the dependencies of a task.
+type dependencyState int
+
+const (
+	dependenciesPending dependencyState = iota
+	dependenciesMet
+	dependenciesBroken
+)
+
+// checkDependenciesLocked reports where the dependencies of t stand and,
+// when they are broken, which one failed. An unknown dependency counts as
+// broken. Caller must hold m.mu.
+func (m *Manager) checkDependenciesLocked(t *Task) (dependencyState, string) {
+	state := dependenciesMet
+	for _, id := range t.DependsOn {
+		parent, ok := m.tasks[id]
+		if !ok {
+			return dependenciesBroken, fmt.Sprintf("dependency %s not found", id)
+		}
+		parent.mu.RLock()
+		status, errMsg := parent.Status, parent.Error
+		parent.mu.RUnlock()
+
+		switch status {
+		case StatusCompleted:
+		case StatusFailed, StatusCancelled, StatusLinked:
+			reason := fmt.Sprintf("dependency %s %s", id, status)
+			if errMsg != "" {
+				reason += ": " + errMsg
+			}
+			return dependenciesBroken, reason
+		default:
+			state = dependenciesPending
+		}
+	}
+	return state, ""
+}
+
+// validateDependenciesLocked rejects dependencies that cannot be met when
+// a task is started: unknown tasks, the task itself, linked tasks and
+// tasks already failed or cancelled. Caller must hold m.mu.
+func (m *Manager) validateDependenciesLocked(t *Task) error {
+	for _, id := range t.DependsOn {
+		if id == t.ID {
+			return fmt.Errorf("task %q cannot depend on itself", id)
+		}
+		parent, ok := m.tasks[id]
+		if !ok {
+			return fmt.Errorf("dependency %q not found", id)
+		}
+		if parent.Type == TypeLinked {
+			return fmt.Errorf("dependency %q is a linked session, not a task run by Herald", id)
+		}
+	}
+	if state, reason := m.checkDependenciesLocked(t); state == dependenciesBroken {
+		return fmt.Errorf("%s", reason)
+	}
+	return nil
+}
+
+// holdLocked parks t until its dependencies complete. Caller must hold m.mu.
+func (m *Manager) holdLocked(t *Task, req executor.Request, maxPerProject int) {
+	m.waiting[t.ID] = &waitingTask{task: t, req: req, maxPerProject: maxPerProject}
+	t.SetStatus(StatusWaiting)
+}
+
+// resolveDependencies starts the waiting tasks whose dependencies all
+// completed and applies the dependency failure policy to those with a
+// failed or cancelled dependency. It repeats until nothing changes so
+// that failures cascade down the dependency graph.
+func (m *Manager) resolveDependencies() {
+	for {
+		var ready, broken []*waitingTask
+		var reasons []string
+
+		m.mu.Lock()
+		for id, w := range m.waiting {
+			switch state, reason := m.checkDependenciesLocked(w.task); state {
+			case dependenciesMet:
+				delete(m.waiting, id)
+				ready = append(ready, w)
+			case dependenciesBroken:
+				delete(m.waiting, id)
+				broken = append(broken, w)
+				reasons = append(reasons, reason)
+			}
+		}
+		m.mu.Unlock()
+
+		if len(ready) == 0 && len(broken) == 0 {
+			return
+		}
+		for i, w := range broken {
+			m.abandon(w.task, reasons[i])
+		}
+		for _, w := range ready {
+			m.release(w)
+		}
+	}
+}
+
+// abandon ends a waiting task whose dependency failed, according to its
+// dependency failure policy.
+func (m *Manager) abandon(t *Task, reason string) {
+	t.mu.RLock()
+	policy, status := t.OnDependencyFailure, t.Status
+	t.mu.RUnlock()
+	if status != StatusWaiting {
+		return // cancelled meanwhile
+	}
+
+	t.SetError(reason)
+	if policy == DependencyFail {
+		t.SetStatus(StatusFailed)
+		m.persist(t)
+		slog.Warn("task failed on dependency", "task_id", t.ID, "reason", reason)
+		m.emit(t, "task.failed", reason)
+		return
+	}
+	t.SetStatus(StatusCancelled)
+	m.persist(t)
+	slog.Info("task cancelled on dependency", "task_id", t.ID, "reason", reason)
+	m.emit(t, "task.cancelled", reason)
+}
+
+// release starts a task whose dependencies completed.
+func (m *Manager) release(w *waitingTask) {
+	t := w.task
+	snap := t.Snapshot()
+	if snap.Status != StatusWaiting {
+		return // cancelled meanwhile
+	}
+
+	slog.Info("task dependencies completed",
+		"task_id", t.ID,
+		"depends_on", strings.Join(snap.DependsOn, ","))
+
+	m.inherit(t, &w.req)
+	t.SetStatus(StatusPending)
+	_ = m.schedule(t, w.req, w.maxPerProject) // a task rejected over budget is failed and notified
+}
+
+// inherit gives t the branch and session of its first dependency when
+// asked to and it has none of its own.
+func (m *Manager) inherit(t *Task, req *executor.Request) {
+	snap := t.Snapshot()
+	if (!snap.InheritBranch && !snap.InheritSession) || len(snap.DependsOn) == 0 {
+		return
+	}
+	parent, err := m.Get(snap.DependsOn[0])
+	if err != nil {
+		return
+	}
+	p := parent.Snapshot()
+	if snap.InheritBranch && snap.GitBranch == "" && p.GitBranch != "" {
+		t.SetGitBranch(p.GitBranch)
+	}
+	if snap.InheritSession && req.SessionID == "" && p.SessionID != "" {
+		t.SetSessionID(p.SessionID)
+		req.SessionID = p.SessionID
+	}
+}
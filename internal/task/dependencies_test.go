@@ -0,0 +1,214 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+)
+
+func TestManager_Start_WhenDependencyPending_WaitsThenRuns(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 2)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	events := make(chan TaskEvent, 32)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	parent := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	child := m.Create("proj", "migrate", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+
+	assert.Equal(t, StatusWaiting, child.Snapshot().Status)
+	e := <-events
+	assert.Equal(t, "task.waiting", e.Type)
+	assert.Equal(t, child.ID, e.TaskID)
+
+	require.NoError(t, m.Start(ctx, parent, executor.Request{TaskID: parent.ID}, 0))
+	<-parent.Done()
+
+	select {
+	case <-child.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("dependent task did not run")
+	}
+	assert.Equal(t, StatusCompleted, child.Snapshot().Status)
+	assert.Equal(t, parent.ID, (<-exec.reqs).TaskID)
+	assert.Equal(t, child.ID, (<-exec.reqs).TaskID)
+}
+
+func TestManager_Start_WhenDependencyFails_CancelsDependents(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockExecutor{delay: 50 * time.Millisecond, err: fmt.Errorf("tests failed")}
+	m := NewManager(mock, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	parent := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	child := m.Create("proj", "migrate", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	grandchild := m.Create("proj", "docs", "", PriorityNormal, 30)
+	grandchild.DependsOn = []string{child.ID}
+
+	require.NoError(t, m.Start(ctx, parent, executor.Request{TaskID: parent.ID}, 0))
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+	require.NoError(t, m.Start(ctx, grandchild, executor.Request{TaskID: grandchild.ID}, 0))
+
+	select {
+	case <-grandchild.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("dependent tasks were not cancelled")
+	}
+
+	snap := child.Snapshot()
+	assert.Equal(t, StatusCancelled, snap.Status)
+	assert.Contains(t, snap.Error, parent.ID+" failed")
+	assert.Contains(t, snap.Error, "tests failed")
+
+	snap = grandchild.Snapshot()
+	assert.Equal(t, StatusCancelled, snap.Status)
+	assert.Contains(t, snap.Error, child.ID+" cancelled")
+}
+
+func TestManager_Start_WhenDependencyFailsWithFailPolicy_FailsDependent(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	parent := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	child := m.Create("proj", "migrate", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	child.OnDependencyFailure = DependencyFail
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+
+	require.NoError(t, m.Cancel(parent.ID))
+
+	select {
+	case <-child.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("dependent task was not failed")
+	}
+	snap := child.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Contains(t, snap.Error, parent.ID+" cancelled")
+}
+
+func TestManager_Start_WhenInheriting_UsesBranchAndSessionOfDependency(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	parent := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	parent.SetGitBranch("herald/refactor")
+	parent.SetSessionID("ses_parent")
+	parent.SetStatus(StatusCompleted)
+
+	child := m.Create("proj", "migrate", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	child.InheritBranch = true
+	child.InheritSession = true
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+
+	select {
+	case req := <-exec.reqs:
+		assert.Equal(t, "ses_parent", req.SessionID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("dependent task did not run")
+	}
+	<-child.Done()
+	assert.Equal(t, "herald/refactor", child.Snapshot().GitBranch)
+}
+
+func TestManager_Start_WhenDependencyInvalid_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	failed := m.Create("proj", "broken", "", PriorityNormal, 30)
+	failed.SetStatus(StatusFailed)
+	linked := m.Create("proj", "external", "", PriorityNormal, 0)
+	linked.Type = TypeLinked
+	linked.SetStatus(StatusLinked)
+
+	tests := []struct {
+		name      string
+		dependsOn func(id string) []string
+		wantErr   string
+	}{
+		{"unknown", func(string) []string { return []string{"herald-missing0"} }, "not found"},
+		{"itself", func(id string) []string { return []string{id} }, "cannot depend on itself"},
+		{"failed", func(string) []string { return []string{failed.ID} }, "failed"},
+		{"linked", func(string) []string { return []string{linked.ID} }, "linked session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tk := m.Create("proj", "child", "", PriorityNormal, 30)
+			tk.DependsOn = tt.dependsOn(tk.ID)
+
+			err := m.Start(ctx, tk, executor.Request{TaskID: tk.ID}, 0)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Equal(t, StatusPending, tk.Snapshot().Status)
+		})
+	}
+}
+
+func TestManager_Cancel_WhenWaiting_CancelsTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	ctx := context.Background()
+
+	parent := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	child := m.Create("proj", "migrate", "", PriorityNormal, 30)
+	child.DependsOn = []string{parent.ID}
+	require.NoError(t, m.Start(ctx, child, executor.Request{TaskID: child.ID}, 0))
+
+	require.NoError(t, m.Cancel(child.ID))
+	assert.Equal(t, StatusCancelled, child.Snapshot().Status)
+
+	// Completing the dependency no longer starts the cancelled task.
+	parent.SetStatus(StatusCompleted)
+	m.dispatch()
+	assert.Equal(t, StatusCancelled, child.Snapshot().Status)
+}
+
+func TestManager_Restore_WhenWaiting_RunsOnceDependencyCompleted(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-parent01", Type: "dispatched", Project: "proj", Prompt: "refactor",
+		Status: "completed", Priority: "normal", CreatedAt: now, CompletedAt: now,
+	}))
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-child001", Type: "dispatched", Project: "proj", Prompt: "migrate",
+		Status: "waiting", Priority: "normal", DependsOn: []string{"herald-parent01"}, CreatedAt: now,
+	}))
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	select {
+	case req := <-exec.reqs:
+		assert.Equal(t, "herald-child001", req.TaskID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting task was not released after restore")
+	}
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/btouchard/herald/internal/store"
+)
+
+// eventLevel returns the level events of eventType are recorded at.
+func eventLevel(eventType string) string {
+	switch eventType {
+	case "task.failed", "task.revert_failed":
+		return store.LevelError
+	case "task.cancelled", "task.permission", "task.awaiting_approval", "task.preempted",
+		"task.timeout_warning", "task.warning", "task.attempt_failed", "task.over_budget":
+		return store.LevelWarn
+	case "task.progress", "task.tool_use", "task.tool_result":
+		return store.LevelDebug
+	default:
+		return store.LevelInfo
+	}
+}
+
+// record appends an event to the timeline of t in the store, which
+// get_logs pages through.
+func (m *Manager) record(t *Task, eventType, message string) {
+	if m.store == nil {
+		return
+	}
+	e := &store.TaskEvent{
+		TaskID:    t.ID,
+		EventType: eventType,
+		Level:     eventLevel(eventType),
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	if err := m.store.AddEvent(e); err != nil {
+		slog.Warn("failed to record task event", "task_id", t.ID, "type", eventType, "error", err)
+	}
+}
+
+// recordWarnings records the warnings of t past the first seen, those
+// added since it had seen warnings, and returns them.
+func (m *Manager) recordWarnings(t *Task, seen int) []string {
+	t.mu.RLock()
+	var added []string
+	if len(t.Warnings) > seen {
+		added = append(added, t.Warnings[seen:]...)
+	}
+	t.mu.RUnlock()
+	for _, w := range added {
+		m.record(t, "task.warning", w)
+	}
+	return added
+}
+
+// warningCount returns how many warnings t has.
+func (t *Task) warningCount() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.Warnings)
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+	"github.com/btouchard/herald/internal/store"
+)
+
+func TestManager_RecordsLifecycleEvents(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	m := NewManager(&mockExecutor{delay: 10 * time.Millisecond}, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+
+	var events []store.TaskEvent
+	require.Eventually(t, func() bool {
+		var err error
+		events, err = db.ListEvents(store.EventFilter{TaskID: tk.ID})
+		return err == nil && len(events) > 0 && events[len(events)-1].EventType == "task.completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var types []string
+	for _, e := range events {
+		types = append(types, e.EventType)
+	}
+	assert.Equal(t, []string{"task.created", "task.started", "task.progress", "task.progress", "task.completed"}, types)
+	assert.Equal(t, store.LevelDebug, events[2].Level)
+	assert.Equal(t, store.LevelInfo, events[4].Level)
+}
+
+func TestManager_RecordsRetriedAttemptsAndFailures(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	m := NewManager(&mockExecutor{err: errors.New("boom")}, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 2, Backoff: time.Millisecond}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+
+	require.Eventually(t, func() bool {
+		events, err := db.ListEvents(store.EventFilter{TaskID: tk.ID, Levels: store.LevelsFrom(store.LevelWarn)})
+		return err == nil && len(events) == 2 &&
+			events[0].EventType == "task.attempt_failed" && events[0].Level == store.LevelWarn &&
+			events[1].EventType == "task.failed" && events[1].Level == store.LevelError
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestEventLevel(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, store.LevelError, eventLevel("task.failed"))
+	assert.Equal(t, store.LevelWarn, eventLevel("task.timeout_warning"))
+	assert.Equal(t, store.LevelDebug, eventLevel("task.tool_use"))
+	assert.Equal(t, store.LevelInfo, eventLevel("task.started"))
+}
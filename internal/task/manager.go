@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,11 +14,15 @@ import (
 
 // TaskEvent represents a task state change for notification dispatch.
 type TaskEvent struct {
-	Type         string // "task.started", "task.progress", "task.completed", "task.failed", "task.cancelled"
+	Type         string // "task.awaiting_approval", "task.waiting", "task.queued", "task.started", "task.progress", "task.permission", "task.timeout_warning", "task.paused", "task.preempted", "task.resumed", "task.completed", "task.failed", "task.cancelled"
 	TaskID       string
 	Project      string
 	Message      string
 	MCPSessionID string
+
+	// Progress is what a task.progress event reports, when an executor
+	// reported it.
+	Progress *executor.ProgressEvent
 }
 
 // NotifyFunc is called when a task lifecycle event occurs.
@@ -32,7 +38,36 @@ type Manager struct {
 	maxTimeout    time.Duration
 	maxOutputSize int
 	cancelFuncs   map[string]context.CancelFunc
+	running       map[string]chan struct{} // closed when the task's goroutine exits
+	reverts       map[string]*revertRequest
+	waiting       map[string]*waitingTask // tasks held until their dependencies complete
+	inboxes       map[string]chan string  // messages waiting to be delivered to running tasks
+	queue         taskQueue
 	onNotify      NotifyFunc
+
+	store             Store
+	interruptedPolicy string
+	workspace         Workspace
+
+	budget         Budget
+	projectBudgets map[string]Budget
+	budgetPolicy   string
+	budgetReset    *time.Timer           // dispatches the queue when the daily budgets reset
+	spent          map[budgetKey]float64 // cost charged per project and period
+
+	permissionURL     string
+	permissionTimeout time.Duration
+	permissionTokens  map[string]string             // token → ID of the task it was issued to
+	permissions       map[string]*pendingPermission // request ID → request waiting for an answer
+
+	approvalPolicy   ApprovalPolicy
+	projectApprovals map[string]ApprovalPolicy
+	approvals        map[string]*waitingTask // tasks held until someone approves them
+	approvalLink     ApprovalLinkFunc
+
+	pausedHoldSlots bool          // paused tasks count against the concurrency limits
+	preemptPriority Priority      // lowest priority that preempts running tasks, empty for none
+	timeoutWarning  time.Duration // warn running tasks this long before their timeout, 0 for never
 }
 
 // NewManager creates a new task Manager.
@@ -50,6 +85,17 @@ func NewManager(exec executor.Executor, maxConcurrent int, maxTimeout time.Durat
 		maxTimeout:    maxTimeout,
 		maxOutputSize: 1048576, // 1MB default
 		cancelFuncs:   make(map[string]context.CancelFunc),
+		running:       make(map[string]chan struct{}),
+		reverts:       make(map[string]*revertRequest),
+		waiting:       make(map[string]*waitingTask),
+		inboxes:       make(map[string]chan string),
+		spent:         make(map[budgetKey]float64),
+
+		permissionTimeout: defaultPermissionTimeout,
+		permissionTokens:  make(map[string]string),
+		permissions:       make(map[string]*pendingPermission),
+
+		approvals: make(map[string]*waitingTask),
 	}
 }
 
@@ -71,6 +117,9 @@ func (m *Manager) Create(project, prompt, context string, priority Priority, tim
 	m.tasks[t.ID] = t
 	m.mu.Unlock()
 
+	m.persistNew(t)
+	m.record(t, "task.created", fmt.Sprintf("task created in %s with %s priority", project, priority))
+
 	slog.Info("task created",
 		"task_id", t.ID,
 		"project", project,
@@ -86,6 +135,8 @@ func (m *Manager) Register(t *Task) {
 	m.tasks[t.ID] = t
 	m.mu.Unlock()
 
+	m.persistNew(t)
+
 	slog.Info("task registered",
 		"task_id", t.ID,
 		"type", string(t.Type),
@@ -139,6 +190,11 @@ func (m *Manager) List(filter Filter) []TaskSnapshot {
 		if !filter.Since.IsZero() && snap.CreatedAt.Before(filter.Since) {
 			continue
 		}
+		if filter.File != "" && !slices.ContainsFunc(snap.FilesModified, func(f string) bool {
+			return strings.Contains(f, filter.File)
+		}) {
+			continue
+		}
 
 		results = append(results, snap)
 	}
@@ -165,145 +221,338 @@ type Filter struct {
 	Project string
 	Limit   int
 	Since   time.Time
+	File    string // only tasks that modified a file whose path contains it
 }
 
 // Start begins executing a task asynchronously.
-// Returns an error if the global or per-project concurrency limit is reached.
+// A task the approval policy applies to is accepted in
+// StatusAwaitingApproval and started once it is approved.
+// A task with dependencies (DependsOn) that have not all completed yet is
+// accepted in StatusWaiting and started once they have.
+// When the global or per-project concurrency limit is reached, the task is
+// accepted in StatusQueued and held in the priority queue until a slot frees up.
 // Uses background context so tasks survive after the MCP request completes.
 func (m *Manager) Start(_ context.Context, t *Task, req executor.Request, maxPerProject int) error {
-	m.mu.RLock()
-	globalRunning := 0
-	projectRunning := 0
-	for _, existing := range m.tasks {
-		existing.mu.RLock()
-		if existing.Status == StatusRunning {
-			globalRunning++
-			if existing.Project == t.Project {
-				projectRunning++
-			}
+	t.mu.RLock()
+	status := t.Status
+	dependsOn := t.DependsOn
+	t.mu.RUnlock()
+	if status != StatusPending {
+		return fmt.Errorf("task %q is already %s", t.ID, status)
+	}
+
+	if reason := m.approvalReason(t); reason != "" {
+		return m.awaitApproval(t, req, maxPerProject, reason)
+	}
+
+	if len(dependsOn) > 0 {
+		m.mu.Lock()
+		if err := m.validateDependenciesLocked(t); err != nil {
+			m.mu.Unlock()
+			return err
 		}
-		existing.mu.RUnlock()
+		state, _ := m.checkDependenciesLocked(t)
+		if state == dependenciesPending {
+			m.holdLocked(t, req, maxPerProject)
+			m.mu.Unlock()
+
+			m.persist(t)
+			slog.Info("task waiting for dependencies",
+				"task_id", t.ID,
+				"depends_on", strings.Join(dependsOn, ","))
+			m.emit(t, "task.waiting", fmt.Sprintf("task waiting for %s", strings.Join(dependsOn, ", ")))
+			return nil
+		}
+		m.mu.Unlock()
+		m.inherit(t, &req)
 	}
-	m.mu.RUnlock()
 
-	if globalRunning >= m.maxConcurrent {
-		return fmt.Errorf("global concurrency limit reached (%d/%d)", globalRunning, m.maxConcurrent)
+	return m.schedule(t, req, maxPerProject)
+}
+
+// schedule launches t right away when a slot is free, or when it can
+// preempt a running task of lower priority, or queues it.
+// When a budget of the task is spent, t is failed with an error wrapping
+// ErrBudgetExhausted, or queued until the budget resets with BudgetQueue.
+func (m *Manager) schedule(t *Task, req executor.Request, maxPerProject int) error {
+	m.mu.Lock()
+	overBudget := m.budgetExhaustedLocked(t.Project)
+	if overBudget != "" && m.budgetPolicy != BudgetQueue {
+		m.mu.Unlock()
+		err := fmt.Errorf("%w: %s", ErrBudgetExhausted, overBudget)
+		t.SetError(err.Error())
+		t.SetStatus(StatusFailed)
+		m.persist(t)
+		slog.Warn("task rejected over budget", "task_id", t.ID, "reason", overBudget)
+		m.emit(t, "task.failed", err.Error())
+		return err
 	}
-	if maxPerProject > 0 && projectRunning >= maxPerProject {
-		return fmt.Errorf("project %q concurrency limit reached (%d/%d)", t.Project, projectRunning, maxPerProject)
+	if overBudget == "" && m.hasSlotLocked(t.Project, maxPerProject) && !m.checkoutBusyLocked(t) {
+		m.launchLocked(t, req, maxPerProject, "task execution started")
+		m.mu.Unlock()
+		return nil
 	}
+	if overBudget == "" {
+		if victim := m.preemptLocked(t, maxPerProject); victim != nil {
+			m.launchLocked(t, req, maxPerProject, fmt.Sprintf("task execution started, preempting %s", victim.ID))
+			m.mu.Unlock()
 
-	timeout := time.Duration(t.TimeoutMinutes) * time.Minute
-	if timeout > m.maxTimeout {
-		slog.Warn("task timeout clamped to max",
-			"task_id", t.ID,
-			"requested", timeout,
-			"max", m.maxTimeout)
-		timeout = m.maxTimeout
+			m.notifyPreempted(victim, t)
+			return nil
+		}
 	}
-	taskCtx, cancel := context.WithTimeout(context.Background(), timeout)
 
-	m.mu.Lock()
-	m.cancelFuncs[t.ID] = cancel
+	m.queue.push(t, req, maxPerProject)
+	t.SetStatus(StatusQueued)
+	pos, total := m.queue.position(t.ID), m.queue.len()
+	if overBudget != "" {
+		m.armBudgetResetLocked()
+	}
 	m.mu.Unlock()
 
-	t.SetStatus(StatusRunning)
+	message := fmt.Sprintf("task queued (position %d of %d)", pos, total)
+	if overBudget != "" {
+		message = fmt.Sprintf("task queued until the budget resets: %s", overBudget)
+		t.SetProgress(message)
+	}
+	m.persist(t)
 
-	go m.run(taskCtx, cancel, t, req)
+	slog.Info("task queued",
+		"task_id", t.ID,
+		"project", t.Project,
+		"priority", string(t.Priority),
+		"position", pos,
+		"over_budget", overBudget != "")
+	m.emit(t, "task.queued", message)
 	return nil
 }
 
-func (m *Manager) run(ctx context.Context, cancel context.CancelFunc, t *Task, req executor.Request) {
-	defer cancel()
-	defer func() {
-		if r := recover(); r != nil {
-			slog.Error("task panicked",
-				"task_id", t.ID,
-				"panic", r)
-			t.SetError(fmt.Sprintf("internal panic: %v", r))
-			t.SetStatus(StatusFailed)
-			m.emit(t, "task.failed", fmt.Sprintf("internal panic: %v", r))
+// hasSlotLocked reports whether a task on project may start right now.
+// Caller must hold m.mu.
+func (m *Manager) hasSlotLocked(project string, maxPerProject int) bool {
+	globalRunning, projectRunning := m.slotsInUseLocked(project)
+	if globalRunning >= m.maxConcurrent {
+		return false
+	}
+	return maxPerProject <= 0 || projectRunning < maxPerProject
+}
+
+// slotsInUseLocked counts the tasks holding a slot, in total and on
+// project. Paused tasks hold one only with pausedHoldSlots, and preempted
+// tasks never do. Caller must hold m.mu.
+func (m *Manager) slotsInUseLocked(project string) (global, inProject int) {
+	for _, existing := range m.tasks {
+		existing.mu.RLock()
+		if existing.Status == StatusRunning ||
+			(m.pausedHoldSlots && existing.Status == StatusPaused && existing.preemptionLocked() == nil) {
+			global++
+			if existing.Project == project {
+				inProject++
+			}
 		}
+		existing.mu.RUnlock()
+	}
+	return global, inProject
+}
+
+// launchLocked marks the task running and spawns its execution goroutine.
+// maxPerProject is kept for the task to resume within its project's limit
+// once preempted. Caller must hold m.mu.
+func (m *Manager) launchLocked(t *Task, req executor.Request, maxPerProject int, startMessage string) {
+	timeout := m.timeoutOf(t)
+	taskCtx, cancel := context.WithCancel(context.Background())
+
+	m.cancelFuncs[t.ID] = cancel
+	m.openInboxLocked(t, &req)
+	m.openPermissionsLocked(t, &req)
+	t.mu.Lock()
+	t.maxPerProject = maxPerProject
+	t.mu.Unlock()
+	t.SetStatus(StatusRunning)
+
+	untrack := m.trackLocked(t.ID)
+	go func() {
+		defer untrack()
+		defer m.dropInbox(t)
+		defer m.closePermissions(t)
+		m.run(taskCtx, cancel, t, req, timeout, startMessage)
 	}()
+}
 
-	m.emit(t, "task.started", "task execution started")
+// dispatch releases waiting tasks whose dependencies are settled, resumes
+// preempted tasks whose preempting task ended, then promotes queued tasks
+// into free slots, highest priority first. A queued task no slot is free
+// for may still preempt a running task of lower priority.
+// A task whose project is at its limit, whose project checkout is busy, or
+// over budget, is skipped so it does not block eligible tasks of other
+// projects behind it.
+func (m *Manager) dispatch() {
+	m.resolveDependencies()
+	m.resumePreempted()
 
-	onProgress := func(eventType, message string) {
-		t.SetProgress(message)
-		if eventType == "started" {
-			var pid int
-			_, _ = fmt.Sscanf(message, "PID %d", &pid)
-			if pid > 0 {
-				t.SetPID(pid)
+	m.mu.Lock()
+	var preempted, by []*Task
+	for _, qt := range m.queue.snapshot() {
+		if m.checkoutBusyLocked(qt.task) {
+			continue
+		}
+		if m.budgetExhaustedLocked(qt.task.Project) != "" {
+			m.armBudgetResetLocked()
+			continue
+		}
+
+		waited := time.Since(qt.task.CreatedAt).Round(time.Second)
+		message := fmt.Sprintf("task promoted from queue after %s, execution started", waited)
+		if !m.hasSlotLocked(qt.task.Project, qt.maxPerProject) {
+			victim := m.preemptLocked(qt.task, qt.maxPerProject)
+			if victim == nil {
+				continue
 			}
+			preempted, by = append(preempted, victim), append(by, qt.task)
+			message += ", preempting " + victim.ID
 		}
-		m.emit(t, "task.progress", message)
+
+		m.queue.remove(qt.task.ID)
+		slog.Info("queued task promoted",
+			"task_id", qt.task.ID,
+			"project", qt.task.Project,
+			"waited", waited)
+		m.launchLocked(qt.task, qt.req, qt.maxPerProject, message)
+	}
+	m.mu.Unlock()
+
+	for i, victim := range preempted {
+		m.notifyPreempted(victim, by[i])
 	}
+}
+
+// QueuePosition returns the 1-based position of a queued task and the
+// current queue length. Position is 0 if the task is not queued.
+func (m *Manager) QueuePosition(id string) (int, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.queue.position(id), m.queue.len()
+}
+
+// run executes t, attempt after attempt while its retry policy allows it.
+// timeout applies to each attempt.
+func (m *Manager) run(ctx context.Context, cancel context.CancelFunc, t *Task, req executor.Request, timeout time.Duration, startMessage string) {
+	defer m.dispatch()
+	defer m.persist(t)
+	defer cancel()
+	defer m.recoverPanic(t)
 
-	result, err := m.executor.Execute(ctx, req, onProgress)
+	m.persist(t)
+	m.emit(t, "task.started", startMessage)
 
+	if err := m.prepareWorkspace(ctx, t, &req); err != nil {
+		// Whatever Prepare did before failing is undone by the release.
+		m.finish(ctx, t, nil, fmt.Errorf("preparing workspace: %w", err))
+		return
+	}
+
+	m.execute(ctx, t, req, timeout)
+}
+
+// recoverPanic fails t if its execution goroutine panicked. It must be
+// deferred directly.
+func (m *Manager) recoverPanic(t *Task) {
+	if r := recover(); r != nil {
+		slog.Error("task panicked",
+			"task_id", t.ID,
+			"panic", r)
+		t.SetError(fmt.Sprintf("internal panic: %v", r))
+		t.SetStatus(StatusFailed)
+		m.emit(t, "task.failed", fmt.Sprintf("internal panic: %v", r))
+	}
+}
+
+// finish records the executor outcome and what t changed in its project,
+// moves t to its terminal state and releases its workspace. The outcome is
+// notified last, along with whatever went wrong releasing the workspace.
+func (m *Manager) finish(ctx context.Context, t *Task, result *executor.Result, err error) {
+	m.measureChanges(t)
 	if result != nil {
-		t.SetCost(result.CostUSD)
+		m.setCost(t, result.CostUSD)
 		t.SetTurns(result.Turns)
 		t.SetSessionID(result.SessionID)
 		t.AppendOutput(result.Output)
 	}
 
+	eventType, msg := conclude(ctx, t, err)
+	if problems := m.releaseWorkspace(t); len(problems) > 0 {
+		msg += "; " + strings.Join(problems, "; ")
+	}
+	m.emit(t, eventType, msg)
+}
+
+// conclude moves t to its terminal state from the executor error, and
+// returns the event type and message to notify it with.
+func conclude(ctx context.Context, t *Task, err error) (string, string) {
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			t.SetError("task timed out")
 			t.SetStatus(StatusFailed)
 			slog.Warn("task timed out", "task_id", t.ID)
-			m.emit(t, "task.failed", "task timed out")
-			return
+			return "task.failed", "task timed out"
 		}
 		if ctx.Err() == context.Canceled {
 			t.SetStatus(StatusCancelled)
-			m.emit(t, "task.cancelled", "task cancelled")
-			return
+			return "task.cancelled", "task cancelled"
 		}
 		t.SetError(err.Error())
 		t.SetStatus(StatusFailed)
-		m.emit(t, "task.failed", err.Error())
-		return
+		return "task.failed", err.Error()
 	}
 
 	t.SetStatus(StatusCompleted)
-	m.emit(t, "task.completed", "task completed successfully")
+	msg := "task completed successfully"
+	t.mu.RLock()
+	changes := FormatChanges(len(t.FilesModified), t.LinesAdded, t.LinesRemoved)
+	t.mu.RUnlock()
+	if changes != "" {
+		msg += ", " + changes
+	}
+	return "task.completed", msg
 }
 
 // emit sends a task event to the notify callback if one is set.
 func (m *Manager) emit(t *Task, eventType, message string) {
+	m.record(t, eventType, message)
+	m.notify(t, TaskEvent{Type: eventType, Message: message})
+}
+
+// notify completes e with the task, project and MCP session of t and sends
+// it to the notify callback if one is set.
+func (m *Manager) notify(t *Task, e TaskEvent) {
 	if m.onNotify == nil {
 		return
 	}
 	t.mu.RLock()
-	mcpSess := t.MCPSessionID
-	proj := t.Project
+	e.MCPSessionID = t.MCPSessionID
+	e.Project = t.Project
 	t.mu.RUnlock()
 
-	m.onNotify(TaskEvent{
-		Type:         eventType,
-		TaskID:       t.ID,
-		Project:      proj,
-		Message:      message,
-		MCPSessionID: mcpSess,
-	})
+	e.TaskID = t.ID
+	m.onNotify(e)
 }
 
-// Cancel stops a running task.
+// Cancel stops a running task or removes a queued one from the queue.
 func (m *Manager) Cancel(id string) error {
-	m.mu.RLock()
+	m.mu.Lock()
 	t, ok := m.tasks[id]
 	cancelFn := m.cancelFuncs[id]
-	m.mu.RUnlock()
+	m.queue.remove(id)
+	delete(m.waiting, id)
+	delete(m.approvals, id)
+	m.mu.Unlock()
 
 	if !ok {
 		return fmt.Errorf("task %q not found", id)
 	}
 
 	if t.IsTerminal() {
-		return fmt.Errorf("task %q is already %s", id, t.Status)
+		return fmt.Errorf("task %q is already %s", id, t.Snapshot().Status)
 	}
 
 	slog.Info("cancelling task", "task_id", id)
@@ -314,14 +563,21 @@ func (m *Manager) Cancel(id string) error {
 
 	t.mu.RLock()
 	pid := t.PID
+	paused := t.Status == StatusPaused
 	t.mu.RUnlock()
 
 	if pid > 0 {
+		if paused {
+			// A stopped process only acts on SIGTERM once continued.
+			_ = executor.ResumeGroup(pid)
+		}
 		go executor.GracefulKill(pid)
 	}
 
 	t.SetStatus(StatusCancelled)
+	m.persist(t)
 	m.emit(t, "task.cancelled", "task cancelled by user")
+	m.dispatch()
 	return nil
 }
 
@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,8 +28,8 @@ func (m *mockExecutor) Capabilities() executor.Capabilities {
 
 func (m *mockExecutor) Execute(ctx context.Context, req executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
 	if onProgress != nil {
-		onProgress("started", fmt.Sprintf("PID %d", 12345))
-		onProgress("progress", "Working on it...")
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: 12345})
+		onProgress(executor.ProgressEvent{Kind: executor.ProgressText, Text: "Working on it..."})
 	}
 
 	select {
@@ -252,7 +253,7 @@ func TestManager_RunningCount(t *testing.T) {
 	assert.Equal(t, 0, m.RunningCount())
 }
 
-func TestManager_Start_WhenGlobalLimitReached_ReturnsError(t *testing.T) {
+func TestManager_Start_WhenGlobalLimitReached_QueuesTask(t *testing.T) {
 	t.Parallel()
 
 	mock := &mockExecutor{delay: 10 * time.Second}
@@ -269,21 +270,33 @@ func TestManager_Start_WhenGlobalLimitReached_ReturnsError(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 	assert.Equal(t, 2, m.RunningCount())
 
-	// Third task should be rejected
+	// Third task should be accepted but queued
 	t3 := m.Create("proj", "task3", "", PriorityNormal, 30)
-	err := m.Start(ctx, t3, executor.Request{TaskID: t3.ID, Prompt: "task3"}, 0)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "global concurrency limit reached")
-	assert.Contains(t, err.Error(), "2/2")
+	require.NoError(t, m.Start(ctx, t3, executor.Request{TaskID: t3.ID, Prompt: "task3"}, 0))
+	assert.Equal(t, StatusQueued, t3.Snapshot().Status)
+
+	pos, total := m.QueuePosition(t3.ID)
+	assert.Equal(t, 1, pos)
+	assert.Equal(t, 1, total)
+
+	// Freeing a slot promotes the queued task
+	require.NoError(t, m.Cancel(t1.ID))
+	assert.Eventually(t, func() bool {
+		return t3.Snapshot().Status == StatusRunning
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pos, _ = m.QueuePosition(t3.ID)
+	assert.Equal(t, 0, pos)
 
 	// Cleanup
-	_ = m.Cancel(t1.ID)
 	_ = m.Cancel(t2.ID)
+	_ = m.Cancel(t3.ID)
 	<-t1.Done()
 	<-t2.Done()
+	<-t3.Done()
 }
 
-func TestManager_Start_WhenProjectLimitReached_ReturnsError(t *testing.T) {
+func TestManager_Start_WhenProjectLimitReached_QueuesTask(t *testing.T) {
 	t.Parallel()
 
 	mock := &mockExecutor{delay: 10 * time.Second}
@@ -296,24 +309,147 @@ func TestManager_Start_WhenProjectLimitReached_ReturnsError(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 
-	// Second task on same project should be rejected
+	// Second task on same project should be queued
 	t2 := m.Create("alpha", "task2", "", PriorityNormal, 30)
-	err := m.Start(ctx, t2, executor.Request{TaskID: t2.ID, Prompt: "task2"}, 1)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "project")
-	assert.Contains(t, err.Error(), "alpha")
+	require.NoError(t, m.Start(ctx, t2, executor.Request{TaskID: t2.ID, Prompt: "task2"}, 1))
+	assert.Equal(t, StatusQueued, t2.Snapshot().Status)
 
-	// Different project should still work
+	// Different project should still start immediately
 	t3 := m.Create("beta", "task3", "", PriorityNormal, 30)
 	require.NoError(t, m.Start(ctx, t3, executor.Request{TaskID: t3.ID, Prompt: "task3"}, 1))
+	assert.Equal(t, StatusRunning, t3.Snapshot().Status)
 
 	// Cleanup
 	_ = m.Cancel(t1.ID)
+	_ = m.Cancel(t2.ID)
 	_ = m.Cancel(t3.ID)
 	<-t1.Done()
+	<-t2.Done()
 	<-t3.Done()
 }
 
+func TestManager_Dispatch_OrdersByPriorityThenCreation(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockExecutor{delay: 10 * time.Second}
+	m := NewManager(mock, 1, 2*time.Hour)
+	ctx := context.Background()
+
+	blocker := m.Create("proj", "blocker", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, blocker, executor.Request{TaskID: blocker.ID}, 0))
+
+	low := m.Create("proj", "low", "", PriorityLow, 30)
+	normal1 := m.Create("proj", "normal1", "", PriorityNormal, 30)
+	normal2 := m.Create("proj", "normal2", "", PriorityNormal, 30)
+	urgent := m.Create("proj", "urgent", "", PriorityUrgent, 30)
+	for _, tk := range []*Task{low, normal1, normal2, urgent} {
+		require.NoError(t, m.Start(ctx, tk, executor.Request{TaskID: tk.ID}, 0))
+	}
+
+	expected := []*Task{urgent, normal1, normal2, low}
+	for i, tk := range expected {
+		pos, total := m.QueuePosition(tk.ID)
+		assert.Equal(t, i+1, pos, "task %s", tk.Prompt)
+		assert.Equal(t, 4, total)
+	}
+
+	// Each cancellation frees the single slot for the next task in order
+	running := blocker
+	for _, next := range expected {
+		require.NoError(t, m.Cancel(running.ID))
+		assert.Eventually(t, func() bool {
+			return next.Snapshot().Status == StatusRunning
+		}, 2*time.Second, 10*time.Millisecond, "expected %s to be promoted", next.Prompt)
+		running = next
+	}
+	require.NoError(t, m.Cancel(running.ID))
+}
+
+func TestManager_Dispatch_SkipsTasksBlockedByProjectLimit(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockExecutor{delay: 10 * time.Second}
+	m := NewManager(mock, 2, 2*time.Hour)
+	ctx := context.Background()
+
+	a1 := m.Create("alpha", "a1", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, a1, executor.Request{TaskID: a1.ID}, 1))
+	b1 := m.Create("beta", "b1", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, b1, executor.Request{TaskID: b1.ID}, 1))
+
+	// Queue an urgent alpha task ahead of a normal beta task
+	a2 := m.Create("alpha", "a2", "", PriorityUrgent, 30)
+	require.NoError(t, m.Start(ctx, a2, executor.Request{TaskID: a2.ID}, 1))
+	b2 := m.Create("beta", "b2", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, b2, executor.Request{TaskID: b2.ID}, 1))
+
+	// Freeing a beta slot must promote b2 even though a2 is first in line
+	require.NoError(t, m.Cancel(b1.ID))
+	assert.Eventually(t, func() bool {
+		return b2.Snapshot().Status == StatusRunning
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StatusQueued, a2.Snapshot().Status)
+
+	for _, tk := range []*Task{a1, a2, b2} {
+		_ = m.Cancel(tk.ID)
+	}
+}
+
+func TestManager_Cancel_WhenQueued_RemovesFromQueue(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockExecutor{delay: 10 * time.Second}
+	m := NewManager(mock, 1, 2*time.Hour)
+	ctx := context.Background()
+
+	t1 := m.Create("proj", "task1", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, t1, executor.Request{TaskID: t1.ID}, 0))
+	t2 := m.Create("proj", "task2", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, t2, executor.Request{TaskID: t2.ID}, 0))
+
+	require.NoError(t, m.Cancel(t2.ID))
+	assert.Equal(t, StatusCancelled, t2.Snapshot().Status)
+	_, total := m.QueuePosition(t2.ID)
+	assert.Equal(t, 0, total)
+
+	_ = m.Cancel(t1.ID)
+	<-t1.Done()
+}
+
+func TestManager_Start_WhenQueued_EmitsQueuedAndPromotedEvents(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockExecutor{delay: 50 * time.Millisecond}
+	m := NewManager(mock, 1, 2*time.Hour)
+	ctx := context.Background()
+
+	events := make(chan TaskEvent, 32)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	t1 := m.Create("proj", "task1", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, t1, executor.Request{TaskID: t1.ID}, 0))
+	t2 := m.Create("proj", "task2", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(ctx, t2, executor.Request{TaskID: t2.ID}, 0))
+
+	<-t2.Done()
+
+	var sawQueued, sawPromoted bool
+	for len(events) > 0 {
+		e := <-events
+		if e.TaskID != t2.ID {
+			continue
+		}
+		if e.Type == "task.queued" {
+			sawQueued = true
+		}
+		if e.Type == "task.started" && strings.Contains(e.Message, "promoted") {
+			sawPromoted = true
+		}
+	}
+	assert.True(t, sawQueued, "expected task.queued event")
+	assert.True(t, sawPromoted, "expected task.started promotion event")
+}
+
 func TestManager_Start_WhenTaskCompletes_FreesSlot(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,91 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// maxPendingMessages bounds the messages waiting to be delivered to a task.
+const maxPendingMessages = 16
+
+// ErrMessagesUnsupported is returned when the executor cannot deliver
+// messages to a running task.
+var ErrMessagesUnsupported = errors.New("executor does not support messages")
+
+// ErrReattached is returned when a message is sent to a task reattached
+// after a restart: the input of its process belonged to the previous
+// Herald instance.
+var ErrReattached = errors.New("task was reattached after a restart and cannot receive messages")
+
+// SendMessage queues text for delivery to the running task id, as a new
+// user turn. Messages are delivered in order, after the executor has
+// written the ones before.
+func (m *Manager) SendMessage(id, text string) error {
+	if !m.executor.Capabilities().SupportsMessages {
+		return ErrMessagesUnsupported
+	}
+
+	m.mu.RLock()
+	t, ok := m.tasks[id]
+	inbox := m.inboxes[id]
+	m.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("task %q not found", id)
+	}
+	if status := t.Snapshot().Status; status != StatusRunning {
+		return fmt.Errorf("task %q is %s: messages can only be sent to running tasks", id, status)
+	}
+	if t.isReattached() {
+		return fmt.Errorf("task %q: %w", id, ErrReattached)
+	}
+	if inbox == nil {
+		return fmt.Errorf("task %q has no inbox", id)
+	}
+
+	select {
+	case inbox <- text:
+		slog.Info("message queued for task", "task_id", id, "length", len(text))
+		return nil
+	default:
+		return fmt.Errorf("task %q already has %d messages waiting to be delivered", id, maxPendingMessages)
+	}
+}
+
+// openInboxLocked creates the inbox of t when the executor supports
+// messages and hands it to the executor through req. Attempts of a retried
+// task share it. Caller must hold m.mu.
+func (m *Manager) openInboxLocked(t *Task, req *executor.Request) {
+	if !m.executor.Capabilities().SupportsMessages {
+		return
+	}
+	inbox := make(chan string, maxPendingMessages)
+	m.inboxes[t.ID] = inbox
+	req.Messages = inbox
+}
+
+// dropInbox removes the inbox of t once it no longer runs. The channel is
+// not closed: a concurrent SendMessage may still hold it.
+func (m *Manager) dropInbox(t *Task) {
+	m.mu.Lock()
+	inbox, ok := m.inboxes[t.ID]
+	delete(m.inboxes, t.ID)
+	m.mu.Unlock()
+
+	if ok && len(inbox) > 0 {
+		slog.Warn("task finished with undelivered messages",
+			"task_id", t.ID,
+			"count", len(inbox))
+	}
+}
+
+// isReattached reports whether t runs a process started by a previous
+// Herald instance.
+func (t *Task) isReattached() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.reattached
+}
@@ -0,0 +1,145 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+)
+
+// messagingExecutor finishes with the first message it receives as output,
+// or waits for the context to end when release is set.
+type messagingExecutor struct {
+	release chan struct{} // when not nil, messages are only read once closed
+}
+
+func (e *messagingExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "messaging", SupportsMessages: true}
+}
+
+func (e *messagingExecutor) Execute(ctx context.Context, req executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	if e.release != nil {
+		select {
+		case <-e.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	select {
+	case msg := <-req.Messages:
+		return &executor.Result{Output: msg}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestManager_SendMessage_WhenRunning_DeliversToExecutor(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&messagingExecutor{}, 3, 2*time.Hour)
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	require.NoError(t, m.SendMessage(tk.ID, "keep the old names"))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.Equal(t, "keep the old names", snap.Output)
+
+	// The inbox goes away with the task.
+	assert.ErrorContains(t, m.SendMessage(tk.ID, "too late"), "messages can only be sent to running tasks")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	assert.Empty(t, m.inboxes)
+}
+
+func TestManager_SendMessage_WhenInboxFull_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	m := NewManager(&messagingExecutor{release: release}, 3, 2*time.Hour)
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	for range maxPendingMessages {
+		require.NoError(t, m.SendMessage(tk.ID, "more"))
+	}
+	assert.ErrorContains(t, m.SendMessage(tk.ID, "one too many"), "already has 16 messages waiting")
+
+	close(release)
+	waitDone(t, tk)
+}
+
+func TestManager_SendMessage_WhenNotRunning_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&messagingExecutor{}, 3, 2*time.Hour)
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+
+	assert.ErrorContains(t, m.SendMessage(tk.ID, "hello"), "is pending: messages can only be sent to running tasks")
+	assert.ErrorContains(t, m.SendMessage("herald-missing", "hello"), "not found")
+}
+
+// messagingReattacher reattaches to processes that run until release is
+// closed.
+type messagingReattacher struct {
+	messagingExecutor
+	reattached chan struct{}
+	release    chan struct{}
+}
+
+func (e *messagingReattacher) Reattach(_ context.Context, _ executor.ReattachRequest, _ executor.ProgressFunc) (*executor.Result, error) {
+	e.reattached <- struct{}{}
+	<-e.release
+	return &executor.Result{Output: "finished after restart"}, nil
+}
+
+func TestManager_SendMessage_WhenReattached_ReturnsError(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00009", Type: "dispatched", Project: "proj", Prompt: "was running",
+		Status: "running", Priority: "normal", PID: 4242, TimeoutMinutes: 30,
+		CreatedAt: now, StartedAt: now,
+	}))
+
+	exec := &messagingReattacher{reattached: make(chan struct{}, 1), release: make(chan struct{})}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	require.NoError(t, m.Restore(testRequestBuilder))
+	<-exec.reattached
+
+	err := m.SendMessage("herald-run00009", "keep the old names")
+	require.ErrorIs(t, err, ErrReattached)
+	assert.ErrorContains(t, err, "herald-run00009")
+
+	close(exec.release)
+	tk, err := m.Get("herald-run00009")
+	require.NoError(t, err)
+	waitDone(t, tk)
+	assert.Equal(t, StatusCompleted, tk.Snapshot().Status)
+}
+
+func TestManager_SendMessage_WhenExecutorUnsupported_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{{block: true}}}, 3, 2*time.Hour)
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	assert.ErrorIs(t, m.SendMessage(tk.ID, "hello"), ErrMessagesUnsupported)
+	exec := m.executor.(*scriptedExecutor)
+	require.Eventually(t, func() bool { return len(exec.requests()) == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Nil(t, exec.requests()[0].Messages)
+
+	require.NoError(t, m.Cancel(tk.ID))
+	waitDone(t, tk)
+}
@@ -0,0 +1,308 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// SetPausedHoldSlots makes paused tasks count against the concurrency
+// limits as if they were running. By default a paused task frees its slot
+// for queued tasks, but those of its project still wait when they would
+// share its project checkout (see CheckoutSharer).
+func (m *Manager) SetPausedHoldSlots(hold bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pausedHoldSlots = hold
+}
+
+// Pause stops the process group of a running task with SIGSTOP. The task
+// timeout stops running until Resume.
+func (m *Manager) Pause(id string) error {
+	m.mu.Lock()
+	t, ok := m.tasks[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("task %q not found", id)
+	}
+	hold := m.pausedHoldSlots
+	err := suspend(t)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	m.persist(t)
+	slog.Info("task paused", "task_id", id, "holds_slot", hold)
+	m.emit(t, "task.paused", "task paused")
+	if !hold {
+		m.dispatch()
+	}
+	return nil
+}
+
+// Resume continues a paused task with SIGCONT. It resumes right away, even
+// when queued tasks took its slot meanwhile.
+func (m *Manager) Resume(id string) error {
+	m.mu.Lock()
+	t, ok := m.tasks[id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("task %q not found", id)
+	}
+
+	paused, err := resume(t)
+	if err != nil {
+		return err
+	}
+
+	m.persist(t)
+	slog.Info("task resumed", "task_id", id, "paused", paused.Round(time.Second))
+	m.emit(t, "task.resumed", fmt.Sprintf("task resumed after %s paused", paused.Round(time.Second)))
+	return nil
+}
+
+// suspend stops the process group of running task t and its timeout.
+func suspend(t *Task) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Status != StatusRunning {
+		return fmt.Errorf("task %q is %s: only running tasks can be paused", t.ID, t.Status)
+	}
+	if t.PID <= 0 {
+		return fmt.Errorf("task %q has no process to pause yet", t.ID)
+	}
+	if err := executor.SuspendGroup(t.PID); err != nil {
+		return fmt.Errorf("pausing task %q: %w", t.ID, err)
+	}
+	t.Status = StatusPaused
+	t.PausedAt = time.Now()
+	t.clock.pause()
+	return nil
+}
+
+// resume continues paused task t and its timeout, ending its preemption
+// if it was preempted. It returns how long t was paused.
+func resume(t *Task) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Status != StatusPaused {
+		return 0, fmt.Errorf("task %q is %s, not paused", t.ID, t.Status)
+	}
+	if err := executor.ResumeGroup(t.PID); err != nil {
+		return 0, fmt.Errorf("resuming task %q: %w", t.ID, err)
+	}
+	now := time.Now()
+	if p := t.preemptionLocked(); p != nil {
+		p.ResumedAt = now
+	}
+	paused := now.Sub(t.PausedAt)
+	t.Status = StatusRunning
+	t.PausedFor += paused
+	t.PausedAt = time.Time{}
+	t.clock.resume()
+	return paused, nil
+}
+
+// activeTime returns how long t has been running since it started, paused
+// time excluded. Caller must hold t.mu.
+func (t *Task) activeTime() time.Duration {
+	if t.StartedAt.IsZero() {
+		return 0
+	}
+	paused := t.PausedFor
+	if !t.PausedAt.IsZero() {
+		paused += time.Since(t.PausedAt)
+	}
+	return time.Since(t.StartedAt) - paused
+}
+
+// pauseClock is a context that times out once it has run for its timeout,
+// not counting the time it was paused. Err reports
+// context.DeadlineExceeded when it timed out, like context.WithTimeout.
+// Its timeout can be changed while it runs, and it can warn shortly
+// before it times out.
+type pauseClock struct {
+	context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	timeout   time.Duration // running time allowed in total
+	remaining time.Duration // running time left at since, or when paused
+	since     time.Time     // when the clock was last started, zero while paused
+	timedOut  bool
+
+	warnBefore time.Duration
+	onWarn     func(left time.Duration)
+	warnTimer  *time.Timer
+	warned     bool
+}
+
+// newPauseClock starts a clock over parent that times out after timeout
+// of running time, elapsed of which has already run. When paused is set
+// the clock starts paused.
+func newPauseClock(parent context.Context, timeout, elapsed time.Duration, paused bool) (*pauseClock, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := &pauseClock{Context: ctx, cancel: cancel, timeout: timeout, remaining: timeout - elapsed}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !paused {
+		c.since = time.Now() // before the timer starts, so that it never fires early
+	}
+	c.timer = time.AfterFunc(max(c.remaining, 0), c.expire)
+	if paused {
+		c.timer.Stop()
+	}
+	return c, func() {
+		c.mu.Lock()
+		c.timer.Stop()
+		if c.warnTimer != nil {
+			c.warnTimer.Stop()
+		}
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// Err returns context.DeadlineExceeded once the clock timed out.
+func (c *pauseClock) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timedOut {
+		return context.DeadlineExceeded
+	}
+	return c.Context.Err()
+}
+
+func (c *pauseClock) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.since.IsZero() || c.Context.Err() != nil || c.leftLocked() > 0 {
+		return // paused or extended meanwhile, or already over
+	}
+	c.timedOut = true
+	c.cancel()
+}
+
+// leftLocked returns the running time left. Caller must hold c.mu.
+func (c *pauseClock) leftLocked() time.Duration {
+	if c.since.IsZero() {
+		return c.remaining
+	}
+	return c.remaining - time.Since(c.since)
+}
+
+// pause stops the clock. Safe on a nil clock.
+func (c *pauseClock) pause() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.since.IsZero() {
+		return
+	}
+	c.timer.Stop()
+	if c.warnTimer != nil {
+		c.warnTimer.Stop()
+	}
+	c.remaining = c.leftLocked()
+	c.since = time.Time{}
+}
+
+// resume starts the clock again with the time it had left. Safe on a nil
+// clock.
+func (c *pauseClock) resume() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.since.IsZero() {
+		return
+	}
+	c.since = time.Now()
+	c.timer.Reset(max(c.remaining, 0))
+	c.armWarningLocked()
+}
+
+// setTimeout replaces the timeout of the clock, counted from when it
+// started. It fails when the clock has already run for that long. Safe on
+// a nil clock.
+func (c *pauseClock) setTimeout(timeout time.Duration) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elapsed := c.timeout - c.leftLocked()
+	if timeout <= elapsed {
+		return fmt.Errorf("it has already run for %s", elapsed.Round(time.Second))
+	}
+	c.timeout = timeout
+	c.remaining = timeout - elapsed
+	c.warned = false
+	if !c.since.IsZero() {
+		c.since = time.Now()
+		c.timer.Reset(c.remaining)
+		c.armWarningLocked()
+	}
+	return nil
+}
+
+// left returns the running time left before the clock times out. Safe on
+// a nil clock, which has none.
+func (c *pauseClock) left() time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leftLocked()
+}
+
+// warn calls fn once before remains until the clock times out, then again
+// after each change of its timeout. The warning is skipped when less than
+// before remains already. Safe on a nil clock.
+func (c *pauseClock) warn(before time.Duration, fn func(left time.Duration)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnBefore = before
+	c.onWarn = fn
+	c.armWarningLocked()
+}
+
+// armWarningLocked schedules the warning for the current timeout. Caller
+// must hold c.mu.
+func (c *pauseClock) armWarningLocked() {
+	if c.warnTimer != nil {
+		c.warnTimer.Stop()
+	}
+	if c.onWarn == nil || c.warned || c.since.IsZero() {
+		return
+	}
+	if wait := c.leftLocked() - c.warnBefore; wait > 0 {
+		c.warnTimer = time.AfterFunc(wait, c.fireWarning)
+	}
+}
+
+func (c *pauseClock) fireWarning() {
+	c.mu.Lock()
+	left := c.leftLocked()
+	if c.since.IsZero() || c.warned || c.Context.Err() != nil || left > c.warnBefore {
+		c.mu.Unlock()
+		return // paused or extended meanwhile, or already over
+	}
+	c.warned = true
+	fn := c.onWarn
+	c.mu.Unlock()
+	fn(left)
+}
@@ -0,0 +1,207 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// sleepExecutor runs `sleep` in its own process group, like the Claude
+// Code executor, until it exits or the task is cancelled.
+type sleepExecutor struct{}
+
+func (sleepExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "sleep"}
+}
+
+func (sleepExecutor) Execute(ctx context.Context, _ executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	cmd := exec.CommandContext(ctx, "sleep", "30")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: cmd.Process.Pid})
+	err := cmd.Wait()
+	return &executor.Result{}, err
+}
+
+// processState returns the state letter of pid from /proc, e.g. "S" or
+// "T" when stopped.
+func processState(t *testing.T, pid int) string {
+	t.Helper()
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		t.Skipf("process state unavailable: %v", err)
+	}
+	fields := strings.Fields(string(raw[strings.LastIndexByte(string(raw), ')')+1:]))
+	return fields[0]
+}
+
+// startSleeping starts a task on a sleepExecutor and waits for its PID.
+func startSleeping(t *testing.T, m *Manager) *Task {
+	t.Helper()
+	tk := m.Create("proj", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	require.Eventually(t, func() bool { return tk.Snapshot().PID > 0 }, 5*time.Second, 5*time.Millisecond)
+	t.Cleanup(func() { _ = m.Cancel(tk.ID) })
+	return tk
+}
+
+func TestManager_Pause_StopsProcessUntilResumed(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	events := make(chan TaskEvent, 16)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+	tk := startSleeping(t, m)
+	pid := tk.Snapshot().PID
+
+	require.NoError(t, m.Pause(tk.ID))
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusPaused, snap.Status)
+	assert.False(t, snap.PausedAt.IsZero())
+	require.Eventually(t, func() bool { return processState(t, pid) == "T" }, 5*time.Second, 5*time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, m.Resume(tk.ID))
+	snap = tk.Snapshot()
+	assert.Equal(t, StatusRunning, snap.Status)
+	assert.True(t, snap.PausedAt.IsZero())
+	assert.GreaterOrEqual(t, snap.PausedFor, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return processState(t, pid) != "T" }, 5*time.Second, 5*time.Millisecond)
+
+	var types []string
+	for len(events) > 0 {
+		types = append(types, (<-events).Type)
+	}
+	assert.Contains(t, types, "task.paused")
+	assert.Contains(t, types, "task.resumed")
+}
+
+func TestManager_Pause_FreesSlotUnlessConfigured(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	first := startSleeping(t, m)
+
+	second := m.Create("proj", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), second, executor.Request{TaskID: second.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(second.ID) })
+	assert.Equal(t, StatusQueued, second.Snapshot().Status)
+
+	require.NoError(t, m.Pause(first.ID))
+	require.Eventually(t, func() bool { return second.Snapshot().Status == StatusRunning }, 5*time.Second, 5*time.Millisecond)
+
+	// Resuming does not wait for a slot.
+	require.NoError(t, m.Resume(first.ID))
+	assert.Equal(t, StatusRunning, first.Snapshot().Status)
+}
+
+func TestManager_Pause_WhenCheckoutShared_FreesSlotForOtherProjectsOnly(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	m.SetWorkspace(&fakeWorkspace{dir: "/projects/proj", shared: true})
+	first := startSleeping(t, m)
+
+	second := m.Create("proj", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), second, executor.Request{TaskID: second.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(second.ID) })
+	other := m.Create("other", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), other, executor.Request{TaskID: other.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(other.ID) })
+
+	require.NoError(t, m.Pause(first.ID))
+	require.Eventually(t, func() bool { return other.Snapshot().Status == StatusRunning }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusQueued, second.Snapshot().Status, "the paused task is frozen mid-edit in the checkout")
+}
+
+func TestManager_Pause_WhenHoldingSlots_KeepsQueue(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	m.SetPausedHoldSlots(true)
+	first := startSleeping(t, m)
+
+	second := m.Create("proj", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), second, executor.Request{TaskID: second.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(second.ID) })
+
+	require.NoError(t, m.Pause(first.ID))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, StatusQueued, second.Snapshot().Status)
+}
+
+func TestManager_Cancel_WhenPaused_EndsTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	tk := startSleeping(t, m)
+	require.NoError(t, m.Pause(tk.ID))
+	require.NoError(t, m.Cancel(tk.ID))
+
+	select {
+	case <-tk.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("paused task was not cancelled")
+	}
+	assert.Equal(t, StatusCancelled, tk.Snapshot().Status)
+}
+
+func TestManager_Pause_WhenNotRunning_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	tk := m.Create("proj", "x", "", PriorityNormal, 30)
+
+	err := m.Pause(tk.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only running tasks can be paused")
+
+	err = m.Resume(tk.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not paused")
+
+	assert.Error(t, m.Pause("herald-ffffffff"))
+}
+
+func TestPauseClock_DoesNotCountPausedTime(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := newPauseClock(context.Background(), 50*time.Millisecond, 0, false)
+	defer cancel()
+	ctx.pause()
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ctx.Err(), "paused time counted against the timeout")
+
+	ctx.resume()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("clock did not time out once resumed")
+	}
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestPauseClock_WhenParentCancelled_ReportsCanceled(t *testing.T) {
+	t.Parallel()
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := newPauseClock(parent, time.Hour, 0, false)
+	defer cancel()
+
+	cancelParent()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,267 @@
+package task
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Scopes of an approval.
+const (
+	PermissionOnce = "once" // allow this tool use only
+	PermissionTask = "task" // allow the same tool use again for the rest of the task
+)
+
+// defaultPermissionTimeout is how long a permission request waits for an
+// answer when SetPermissionPrompts is given no timeout.
+const defaultPermissionTimeout = 10 * time.Minute
+
+// PermissionRequest is a tool use a running task waits for approval of.
+type PermissionRequest struct {
+	ID        string
+	TaskID    string
+	Tool      string // tool name, e.g. "Bash"
+	Summary   string // tool use as shown to the user, e.g. "Bash(rm -rf build)"
+	CreatedAt time.Time
+	Deadline  time.Time // denied automatically once passed
+}
+
+// PermissionDecision answers a PermissionRequest.
+type PermissionDecision struct {
+	Allow   bool
+	Message string // why the tool use was denied
+}
+
+// pendingPermission is a PermissionRequest waiting for its answer.
+type pendingPermission struct {
+	req    PermissionRequest
+	key    string                  // the tool use, see toolUseKey
+	answer chan PermissionDecision // buffered, answered once
+}
+
+// SetPermissionPrompts forwards the permission requests of tasks to url,
+// Herald's approval endpoint, for executors that support it. Requests left
+// unanswered for timeout are denied.
+func (m *Manager) SetPermissionPrompts(url string, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultPermissionTimeout
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.permissionURL = url
+	m.permissionTimeout = timeout
+}
+
+// PermissionTask returns the ID of the running task a permission token was
+// issued to.
+func (m *Manager) PermissionTask(token string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.permissionTokens[token]
+	return id, ok
+}
+
+// RequestPermission asks the user to approve a tool use of the running task
+// taskID and waits for the answer. Tool uses approved for the task are
+// allowed right away; a request left unanswered past the timeout is denied.
+func (m *Manager) RequestPermission(ctx context.Context, taskID, tool string, input map[string]any) PermissionDecision {
+	m.mu.Lock()
+	t, ok := m.tasks[taskID]
+	timeout := m.permissionTimeout
+	if !ok {
+		m.mu.Unlock()
+		return PermissionDecision{Message: fmt.Sprintf("task %q not found", taskID)}
+	}
+
+	t.mu.Lock()
+	if t.Status != StatusRunning {
+		status := t.Status
+		t.mu.Unlock()
+		m.mu.Unlock()
+		return PermissionDecision{Message: fmt.Sprintf("task is %s", status)}
+	}
+	key := toolUseKey(tool, input)
+	if slices.Contains(t.grantedUses, key) {
+		t.mu.Unlock()
+		m.mu.Unlock()
+		return PermissionDecision{Allow: true}
+	}
+
+	now := time.Now()
+	p := &pendingPermission{
+		req: PermissionRequest{
+			ID:        newPermissionID(),
+			TaskID:    taskID,
+			Tool:      tool,
+			Summary:   describeToolUse(tool, input),
+			CreatedAt: now,
+			Deadline:  now.Add(timeout),
+		},
+		key:    key,
+		answer: make(chan PermissionDecision, 1),
+	}
+	t.PendingPermissions = append(t.PendingPermissions, p.req)
+	t.Progress = "waiting for approval: " + p.req.Summary
+	t.mu.Unlock()
+	m.permissions[p.req.ID] = p
+	m.mu.Unlock()
+
+	slog.Info("permission requested",
+		"task_id", taskID,
+		"request_id", p.req.ID,
+		"tool", p.req.Summary)
+	m.emit(t, "task.permission", fmt.Sprintf("%s wants to run %s — approve_permission or deny_permission with request_id %s (denied automatically in %s)",
+		taskID, p.req.Summary, p.req.ID, timeout.Round(time.Second)))
+
+	var d PermissionDecision
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case d = <-p.answer:
+	case <-timer.C:
+		d = PermissionDecision{Message: fmt.Sprintf("no answer within %s, denied automatically", timeout.Round(time.Second))}
+	case <-ctx.Done():
+		d = PermissionDecision{Message: "the permission request was abandoned"}
+	}
+	m.settlePermission(p.req.ID)
+
+	answer := "permission granted: " + p.req.Summary
+	if !d.Allow {
+		answer = "permission denied: " + p.req.Summary
+	}
+	t.SetProgress(answer)
+	m.record(t, "task.permission_answered", answer)
+	slog.Info("permission answered",
+		"task_id", taskID,
+		"request_id", p.req.ID,
+		"allowed", d.Allow,
+		"message", d.Message)
+	return d
+}
+
+// AnswerPermission answers the pending permission request requestID. An
+// approval with PermissionTask scope also allows the same tool use — the
+// same command, file or input — for the rest of the task, not the tool.
+func (m *Manager) AnswerPermission(requestID string, d PermissionDecision, scope string) (PermissionRequest, error) {
+	p, ok := m.settlePermission(requestID)
+	if !ok {
+		return PermissionRequest{}, fmt.Errorf("permission request %q not found or already answered", requestID)
+	}
+
+	if d.Allow && scope == PermissionTask {
+		m.mu.RLock()
+		t := m.tasks[p.req.TaskID]
+		m.mu.RUnlock()
+		if t != nil {
+			t.mu.Lock()
+			t.grantedUses = append(t.grantedUses, p.key)
+			t.mu.Unlock()
+		}
+	}
+	p.answer <- d
+	return p.req, nil
+}
+
+// settlePermission removes the request requestID from the pending ones, so
+// that it is answered once.
+func (m *Manager) settlePermission(requestID string) (*pendingPermission, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.permissions[requestID]
+	if !ok {
+		return nil, false
+	}
+	delete(m.permissions, requestID)
+
+	if t := m.tasks[p.req.TaskID]; t != nil {
+		t.mu.Lock()
+		t.PendingPermissions = slices.DeleteFunc(t.PendingPermissions, func(r PermissionRequest) bool {
+			return r.ID == requestID
+		})
+		t.mu.Unlock()
+	}
+	return p, true
+}
+
+// openPermissionsLocked issues the permission token of t when permission
+// requests are forwarded and the executor supports it, and hands it to the
+// executor through req. Caller must hold m.mu.
+func (m *Manager) openPermissionsLocked(t *Task, req *executor.Request) {
+	if m.permissionURL == "" || !m.executor.Capabilities().SupportsPermissionPrompts {
+		return
+	}
+	token := rand.Text()
+	m.permissionTokens[token] = t.ID
+	req.Permissions = &executor.PermissionPrompt{URL: m.permissionURL, Token: token}
+}
+
+// closePermissions revokes the permission token of t once it no longer
+// runs, along with what was approved for it.
+func (m *Manager) closePermissions(t *Task) {
+	m.mu.Lock()
+	for token, id := range m.permissionTokens {
+		if id == t.ID {
+			delete(m.permissionTokens, token)
+		}
+	}
+	m.mu.Unlock()
+
+	t.mu.Lock()
+	t.grantedUses = nil
+	t.mu.Unlock()
+}
+
+// newPermissionID creates a permission request ID in the format
+// perm-{8 hex chars}.
+func newPermissionID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("perm-%x", b)
+}
+
+// describeToolUse renders a tool use the way Claude Code names permission
+// rules, e.g. "Bash(rm -rf build)" or "Edit(internal/auth.go)".
+func describeToolUse(tool string, input map[string]any) string {
+	return formatToolUse(tool, input, 200)
+}
+
+// toolUseKey identifies a tool use for approvals with PermissionTask scope:
+// describeToolUse, untruncated, so that an approval of "Bash(ls)" does not
+// allow "Bash(rm -rf build)".
+func toolUseKey(tool string, input map[string]any) string {
+	return formatToolUse(tool, input, 0)
+}
+
+// formatToolUse renders a tool use with its argument shortened to max
+// runes, or whole when max is 0.
+func formatToolUse(tool string, input map[string]any, max int) string {
+	for _, key := range []string{"command", "file_path", "notebook_path", "path", "url", "pattern"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			return fmt.Sprintf("%s(%s)", tool, truncateRunes(v, max))
+		}
+	}
+	if len(input) == 0 {
+		return tool
+	}
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return tool
+	}
+	return fmt.Sprintf("%s(%s)", tool, truncateRunes(string(raw), max))
+}
+
+// truncateRunes shortens s to at most max runes, marking the cut. A max of
+// 0 keeps s whole.
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "…"
+}
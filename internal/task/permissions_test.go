@@ -0,0 +1,220 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// pendingRequest waits for tk to ask for a permission and returns it.
+func pendingRequest(t *testing.T, tk *Task) PermissionRequest {
+	t.Helper()
+	var r PermissionRequest
+	require.Eventually(t, func() bool {
+		snap := tk.Snapshot()
+		if len(snap.PendingPermissions) == 0 {
+			return false
+		}
+		r = snap.PendingPermissions[0]
+		return true
+	}, 5*time.Second, time.Millisecond)
+	return r
+}
+
+// runningTask creates a task of m marked running.
+func runningTask(m *Manager) *Task {
+	tk := m.Create("proj", "clean up", "", PriorityNormal, 30)
+	tk.SetStatus(StatusRunning)
+	return tk
+}
+
+func TestManager_RequestPermission_WhenApprovedOnce_AllowsThisUseOnly(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	var mu sync.Mutex
+	var events []TaskEvent
+	m.SetNotifyFunc(func(e TaskEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	tk := runningTask(m)
+
+	decided := make(chan PermissionDecision, 1)
+	go func() {
+		decided <- m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "rm -rf build"})
+	}()
+
+	r := pendingRequest(t, tk)
+	assert.Equal(t, "Bash(rm -rf build)", r.Summary)
+	assert.Equal(t, "waiting for approval: Bash(rm -rf build)", tk.Snapshot().Progress)
+
+	answered, err := m.AnswerPermission(r.ID, PermissionDecision{Allow: true}, PermissionOnce)
+	require.NoError(t, err)
+	assert.Equal(t, tk.ID, answered.TaskID)
+	assert.True(t, (<-decided).Allow)
+
+	snap := tk.Snapshot()
+	assert.Empty(t, snap.PendingPermissions)
+	assert.Equal(t, "permission granted: Bash(rm -rf build)", snap.Progress)
+
+	_, err = m.AnswerPermission(r.ID, PermissionDecision{Allow: true}, PermissionOnce)
+	assert.ErrorContains(t, err, "not found or already answered")
+
+	mu.Lock()
+	require.Len(t, events, 1)
+	assert.Equal(t, "task.permission", events[0].Type)
+	assert.Contains(t, events[0].Message, "wants to run Bash(rm -rf build)")
+	assert.Contains(t, events[0].Message, r.ID)
+	mu.Unlock()
+
+	// Approved once: the next use asks again.
+	go func() {
+		decided <- m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "rm -rf dist"})
+	}()
+	r = pendingRequest(t, tk)
+	_, err = m.AnswerPermission(r.ID, PermissionDecision{Message: "no"}, PermissionOnce)
+	require.NoError(t, err)
+	assert.Equal(t, PermissionDecision{Message: "no"}, <-decided)
+}
+
+func TestManager_RequestPermission_WhenApprovedForTask_AllowsSameUseUntilTaskEnds(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	tk := runningTask(m)
+
+	decided := make(chan PermissionDecision, 1)
+	go func() {
+		decided <- m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "make clean"})
+	}()
+	r := pendingRequest(t, tk)
+	_, err := m.AnswerPermission(r.ID, PermissionDecision{Allow: true}, PermissionTask)
+	require.NoError(t, err)
+	require.True(t, (<-decided).Allow)
+
+	d := m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "make clean"})
+	assert.True(t, d.Allow, "the tool use is approved for the rest of the task")
+
+	go func() {
+		decided <- m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "rm -rf /"})
+	}()
+	r = pendingRequest(t, tk)
+	_, err = m.AnswerPermission(r.ID, PermissionDecision{Message: "no"}, PermissionOnce)
+	require.NoError(t, err)
+	assert.False(t, (<-decided).Allow, "other uses of the tool still ask")
+
+	m.closePermissions(tk)
+	go func() {
+		decided <- m.RequestPermission(context.Background(), tk.ID, "Bash", map[string]any{"command": "make clean"})
+	}()
+	r = pendingRequest(t, tk)
+	_, err = m.AnswerPermission(r.ID, PermissionDecision{Message: "no"}, PermissionOnce)
+	require.NoError(t, err)
+	assert.False(t, (<-decided).Allow, "approvals do not outlive the task's run")
+}
+
+func TestManager_RequestPermission_WhenUnanswered_DeniesAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	m.SetPermissionPrompts("http://127.0.0.1:8420/permissions/mcp", 20*time.Millisecond)
+	tk := runningTask(m)
+
+	d := m.RequestPermission(context.Background(), tk.ID, "WebFetch", map[string]any{"url": "https://example.com"})
+	assert.False(t, d.Allow)
+	assert.Contains(t, d.Message, "denied automatically")
+
+	snap := tk.Snapshot()
+	assert.Empty(t, snap.PendingPermissions)
+	assert.Equal(t, "permission denied: WebFetch(https://example.com)", snap.Progress)
+}
+
+func TestManager_RequestPermission_WhenTaskNotRunning_Denies(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&scriptedExecutor{outcomes: []attemptOutcome{success("")}}, 3, 2*time.Hour)
+	tk := m.Create("proj", "clean up", "", PriorityNormal, 30)
+
+	d := m.RequestPermission(context.Background(), tk.ID, "Bash", nil)
+	assert.False(t, d.Allow)
+	assert.Equal(t, "task is pending", d.Message)
+	assert.Contains(t, m.RequestPermission(context.Background(), "herald-missing", "Bash", nil).Message, "not found")
+}
+
+// permissionExecutor supports permission prompts and records the request.
+type permissionExecutor struct {
+	scriptedExecutor
+}
+
+func (p *permissionExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "prompting", SupportsPermissionPrompts: true}
+}
+
+func TestManager_Start_WhenPermissionPromptsSet_IssuesTaskToken(t *testing.T) {
+	t.Parallel()
+
+	exec := &permissionExecutor{scriptedExecutor{outcomes: []attemptOutcome{{block: true}}}}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetPermissionPrompts("http://127.0.0.1:8420/permissions/mcp", time.Minute)
+
+	tk := m.Create("proj", "clean up", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	require.Eventually(t, func() bool { return len(exec.requests()) == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	perms := exec.requests()[0].Permissions
+	require.NotNil(t, perms)
+	assert.Equal(t, "http://127.0.0.1:8420/permissions/mcp", perms.URL)
+	id, ok := m.PermissionTask(perms.Token)
+	require.True(t, ok)
+	assert.Equal(t, tk.ID, id)
+
+	require.NoError(t, m.Cancel(tk.ID))
+	waitDone(t, tk)
+	require.Eventually(t, func() bool {
+		_, ok := m.PermissionTask(perms.Token)
+		return !ok
+	}, 5*time.Second, 5*time.Millisecond, "the token is revoked once the task ends")
+}
+
+func TestManager_Start_WhenExecutorCannotPrompt_IssuesNoToken(t *testing.T) {
+	t.Parallel()
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{success("")}}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetPermissionPrompts("http://127.0.0.1:8420/permissions/mcp", time.Minute)
+
+	tk := m.Create("proj", "clean up", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+	assert.Nil(t, exec.requests()[0].Permissions)
+}
+
+func TestDescribeToolUse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tool  string
+		input map[string]any
+		want  string
+	}{
+		{"Bash", map[string]any{"command": "rm -rf build", "description": "clean"}, "Bash(rm -rf build)"},
+		{"Edit", map[string]any{"file_path": "internal/auth.go", "old_string": "a"}, "Edit(internal/auth.go)"},
+		{"WebFetch", map[string]any{"url": "https://example.com", "prompt": "read"}, "WebFetch(https://example.com)"},
+		{"mcp__db__query", map[string]any{"sql": "DROP TABLE users"}, `mcp__db__query({"sql":"DROP TABLE users"})`},
+		{"TodoWrite", nil, "TodoWrite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, describeToolUse(tt.tool, tt.input))
+		})
+	}
+}
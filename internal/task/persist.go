@@ -0,0 +1,457 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+	"github.com/btouchard/herald/internal/store"
+)
+
+// Store persists task state. Defined at the consumer side per Go
+// convention; satisfied by store.Store.
+type Store interface {
+	CreateTask(t *store.TaskRecord) error
+	UpdateTask(t *store.TaskRecord) error
+	ListTasks(f store.TaskFilter) ([]store.TaskRecord, error)
+	AddAttempt(a *store.AttemptRecord) error
+	ListAttempts(taskID string) ([]store.AttemptRecord, error)
+	AddTranscript(entries []store.TranscriptEntry) error
+	AddEvent(e *store.TaskEvent) error
+}
+
+// RequestBuilder rebuilds the executor request for a task loaded from the
+// store so it can be requeued after a restart. It also returns the
+// per-project concurrency limit to enforce.
+type RequestBuilder func(snap TaskSnapshot) (executor.Request, int, error)
+
+// Policies for tasks that were queued or running when Herald stopped.
+const (
+	InterruptedFail    = "fail"    // mark the task failed
+	InterruptedRequeue = "requeue" // requeue the task, resuming its session when known
+)
+
+// interruptedError is recorded on tasks failed because Herald stopped mid-run.
+const interruptedError = "interrupted: Herald stopped while the task was running"
+
+// interruptedQueuedError is recorded on tasks failed because Herald stopped
+// before they ran.
+const interruptedQueuedError = "interrupted: Herald stopped before the task ran"
+
+// SetStore enables write-through persistence of every task state change.
+func (m *Manager) SetStore(s Store) {
+	m.store = s
+}
+
+// SetInterruptedPolicy sets what Restore does with tasks that were queued or
+// running when Herald stopped (InterruptedFail or InterruptedRequeue).
+func (m *Manager) SetInterruptedPolicy(policy string) {
+	m.interruptedPolicy = policy
+}
+
+// Save persists the current state of a task. Handlers call it after
+// mutating a task outside the manager (e.g. herald_push updates).
+func (m *Manager) Save(t *Task) {
+	m.persist(t)
+}
+
+// persistNew inserts a freshly created task into the store.
+func (m *Manager) persistNew(t *Task) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.CreateTask(toRecord(t.Snapshot())); err != nil {
+		slog.Warn("failed to persist new task", "task_id", t.ID, "error", err)
+	}
+}
+
+// persist writes the current task state to the store.
+func (m *Manager) persist(t *Task) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.UpdateTask(toRecord(t.Snapshot())); err != nil {
+		slog.Warn("failed to persist task", "task_id", t.ID, "error", err)
+	}
+}
+
+// Restore loads persisted tasks into memory. Terminal and linked tasks are
+// restored as-is so list_tasks, get_result and get_diff keep working.
+// Running tasks are reattached to their process when the executor supports
+// it, paused ones staying paused. Waiting tasks keep waiting for their
+// dependencies and tasks awaiting approval keep awaiting it. Queued and
+// pending tasks, and running ones whose process cannot be reattached, are
+// marked interrupted and then failed or requeued according to the
+// interrupted policy. build rebuilds the executor request of requeued, waiting and
+// awaiting tasks.
+func (m *Manager) Restore(build RequestBuilder) error {
+	if m.store == nil {
+		return nil
+	}
+
+	records, err := m.store.ListTasks(store.TaskFilter{Status: "all"})
+	if err != nil {
+		return fmt.Errorf("loading tasks: %w", err)
+	}
+
+	// Interrupted tasks are reset to pending before any of them is requeued
+	// so stale "running" records do not occupy concurrency slots. Running
+	// tasks with a known PID stay running when the executor can reattach
+	// to their process.
+	var interrupted, waiting, awaiting []*Task
+	wasRunning := make(map[string]bool)
+	reattacher, canReattach := m.reattacher()
+	reattached := 0
+	m.mu.Lock()
+	for _, r := range records {
+		t := fromRecord(r, m.maxOutputSize)
+		m.restoreAttempts(t)
+		m.chargeRestoredLocked(t)
+		switch t.Status {
+		case StatusRunning, StatusPaused, StatusQueued, StatusPending:
+			active := t.Status == StatusRunning || t.Status == StatusPaused
+			if active && t.PID > 0 && canReattach {
+				m.tasks[t.ID] = t
+				m.startReattach(t, reattacher, build)
+				reattached++
+				continue
+			}
+			if t.Status == StatusPaused && t.PID > 0 {
+				// Not left stopped for good.
+				_ = executor.ResumeGroup(t.PID)
+			}
+			wasRunning[t.ID] = active
+			t.Status = StatusPending
+			t.Interrupted = true
+			t.PID = 0
+			interrupted = append(interrupted, t)
+		case StatusWaiting:
+			waiting = append(waiting, t)
+		case StatusAwaitingApproval:
+			awaiting = append(awaiting, t)
+		}
+		m.tasks[t.ID] = t
+	}
+	m.mu.Unlock()
+
+	slog.Info("tasks restored from store",
+		"total", len(records),
+		"reattached", reattached,
+		"interrupted", len(interrupted),
+		"waiting", len(waiting),
+		"awaiting_approval", len(awaiting))
+
+	// Waiting tasks wait again; the ones whose dependencies were settled
+	// meanwhile are resolved once everything is restored.
+	for _, t := range waiting {
+		req, maxPerProject, err := build(t.Snapshot())
+		if err != nil {
+			m.failInterrupted(t, fmt.Sprintf("interrupted: cannot restore after restart: %s", err))
+			continue
+		}
+		m.mu.Lock()
+		m.waiting[t.ID] = &waitingTask{task: t, req: req, maxPerProject: maxPerProject}
+		m.mu.Unlock()
+	}
+	for _, t := range awaiting {
+		req, maxPerProject, err := build(t.Snapshot())
+		if err != nil {
+			m.failInterrupted(t, fmt.Sprintf("interrupted: cannot restore after restart: %s", err))
+			continue
+		}
+		m.mu.Lock()
+		m.approvals[t.ID] = &waitingTask{task: t, req: req, maxPerProject: maxPerProject}
+		m.mu.Unlock()
+	}
+
+	// Requeue oldest first; the queue orders by priority then creation time anyway.
+	for i := len(interrupted) - 1; i >= 0; i-- {
+		t := interrupted[i]
+		m.recoverInterrupted(t, wasRunning[t.ID], build)
+	}
+	m.dispatch()
+	return nil
+}
+
+// recoverInterrupted applies the interrupted policy to a task that was
+// queued or active when Herald stopped.
+func (m *Manager) recoverInterrupted(t *Task, wasRunning bool, build RequestBuilder) {
+	if m.interruptedPolicy != InterruptedRequeue {
+		msg := interruptedQueuedError
+		if wasRunning {
+			msg = interruptedError
+		}
+		m.failInterrupted(t, msg)
+		return
+	}
+
+	req, maxPerProject, err := build(t.Snapshot())
+	if err != nil {
+		m.failInterrupted(t, fmt.Sprintf("interrupted: cannot requeue after restart: %s", err))
+		return
+	}
+
+	slog.Info("requeueing interrupted task",
+		"task_id", t.ID,
+		"was_running", wasRunning,
+		"session_id", req.SessionID)
+
+	if err := m.Start(context.Background(), t, req, maxPerProject); err != nil && !t.IsTerminal() {
+		m.failInterrupted(t, fmt.Sprintf("interrupted: cannot requeue after restart: %s", err))
+	}
+}
+
+// persistAttempt records an attempt of t in the store.
+func (m *Manager) persistAttempt(t *Task, a Attempt) {
+	if m.store == nil {
+		return
+	}
+	err := m.store.AddAttempt(&store.AttemptRecord{
+		TaskID:    t.ID,
+		Number:    a.Number,
+		Outcome:   a.Outcome,
+		ExitCode:  a.ExitCode,
+		Error:     a.Error,
+		RetryOn:   a.RetryOn,
+		SessionID: a.SessionID,
+		CostUSD:   a.CostUSD,
+		Turns:     a.Turns,
+		StartedAt: a.StartedAt,
+		EndedAt:   a.EndedAt,
+	})
+	if err != nil {
+		slog.Warn("failed to persist task attempt", "task_id", t.ID, "attempt", a.Number, "error", err)
+	}
+}
+
+// persistTranscript appends the transcript the executor recorded for
+// attempt n of t to the store.
+func (m *Manager) persistTranscript(t *Task, n int, result *executor.Result) {
+	if m.store == nil || result == nil || len(result.Transcript) == 0 {
+		return
+	}
+	entries := make([]store.TranscriptEntry, len(result.Transcript))
+	for i, e := range result.Transcript {
+		entries[i] = store.TranscriptEntry{
+			TaskID:       t.ID,
+			Attempt:      n,
+			Kind:         e.Kind,
+			Tool:         e.Tool,
+			ToolUseID:    e.ToolUseID,
+			Text:         e.Text,
+			Input:        e.Input,
+			IsError:      e.IsError,
+			InputTokens:  e.InputTokens,
+			OutputTokens: e.OutputTokens,
+			Duration:     e.Duration,
+			CreatedAt:    e.At,
+		}
+	}
+	if err := m.store.AddTranscript(entries); err != nil {
+		slog.Warn("failed to persist task transcript", "task_id", t.ID, "attempt", n, "error", err)
+	}
+}
+
+// restoreAttempts loads the recorded attempts of a task with a retry
+// policy. Must be called before t is shared.
+func (m *Manager) restoreAttempts(t *Task) {
+	if !t.Retry.Enabled() {
+		return
+	}
+	records, err := m.store.ListAttempts(t.ID)
+	if err != nil {
+		slog.Warn("failed to load task attempts", "task_id", t.ID, "error", err)
+		return
+	}
+	for _, r := range records {
+		t.Attempts = append(t.Attempts, Attempt{
+			Number:    r.Number,
+			Outcome:   r.Outcome,
+			ExitCode:  r.ExitCode,
+			Error:     r.Error,
+			RetryOn:   r.RetryOn,
+			SessionID: r.SessionID,
+			CostUSD:   r.CostUSD,
+			Turns:     r.Turns,
+			StartedAt: r.StartedAt,
+			EndedAt:   r.EndedAt,
+		})
+	}
+}
+
+func (m *Manager) failInterrupted(t *Task, msg string) {
+	slog.Warn("interrupted task failed", "task_id", t.ID, "reason", msg)
+	t.SetError(msg)
+	t.SetStatus(StatusFailed)
+	m.persist(t)
+}
+
+// toRecord converts a snapshot to its persisted form.
+func toRecord(s TaskSnapshot) *store.TaskRecord {
+	return &store.TaskRecord{
+		ID:             s.ID,
+		Type:           string(s.Type),
+		Project:        s.Project,
+		Prompt:         s.Prompt,
+		Context:        s.Context,
+		Status:         string(s.Status),
+		Priority:       string(s.Priority),
+		Model:          s.Model,
+		SessionID:      s.SessionID,
+		PID:            s.PID,
+		GitBranch:      s.GitBranch,
+		WorktreePath:   s.WorktreePath,
+		BaseCommit:     s.BaseCommit,
+		OriginalBranch: s.OriginalBranch,
+		BranchCreated:  s.BranchCreated,
+		Stashed:        s.Stashed,
+		Output:         s.Output,
+		Progress:       s.Progress,
+		Error:          s.Error,
+		Warnings:       s.Warnings,
+		CostUSD:        s.CostUSD,
+		Turns:          s.Turns,
+		FilesModified:  s.FilesModified,
+		LinesAdded:     s.LinesAdded,
+		LinesRemoved:   s.LinesRemoved,
+		TimeoutMinutes: s.TimeoutMinutes,
+		DryRun:         s.DryRun,
+		Interrupted:    s.Interrupted,
+
+		DependsOn:           s.DependsOn,
+		OnDependencyFailure: s.OnDependencyFailure,
+		InheritBranch:       s.InheritBranch,
+		InheritSession:      s.InheritSession,
+		RetryPolicy:         encodeRetryPolicy(s.Retry),
+		MaxBudgetUSD:        s.MaxBudgetUSD,
+		ApprovalReason:      s.ApprovalReason,
+		ApprovedBy:          s.ApprovedBy,
+		PausedAt:            s.PausedAt,
+		PausedFor:           s.PausedFor,
+		Preemptions:         encodePreemptions(s.Preemptions),
+		CreatedAt:           s.CreatedAt,
+		StartedAt:           s.StartedAt,
+		CompletedAt:         s.CompletedAt,
+	}
+}
+
+// encodeRetryPolicy serializes a retry policy for the store.
+func encodeRetryPolicy(p *retry.Policy) string {
+	if p == nil {
+		return ""
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeRetryPolicy(s string) *retry.Policy {
+	if s == "" {
+		return nil
+	}
+	var p retry.Policy
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return nil
+	}
+	if err := p.Validate(); err != nil {
+		slog.Warn("ignoring invalid stored retry policy", "error", err)
+		return nil
+	}
+	return &p
+}
+
+// encodePreemptions serializes the preemption history of a task for the
+// store.
+func encodePreemptions(p []Preemption) string {
+	if len(p) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodePreemptions(s string) []Preemption {
+	if s == "" {
+		return nil
+	}
+	var p []Preemption
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return nil
+	}
+	return p
+}
+
+// fromRecord rebuilds a Task from its persisted form.
+func fromRecord(r store.TaskRecord, maxOutputSize int) *Task {
+	t := &Task{
+		ID:             r.ID,
+		Type:           Type(r.Type),
+		Project:        r.Project,
+		Prompt:         r.Prompt,
+		Context:        r.Context,
+		Status:         Status(r.Status),
+		Priority:       Priority(r.Priority),
+		Model:          r.Model,
+		SessionID:      r.SessionID,
+		PID:            r.PID,
+		GitBranch:      r.GitBranch,
+		WorktreePath:   r.WorktreePath,
+		BaseCommit:     r.BaseCommit,
+		OriginalBranch: r.OriginalBranch,
+		BranchCreated:  r.BranchCreated,
+		Stashed:        r.Stashed,
+		output:         []byte(r.Output),
+		maxOutputSize:  maxOutputSize,
+		outputTotal:    len(r.Output),
+		Progress:       r.Progress,
+		Error:          r.Error,
+		Warnings:       r.Warnings,
+		CostUSD:        r.CostUSD,
+		Turns:          r.Turns,
+		FilesModified:  r.FilesModified,
+		LinesAdded:     r.LinesAdded,
+		LinesRemoved:   r.LinesRemoved,
+		TimeoutMinutes: r.TimeoutMinutes,
+		DryRun:         r.DryRun,
+		Interrupted:    r.Interrupted,
+
+		DependsOn:           r.DependsOn,
+		OnDependencyFailure: r.OnDependencyFailure,
+		InheritBranch:       r.InheritBranch,
+		InheritSession:      r.InheritSession,
+		Retry:               decodeRetryPolicy(r.RetryPolicy),
+		MaxBudgetUSD:        r.MaxBudgetUSD,
+		ApprovalReason:      r.ApprovalReason,
+		ApprovedBy:          r.ApprovedBy,
+		PausedAt:            r.PausedAt,
+		PausedFor:           r.PausedFor,
+		Preemptions:         decodePreemptions(r.Preemptions),
+		CreatedAt:           r.CreatedAt,
+		StartedAt:           r.StartedAt,
+		CompletedAt:         r.CompletedAt,
+		done:                make(chan struct{}),
+	}
+	if t.Type == "" {
+		t.Type = TypeDispatched
+	}
+	if t.Priority == "" {
+		t.Priority = PriorityNormal
+	}
+	if t.TimeoutMinutes <= 0 {
+		t.TimeoutMinutes = 30
+	}
+
+	switch t.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		close(t.done)
+	}
+	return t
+}
@@ -0,0 +1,385 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+	"github.com/btouchard/herald/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.SQLiteStore {
+	t.Helper()
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "herald.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func testRequestBuilder(snap TaskSnapshot) (executor.Request, int, error) {
+	return executor.Request{
+		TaskID:    snap.ID,
+		Prompt:    snap.Prompt,
+		SessionID: snap.SessionID,
+	}, 0, nil
+}
+
+func TestManager_WriteThrough_PersistsLifecycle(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	mock := &mockExecutor{delay: 20 * time.Millisecond, output: "all done", cost: 0.12, turns: 2}
+	m := NewManager(mock, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	tk := m.Create("proj", "fix bug", "why", PriorityHigh, 30)
+	rec, err := db.GetTask(tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", rec.Status)
+	assert.Equal(t, "high", rec.Priority)
+
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+
+	assert.Eventually(t, func() bool {
+		rec, err := db.GetTask(tk.ID)
+		return err == nil && rec.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	rec, err = db.GetTask(tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ses_test123", rec.SessionID)
+	assert.Equal(t, "all done", rec.Output)
+	assert.InDelta(t, 0.12, rec.CostUSD, 0.001)
+	assert.Equal(t, 2, rec.Turns)
+	assert.Equal(t, 12345, rec.PID)
+	assert.False(t, rec.StartedAt.IsZero())
+	assert.False(t, rec.CompletedAt.IsZero())
+}
+
+func TestManager_WriteThrough_PersistsQueuedAndCancelled(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	m := NewManager(&mockExecutor{delay: 10 * time.Second}, 1, 2*time.Hour)
+	m.SetStore(db)
+
+	t1 := m.Create("proj", "one", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), t1, executor.Request{TaskID: t1.ID}, 0))
+	t2 := m.Create("proj", "two", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), t2, executor.Request{TaskID: t2.ID}, 0))
+
+	rec, err := db.GetTask(t2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", rec.Status)
+
+	require.NoError(t, m.Cancel(t2.ID))
+	rec, err = db.GetTask(t2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", rec.Status)
+
+	_ = m.Cancel(t1.ID)
+	<-t1.Done()
+}
+
+func TestManager_Restore_LoadsTerminalAndLinkedTasks(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-done0001", Type: "dispatched", Project: "proj", Prompt: "x",
+		Status: "completed", Priority: "normal", Output: "result text", CostUSD: 0.5,
+		GitBranch: "herald/feature", CreatedAt: now, StartedAt: now, CompletedAt: now.Add(time.Minute),
+	}))
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-link0001", Type: "linked", Project: "proj", Prompt: "",
+		Status: "linked", Priority: "normal", SessionID: "ses_linked", Output: "summary",
+		FilesModified: []string{"a.go"}, CreatedAt: now,
+	}))
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetStore(db)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	done, err := m.Get("herald-done0001")
+	require.NoError(t, err)
+	snap := done.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.Equal(t, "result text", snap.Output)
+	assert.Equal(t, "herald/feature", snap.GitBranch)
+	assert.Equal(t, time.Minute, snap.Duration())
+
+	select {
+	case <-done.Done():
+	default:
+		t.Fatal("restored terminal task should have a closed Done channel")
+	}
+
+	linked := m.GetBySessionID("ses_linked", StatusLinked)
+	require.NotNil(t, linked)
+	assert.Equal(t, []string{"a.go"}, linked.Snapshot().FilesModified)
+
+	assert.Len(t, m.List(Filter{Status: "all"}), 2)
+}
+
+func TestManager_Restore_WhenPolicyFail_FailsRunningAndQueuedTasks(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00001", Type: "dispatched", Project: "proj", Prompt: "was running",
+		Status: "running", Priority: "normal", PID: 4242, CreatedAt: now, StartedAt: now,
+	}))
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-que00001", Type: "dispatched", Project: "proj", Prompt: "was queued",
+		Status: "queued", Priority: "normal", CreatedAt: now.Add(time.Second),
+	}))
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 2)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetInterruptedPolicy(InterruptedFail)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	running, err := m.Get("herald-run00001")
+	require.NoError(t, err)
+	snap := running.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.True(t, snap.Interrupted)
+	assert.Equal(t, interruptedError, snap.Error)
+
+	rec, err := db.GetTask("herald-run00001")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", rec.Status)
+	assert.True(t, rec.Interrupted)
+
+	queued, err := m.Get("herald-que00001")
+	require.NoError(t, err)
+	snap = queued.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.True(t, snap.Interrupted)
+	assert.Equal(t, interruptedQueuedError, snap.Error)
+
+	rec, err = db.GetTask("herald-que00001")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", rec.Status)
+	assert.Empty(t, exec.reqs, "no interrupted task may start under the fail policy")
+}
+
+// recordingExecutor captures the requests it receives.
+type recordingExecutor struct {
+	reqs chan executor.Request
+}
+
+func (r *recordingExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "recording"}
+}
+
+func (r *recordingExecutor) Execute(_ context.Context, req executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	r.reqs <- req
+	return &executor.Result{SessionID: req.SessionID}, nil
+}
+
+func TestManager_Restore_WhenPolicyRequeue_ResumesSession(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00002", Type: "dispatched", Project: "proj", Prompt: "was running",
+		Status: "running", Priority: "normal", SessionID: "ses_inflight", CreatedAt: now, StartedAt: now,
+	}))
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetInterruptedPolicy(InterruptedRequeue)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	select {
+	case req := <-exec.reqs:
+		assert.Equal(t, "herald-run00002", req.TaskID)
+		assert.Equal(t, "ses_inflight", req.SessionID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("requeued task was not executed")
+	}
+}
+
+func TestManager_Restore_WhenBuilderFails_FailsTask(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-que00002", Type: "dispatched", Project: "removed", Prompt: "x",
+		Status: "queued", Priority: "normal", CreatedAt: time.Now(),
+	}))
+
+	m := NewManager(&mockExecutor{}, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetInterruptedPolicy(InterruptedRequeue)
+	require.NoError(t, m.Restore(func(TaskSnapshot) (executor.Request, int, error) {
+		return executor.Request{}, 0, fmt.Errorf("project %q not found", "removed")
+	}))
+
+	tk, err := m.Get("herald-que00002")
+	require.NoError(t, err)
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Contains(t, snap.Error, "cannot requeue")
+	assert.Contains(t, snap.Error, "removed")
+}
+
+// reattachingExecutor implements executor.Reattacher for restore tests.
+type reattachingExecutor struct {
+	recordingExecutor
+	reattachErr error
+	reattached  chan executor.ReattachRequest
+}
+
+func (r *reattachingExecutor) Reattach(_ context.Context, req executor.ReattachRequest, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	r.reattached <- req
+	if r.reattachErr != nil {
+		return nil, r.reattachErr
+	}
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressText, Text: "still working"})
+	return &executor.Result{SessionID: "ses_orphan", Output: "finished after restart", CostUSD: 0.3, Turns: 4}, nil
+}
+
+func TestManager_Restore_WhenExecutorReattaches_CompletesRunningTask(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	started := time.Now().Add(-5 * time.Minute).Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00003", Type: "dispatched", Project: "proj", Prompt: "was running",
+		Status: "running", Priority: "normal", PID: 4242, TimeoutMinutes: 30,
+		CreatedAt: started, StartedAt: started,
+	}))
+
+	exec := &reattachingExecutor{reattached: make(chan executor.ReattachRequest, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetInterruptedPolicy(InterruptedFail)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	req := <-exec.reattached
+	assert.Equal(t, "herald-run00003", req.TaskID)
+	assert.Equal(t, 4242, req.PID)
+
+	tk, err := m.Get("herald-run00003")
+	require.NoError(t, err)
+	select {
+	case <-tk.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("reattached task did not complete in time")
+	}
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.False(t, snap.Interrupted)
+	assert.Equal(t, "finished after restart", snap.Output)
+	assert.Equal(t, "ses_orphan", snap.SessionID)
+	assert.True(t, started.Equal(snap.StartedAt), "reattached task keeps its original start time")
+
+	assert.Eventually(t, func() bool {
+		rec, err := db.GetTask("herald-run00003")
+		return err == nil && rec.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestManager_Restore_WhenNotReattachable_AppliesInterruptedPolicy(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, db.CreateTask(&store.TaskRecord{
+		ID: "herald-run00004", Type: "dispatched", Project: "proj", Prompt: "was running",
+		Status: "running", Priority: "normal", PID: 4242, SessionID: "ses_lost",
+		CreatedAt: now, StartedAt: now,
+	}))
+
+	exec := &reattachingExecutor{
+		recordingExecutor: recordingExecutor{reqs: make(chan executor.Request, 1)},
+		reattachErr:       fmt.Errorf("%w: no spool", executor.ErrNotReattachable),
+		reattached:        make(chan executor.ReattachRequest, 1),
+	}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+	m.SetInterruptedPolicy(InterruptedRequeue)
+	require.NoError(t, m.Restore(testRequestBuilder))
+
+	<-exec.reattached
+	select {
+	case req := <-exec.reqs:
+		assert.Equal(t, "ses_lost", req.SessionID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not requeued after failed reattach")
+	}
+
+	tk, err := m.Get("herald-run00004")
+	require.NoError(t, err)
+	<-tk.Done()
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.True(t, snap.Interrupted)
+}
+
+// transcriptExecutor fails its first attempt, recording a transcript for
+// each one.
+type transcriptExecutor struct {
+	mu       sync.Mutex
+	attempts int
+}
+
+func (e *transcriptExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "transcript"}
+}
+
+func (e *transcriptExecutor) Execute(context.Context, executor.Request, executor.ProgressFunc) (*executor.Result, error) {
+	e.mu.Lock()
+	e.attempts++
+	n := e.attempts
+	e.mu.Unlock()
+
+	result := &executor.Result{Transcript: []executor.TranscriptEntry{
+		{Kind: executor.EntryToolUse, Tool: "Bash", Input: fmt.Sprintf(`{"command":"attempt %d"}`, n), At: time.Now()},
+		{Kind: executor.EntryToolResult, Tool: "Bash", Text: "ok", Duration: time.Second, At: time.Now()},
+	}}
+	if n == 1 {
+		return result, fmt.Errorf("attempt %d failed", n)
+	}
+	return result, nil
+}
+
+func TestManager_PersistsTranscriptOfEachAttempt(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	m := NewManager(&transcriptExecutor{}, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 2, Backoff: time.Millisecond}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+	require.Equal(t, StatusCompleted, tk.Snapshot().Status)
+
+	entries, err := db.ListTranscript(store.TranscriptFilter{TaskID: tk.ID})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, 1, entries[0].Attempt)
+	assert.JSONEq(t, `{"command":"attempt 1"}`, entries[0].Input)
+	assert.Equal(t, 2, entries[3].Attempt)
+	assert.Equal(t, 4, entries[3].Seq)
+	assert.Equal(t, time.Second, entries[3].Duration)
+}
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Preemption records a time a task was paused to make room for a task of
+// higher priority.
+type Preemption struct {
+	By        string    `json:"by"` // ID of the task that took the slot
+	PausedAt  time.Time `json:"paused_at"`
+	ResumedAt time.Time `json:"resumed_at,omitzero"` // zero while the task is still preempted
+}
+
+// SetPreemptPriority enables preemption: when no slot is free for a task of
+// priority p or higher, the running task of the lowest priority below it is
+// paused to make room, and resumed once that task ends. An empty priority
+// disables preemption.
+func (m *Manager) SetPreemptPriority(p Priority) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.preemptPriority = p
+}
+
+// preemptionLocked returns the preemption t is paused by, nil when t is not
+// preempted. Caller must hold t.mu.
+func (t *Task) preemptionLocked() *Preemption {
+	if t.Status != StatusPaused || len(t.Preemptions) == 0 {
+		return nil
+	}
+	p := &t.Preemptions[len(t.Preemptions)-1]
+	if !p.ResumedAt.IsZero() {
+		return nil
+	}
+	return p
+}
+
+// preemptLocked pauses a running task of lower priority than t so that t
+// can start, and returns it. It returns nil when preemption is disabled,
+// does not apply to t or no running task can make room for it. When t is
+// held back by its project's limit, only tasks of that project can, and
+// only when t would not share their checkout: a task frozen mid-edit in
+// the checkout t is about to stash and switch branches in would resume on
+// the wrong branch. Caller must hold m.mu.
+func (m *Manager) preemptLocked(t *Task, maxPerProject int) *Task {
+	if m.preemptPriority == "" || t.Priority.Weight() < m.preemptPriority.Weight() {
+		return nil
+	}
+	if m.checkoutBusyLocked(t) {
+		return nil
+	}
+	_, inProject := m.slotsInUseLocked(t.Project)
+	sameProject := maxPerProject > 0 && inProject >= maxPerProject
+
+	var candidates []*Task
+	for _, existing := range m.tasks {
+		existing.mu.RLock()
+		eligible := existing.Status == StatusRunning && existing.PID > 0 &&
+			existing.Priority.Weight() < t.Priority.Weight() &&
+			(!sameProject || existing.Project == t.Project)
+		existing.mu.RUnlock()
+		if eligible {
+			candidates = append(candidates, existing)
+		}
+	}
+	// Lowest priority first, then the most recently started: it loses the
+	// least work if it never gets to resume.
+	slices.SortFunc(candidates, func(a, b *Task) int {
+		if wa, wb := a.Priority.Weight(), b.Priority.Weight(); wa != wb {
+			return wa - wb
+		}
+		return b.Snapshot().StartedAt.Compare(a.Snapshot().StartedAt)
+	})
+
+	for _, victim := range candidates {
+		if err := suspend(victim); err != nil {
+			slog.Warn("failed to preempt task", "task_id", victim.ID, "for", t.ID, "error", err)
+			continue
+		}
+		victim.mu.Lock()
+		victim.Preemptions = append(victim.Preemptions, Preemption{By: t.ID, PausedAt: victim.PausedAt})
+		victim.mu.Unlock()
+		return victim
+	}
+	return nil
+}
+
+// notifyPreempted reports that victim was paused to make room for t.
+func (m *Manager) notifyPreempted(victim, t *Task) {
+	m.persist(victim)
+	slog.Info("task preempted",
+		"task_id", victim.ID,
+		"by", t.ID,
+		"priority", string(t.Priority))
+	m.emit(victim, "task.preempted", fmt.Sprintf("task paused to make room for %s task %s, resumes when it ends", t.Priority, t.ID))
+}
+
+// resumePreempted continues the preempted tasks whose preempting task has
+// ended, highest priority first, while slots are free.
+func (m *Manager) resumePreempted() {
+	m.mu.Lock()
+	var candidates []*Task
+	for _, t := range m.tasks {
+		t.mu.RLock()
+		p := t.preemptionLocked()
+		t.mu.RUnlock()
+		if p == nil {
+			continue
+		}
+		if by, ok := m.tasks[p.By]; ok && !by.IsTerminal() {
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+	slices.SortFunc(candidates, func(a, b *Task) int {
+		return b.Priority.Weight() - a.Priority.Weight()
+	})
+
+	var resumed []*Task
+	var pauses []time.Duration
+	for _, t := range candidates {
+		if !m.hasSlotLocked("", 0) {
+			break
+		}
+		t.mu.RLock()
+		project, maxPerProject := t.Project, t.maxPerProject
+		t.mu.RUnlock()
+		if !m.hasSlotLocked(project, maxPerProject) {
+			continue
+		}
+		paused, err := resume(t)
+		if err != nil {
+			slog.Warn("failed to resume preempted task", "task_id", t.ID, "error", err)
+			continue
+		}
+		resumed = append(resumed, t)
+		pauses = append(pauses, paused)
+	}
+	m.mu.Unlock()
+
+	for i, t := range resumed {
+		paused := pauses[i].Round(time.Second)
+		m.persist(t)
+		slog.Info("preempted task resumed", "task_id", t.ID, "paused", paused)
+		m.emit(t, "task.resumed", fmt.Sprintf("task resumed after %s preempted", paused))
+	}
+}
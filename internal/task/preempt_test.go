@@ -0,0 +1,180 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// startSleepingWith starts a task of the given priority on a sleepExecutor
+// and waits for its PID.
+func startSleepingWith(t *testing.T, m *Manager, project string, priority Priority) *Task {
+	t.Helper()
+	tk := m.Create(project, "sleep", "", priority, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	require.Eventually(t, func() bool { return tk.Snapshot().PID > 0 }, 5*time.Second, 5*time.Millisecond)
+	t.Cleanup(func() { _ = m.Cancel(tk.ID) })
+	return tk
+}
+
+func TestManager_Start_WhenPreemptionEnabled_PausesLowestPriority(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 2, 2*time.Hour)
+	m.SetPreemptPriority(PriorityUrgent)
+	events := make(chan TaskEvent, 32)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	normal := startSleepingWith(t, m, "api", PriorityNormal)
+	low := startSleepingWith(t, m, "api", PriorityLow)
+	urgent := startSleepingWith(t, m, "web", PriorityUrgent)
+
+	assert.Equal(t, StatusRunning, normal.Snapshot().Status)
+	snap := low.Snapshot()
+	assert.Equal(t, StatusPaused, snap.Status)
+	require.Len(t, snap.Preemptions, 1)
+	assert.Equal(t, urgent.ID, snap.Preemptions[0].By)
+	assert.True(t, snap.Preemptions[0].ResumedAt.IsZero())
+	require.Eventually(t, func() bool { return processState(t, snap.PID) == "T" }, 5*time.Second, 5*time.Millisecond)
+
+	var preempted bool
+	for len(events) > 0 {
+		if e := <-events; e.Type == "task.preempted" && e.TaskID == low.ID {
+			preempted = true
+		}
+	}
+	assert.True(t, preempted, "no task.preempted event")
+
+	require.NoError(t, m.Cancel(urgent.ID))
+	snap = low.Snapshot()
+	assert.Equal(t, StatusRunning, snap.Status)
+	assert.False(t, snap.Preemptions[0].ResumedAt.IsZero())
+	assert.Positive(t, snap.PausedFor)
+}
+
+func TestManager_Start_WhenPreemptionDisabled_QueuesTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	low := startSleepingWith(t, m, "api", PriorityLow)
+
+	urgent := m.Create("api", "sleep", "", PriorityUrgent, 30)
+	require.NoError(t, m.Start(context.Background(), urgent, executor.Request{TaskID: urgent.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(urgent.ID) })
+
+	assert.Equal(t, StatusQueued, urgent.Snapshot().Status)
+	assert.Equal(t, StatusRunning, low.Snapshot().Status)
+}
+
+func TestManager_Start_WhenBelowPreemptPriority_QueuesTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	m.SetPreemptPriority(PriorityUrgent)
+	low := startSleepingWith(t, m, "api", PriorityLow)
+
+	high := m.Create("api", "sleep", "", PriorityHigh, 30)
+	require.NoError(t, m.Start(context.Background(), high, executor.Request{TaskID: high.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(high.ID) })
+
+	assert.Equal(t, StatusQueued, high.Snapshot().Status)
+	assert.Empty(t, low.Snapshot().Preemptions)
+}
+
+func TestManager_Start_WhenProjectFull_PreemptsWithinProject(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	m.SetPreemptPriority(PriorityHigh)
+	other := startSleepingWith(t, m, "web", PriorityLow)
+	own := startSleepingWith(t, m, "api", PriorityNormal)
+
+	high := m.Create("api", "sleep", "", PriorityHigh, 30)
+	require.NoError(t, m.Start(context.Background(), high, executor.Request{TaskID: high.ID}, 1))
+	t.Cleanup(func() { _ = m.Cancel(high.ID) })
+
+	assert.Equal(t, StatusRunning, high.Snapshot().Status)
+	assert.Equal(t, StatusPaused, own.Snapshot().Status)
+	assert.Equal(t, StatusRunning, other.Snapshot().Status)
+}
+
+func TestManager_Start_WhenProjectFullAndCheckoutShared_QueuesTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	m.SetWorkspace(&fakeWorkspace{dir: "/projects/api", shared: true})
+	m.SetPreemptPriority(PriorityHigh)
+	own := startSleepingWith(t, m, "api", PriorityNormal)
+
+	high := m.Create("api", "sleep", "", PriorityHigh, 30)
+	require.NoError(t, m.Start(context.Background(), high, executor.Request{TaskID: high.ID}, 1))
+	t.Cleanup(func() { _ = m.Cancel(high.ID) })
+
+	assert.Equal(t, StatusQueued, high.Snapshot().Status, "it would stash and switch branches under the running task")
+	assert.Equal(t, StatusRunning, own.Snapshot().Status)
+}
+
+func TestManager_Dispatch_WhenQueuedTaskCanPreempt_PreemptsForIt(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 1, 2*time.Hour)
+	low := startSleepingWith(t, m, "api", PriorityLow)
+	urgent := m.Create("web", "sleep", "", PriorityUrgent, 30)
+	require.NoError(t, m.Start(context.Background(), urgent, executor.Request{TaskID: urgent.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(urgent.ID) })
+	require.Equal(t, StatusQueued, urgent.Snapshot().Status)
+
+	m.SetPreemptPriority(PriorityUrgent)
+	m.dispatch()
+
+	assert.Equal(t, StatusRunning, urgent.Snapshot().Status)
+	snap := low.Snapshot()
+	assert.Equal(t, StatusPaused, snap.Status)
+	require.Len(t, snap.Preemptions, 1)
+	assert.Equal(t, urgent.ID, snap.Preemptions[0].By)
+}
+
+func TestManager_ResumePreempted_WhenProjectFull_WaitsForProjectSlot(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 2, 2*time.Hour)
+	m.SetPreemptPriority(PriorityUrgent)
+	own := m.Create("api", "sleep", "", PriorityLow, 30)
+	require.NoError(t, m.Start(context.Background(), own, executor.Request{TaskID: own.ID}, 1))
+	require.Eventually(t, func() bool { return own.Snapshot().PID > 0 }, 5*time.Second, 5*time.Millisecond)
+	t.Cleanup(func() { _ = m.Cancel(own.ID) })
+	other := startSleepingWith(t, m, "web", PriorityNormal)
+	urgent := startSleepingWith(t, m, "web", PriorityUrgent)
+	require.Equal(t, StatusPaused, own.Snapshot().Status)
+
+	// Another task of the project takes the slot freed on "web".
+	next := m.Create("api", "sleep", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), next, executor.Request{TaskID: next.ID}, 1))
+	t.Cleanup(func() { _ = m.Cancel(next.ID) })
+	require.NoError(t, m.Cancel(other.ID))
+	require.Equal(t, StatusRunning, next.Snapshot().Status)
+
+	require.NoError(t, m.Cancel(urgent.ID))
+	assert.Equal(t, StatusPaused, own.Snapshot().Status, "api is at its max_concurrent_tasks")
+
+	require.NoError(t, m.Cancel(next.ID))
+	require.Eventually(t, func() bool { return own.Snapshot().Status == StatusRunning }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestPreemptions_RoundTripThroughRecord(t *testing.T) {
+	t.Parallel()
+
+	paused := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "herald-pre00001", Preemptions: []Preemption{
+		{By: "herald-urg00001", PausedAt: paused, ResumedAt: paused.Add(5 * time.Minute)},
+		{By: "herald-urg00002", PausedAt: paused.Add(time.Hour)},
+	}}
+
+	rec := toRecord(tk.Snapshot())
+	assert.Equal(t, tk.Preemptions, fromRecord(*rec, 0).Preemptions)
+}
@@ -0,0 +1,66 @@
+package task
+
+import "github.com/btouchard/herald/internal/executor"
+
+// progressFunc returns the executor callback that records progress for t.
+// spent is what earlier attempts of t cost, added to the cost the executor
+// reports with ProgressUsage events.
+func (m *Manager) progressFunc(t *Task, spent float64) executor.ProgressFunc {
+	return func(e executor.ProgressEvent) {
+		switch e.Kind {
+		case executor.ProgressUsage:
+			m.trackCost(t, spent+e.CostUSD)
+			return
+
+		case executor.ProgressStarted:
+			if e.PID > 0 {
+				t.SetPID(e.PID)
+				m.persist(t)
+			}
+
+		case executor.ProgressSession:
+			if e.SessionID != "" {
+				t.SetSessionID(e.SessionID)
+				m.persist(t)
+				m.record(t, "task.session", e.String())
+			}
+			return
+
+		case executor.ProgressToolFinished:
+			m.record(t, "task.tool_result", e.String())
+			return
+
+		case executor.ProgressWarning:
+			t.AddWarning(e.Text)
+			m.persist(t)
+			m.record(t, "task.warning", e.Text)
+			return
+
+		case executor.ProgressToolStarted:
+			m.emitProgress(t, "task.tool_use", e)
+			return
+
+		case executor.ProgressTodos:
+			t.SetTodos(e.Todos)
+			m.emitProgress(t, "task.todos", e)
+			return
+		}
+		m.emitProgress(t, "task.progress", e)
+	}
+}
+
+// emitProgress makes e the progress of t, records it as eventType and
+// notifies it as task.progress.
+func (m *Manager) emitProgress(t *Task, eventType string, e executor.ProgressEvent) {
+	message := e.String()
+	t.SetProgress(message)
+	m.record(t, eventType, message)
+	m.notify(t, TaskEvent{Type: "task.progress", Message: message, Progress: &e})
+}
+
+// SetTodos records the todo list the executor keeps for the task.
+func (t *Task) SetTodos(todos []executor.Todo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Todos = todos
+}
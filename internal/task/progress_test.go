@@ -0,0 +1,94 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/store"
+)
+
+// progressExecutor reports one progress event of each kind.
+type progressExecutor struct{}
+
+func (progressExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "progress"}
+}
+
+func (progressExecutor) Execute(_ context.Context, _ executor.Request, onProgress executor.ProgressFunc) (*executor.Result, error) {
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressStarted, PID: 4242})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressSession, SessionID: "ses_live"})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressToolStarted, Tool: "Bash", ToolUseID: "t1", Input: "go test ./..."})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressToolFinished, Tool: "Bash", ToolUseID: "t1", Duration: 2 * time.Second})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressUsage, CostUSD: 0.12})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressWarning, Text: "output truncated"})
+	onProgress(executor.ProgressEvent{Kind: executor.ProgressTodos, Todos: []executor.Todo{
+		{Content: "Fix the bug", Status: executor.TodoCompleted},
+		{Content: "Run tests", Status: executor.TodoInProgress},
+	}})
+	return &executor.Result{SessionID: "ses_live"}, nil
+}
+
+func TestManager_ProgressFunc_RecordsEachKindOfProgress(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	m := NewManager(progressExecutor{}, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	var mu sync.Mutex
+	var notified []TaskEvent
+	m.SetNotifyFunc(func(e TaskEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, e)
+	})
+
+	tk := m.Create("proj", "fix bug", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+
+	snap := tk.Snapshot()
+	assert.Equal(t, 4242, snap.PID)
+	assert.Equal(t, []string{"output truncated"}, snap.Warnings)
+	assert.Len(t, snap.Todos, 2)
+	assert.Equal(t, "Todos: 1/2 done — Run tests", snap.Progress)
+
+	var events []store.TaskEvent
+	require.Eventually(t, func() bool {
+		var err error
+		events, err = db.ListEvents(store.EventFilter{TaskID: tk.ID})
+		return err == nil && len(events) > 0 && events[len(events)-1].EventType == "task.completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var recorded []string
+	for _, e := range events[2:] { // after task.created and task.started
+		recorded = append(recorded, e.EventType+": "+e.Message)
+	}
+	assert.Equal(t, []string{
+		"task.progress: PID 4242",
+		"task.session: session ses_live initialized",
+		"task.tool_use: Using tool: Bash — go test ./...",
+		"task.tool_result: Bash finished in 2s",
+		"task.warning: output truncated",
+		"task.todos: Todos: 1/2 done — Run tests",
+		"task.completed: task completed successfully",
+	}, recorded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var progress []TaskEvent
+	for _, e := range notified {
+		if e.Type == "task.progress" {
+			progress = append(progress, e)
+		}
+	}
+	require.Len(t, progress, 3, "started, tool use and todos are notified")
+	require.NotNil(t, progress[2].Progress)
+	assert.Equal(t, executor.ProgressTodos, progress[2].Progress.Kind)
+}
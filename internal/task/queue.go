@@ -0,0 +1,88 @@
+package task
+
+import (
+	"sort"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// queuedTask is a task waiting for a concurrency slot, together with
+// everything needed to launch it once a slot frees up.
+type queuedTask struct {
+	task          *Task
+	req           executor.Request
+	maxPerProject int
+	weight        int
+	seq           uint64
+}
+
+// taskQueue holds tasks waiting for execution, ordered by priority weight
+// (highest first), then by creation time (oldest first).
+// It is not safe for concurrent use; callers hold Manager.mu.
+type taskQueue struct {
+	items []*queuedTask
+	seq   uint64
+}
+
+// push inserts a task at its ordered position.
+func (q *taskQueue) push(t *Task, req executor.Request, maxPerProject int) {
+	q.seq++
+	qt := &queuedTask{
+		task:          t,
+		req:           req,
+		maxPerProject: maxPerProject,
+		weight:        t.Priority.Weight(),
+		seq:           q.seq,
+	}
+
+	i := sort.Search(len(q.items), func(i int) bool {
+		return q.less(qt, q.items[i])
+	})
+	q.items = append(q.items, nil)
+	copy(q.items[i+1:], q.items[i:])
+	q.items[i] = qt
+}
+
+// less reports whether a should run before b.
+func (q *taskQueue) less(a, b *queuedTask) bool {
+	if a.weight != b.weight {
+		return a.weight > b.weight
+	}
+	if !a.task.CreatedAt.Equal(b.task.CreatedAt) {
+		return a.task.CreatedAt.Before(b.task.CreatedAt)
+	}
+	return a.seq < b.seq
+}
+
+// remove drops the task with the given ID. Returns false if it was not queued.
+func (q *taskQueue) remove(id string) bool {
+	for i, qt := range q.items {
+		if qt.task.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the 1-based queue position of a task, or 0 if not queued.
+func (q *taskQueue) position(id string) int {
+	for i, qt := range q.items {
+		if qt.task.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// len returns the number of queued tasks.
+func (q *taskQueue) len() int {
+	return len(q.items)
+}
+
+// snapshot returns a copy of the queued entries in execution order.
+func (q *taskQueue) snapshot() []*queuedTask {
+	out := make([]*queuedTask, len(q.items))
+	copy(out, q.items)
+	return out
+}
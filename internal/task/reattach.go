@@ -0,0 +1,77 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// reattacher returns the executor as an executor.Reattacher when it can
+// resume monitoring processes started before a restart.
+func (m *Manager) reattacher() (executor.Reattacher, bool) {
+	r, ok := m.executor.(executor.Reattacher)
+	return r, ok
+}
+
+// startReattach resumes monitoring a task whose process may have outlived
+// the previous Herald instance. The task keeps its original start time and
+// only gets what remains of its timeout; a paused task stays paused. Its
+// input went away with the previous instance, so it gets no inbox and
+// SendMessage rejects it. Must be called with m.mu held.
+func (m *Manager) startReattach(t *Task, r executor.Reattacher, build RequestBuilder) {
+	t.mu.Lock()
+	t.reattached = true
+	t.mu.Unlock()
+	if build != nil {
+		if _, maxPerProject, err := build(t.Snapshot()); err == nil {
+			t.mu.Lock()
+			t.maxPerProject = maxPerProject
+			t.mu.Unlock()
+		}
+	}
+	taskCtx, cancel := newPauseClock(context.Background(), m.timeoutOf(t), t.activeTime(), t.Status == StatusPaused)
+	m.watchTimeout(t, taskCtx)
+
+	m.cancelFuncs[t.ID] = cancel
+	untrack := m.trackLocked(t.ID)
+	go func() {
+		defer untrack()
+		m.reattach(taskCtx, cancel, t, r, build)
+	}()
+}
+
+func (m *Manager) reattach(ctx context.Context, cancel context.CancelFunc, t *Task, r executor.Reattacher, build RequestBuilder) {
+	defer m.dispatch()
+	defer m.persist(t)
+	defer cancel()
+	defer m.recoverPanic(t)
+
+	snap := t.Snapshot()
+	slog.Info("reattaching to task process",
+		"task_id", t.ID,
+		"pid", snap.PID)
+	m.emit(t, "task.progress", "reattached to running process after Herald restart")
+
+	result, err := r.Reattach(ctx, executor.ReattachRequest{TaskID: t.ID, PID: snap.PID}, m.progressFunc(t, 0))
+	if errors.Is(err, executor.ErrNotReattachable) {
+		slog.Warn("task process cannot be reattached", "task_id", t.ID, "error", err)
+		m.mu.Lock()
+		delete(m.cancelFuncs, t.ID)
+		m.mu.Unlock()
+		t.mu.Lock()
+		t.Status = StatusPending
+		t.Interrupted = true
+		t.PID = 0
+		t.mu.Unlock()
+		m.recoverInterrupted(t, true, build)
+		return
+	}
+
+	m.persistTranscript(t, len(snap.Attempts)+1, result)
+	if result != nil {
+		t.addEdits(editChanges(result.Transcript, snap.WorktreePath))
+	}
+	m.finish(ctx, t, result, err)
+}
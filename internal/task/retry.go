@@ -0,0 +1,164 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Attempt outcomes.
+const (
+	AttemptCompleted = "completed"
+	AttemptFailed    = "failed"
+	AttemptTimedOut  = "timed_out"
+	AttemptCancelled = "cancelled"
+)
+
+// Attempt records one execution of a task with a retry policy.
+type Attempt struct {
+	Number    int
+	Outcome   string // AttemptCompleted, AttemptFailed, AttemptTimedOut or AttemptCancelled
+	ExitCode  int
+	Error     string
+	RetryOn   string // failure class that triggered a retry, empty for the final attempt
+	SessionID string
+	CostUSD   float64
+	Turns     int
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// AddAttempt records a finished attempt.
+func (t *Task) AddAttempt(a Attempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Attempts = append(t.Attempts, a)
+}
+
+// execute runs the attempts of t until one succeeds or the retry policy
+// gives up, then records the final outcome. Failed attempts that are
+// retried are recorded as events but not notified.
+func (m *Manager) execute(ctx context.Context, t *Task, req executor.Request, timeout time.Duration) {
+	// A requeued task goes on counting its attempts and what they cost.
+	t.mu.RLock()
+	policy := t.Retry
+	n := len(t.Attempts) + 1
+	var spentCost float64
+	var spentTurns int
+	if n > 1 {
+		spentCost, spentTurns = t.CostUSD, t.Turns
+	}
+	t.mu.RUnlock()
+
+	for ; ; n++ {
+		if n > 1 {
+			timeout = m.timeoutOf(t) // extend_task applies to the next attempts too
+		}
+		attemptCtx, cancelAttempt := newPauseClock(ctx, timeout, 0, false)
+		m.watchTimeout(t, attemptCtx)
+		started := time.Now()
+		result, err := m.executor.Execute(attemptCtx, req, m.progressFunc(t, spentCost))
+		timedOut := attemptCtx.Err() == context.DeadlineExceeded
+		m.persistTranscript(t, n, result)
+		if result != nil {
+			t.addEdits(editChanges(result.Transcript, req.ProjectPath))
+		}
+		overBudget := t.budgetStopReason()
+		if err != nil && overBudget != "" {
+			err = fmt.Errorf("stopped over budget: %s", overBudget)
+		}
+
+		var retryOn string
+		if err != nil && ctx.Err() == nil && overBudget == "" && policy.Enabled() && n < policy.MaxAttempts {
+			var exitCode int
+			var stderr string
+			if result != nil {
+				exitCode, stderr = result.ExitCode, result.Stderr
+			}
+			retryOn = policy.Match(exitCode, stderr, timedOut)
+		}
+		if policy.Enabled() {
+			a := newAttempt(n, started, result, err, timedOut, ctx.Err() != nil)
+			a.RetryOn = retryOn
+			t.AddAttempt(a)
+			m.persistAttempt(t, a)
+		}
+
+		if retryOn == "" {
+			if result != nil {
+				result.CostUSD += spentCost
+				result.Turns += spentTurns
+			}
+			m.finish(attemptCtx, t, result, err)
+			cancelAttempt()
+			return
+		}
+		cancelAttempt()
+
+		if result != nil {
+			spentCost += result.CostUSD
+			spentTurns += result.Turns
+			m.setCost(t, spentCost)
+			t.SetTurns(spentTurns)
+			t.AppendOutput(result.Output)
+			if result.SessionID != "" {
+				t.SetSessionID(result.SessionID)
+				if policy.ResumeSession {
+					req.SessionID = result.SessionID
+				}
+			}
+		}
+
+		delay := policy.Delay(n)
+		slog.Warn("task attempt failed, retrying",
+			"task_id", t.ID,
+			"attempt", n,
+			"max_attempts", policy.MaxAttempts,
+			"retry_on", retryOn,
+			"delay", delay,
+			"error", err)
+		t.SetPID(0)
+		t.SetProgress(fmt.Sprintf("attempt %d of %d failed (%s), retrying in %s", n, policy.MaxAttempts, retryOn, delay))
+		m.persist(t)
+		m.record(t, "task.attempt_failed", fmt.Sprintf("attempt %d of %d failed (%s): %v, retrying in %s", n, policy.MaxAttempts, retryOn, err, delay))
+
+		select {
+		case <-ctx.Done():
+			m.finish(ctx, t, nil, ctx.Err())
+			return
+		case <-time.After(delay):
+		}
+		if err := m.CheckBudget(t.Project); err != nil {
+			m.finish(ctx, t, nil, err)
+			return
+		}
+		t.AppendOutput(fmt.Sprintf("\n--- attempt %d of %d ---\n", n+1, policy.MaxAttempts))
+	}
+}
+
+// newAttempt describes attempt n from its executor outcome.
+func newAttempt(n int, started time.Time, result *executor.Result, err error, timedOut, cancelled bool) Attempt {
+	a := Attempt{Number: n, Outcome: AttemptCompleted, StartedAt: started, EndedAt: time.Now()}
+	if result != nil {
+		a.ExitCode = result.ExitCode
+		a.SessionID = result.SessionID
+		a.CostUSD = result.CostUSD
+		a.Turns = result.Turns
+	}
+	switch {
+	case err == nil:
+	case timedOut:
+		a.Outcome = AttemptTimedOut
+		a.Error = "task timed out"
+	case cancelled:
+		a.Outcome = AttemptCancelled
+		a.Error = "task cancelled"
+	default:
+		a.Outcome = AttemptFailed
+		a.Error = err.Error()
+	}
+	return a
+}
@@ -0,0 +1,220 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
+)
+
+// attemptOutcome is what scriptedExecutor returns for one attempt.
+type attemptOutcome struct {
+	result *executor.Result
+	err    error
+	block  bool // wait for the context to end
+}
+
+// scriptedExecutor returns one outcome per call, in order, and records the
+// requests it receives.
+type scriptedExecutor struct {
+	mu       sync.Mutex
+	outcomes []attemptOutcome
+	reqs     []executor.Request
+}
+
+func (s *scriptedExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{Name: "scripted"}
+}
+
+func (s *scriptedExecutor) Execute(ctx context.Context, req executor.Request, _ executor.ProgressFunc) (*executor.Result, error) {
+	s.mu.Lock()
+	s.reqs = append(s.reqs, req)
+	o := s.outcomes[0]
+	if len(s.outcomes) > 1 {
+		s.outcomes = s.outcomes[1:]
+	}
+	s.mu.Unlock()
+
+	if o.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return o.result, o.err
+}
+
+func (s *scriptedExecutor) requests() []executor.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]executor.Request(nil), s.reqs...)
+}
+
+func exitFailure(code int, stderr, session string) attemptOutcome {
+	return attemptOutcome{
+		result: &executor.Result{ExitCode: code, Stderr: stderr, SessionID: session, CostUSD: 0.10, Turns: 2, Output: "partial"},
+		err:    fmt.Errorf("claude exited with code %d", code),
+	}
+}
+
+func success(session string) attemptOutcome {
+	return attemptOutcome{result: &executor.Result{SessionID: session, CostUSD: 0.25, Turns: 3, Output: "done"}}
+}
+
+func waitDone(t *testing.T, tk *Task) {
+	t.Helper()
+	select {
+	case <-tk.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("task did not finish")
+	}
+}
+
+func TestManager_Run_WhenFailureRetryable_RetriesAndNotifiesOnlyOutcome(t *testing.T) {
+	t.Parallel()
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{
+		exitFailure(1, "API Error: 529 overloaded_error", "ses_first"),
+		success("ses_first"),
+	}}
+	m := NewManager(exec, 3, 2*time.Hour)
+
+	var mu sync.Mutex
+	var events []string
+	m.SetNotifyFunc(func(e TaskEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Type != "task.progress" {
+			events = append(events, e.Type)
+		}
+	})
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond, StderrPatterns: []string{"overloaded"}, ResumeSession: true}
+	require.NoError(t, tk.Retry.Validate())
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	assert.InDelta(t, 0.35, snap.CostUSD, 0.001)
+	assert.Equal(t, 5, snap.Turns)
+	assert.Contains(t, snap.Output, "partial")
+	assert.Contains(t, snap.Output, "--- attempt 2 of 3 ---")
+
+	require.Len(t, snap.Attempts, 2)
+	assert.Equal(t, AttemptFailed, snap.Attempts[0].Outcome)
+	assert.Equal(t, 1, snap.Attempts[0].ExitCode)
+	assert.Equal(t, `stderr matches "overloaded"`, snap.Attempts[0].RetryOn)
+	assert.Equal(t, AttemptCompleted, snap.Attempts[1].Outcome)
+	assert.Empty(t, snap.Attempts[1].RetryOn)
+
+	reqs := exec.requests()
+	require.Len(t, reqs, 2)
+	assert.Empty(t, reqs[0].SessionID)
+	assert.Equal(t, "ses_first", reqs[1].SessionID, "the retry resumes the failed attempt's session")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"task.started", "task.completed"}, events)
+}
+
+func TestManager_Run_WhenFailureNotRetryable_FailsRightAway(t *testing.T) {
+	t.Parallel()
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{exitFailure(2, "invalid prompt", "")}}
+	m := NewManager(exec, 3, 2*time.Hour)
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond, ExitCodes: []int{1}}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Contains(t, snap.Error, "code 2")
+	require.Len(t, snap.Attempts, 1)
+	assert.Empty(t, snap.Attempts[0].RetryOn)
+	assert.Len(t, exec.requests(), 1)
+}
+
+func TestManager_Run_WhenAttemptsExhausted_FailsWithEveryAttemptRecorded(t *testing.T) {
+	t.Parallel()
+	db := newTestStore(t)
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{exitFailure(1, "", "")}}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetStore(db)
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	require.Len(t, snap.Attempts, 3)
+	assert.Equal(t, "failure", snap.Attempts[1].RetryOn)
+	assert.Empty(t, snap.Attempts[2].RetryOn)
+	assert.InDelta(t, 0.30, snap.CostUSD, 0.001)
+
+	records, err := db.ListAttempts(tk.ID)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, 3, records[2].Number)
+
+	// The policy and the attempts survive a restart.
+	m2 := NewManager(exec, 3, 2*time.Hour)
+	m2.SetStore(db)
+	require.NoError(t, m2.Restore(testRequestBuilder))
+	restored, err := m2.Get(tk.ID)
+	require.NoError(t, err)
+	rsnap := restored.Snapshot()
+	require.NotNil(t, rsnap.Retry)
+	assert.Equal(t, 3, rsnap.Retry.MaxAttempts)
+	assert.Len(t, rsnap.Attempts, 3)
+}
+
+func TestManager_Run_WhenTimedOutAndOnTimeout_Retries(t *testing.T) {
+	t.Parallel()
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{{block: true}, success("")}}
+	m := NewManager(exec, 3, 50*time.Millisecond) // every attempt times out after 50ms
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 2, Backoff: time.Millisecond, OnTimeout: true}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	waitDone(t, tk)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusCompleted, snap.Status)
+	require.Len(t, snap.Attempts, 2)
+	assert.Equal(t, AttemptTimedOut, snap.Attempts[0].Outcome)
+	assert.Equal(t, "timeout", snap.Attempts[0].RetryOn)
+}
+
+func TestManager_Cancel_WhenWaitingForRetry_CancelsTask(t *testing.T) {
+	t.Parallel()
+
+	exec := &scriptedExecutor{outcomes: []attemptOutcome{exitFailure(1, "", ""), success("")}}
+	m := NewManager(exec, 3, 2*time.Hour)
+
+	tk := m.Create("proj", "refactor", "", PriorityNormal, 30)
+	tk.Retry = &retry.Policy{MaxAttempts: 3, Backoff: time.Hour}
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(tk.Snapshot().Progress, "retrying in 1h0m0s")
+	}, 5*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, m.Cancel(tk.ID))
+	waitDone(t, tk)
+	assert.Equal(t, StatusCancelled, tk.Snapshot().Status)
+	assert.Len(t, exec.requests(), 1)
+}
@@ -0,0 +1,101 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// revertRequest asks a task's execution goroutine to revert its workspace
+// instead of releasing it. The outcome is read once stopped is closed.
+type revertRequest struct {
+	stopped <-chan struct{}
+
+	reverted bool
+	undone   []string
+	err      error
+}
+
+// trackLocked registers the execution goroutine of a task and returns the
+// func that goroutine must call when it exits. Caller must hold m.mu.
+func (m *Manager) trackLocked(id string) func() {
+	stopped := make(chan struct{})
+	m.running[id] = stopped
+	return func() {
+		m.mu.Lock()
+		if m.running[id] == stopped {
+			delete(m.running, id)
+		}
+		m.mu.Unlock()
+		close(stopped)
+	}
+}
+
+// CancelAndRevert cancels a task like Cancel, waits for its process to
+// stop and undoes the changes it made to the project (see
+// Workspace.Revert) instead of the usual release. It returns a line per
+// step undone. If ctx ends first, the revert still completes in the
+// background.
+func (m *Manager) CancelAndRevert(ctx context.Context, id string) ([]string, error) {
+	m.mu.Lock()
+	var rv *revertRequest
+	if stopped, ok := m.running[id]; ok {
+		rv = &revertRequest{stopped: stopped}
+		m.reverts[id] = rv
+	}
+	m.mu.Unlock()
+
+	if err := m.Cancel(id); err != nil {
+		m.mu.Lock()
+		delete(m.reverts, id)
+		m.mu.Unlock()
+		return nil, err
+	}
+	if rv == nil {
+		return nil, nil // never started, nothing to undo
+	}
+
+	select {
+	case <-rv.stopped:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("task is still stopping, the revert will complete in the background: %w", ctx.Err())
+	}
+
+	m.mu.Lock()
+	delete(m.reverts, id)
+	m.mu.Unlock()
+
+	if !rv.reverted {
+		return nil, fmt.Errorf("task %q finished before it could be reverted", id)
+	}
+	return rv.undone, rv.err
+}
+
+// revertWorkspace performs a pending revert request for t. It reports
+// false when none was made, in which case the workspace should be
+// released as usual.
+func (m *Manager) revertWorkspace(t *Task) bool {
+	m.mu.Lock()
+	rv := m.reverts[t.ID]
+	delete(m.reverts, t.ID)
+	m.mu.Unlock()
+	if rv == nil {
+		return false
+	}
+
+	rv.undone, rv.err = m.workspace.Revert(context.Background(), t)
+	rv.reverted = true
+	m.persist(t)
+
+	if rv.err != nil {
+		slog.Error("task revert incomplete",
+			"task_id", t.ID,
+			"undone", rv.undone,
+			"error", rv.err)
+		m.record(t, "task.revert_failed", fmt.Sprintf("revert incomplete: %v", rv.err))
+	} else {
+		slog.Info("task reverted", "task_id", t.ID, "undone", rv.undone)
+		m.record(t, "task.reverted", "task changes reverted")
+	}
+	return true
+}
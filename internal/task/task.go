@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/retry"
 )
 
 // Type distinguishes how a task was created.
@@ -19,13 +22,16 @@ const (
 type Status string
 
 const (
-	StatusPending   Status = "pending"
-	StatusQueued    Status = "queued"
-	StatusRunning   Status = "running"
-	StatusCompleted Status = "completed"
-	StatusFailed    Status = "failed"
-	StatusCancelled Status = "cancelled"
-	StatusLinked    Status = "linked" // external session registered, not managed by Herald
+	StatusPending          Status = "pending"
+	StatusAwaitingApproval Status = "awaiting_approval" // held until someone approves it
+	StatusQueued           Status = "queued"
+	StatusWaiting          Status = "waiting" // held until the tasks it depends on complete
+	StatusRunning          Status = "running"
+	StatusPaused           Status = "paused" // process stopped with SIGSTOP until resumed
+	StatusCompleted        Status = "completed"
+	StatusFailed           Status = "failed"
+	StatusCancelled        Status = "cancelled"
+	StatusLinked           Status = "linked" // external session registered, not managed by Herald
 )
 
 // Priority determines task ordering in the execution queue.
@@ -70,22 +76,57 @@ type Task struct {
 	MCPSessionID   string // MCP client session for push notifications (runtime-only)
 	PID            int
 	GitBranch      string
+	WorktreePath   string // dedicated git worktree the task runs in, if any
+	BaseCommit     string // commit the task branch was created from
+	OriginalBranch string // branch the project checkout was on before the task switched it
+	BranchCreated  bool   // GitBranch was created for this task
+	Stashed        bool   // local changes were stashed before the task ran
 
 	output        []byte
 	maxOutputSize int
 	outputTotal   int
 	Progress      string
+	Todos         []executor.Todo // the todo list the executor keeps while it runs
 	Error         string
+	Warnings      []string // non-fatal problems, e.g. git steps around execution
 
-	CostUSD      float64
-	Turns        int
+	CostUSD       float64
+	Turns         int
 	FilesModified []string
-	LinesAdded   int
-	LinesRemoved int
+	LinesAdded    int
+	LinesRemoved  int
+	edits         Changes // changes seen in the transcript, for projects git cannot measure
 
 	TimeoutMinutes int
 	DryRun         bool
 	AllowedTools   []string
+	Interrupted    bool // Herald stopped while the task was running or queued
+
+	DependsOn           []string // tasks that must complete before this one starts
+	OnDependencyFailure string   // DependencyCancel or DependencyFail
+	InheritBranch       bool     // run on the first dependency's git branch
+	InheritSession      bool     // resume the first dependency's Claude Code session
+
+	Retry    *retry.Policy // how failed attempts are retried, nil for no retries
+	Attempts []Attempt     // attempts so far, recorded when Retry allows more than one
+
+	MaxBudgetUSD float64 // cost cap of the task, 0 for none
+	budgetStop   string  // why the task was stopped for going over budget
+
+	PendingPermissions []PermissionRequest // tool uses waiting for approval
+	grantedUses        []string            // tool uses approved for the rest of the task, see toolUseKey
+
+	ApprovalReason string // why the task needs approval before it starts, empty when it does not
+	ApprovedBy     string // how the task was approved (ApprovalChat, ApprovalCLI or ApprovalLink)
+
+	PausedAt  time.Time     // when the task was paused, zero unless paused
+	PausedFor time.Duration // time spent paused before PausedAt
+	clock     *pauseClock   // timeout of the current attempt, stopped while paused
+
+	Preemptions []Preemption // times the task was paused for a task of higher priority
+
+	maxPerProject int  // max_concurrent_tasks of its project when it started, 0 for no limit
+	reattached    bool // its process was started by a previous Herald instance
 
 	CreatedAt   time.Time
 	StartedAt   time.Time
@@ -218,6 +259,45 @@ func (t *Task) SetSessionID(id string) {
 	t.SessionID = id
 }
 
+// SetWorktree records the git worktree and branch the task runs on.
+func (t *Task) SetWorktree(path, branch string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.WorktreePath = path
+	t.GitBranch = branch
+}
+
+// SetGitBranch records the branch the task runs on.
+func (t *Task) SetGitBranch(branch string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.GitBranch = branch
+}
+
+// SetBaseCommit records the commit the task branch was created from.
+func (t *Task) SetBaseCommit(hash string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.BaseCommit = hash
+}
+
+// SetCheckout records how the project checkout was changed for the task,
+// so it can be restored or reverted afterwards.
+func (t *Task) SetCheckout(originalBranch string, branchCreated, stashed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.OriginalBranch = originalBranch
+	t.BranchCreated = branchCreated
+	t.Stashed = stashed
+}
+
+// AddWarning records a non-fatal problem to report with the task result.
+func (t *Task) AddWarning(msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Warnings = append(t.Warnings, msg)
+}
+
 // SetPID stores the process ID.
 func (t *Task) SetPID(pid int) {
 	t.mu.Lock()
@@ -225,7 +305,8 @@ func (t *Task) SetPID(pid int) {
 	t.PID = pid
 }
 
-// SetCost updates the accumulated cost.
+// SetCost updates the accumulated cost without charging it to the budgets,
+// which the manager does as the executor reports cost.
 func (t *Task) SetCost(usd float64) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -286,10 +367,18 @@ func (t *Task) Snapshot() TaskSnapshot {
 		Model:          t.Model,
 		SessionID:      t.SessionID,
 		MCPSessionID:   t.MCPSessionID,
+		PID:            t.PID,
 		GitBranch:      t.GitBranch,
+		WorktreePath:   t.WorktreePath,
+		BaseCommit:     t.BaseCommit,
+		OriginalBranch: t.OriginalBranch,
+		BranchCreated:  t.BranchCreated,
+		Stashed:        t.Stashed,
 		Output:         string(t.output),
 		Progress:       t.Progress,
+		Todos:          append([]executor.Todo(nil), t.Todos...),
 		Error:          t.Error,
+		Warnings:       append([]string(nil), t.Warnings...),
 		CostUSD:        t.CostUSD,
 		Turns:          t.Turns,
 		FilesModified:  t.FilesModified,
@@ -297,9 +386,32 @@ func (t *Task) Snapshot() TaskSnapshot {
 		LinesRemoved:   t.LinesRemoved,
 		TimeoutMinutes: t.TimeoutMinutes,
 		DryRun:         t.DryRun,
-		CreatedAt:      t.CreatedAt,
-		StartedAt:      t.StartedAt,
-		CompletedAt:    t.CompletedAt,
+		Interrupted:    t.Interrupted,
+
+		DependsOn:           append([]string(nil), t.DependsOn...),
+		OnDependencyFailure: t.OnDependencyFailure,
+		InheritBranch:       t.InheritBranch,
+		InheritSession:      t.InheritSession,
+
+		Retry:    t.Retry,
+		Attempts: append([]Attempt(nil), t.Attempts...),
+
+		MaxBudgetUSD: t.MaxBudgetUSD,
+
+		PendingPermissions: append([]PermissionRequest(nil), t.PendingPermissions...),
+
+		ApprovalReason: t.ApprovalReason,
+		ApprovedBy:     t.ApprovedBy,
+
+		PausedAt:  t.PausedAt,
+		PausedFor: t.PausedFor,
+		TimeLeft:  t.timeLeftLocked(),
+
+		Preemptions: append([]Preemption(nil), t.Preemptions...),
+
+		CreatedAt:   t.CreatedAt,
+		StartedAt:   t.StartedAt,
+		CompletedAt: t.CompletedAt,
 	}
 }
 
@@ -315,10 +427,18 @@ type TaskSnapshot struct {
 	Model          string
 	SessionID      string
 	MCPSessionID   string
+	PID            int
 	GitBranch      string
+	WorktreePath   string
+	BaseCommit     string
+	OriginalBranch string
+	BranchCreated  bool
+	Stashed        bool
 	Output         string
 	Progress       string
+	Todos          []executor.Todo
 	Error          string
+	Warnings       []string
 	CostUSD        float64
 	Turns          int
 	FilesModified  []string
@@ -326,9 +446,32 @@ type TaskSnapshot struct {
 	LinesRemoved   int
 	TimeoutMinutes int
 	DryRun         bool
-	CreatedAt      time.Time
-	StartedAt      time.Time
-	CompletedAt    time.Time
+	Interrupted    bool
+
+	DependsOn           []string
+	OnDependencyFailure string
+	InheritBranch       bool
+	InheritSession      bool
+
+	Retry    *retry.Policy
+	Attempts []Attempt
+
+	MaxBudgetUSD float64
+
+	PendingPermissions []PermissionRequest
+
+	ApprovalReason string
+	ApprovedBy     string
+
+	PausedAt  time.Time
+	PausedFor time.Duration
+	TimeLeft  time.Duration // until the task times out, 0 unless running or paused
+
+	Preemptions []Preemption
+
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	CompletedAt time.Time
 }
 
 // Duration returns the elapsed time from start to completion (or now if still running).
@@ -0,0 +1,97 @@
+package task
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SetTimeoutWarning makes running tasks emit a task.timeout_warning event
+// when before remains until their timeout. Zero disables the warning. Like SetMaxOutputSize, it must be called before tasks start.
+func (m *Manager) SetTimeoutWarning(before time.Duration) {
+	m.timeoutWarning = before
+}
+
+// Extend replaces the timeout of a running or paused task with minutes,
+// counted from when its current attempt started, and returns the time it
+// has left. The new timeout may be shorter than the previous one, but not
+// over the max timeout, nor shorter than what the task has already run.
+func (m *Manager) Extend(id string, minutes int) (time.Duration, error) {
+	if minutes < 1 {
+		return 0, fmt.Errorf("timeout must be at least 1 minute, got %d", minutes)
+	}
+	timeout := time.Duration(minutes) * time.Minute
+	if timeout > m.maxTimeout {
+		return 0, fmt.Errorf("timeout of %s is over the max timeout of %s", timeout, m.maxTimeout)
+	}
+
+	t, err := m.Get(id)
+	if err != nil {
+		return 0, err
+	}
+
+	t.mu.Lock()
+	if t.Status != StatusRunning && t.Status != StatusPaused {
+		status := t.Status
+		t.mu.Unlock()
+		return 0, fmt.Errorf("task %q is %s: only running or paused tasks have a timeout to change", id, status)
+	}
+	if err := t.clock.setTimeout(timeout); err != nil {
+		t.mu.Unlock()
+		return 0, fmt.Errorf("cannot set the timeout of task %q to %s: %w", id, timeout, err)
+	}
+	previous := t.TimeoutMinutes
+	t.TimeoutMinutes = minutes
+	left := t.clock.left()
+	t.mu.Unlock()
+
+	m.persist(t)
+	m.record(t, "task.timeout_changed", fmt.Sprintf("timeout changed from %d to %d minutes, %s left", previous, minutes, left.Round(time.Second)))
+	slog.Info("task timeout changed",
+		"task_id", id,
+		"from_minutes", previous,
+		"to_minutes", minutes,
+		"left", left.Round(time.Second))
+	return left, nil
+}
+
+// timeoutOf returns the timeout of each attempt of t, clamped to the max
+// timeout.
+func (m *Manager) timeoutOf(t *Task) time.Duration {
+	t.mu.RLock()
+	timeout := time.Duration(t.TimeoutMinutes) * time.Minute
+	t.mu.RUnlock()
+	if timeout > m.maxTimeout {
+		slog.Warn("task timeout clamped to max",
+			"task_id", t.ID,
+			"requested", timeout,
+			"max", m.maxTimeout)
+		timeout = m.maxTimeout
+	}
+	return timeout
+}
+
+// timeLeftLocked returns how long t may still run before it times out, 0
+// unless it is running or paused. Caller must hold t.mu.
+func (t *Task) timeLeftLocked() time.Duration {
+	if t.Status != StatusRunning && t.Status != StatusPaused {
+		return 0
+	}
+	return max(t.clock.left(), 0)
+}
+
+// watchTimeout makes clock the timeout of t, which Pause, Resume and
+// Extend act on, and arms its timeout warning.
+func (m *Manager) watchTimeout(t *Task, clock *pauseClock) {
+	t.mu.Lock()
+	t.clock = clock
+	t.mu.Unlock()
+
+	if m.timeoutWarning > 0 {
+		clock.warn(m.timeoutWarning, func(left time.Duration) {
+			msg := fmt.Sprintf("task times out in %s — use extend_task to give it more time", left.Round(time.Second))
+			slog.Info("task timeout warning", "task_id", t.ID, "left", left.Round(time.Second))
+			m.emit(t, "task.timeout_warning", msg)
+		})
+	}
+}
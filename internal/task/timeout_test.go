@@ -0,0 +1,129 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+func TestManager_Extend_ReplacesTimeoutOfRunningTask(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	tk := startSleeping(t, m)
+
+	left, err := m.Extend(tk.ID, 90)
+	require.NoError(t, err)
+	assert.InDelta(t, float64(90*time.Minute), float64(left), float64(time.Minute))
+	assert.Equal(t, 90, tk.Snapshot().TimeoutMinutes)
+
+	left, err = m.Extend(tk.ID, 10)
+	require.NoError(t, err, "shortening is allowed")
+	assert.LessOrEqual(t, left, 10*time.Minute)
+}
+
+func TestManager_Extend_WhenPaused_KeepsClockStopped(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	tk := startSleeping(t, m)
+	require.NoError(t, m.Pause(tk.ID))
+
+	left, err := m.Extend(tk.ID, 60)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, left, tk.clock.left())
+}
+
+func TestManager_Extend_RejectsInvalidTimeouts(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	tk := startSleeping(t, m)
+
+	_, err := m.Extend(tk.ID, 121)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "over the max timeout")
+
+	_, err = m.Extend(tk.ID, 0)
+	require.Error(t, err)
+
+	queued := m.Create("proj", "x", "", PriorityNormal, 30)
+	_, err = m.Extend(queued.ID, 60)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only running or paused tasks")
+}
+
+func TestManager_TimeoutWarning_EmitsEventBeforeTimeout(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(sleepExecutor{}, 3, 2*time.Hour)
+	m.SetTimeoutWarning(time.Minute - 50*time.Millisecond)
+	events := make(chan TaskEvent, 16)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	tk := m.Create("proj", "sleep", "", PriorityNormal, 1)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	t.Cleanup(func() { _ = m.Cancel(tk.ID) })
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.Type == "task.timeout_warning" {
+				assert.Contains(t, e.Message, "extend_task")
+				return
+			}
+		case <-deadline:
+			t.Fatal("no timeout warning")
+		}
+	}
+}
+
+func TestPauseClock_SetTimeout_MovesDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := newPauseClock(context.Background(), 50*time.Millisecond, 0, false)
+	defer cancel()
+	require.NoError(t, ctx.setTimeout(time.Hour))
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ctx.Err(), "clock timed out on its previous timeout")
+
+	require.Error(t, ctx.setTimeout(50*time.Millisecond), "already ran longer than that")
+	require.NoError(t, ctx.setTimeout(150*time.Millisecond))
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("clock did not time out on its new timeout")
+	}
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestPauseClock_Warn_FiresOncePerTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := newPauseClock(context.Background(), 100*time.Millisecond, 0, false)
+	defer cancel()
+	warnings := make(chan time.Duration, 4)
+	ctx.warn(80*time.Millisecond, func(left time.Duration) { warnings <- left })
+
+	select {
+	case left := <-warnings:
+		assert.LessOrEqual(t, left, 80*time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no warning")
+	}
+
+	require.NoError(t, ctx.setTimeout(time.Hour))
+	select {
+	case <-warnings:
+		t.Fatal("warned again long before the new timeout")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"context"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// Workspace prepares the working tree a task executes in and tidies it up
+// once the task is terminal. Defined at the consumer side per Go
+// convention; implemented by workspace.Manager.
+type Workspace interface {
+	// Prepare runs right before execution. It may point req.ProjectPath at
+	// another directory, recording it on t (see Task.SetWorktree).
+	Prepare(ctx context.Context, t *Task, req *executor.Request) error
+	// Release runs after execution, once t is in its terminal state and
+	// before its outcome is notified. Warnings it adds are notified with it.
+	Release(ctx context.Context, t *Task)
+	// Revert runs instead of Release for a task cancelled with
+	// CancelAndRevert. It undoes the task's changes and returns a line per
+	// step undone.
+	Revert(ctx context.Context, t *Task) ([]string, error)
+}
+
+// CheckoutSharer is an optional interface for a Workspace whose tasks of
+// some projects all run in the project checkout, which only one of them
+// can use at a time, e.g. for branch and stash automation.
+type CheckoutSharer interface {
+	SharesCheckout(project string) bool
+}
+
+// SetWorkspace installs the hook that prepares and releases task working
+// trees around execution.
+func (m *Manager) SetWorkspace(w Workspace) {
+	m.workspace = w
+}
+
+// checkoutBusyLocked reports whether another task of t's project is
+// running in the project checkout t would share with it, or paused there
+// mid-edit. t then waits in the queue rather than holding a slot while
+// Prepare waits for the checkout. Caller must hold m.mu.
+func (m *Manager) checkoutBusyLocked(t *Task) bool {
+	cs, ok := m.workspace.(CheckoutSharer)
+	if !ok || !cs.SharesCheckout(t.Project) {
+		return false
+	}
+	for _, existing := range m.tasks {
+		if existing == t {
+			continue
+		}
+		existing.mu.RLock()
+		busy := existing.Project == t.Project &&
+			(existing.Status == StatusRunning || existing.Status == StatusPaused)
+		existing.mu.RUnlock()
+		if busy {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareWorkspace runs the workspace hook before execution and persists
+// whatever it recorded on the task.
+func (m *Manager) prepareWorkspace(ctx context.Context, t *Task, req *executor.Request) error {
+	if m.workspace == nil {
+		return nil
+	}
+	seen := t.warningCount()
+	err := m.workspace.Prepare(ctx, t, req)
+	m.recordWarnings(t, seen)
+	if err != nil {
+		return err
+	}
+	m.persist(t)
+	return nil
+}
+
+// releaseWorkspace runs the workspace hook once the task is terminal, or
+// reverts the task's changes when CancelAndRevert asked for it. It returns
+// the warnings the hook added, i.e. what went wrong.
+func (m *Manager) releaseWorkspace(t *Task) []string {
+	if m.workspace == nil {
+		return nil
+	}
+	seen := t.warningCount()
+	if !m.revertWorkspace(t) {
+		m.workspace.Release(context.Background(), t)
+	}
+	return m.recordWarnings(t, seen)
+}
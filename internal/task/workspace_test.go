@@ -0,0 +1,199 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/executor"
+)
+
+// fakeWorkspace points tasks at a fixed directory and records releases
+// and reverts.
+type fakeWorkspace struct {
+	dir          string
+	prepareErr   error
+	releaseWarns string // added as a warning by each release
+	shared       bool   // every project shares its checkout
+
+	mu       sync.Mutex
+	released []Status
+	reverted []Status
+}
+
+func (w *fakeWorkspace) Prepare(_ context.Context, t *Task, req *executor.Request) error {
+	if w.prepareErr != nil {
+		return w.prepareErr
+	}
+	t.SetWorktree(w.dir, "herald/"+t.ID)
+	req.ProjectPath = w.dir
+	return nil
+}
+
+func (w *fakeWorkspace) Release(_ context.Context, t *Task) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.released = append(w.released, t.Snapshot().Status)
+	if w.releaseWarns != "" {
+		t.AddWarning(w.releaseWarns)
+	}
+}
+
+func (w *fakeWorkspace) SharesCheckout(string) bool {
+	return w.shared
+}
+
+func (w *fakeWorkspace) Revert(_ context.Context, t *Task) ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reverted = append(w.reverted, t.Snapshot().Status)
+	return []string{"deleted branch herald/" + t.ID}, nil
+}
+
+func TestManager_Workspace_PreparesBeforeAndReleasesAfterExecution(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	ws := &fakeWorkspace{dir: "/work/worktrees/x"}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetWorkspace(ws)
+
+	tk := m.Create("proj", "do it", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID, ProjectPath: "/projects/proj"}, 0))
+
+	req := <-exec.reqs
+	assert.Equal(t, "/work/worktrees/x", req.ProjectPath)
+
+	<-tk.Done()
+	assert.Eventually(t, func() bool {
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+		return len(ws.released) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, StatusCompleted, ws.released[0])
+
+	snap := tk.Snapshot()
+	assert.Equal(t, "/work/worktrees/x", snap.WorktreePath)
+	assert.Equal(t, "herald/"+tk.ID, snap.GitBranch)
+}
+
+func TestManager_Workspace_WhenPrepareFails_FailsTask(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetWorkspace(&fakeWorkspace{prepareErr: errors.New("branch already checked out")})
+
+	tk := m.Create("proj", "do it", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+	<-tk.Done()
+
+	snap := tk.Snapshot()
+	assert.Equal(t, StatusFailed, snap.Status)
+	assert.Contains(t, snap.Error, "preparing workspace: branch already checked out")
+	assert.Empty(t, exec.reqs, "executor must not run")
+}
+
+func TestManager_Workspace_WhenPrepareFails_ReleasesBeforeNotifying(t *testing.T) {
+	t.Parallel()
+
+	exec := &recordingExecutor{reqs: make(chan executor.Request, 1)}
+	ws := &fakeWorkspace{
+		prepareErr:   errors.New("resolving HEAD: bad object"),
+		releaseWarns: "git: could not switch back to main",
+	}
+	m := NewManager(exec, 3, 2*time.Hour)
+	m.SetWorkspace(ws)
+	events := make(chan TaskEvent, 10)
+	m.SetNotifyFunc(func(e TaskEvent) { events <- e })
+
+	tk := m.Create("proj", "do it", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	for e := range events {
+		if e.Type == "task.failed" {
+			assert.Equal(t, "preparing workspace: resolving HEAD: bad object; git: could not switch back to main", e.Message)
+			break
+		}
+	}
+	ws.mu.Lock()
+	assert.Equal(t, []Status{StatusFailed}, ws.released, "what Prepare did is undone before the failure is notified")
+	ws.mu.Unlock()
+	assert.Equal(t, []string{"git: could not switch back to main"}, tk.Snapshot().Warnings)
+}
+
+func TestManager_Workspace_WhenCheckoutShared_RunsProjectTasksOneAtATime(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager(&mockExecutor{delay: 200 * time.Millisecond}, 3, 2*time.Hour)
+	m.SetWorkspace(&fakeWorkspace{dir: "/projects/proj", shared: true})
+
+	first := m.Create("proj", "first", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), first, executor.Request{TaskID: first.ID}, 0))
+	second := m.Create("proj", "second", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), second, executor.Request{TaskID: second.ID}, 0))
+	other := m.Create("other", "other project", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), other, executor.Request{TaskID: other.ID}, 0))
+
+	assert.Equal(t, StatusQueued, second.Snapshot().Status, "the checkout is in use by the first task")
+	assert.Equal(t, StatusRunning, other.Snapshot().Status, "other projects are not held back")
+
+	for _, tk := range []*Task{first, second} {
+		select {
+		case <-tk.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("task %s did not finish", tk.ID)
+		}
+	}
+	assert.Equal(t, StatusCompleted, second.Snapshot().Status)
+	assert.False(t, second.Snapshot().StartedAt.Before(first.Snapshot().CompletedAt), "the second task starts once the first ended")
+}
+
+func TestManager_CancelAndRevert_RevertsInsteadOfReleasing(t *testing.T) {
+	t.Parallel()
+
+	ws := &fakeWorkspace{dir: "/work/worktrees/x"}
+	m := NewManager(&mockExecutor{delay: 10 * time.Second}, 3, 2*time.Hour)
+	m.SetWorkspace(ws)
+
+	tk := m.Create("proj", "long task", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), tk, executor.Request{TaskID: tk.ID}, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	undone, err := m.CancelAndRevert(ctx, tk.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"deleted branch herald/" + tk.ID}, undone)
+	assert.Equal(t, StatusCancelled, tk.Snapshot().Status)
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	assert.Equal(t, []Status{StatusCancelled}, ws.reverted)
+	assert.Empty(t, ws.released, "a reverted task is not released")
+}
+
+func TestManager_CancelAndRevert_WhenQueued_HasNothingToUndo(t *testing.T) {
+	t.Parallel()
+
+	ws := &fakeWorkspace{dir: "/work/worktrees/x"}
+	m := NewManager(&mockExecutor{delay: 10 * time.Second}, 1, 2*time.Hour)
+	m.SetWorkspace(ws)
+
+	running := m.Create("proj", "first", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), running, executor.Request{TaskID: running.ID}, 0))
+	queued := m.Create("proj", "second", "", PriorityNormal, 30)
+	require.NoError(t, m.Start(context.Background(), queued, executor.Request{TaskID: queued.ID}, 0))
+	require.Equal(t, StatusQueued, queued.Snapshot().Status)
+
+	undone, err := m.CancelAndRevert(context.Background(), queued.ID)
+	require.NoError(t, err)
+	assert.Empty(t, undone)
+	assert.Equal(t, StatusCancelled, queued.Snapshot().Status)
+
+	_ = m.Cancel(running.ID)
+}
@@ -0,0 +1,212 @@
+// Package template resolves the task templates defined in herald.yaml and
+// renders their prompt skeletons.
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btouchard/herald/internal/config"
+)
+
+// Built-in variables, always available to a skeleton.
+const (
+	VarPrompt  = "prompt"  // the caller's prompt
+	VarProject = "project" // the project name
+)
+
+// placeholder matches {{name}}, with optional inner spaces.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Template is a named task preset: a prompt skeleton plus defaults for the
+// task's settings.
+type Template struct {
+	Name         string
+	Project      string // project that defines it, "" for global templates
+	Description  string
+	Prompt       string
+	Defaults     map[string]string // default variable values
+	Model        string
+	Timeout      time.Duration
+	AllowedTools []string
+	Priority     string
+	DryRun       bool
+}
+
+// Variables returns the placeholders used in the skeleton, in order of
+// first appearance, built-ins included.
+func (t *Template) Variables() []string {
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(t.Prompt, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Render fills the skeleton with vars, falling back on the template's
+// defaults. The caller's prompt (vars["prompt"]) is appended when the
+// skeleton has no {{prompt}} placeholder. Surrounding blank space is
+// trimmed. It fails listing every variable left without a value.
+func (t *Template) Render(vars map[string]string) (string, error) {
+	var missing []string
+	rendered := placeholder.ReplaceAllStringFunc(t.Prompt, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if v, ok := t.Defaults[name]; ok {
+			return v
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return m
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("template %q: missing value for %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	rendered = strings.TrimSpace(rendered)
+	prompt := strings.TrimSpace(vars[VarPrompt])
+	if prompt != "" && !slices.Contains(t.Variables(), VarPrompt) {
+		if rendered != "" {
+			rendered += "\n\n"
+		}
+		rendered += prompt
+	}
+	return rendered, nil
+}
+
+// ToolsFor returns the allowed tools a task on a project gets from the
+// template. Global templates can only narrow a project's allowed_tools,
+// never extend them (an empty list would lift every restriction, so the
+// project's list is kept when nothing overlaps); a project's own templates
+// are used as written.
+func (t *Template) ToolsFor(projectTools []string) []string {
+	if len(t.AllowedTools) == 0 {
+		return projectTools
+	}
+	if t.Project != "" || len(projectTools) == 0 {
+		return t.AllowedTools
+	}
+	var tools []string
+	for _, tool := range t.AllowedTools {
+		if slices.Contains(projectTools, tool) {
+			tools = append(tools, tool)
+		}
+	}
+	if len(tools) == 0 {
+		return projectTools
+	}
+	return tools
+}
+
+// Registry holds the global templates and the per-project ones.
+type Registry struct {
+	global   map[string]*Template
+	projects map[string]map[string]*Template
+}
+
+// NewRegistry builds a Registry from the global templates and those
+// declared under each project.
+func NewRegistry(global map[string]config.Template, projects map[string]config.Project) *Registry {
+	r := &Registry{
+		global:   make(map[string]*Template, len(global)),
+		projects: make(map[string]map[string]*Template),
+	}
+	for name, cfg := range global {
+		r.global[name] = fromConfig(name, "", cfg)
+	}
+	for proj, p := range projects {
+		if len(p.Templates) == 0 {
+			continue
+		}
+		r.projects[proj] = make(map[string]*Template, len(p.Templates))
+		for name, cfg := range p.Templates {
+			r.projects[proj][name] = fromConfig(name, proj, cfg)
+		}
+	}
+	return r
+}
+
+func fromConfig(name, project string, cfg config.Template) *Template {
+	return &Template{
+		Name:         name,
+		Project:      project,
+		Description:  cfg.Description,
+		Prompt:       cfg.Prompt,
+		Defaults:     cfg.Variables,
+		Model:        cfg.Model,
+		Timeout:      cfg.Timeout,
+		AllowedTools: cfg.AllowedTools,
+		Priority:     cfg.Priority,
+		DryRun:       cfg.DryRun,
+	}
+}
+
+// Get returns the template a task on project gets for name: the project's
+// own template if it defines one, the global one otherwise.
+func (r *Registry) Get(project, name string) (*Template, error) {
+	if t, ok := r.projects[project][name]; ok {
+		return t, nil
+	}
+	if t, ok := r.global[name]; ok {
+		return t, nil
+	}
+	names := make([]string, 0)
+	for _, t := range r.List(project) {
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("template %q not found (no templates configured)", name)
+	}
+	return nil, fmt.Errorf("template %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// List returns the templates available to project, sorted by name. With
+// an empty project only global templates are listed.
+func (r *Registry) List(project string) []*Template {
+	byName := make(map[string]*Template, len(r.global))
+	for name, t := range r.global {
+		byName[name] = t
+	}
+	for name, t := range r.projects[project] {
+		byName[name] = t
+	}
+
+	result := make([]*Template, 0, len(byName))
+	for _, t := range byName {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// All returns every template: global ones first, then each project's own,
+// by project then name.
+func (r *Registry) All() []*Template {
+	result := r.List("")
+
+	projects := make([]string, 0, len(r.projects))
+	for proj := range r.projects {
+		projects = append(projects, proj)
+	}
+	sort.Strings(projects)
+	for _, proj := range projects {
+		names := make([]string, 0, len(r.projects[proj]))
+		for name := range r.projects[proj] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result = append(result, r.projects[proj][name])
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/config"
+)
+
+func newTestRegistry() *Registry {
+	return NewRegistry(
+		map[string]config.Template{
+			"review": {
+				Description: "Code review",
+				Prompt:      "Review {{scope}} with a focus on {{ focus }}.\n\n{{prompt}}",
+				Variables:   map[string]string{"focus": "correctness"},
+				Model:       "claude-opus-4-6",
+				Timeout:     20 * time.Minute,
+				DryRun:      true,
+			},
+			"fix": {Prompt: "Fix the following bug in {{project}}."},
+		},
+		map[string]config.Project{
+			"app": {Templates: map[string]config.Template{
+				"fix": {Prompt: "Fix it the app way."},
+			}},
+		},
+	)
+}
+
+func TestTemplate_Variables_ReturnsPlaceholdersInOrder(t *testing.T) {
+	t.Parallel()
+	tmpl, err := newTestRegistry().Get("", "review")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"scope", "focus", "prompt"}, tmpl.Variables())
+}
+
+func TestTemplate_Render_UsesVarsThenDefaults(t *testing.T) {
+	t.Parallel()
+	tmpl, err := newTestRegistry().Get("", "review")
+	require.NoError(t, err)
+
+	out, err := tmpl.Render(map[string]string{"scope": "internal/auth", "prompt": "Look at token rotation."})
+	require.NoError(t, err)
+	assert.Equal(t, "Review internal/auth with a focus on correctness.\n\nLook at token rotation.", out)
+
+	out, err = tmpl.Render(map[string]string{"scope": "cmd", "focus": "security", "prompt": "p"})
+	require.NoError(t, err)
+	assert.Equal(t, "Review cmd with a focus on security.\n\np", out)
+}
+
+func TestTemplate_Render_WhenVariableMissing_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tmpl, err := newTestRegistry().Get("", "review")
+	require.NoError(t, err)
+
+	_, err = tmpl.Render(map[string]string{"prompt": "p"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing value for scope")
+}
+
+func TestTemplate_Render_WhenNoPromptPlaceholder_AppendsPrompt(t *testing.T) {
+	t.Parallel()
+	tmpl, err := newTestRegistry().Get("", "fix")
+	require.NoError(t, err)
+
+	out, err := tmpl.Render(map[string]string{"project": "api", "prompt": "Login returns 500."})
+	require.NoError(t, err)
+	assert.Equal(t, "Fix the following bug in api.\n\nLogin returns 500.", out)
+}
+
+func TestRegistry_Get_PrefersProjectTemplate(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+
+	tmpl, err := r.Get("app", "fix")
+	require.NoError(t, err)
+	assert.Equal(t, "app", tmpl.Project)
+	assert.Equal(t, "Fix it the app way.", tmpl.Prompt)
+
+	tmpl, err = r.Get("other", "fix")
+	require.NoError(t, err)
+	assert.Empty(t, tmpl.Project)
+}
+
+func TestRegistry_Get_WhenUnknown_ListsAvailable(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestRegistry().Get("app", "deploy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: fix, review")
+}
+
+func TestRegistry_List_MergesProjectTemplates(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+
+	list := r.List("app")
+	require.Len(t, list, 2)
+	assert.Equal(t, "fix", list[0].Name)
+	assert.Equal(t, "app", list[0].Project)
+	assert.Equal(t, "review", list[1].Name)
+
+	assert.Len(t, NewRegistry(nil, nil).List(""), 0)
+}
+
+func TestTemplate_ToolsFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		tmpl         Template
+		projectTools []string
+		want         []string
+	}{
+		{"no template tools", Template{}, []string{"Read"}, []string{"Read"}},
+		{"unrestricted project", Template{AllowedTools: []string{"Read", "Bash"}}, nil, []string{"Read", "Bash"}},
+		{"global narrows", Template{AllowedTools: []string{"Read", "Bash"}}, []string{"Read", "Edit"}, []string{"Read"}},
+		{"global without overlap keeps project", Template{AllowedTools: []string{"Bash"}}, []string{"Read"}, []string{"Read"}},
+		{"project template as written", Template{Project: "app", AllowedTools: []string{"Bash"}}, []string{"Read"}, []string{"Bash"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.tmpl.ToolsFor(tt.projectTools))
+		})
+	}
+}
+
+func TestRegistry_All_ListsGlobalThenProjectTemplates(t *testing.T) {
+	t.Parallel()
+
+	all := newTestRegistry().All()
+	require.Len(t, all, 3)
+	assert.Equal(t, "fix", all[0].Name)
+	assert.Empty(t, all[0].Project)
+	assert.Equal(t, "review", all[1].Name)
+	assert.Equal(t, "fix", all[2].Name)
+	assert.Equal(t, "app", all[2].Project)
+}
@@ -0,0 +1,596 @@
+// Package workspace prepares the git working tree a task executes in and
+// tidies it up afterwards: dedicated worktrees, or branch, stash and
+// commit automation in the project checkout itself.
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/git"
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
+)
+
+// Worktree cleanup policies, applied once a task is terminal.
+const (
+	CleanupKeep      = "keep"       // keep the worktree for inspection
+	CleanupOnSuccess = "on_success" // remove it when the task completed, unless it has uncommitted changes
+	CleanupAlways    = "always"     // remove it regardless of outcome, discarding uncommitted changes
+)
+
+// Manager prepares task working trees. It implements task.Workspace.
+// Everything it changes is recorded on the task (and so persisted), which
+// lets Release and Revert work after a Herald restart.
+type Manager struct {
+	projects *project.Manager
+	workDir  string
+
+	mu        sync.Mutex
+	checkouts map[string]chan struct{} // project → holds a token while a task automates its checkout
+	holders   map[string]string        // task ID → project whose checkout it holds
+}
+
+// NewManager creates a Manager that places task worktrees under workDir.
+func NewManager(pm *project.Manager, workDir string) *Manager {
+	return &Manager{
+		projects:  pm,
+		workDir:   workDir,
+		checkouts: make(map[string]chan struct{}),
+		holders:   make(map[string]string),
+	}
+}
+
+// SharesCheckout reports whether the tasks of project run their git
+// automation in the project checkout itself, which only one of them can
+// use at a time (see Prepare). It implements task.CheckoutSharer.
+func (m *Manager) SharesCheckout(project string) bool {
+	proj, err := m.projects.Get(project)
+	if err != nil {
+		return false
+	}
+	return !proj.Git.Worktree && (proj.Git.AutoBranch || proj.Git.AutoStash || proj.Git.AutoCommit)
+}
+
+// lockCheckout waits until no other task automates the checkout of project
+// and takes it for task id, until unlockCheckout.
+func (m *Manager) lockCheckout(ctx context.Context, id, project string) error {
+	m.mu.Lock()
+	if m.holders[id] == project {
+		m.mu.Unlock()
+		return nil
+	}
+	checkout, ok := m.checkouts[project]
+	if !ok {
+		checkout = make(chan struct{}, 1)
+		m.checkouts[project] = checkout
+	}
+	m.mu.Unlock()
+
+	select {
+	case checkout <- struct{}{}:
+	default:
+		slog.Info("waiting for the project checkout", "task_id", id, "project", project)
+		select {
+		case checkout <- struct{}{}:
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for the checkout of project %q: %w", project, ctx.Err())
+		}
+	}
+
+	m.mu.Lock()
+	m.holders[id] = project
+	m.mu.Unlock()
+	return nil
+}
+
+// unlockCheckout gives back the checkout task id holds, if any.
+func (m *Manager) unlockCheckout(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	project, ok := m.holders[id]
+	if !ok {
+		return
+	}
+	delete(m.holders, id)
+	<-m.checkouts[project]
+}
+
+// Dir returns the worktree directory of a task.
+func (m *Manager) Dir(taskID string) string {
+	return filepath.Join(m.workDir, "worktrees", taskID)
+}
+
+// Prepare readies the working tree before execution. For projects with
+// git.worktree enabled it creates a dedicated worktree and points the
+// request at it. Otherwise it applies git.auto_stash and switches the
+// project checkout to the task branch (the git_branch argument, or the
+// branch prefix followed by the task ID with git.auto_branch). The commit
+// the task starts from is recorded as its base commit in every git
+// project, so the task can be reverted. Tasks automating the checkout wait
+// for each other: the checkout is held from Prepare until Release or
+// Revert.
+func (m *Manager) Prepare(ctx context.Context, t *task.Task, req *executor.Request) error {
+	snap := t.Snapshot()
+	proj, err := m.projects.Get(snap.Project)
+	if err != nil {
+		return err
+	}
+	if proj.Git.Worktree {
+		return m.prepareWorktree(ctx, t, snap, proj, req)
+	}
+
+	branch := snap.GitBranch
+	if branch == "" && proj.Git.AutoBranch {
+		branch = proj.Git.BranchPrefix + snap.ID
+	}
+	automated := branch != "" || proj.Git.AutoStash || proj.Git.AutoCommit
+	if automated {
+		if err := m.lockCheckout(ctx, snap.ID, proj.Name); err != nil {
+			return err
+		}
+	}
+
+	ops := git.NewOps(proj.Path)
+	if !ops.IsGitRepo(ctx) || !ops.HasCommits(ctx) {
+		if snap.GitBranch != "" {
+			return fmt.Errorf("cannot switch to branch %q: project %q is not a git repository with commits", snap.GitBranch, proj.Name)
+		}
+		if automated {
+			t.AddWarning("git: automation skipped, project is not a git repository with commits")
+		}
+		return nil
+	}
+
+	original, err := ops.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if snap.BaseCommit != "" && (branch == "" || branch == original) {
+		// Requeued after a restart: the checkout is still where the first
+		// Prepare left it, and that Prepare recorded how to restore it.
+		return nil
+	}
+	base, err := ops.HeadCommit(ctx)
+	if err != nil {
+		return err
+	}
+	if !automated {
+		t.SetBaseCommit(base)
+		return nil
+	}
+
+	stashed := false
+	if proj.Git.AutoStash {
+		clean, err := ops.IsClean(ctx)
+		if err != nil {
+			return err
+		}
+		if !clean {
+			if err := ops.Stash(ctx); err != nil {
+				return err
+			}
+			stashed = true
+			slog.Info("stashed local changes before task", "task_id", snap.ID, "project", proj.Name)
+		}
+	}
+
+	switched, created := "", false
+	if branch != "" && branch != original {
+		if ops.BranchExists(ctx, branch) {
+			err = ops.Checkout(ctx, branch)
+		} else {
+			err = ops.CreateBranch(ctx, branch)
+			created = true
+		}
+		if err != nil {
+			if stashed {
+				if popErr := ops.StashPop(ctx); popErr != nil {
+					err = fmt.Errorf("%w (and restoring stashed changes failed: %s)", err, popErr)
+				}
+			}
+			return err
+		}
+		switched = original
+		slog.Info("switched to task branch",
+			"task_id", snap.ID,
+			"branch", branch,
+			"from", original)
+	}
+
+	if branch == "" {
+		branch = original
+	}
+	// Recorded before anything else can fail, for Release to restore the
+	// checkout.
+	t.SetGitBranch(branch)
+	t.SetBaseCommit(base)
+	t.SetCheckout(switched, created, stashed)
+	if switched != "" && !created {
+		// An existing branch may not point at the commit we were on.
+		if base, err = ops.HeadCommit(ctx); err != nil {
+			return err
+		}
+		t.SetBaseCommit(base)
+	}
+	return nil
+}
+
+// prepareWorktree creates a worktree on the task branch (the git_branch
+// argument, or the branch prefix followed by the task ID). An existing
+// worktree, e.g. for a task requeued after a restart, is reused.
+func (m *Manager) prepareWorktree(ctx context.Context, t *task.Task, snap task.TaskSnapshot, proj *project.Project, req *executor.Request) error {
+	ops := git.NewOps(proj.Path)
+	if !ops.IsGitRepo(ctx) {
+		return fmt.Errorf("project %q is not a git repository", proj.Name)
+	}
+	if !ops.HasCommits(ctx) {
+		return fmt.Errorf("project %q has no commits to branch from", proj.Name)
+	}
+
+	branch := snap.GitBranch
+	if branch == "" {
+		branch = proj.Git.BranchPrefix + snap.ID
+	}
+
+	dir := m.Dir(snap.ID)
+	if _, err := os.Stat(dir); err == nil {
+		slog.Info("reusing task worktree", "task_id", snap.ID, "path", dir)
+	} else {
+		base, err := ops.HeadCommit(ctx)
+		if err != nil {
+			return err
+		}
+		created := !ops.BranchExists(ctx, branch)
+		if !created {
+			if base, err = ops.ResolveCommit(ctx, branch); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0750); err != nil {
+			return fmt.Errorf("creating worktree directory: %w", err)
+		}
+		if err := ops.AddWorktree(ctx, dir, branch, base); err != nil {
+			return err
+		}
+		t.SetBaseCommit(base)
+		t.SetCheckout("", created, false)
+		slog.Info("task worktree created",
+			"task_id", snap.ID,
+			"path", dir,
+			"branch", branch)
+	}
+
+	t.SetWorktree(dir, branch)
+	req.ProjectPath = dir
+	return nil
+}
+
+// Release tidies up after execution. With git.auto_commit, leftover
+// changes are committed on the task branch (on the project's own branch
+// only when the task completed). A switched checkout is then returned to
+// its original branch and stashed changes are restored, unless the task
+// did not complete and left changes in the checkout the stash would be
+// mixed with; task worktrees are removed according to
+// git.worktree_cleanup. Problems are recorded as task warnings rather than
+// failing the task.
+func (m *Manager) Release(ctx context.Context, t *task.Task) {
+	snap := t.Snapshot()
+	defer m.unlockCheckout(snap.ID)
+	proj, err := m.projects.Get(snap.Project)
+	if err != nil {
+		slog.Warn("cannot release task workspace", "task_id", snap.ID, "error", err)
+		return
+	}
+
+	if snap.WorktreePath != "" {
+		if proj.Git.AutoCommit {
+			commitLeftovers(ctx, t, git.NewOps(snap.WorktreePath))
+		}
+		m.removeWorktree(ctx, t, snap, proj)
+		return
+	}
+	if snap.BaseCommit == "" {
+		return
+	}
+
+	ops := git.NewOps(proj.Path)
+	if proj.Git.AutoCommit && (snap.OriginalBranch != "" || snap.Status == task.StatusCompleted) {
+		commitLeftovers(ctx, t, ops)
+	}
+
+	if snap.OriginalBranch != "" {
+		if err := ops.Checkout(ctx, snap.OriginalBranch); err != nil {
+			warn(t, fmt.Sprintf("git: could not switch back to %s: %s", snap.OriginalBranch, err))
+			if snap.Stashed {
+				warn(t, "git: stashed changes were left in the stash (git stash pop to restore them)")
+			}
+			return
+		}
+	}
+
+	if !snap.Stashed {
+		return
+	}
+	if snap.OriginalBranch == "" && snap.Status != task.StatusCompleted {
+		// Nothing committed what the task left on the project's branch.
+		if clean, err := ops.IsClean(ctx); err != nil || !clean {
+			warn(t, "git: stashed changes were left in the stash, the task did not complete and left changes in the working tree (git stash pop to restore them)")
+			return
+		}
+	}
+	if err := ops.StashPop(ctx); err != nil {
+		warn(t, fmt.Sprintf("git: could not restore stashed changes, they remain in the stash: %s", err))
+	}
+}
+
+// Changes measures what t changed since its base commit with git diff
+// --numstat, in its worktree or the project checkout, untracked files
+// included. Without git.auto_stash or a worktree, changes left in the
+// checkout before the task started are counted too. It reports false for
+// tasks without a base commit, e.g. in projects that are not git
+// repositories. It implements task.ChangeMeasurer.
+func (m *Manager) Changes(ctx context.Context, t *task.Task) (task.Changes, bool) {
+	snap := t.Snapshot()
+	if snap.BaseCommit == "" {
+		return task.Changes{}, false
+	}
+	dir := snap.WorktreePath
+	if dir == "" {
+		proj, err := m.projects.Get(snap.Project)
+		if err != nil {
+			return task.Changes{}, false
+		}
+		dir = proj.Path
+	}
+
+	stats, err := git.NewOps(dir).NumStat(ctx, snap.BaseCommit)
+	if err != nil {
+		warn(t, fmt.Sprintf("git: could not measure the task's changes: %s", err))
+		return task.Changes{}, false
+	}
+	var c task.Changes
+	for _, s := range stats {
+		c.Files = append(c.Files, s.Path)
+		c.Added += s.Added
+		c.Removed += s.Removed
+	}
+	return c, true
+}
+
+// Revert undoes what a cancelled task did to the project, in place of
+// Release. Uncommitted changes are discarded (or stashed when they may
+// include work that predates the task), a task branch created for the task
+// is deleted and an existing one reset to the base commit, then the
+// original branch and stashed changes are restored. It returns a line per
+// step undone; on error, the steps completed so far.
+func (m *Manager) Revert(ctx context.Context, t *task.Task) ([]string, error) {
+	snap := t.Snapshot()
+	defer m.unlockCheckout(snap.ID)
+	if snap.BaseCommit == "" {
+		return nil, nil
+	}
+	proj, err := m.projects.Get(snap.Project)
+	if err != nil {
+		return nil, err
+	}
+	if snap.WorktreePath != "" {
+		return m.revertWorktree(ctx, t, snap, proj)
+	}
+
+	ops := git.NewOps(proj.Path)
+	var undone []string
+
+	clean, err := ops.IsClean(ctx)
+	if err != nil {
+		return undone, err
+	}
+	if !clean {
+		if snap.Stashed {
+			// The developer's own changes are safe in the stash, so what
+			// is left in the tree is the task's.
+			if err := ops.Discard(ctx); err != nil {
+				return undone, err
+			}
+			undone = append(undone, "discarded uncommitted changes")
+		} else {
+			msg := "herald: changes reverted from " + snap.ID
+			if err := ops.StashWithMessage(ctx, msg); err != nil {
+				return undone, err
+			}
+			undone = append(undone, fmt.Sprintf("moved uncommitted changes to the stash as %q, as they may include work from before the task", msg))
+		}
+	}
+
+	if snap.OriginalBranch == "" {
+		branch := snap.GitBranch
+		if branch == "" {
+			if branch, err = ops.CurrentBranch(ctx); err != nil {
+				return undone, err
+			}
+		}
+		line, err := resetCommits(ctx, ops, branch, snap.BaseCommit, func() error {
+			return ops.ResetHard(ctx, snap.BaseCommit)
+		})
+		if err != nil {
+			return undone, err
+		}
+		undone = append(undone, line...)
+	} else {
+		if err := ops.Checkout(ctx, snap.OriginalBranch); err != nil {
+			return undone, err
+		}
+		undone = append(undone, "switched back to "+snap.OriginalBranch)
+
+		if snap.BranchCreated {
+			line, err := deleteBranch(ctx, ops, snap.GitBranch, snap.BaseCommit)
+			if err != nil {
+				return undone, err
+			}
+			undone = append(undone, line)
+		} else {
+			line, err := resetCommits(ctx, ops, snap.GitBranch, snap.BaseCommit, func() error {
+				return ops.ResetBranch(ctx, snap.GitBranch, snap.BaseCommit)
+			})
+			if err != nil {
+				return undone, err
+			}
+			undone = append(undone, line...)
+		}
+	}
+
+	if snap.Stashed {
+		if err := ops.StashPop(ctx); err != nil {
+			return undone, fmt.Errorf("%w; your changes remain in the stash", err)
+		}
+		undone = append(undone, "restored the changes stashed before the task")
+	}
+	return undone, nil
+}
+
+// revertWorktree removes a task worktree together with the branch created
+// for it, or resets an existing task branch to the base commit.
+func (m *Manager) revertWorktree(ctx context.Context, t *task.Task, snap task.TaskSnapshot, proj *project.Project) ([]string, error) {
+	ops := git.NewOps(proj.Path)
+	var undone []string
+
+	if snap.BranchCreated {
+		if err := ops.RemoveWorktree(ctx, snap.WorktreePath, true); err != nil {
+			return undone, err
+		}
+		t.SetWorktree("", snap.GitBranch)
+		undone = append(undone, "removed worktree "+snap.WorktreePath+" and its uncommitted changes")
+
+		line, err := deleteBranch(ctx, ops, snap.GitBranch, snap.BaseCommit)
+		if err != nil {
+			return undone, err
+		}
+		return append(undone, line), nil
+	}
+
+	wt := git.NewOps(snap.WorktreePath)
+	clean, err := wt.IsClean(ctx)
+	if err != nil {
+		return undone, err
+	}
+	if !clean {
+		if err := wt.Discard(ctx); err != nil {
+			return undone, err
+		}
+		undone = append(undone, "discarded uncommitted changes in worktree "+snap.WorktreePath)
+	}
+	line, err := resetCommits(ctx, ops, snap.GitBranch, snap.BaseCommit, func() error {
+		return wt.ResetHard(ctx, snap.BaseCommit)
+	})
+	if err != nil {
+		return undone, err
+	}
+	undone = append(undone, line...)
+
+	m.removeWorktree(ctx, t, t.Snapshot(), proj)
+	return undone, nil
+}
+
+// resetCommits drops the commits branch gained since base using reset,
+// reporting how many were dropped.
+func resetCommits(ctx context.Context, ops *git.Ops, branch, base string, reset func() error) ([]string, error) {
+	n, err := ops.CountCommits(ctx, base, branch)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	if err := reset(); err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("reset %s to %s, dropping %s", branch, shortHash(base), plural(n, "commit"))}, nil
+}
+
+// deleteBranch deletes a branch created for a task, reporting how many
+// commits it held.
+func deleteBranch(ctx context.Context, ops *git.Ops, branch, base string) (string, error) {
+	n, err := ops.CountCommits(ctx, base, branch)
+	if err != nil {
+		return "", err
+	}
+	if err := ops.DeleteBranch(ctx, branch); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted branch %s (%s)", branch, plural(n, "commit")), nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// commitLeftovers commits whatever the task left uncommitted.
+func commitLeftovers(ctx context.Context, t *task.Task, ops *git.Ops) {
+	snap := t.Snapshot()
+	hash, err := ops.CommitAll(ctx, commitMessage(snap))
+	if err != nil {
+		warn(t, fmt.Sprintf("git: auto-commit failed: %s", err))
+		return
+	}
+	if hash != "" {
+		slog.Info("committed task changes", "task_id", snap.ID, "commit", hash)
+	}
+}
+
+// commitMessage summarizes the prompt and references the task ID in a
+// trailer.
+func commitMessage(snap task.TaskSnapshot) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(snap.Prompt), "\n")
+	if len(subject) > 72 {
+		subject = subject[:69] + "..."
+	}
+	if subject == "" {
+		subject = "Changes from " + snap.ID
+	}
+	msg := fmt.Sprintf("%s\n\nHerald-Task: %s", subject, snap.ID)
+	if snap.Status != task.StatusCompleted {
+		msg += fmt.Sprintf("\nHerald-Status: %s", snap.Status)
+	}
+	return msg
+}
+
+// removeWorktree applies the project's git.worktree_cleanup policy. The
+// task branch is always kept.
+func (m *Manager) removeWorktree(ctx context.Context, t *task.Task, snap task.TaskSnapshot, proj *project.Project) {
+	force := false
+	switch proj.Git.WorktreeCleanup {
+	case CleanupAlways:
+		force = true
+	case CleanupOnSuccess:
+		if snap.Status != task.StatusCompleted {
+			return
+		}
+	default:
+		return
+	}
+
+	if err := git.NewOps(proj.Path).RemoveWorktree(ctx, snap.WorktreePath, force); err != nil {
+		slog.Warn("task worktree kept", "task_id", snap.ID, "path", snap.WorktreePath, "error", err)
+		return
+	}
+	t.SetWorktree("", snap.GitBranch)
+	slog.Info("task worktree removed", "task_id", snap.ID, "path", snap.WorktreePath)
+}
+
+func warn(t *task.Task, msg string) {
+	slog.Warn("task workspace", "task_id", t.ID, "warning", msg)
+	t.AddWarning(msg)
+}
@@ -0,0 +1,537 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/btouchard/herald/internal/config"
+	"github.com/btouchard/herald/internal/executor"
+	"github.com/btouchard/herald/internal/git"
+	"github.com/btouchard/herald/internal/project"
+	"github.com/btouchard/herald/internal/task"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	cmds := [][]string{
+		{"git", "init", "-b", "main"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "commit", "--allow-empty", "-m", "initial commit"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // test helper with hardcoded args
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "cmd %v failed: %s", args, out)
+	}
+
+	return dir
+}
+
+func newTestManager(t *testing.T, repo string, gitCfg config.GitConfig) (*Manager, *task.Task) {
+	t.Helper()
+	pm := project.NewManager(map[string]config.Project{
+		"app": {Path: repo, Git: gitCfg},
+	})
+	tm := task.NewManager(nil, 3, time.Hour)
+	return NewManager(pm, t.TempDir()), tm.Create("app", "do it", "", task.PriorityNormal, 30)
+}
+
+func TestPrepare_WhenWorktreeDisabled_LeavesRequestUntouched(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{})
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(context.Background(), tk, &req))
+
+	assert.Equal(t, repo, req.ProjectPath)
+	assert.Empty(t, tk.Snapshot().WorktreePath)
+}
+
+func TestPrepare_WhenWorktreeEnabled_CreatesWorktreeOnTaskBranch(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{Worktree: true})
+	ctx := context.Background()
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	snap := tk.Snapshot()
+	assert.Equal(t, ws.Dir(tk.ID), req.ProjectPath)
+	assert.Equal(t, ws.Dir(tk.ID), snap.WorktreePath)
+	assert.Equal(t, "herald/"+tk.ID, snap.GitBranch)
+
+	branch, err := git.NewOps(req.ProjectPath).CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "herald/"+tk.ID, branch)
+
+	// Preparing again (e.g. a requeued task) reuses the worktree
+	req2 := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req2))
+	assert.Equal(t, req.ProjectPath, req2.ProjectPath)
+}
+
+func TestPrepare_WhenGitBranchGiven_UsesIt(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{Worktree: true})
+	tk.GitBranch = "feature/login"
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(context.Background(), tk, &req))
+	assert.Equal(t, "feature/login", tk.Snapshot().GitBranch)
+}
+
+func TestPrepare_WhenNotGitRepo_ReturnsError(t *testing.T) {
+	t.Parallel()
+	ws, tk := newTestManager(t, t.TempDir(), config.GitConfig{Worktree: true})
+
+	req := executor.Request{TaskID: tk.ID}
+	err := ws.Prepare(context.Background(), tk, &req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a git repository")
+}
+
+func TestRelease_AppliesCleanupPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cleanup     string
+		status      task.Status
+		dirty       bool
+		wantRemoved bool
+	}{
+		{"keep", CleanupKeep, task.StatusCompleted, false, false},
+		{"on_success completed", CleanupOnSuccess, task.StatusCompleted, false, true},
+		{"on_success failed", CleanupOnSuccess, task.StatusFailed, false, false},
+		{"on_success with uncommitted changes", CleanupOnSuccess, task.StatusCompleted, true, false},
+		{"always with uncommitted changes", CleanupAlways, task.StatusFailed, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := initTestRepo(t)
+			ws, tk := newTestManager(t, repo, config.GitConfig{Worktree: true, WorktreeCleanup: tt.cleanup})
+			ctx := context.Background()
+
+			req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+			require.NoError(t, ws.Prepare(ctx, tk, &req))
+			if tt.dirty {
+				require.NoError(t, os.WriteFile(filepath.Join(req.ProjectPath, "wip.txt"), []byte("wip"), 0600))
+			}
+			tk.SetStatus(tt.status)
+
+			ws.Release(ctx, tk)
+
+			_, err := os.Stat(req.ProjectPath)
+			if tt.wantRemoved {
+				assert.True(t, os.IsNotExist(err), "worktree should be removed")
+				assert.Empty(t, tk.Snapshot().WorktreePath)
+			} else {
+				assert.NoError(t, err, "worktree should be kept")
+				assert.Equal(t, req.ProjectPath, tk.Snapshot().WorktreePath)
+			}
+			assert.True(t, git.NewOps(repo).BranchExists(ctx, "herald/"+tk.ID), "task branch is always kept")
+		})
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...) //nolint:gosec // test helper
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, out)
+	return string(out)
+}
+
+func commitFile(t *testing.T, repo, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0600))
+	runGit(t, repo, "add", name)
+	runGit(t, repo, "commit", "-m", "add "+name)
+}
+
+func TestLifecycle_BranchStashAndCommit(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	commitFile(t, repo, "app.go", "package app\n")
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoBranch: true, AutoStash: true, AutoCommit: true})
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	base, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+
+	// Developer has work in progress
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "app.go"), []byte("package app // wip\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("todo"), 0600))
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+	assert.Equal(t, repo, req.ProjectPath)
+
+	snap := tk.Snapshot()
+	assert.Equal(t, "herald/"+tk.ID, snap.GitBranch)
+	assert.Equal(t, base, snap.BaseCommit)
+	branch, err := ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "herald/"+tk.ID, branch)
+	clean, err := ops.IsClean(ctx)
+	require.NoError(t, err)
+	assert.True(t, clean, "local changes should be stashed before the task runs")
+
+	// The task writes a file and leaves it uncommitted
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "feature.go"), []byte("package app\n"), 0600))
+	tk.SetStatus(task.StatusCompleted)
+	ws.Release(ctx, tk)
+
+	assert.Empty(t, tk.Snapshot().Warnings)
+
+	branch, err = ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch, "original branch restored")
+
+	content, err := os.ReadFile(filepath.Join(repo, "app.go")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "package app // wip\n", string(content), "stash restored")
+	_, err = os.Stat(filepath.Join(repo, "notes.txt"))
+	assert.NoError(t, err, "untracked file restored")
+	_, err = os.Stat(filepath.Join(repo, "feature.go"))
+	assert.True(t, os.IsNotExist(err), "task changes stay on the task branch")
+
+	log := runGit(t, repo, "log", "-1", "--format=%B", "herald/"+tk.ID)
+	assert.Contains(t, log, "Herald-Task: "+tk.ID)
+}
+
+func TestPrepare_WhenGitBranchGivenWithoutAutoBranch_SwitchesToIt(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	runGit(t, repo, "branch", "feature/existing")
+	ws, tk := newTestManager(t, repo, config.GitConfig{})
+	tk.GitBranch = "feature/existing"
+	ctx := context.Background()
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	branch, err := git.NewOps(repo).CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/existing", branch)
+
+	tk.SetStatus(task.StatusCompleted)
+	ws.Release(ctx, tk)
+
+	branch, err = git.NewOps(repo).CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+}
+
+func TestPrepare_WhenGitBranchGivenForNonRepo_ReturnsError(t *testing.T) {
+	t.Parallel()
+	ws, tk := newTestManager(t, t.TempDir(), config.GitConfig{})
+	tk.GitBranch = "feature/x"
+
+	req := executor.Request{TaskID: tk.ID}
+	err := ws.Prepare(context.Background(), tk, &req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "feature/x")
+}
+
+func TestRelease_WhenFailedOnOwnBranch_DoesNotCommit(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoCommit: true})
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+	base := tk.Snapshot().BaseCommit
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "half.go"), []byte("package half\n"), 0600))
+	tk.SetStatus(task.StatusFailed)
+	ws.Release(ctx, tk)
+
+	head, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, base, head, "failed work is not committed on the project's own branch")
+}
+
+func TestRelease_WhenStashPopConflicts_ReportsWarning(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	commitFile(t, repo, "app.go", "package app\n")
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoStash: true})
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "app.go"), []byte("package app // mine\n"), 0600))
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	// The task edits the same file and leaves it uncommitted
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "app.go"), []byte("package app // task\n"), 0600))
+	tk.SetStatus(task.StatusCompleted)
+	ws.Release(ctx, tk)
+
+	warnings := tk.Snapshot().Warnings
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "stashed changes")
+	assert.Contains(t, runGit(t, repo, "stash", "list"), "herald: auto-stash")
+}
+
+func TestRelease_WhenFailedTaskLeftChanges_KeepsStash(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	commitFile(t, repo, "app.go", "package app\n")
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoStash: true})
+	ctx := context.Background()
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("mine"), 0600))
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	// The task fails halfway through its edits
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "app.go"), []byte("package app // half\n"), 0600))
+	tk.SetStatus(task.StatusFailed)
+	ws.Release(ctx, tk)
+
+	warnings := tk.Snapshot().Warnings
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "left in the stash")
+	assert.Contains(t, runGit(t, repo, "stash", "list"), "herald: auto-stash")
+	_, err := os.Stat(filepath.Join(repo, "notes.txt"))
+	assert.True(t, os.IsNotExist(err), "stashed changes are not mixed with the task's")
+}
+
+func TestPrepare_WhenCheckoutInUse_WaitsForRelease(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, first := newTestManager(t, repo, config.GitConfig{AutoBranch: true, AutoStash: true, AutoCommit: true})
+	second := task.NewManager(nil, 3, time.Hour).Create("app", "do it too", "", task.PriorityNormal, 30)
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	require.True(t, ws.SharesCheckout("app"))
+	req := executor.Request{TaskID: first.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, first, &req))
+
+	prepared := make(chan error, 1)
+	go func() {
+		req := executor.Request{TaskID: second.ID, ProjectPath: repo}
+		prepared <- ws.Prepare(ctx, second, &req)
+	}()
+	select {
+	case err := <-prepared:
+		t.Fatalf("second task prepared while the first holds the checkout: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// The first task leaves a file, committed on its own branch only
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "first.go"), []byte("package app\n"), 0600))
+	first.SetStatus(task.StatusCompleted)
+	ws.Release(ctx, first)
+
+	require.NoError(t, <-prepared)
+	branch, err := ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "herald/"+second.ID, branch)
+	_, err = os.Stat(filepath.Join(repo, "first.go"))
+	assert.True(t, os.IsNotExist(err), "the first task's changes stay on its branch")
+
+	second.SetStatus(task.StatusCompleted)
+	ws.Release(ctx, second)
+	branch, err = ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+}
+
+func TestPrepare_WhenWaitingForCheckoutCancelled_ReturnsError(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, first := newTestManager(t, repo, config.GitConfig{AutoStash: true})
+	second := task.NewManager(nil, 3, time.Hour).Create("app", "do it too", "", task.PriorityNormal, 30)
+
+	req := executor.Request{TaskID: first.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(context.Background(), first, &req))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req = executor.Request{TaskID: second.ID, ProjectPath: repo}
+	err := ws.Prepare(ctx, second, &req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "waiting for the checkout")
+
+	// A release without the checkout leaves it to its holder
+	ws.Release(context.Background(), second)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, ws.Prepare(ctx, second, &req), "the first task still holds the checkout")
+}
+
+func TestRevert_WhenAutoBranchAndStash_DeletesBranchAndRestoresChanges(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	commitFile(t, repo, "app.go", "package app\n")
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoBranch: true, AutoStash: true, AutoCommit: true})
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "app.go"), []byte("package app // wip\n"), 0600))
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	// The task commits once and leaves a half-edited file behind
+	commitFile(t, repo, "feature.go", "package app\n")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "half.go"), []byte("package app\nfunc"), 0600))
+	tk.SetStatus(task.StatusCancelled)
+
+	undone, err := ws.Revert(ctx, tk)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"discarded uncommitted changes",
+		"switched back to main",
+		"deleted branch herald/" + tk.ID + " (1 commit)",
+		"restored the changes stashed before the task",
+	}, undone)
+
+	branch, err := ops.CurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	assert.False(t, ops.BranchExists(ctx, "herald/"+tk.ID))
+	content, err := os.ReadFile(filepath.Join(repo, "app.go")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "package app // wip\n", string(content))
+	for _, name := range []string{"feature.go", "half.go"} {
+		_, err = os.Stat(filepath.Join(repo, name))
+		assert.True(t, os.IsNotExist(err), "%s should be gone", name)
+	}
+}
+
+func TestRevert_WhenExistingBranch_ResetsItAndStashesLeftovers(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	runGit(t, repo, "branch", "feature/existing")
+	ws, tk := newTestManager(t, repo, config.GitConfig{})
+	tk.GitBranch = "feature/existing"
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+	base := tk.Snapshot().BaseCommit
+
+	commitFile(t, repo, "a.go", "package a\n")
+	commitFile(t, repo, "b.go", "package b\n")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "half.go"), []byte("package half\n"), 0600))
+
+	undone, err := ws.Revert(ctx, tk)
+	require.NoError(t, err)
+	require.Len(t, undone, 3)
+	assert.Contains(t, undone[0], "moved uncommitted changes to the stash")
+	assert.Equal(t, "switched back to main", undone[1])
+	assert.Equal(t, "reset feature/existing to "+base[:12]+", dropping 2 commits", undone[2])
+
+	assert.True(t, ops.BranchExists(ctx, "feature/existing"), "an existing branch is kept")
+	n, err := ops.CountCommits(ctx, base, "feature/existing")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Contains(t, runGit(t, repo, "stash", "list"), "herald: changes reverted from "+tk.ID)
+}
+
+func TestRevert_WhenNoAutomation_ResetsProjectBranch(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{})
+	ctx := context.Background()
+	ops := git.NewOps(repo)
+
+	base, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+	assert.Equal(t, base, tk.Snapshot().BaseCommit, "base commit is recorded even without git automation")
+
+	commitFile(t, repo, "a.go", "package a\n")
+
+	undone, err := ws.Revert(ctx, tk)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reset main to " + base[:12] + ", dropping 1 commit"}, undone)
+	head, err := ops.HeadCommit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, base, head)
+}
+
+func TestRevert_WhenWorktree_RemovesWorktreeAndBranch(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	ws, tk := newTestManager(t, repo, config.GitConfig{Worktree: true})
+	ctx := context.Background()
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+	require.NoError(t, os.WriteFile(filepath.Join(req.ProjectPath, "half.go"), []byte("package half\n"), 0600))
+
+	undone, err := ws.Revert(ctx, tk)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"removed worktree " + req.ProjectPath + " and its uncommitted changes",
+		"deleted branch herald/" + tk.ID + " (0 commits)",
+	}, undone)
+
+	_, err = os.Stat(req.ProjectPath)
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, tk.Snapshot().WorktreePath)
+	assert.False(t, git.NewOps(repo).BranchExists(ctx, "herald/"+tk.ID))
+}
+
+func TestChanges_MeasuresTaskChangesAgainstBaseCommit(t *testing.T) {
+	t.Parallel()
+	repo := initTestRepo(t)
+	commitFile(t, repo, "app.go", "package app\n")
+	ws, tk := newTestManager(t, repo, config.GitConfig{AutoBranch: true, AutoStash: true})
+	ctx := context.Background()
+
+	req := executor.Request{TaskID: tk.ID, ProjectPath: repo}
+	require.NoError(t, ws.Prepare(ctx, tk, &req))
+
+	// The task commits an edit and leaves a new file uncommitted
+	commitFile(t, repo, "app.go", "package app\n\nfunc Run() {}\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "internal", "auth"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "internal", "auth", "auth.go"), []byte("package auth\n"), 0600))
+
+	c, ok := ws.Changes(ctx, tk)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []string{"app.go", "internal/auth/auth.go"}, c.Files)
+	assert.Equal(t, 3, c.Added)
+	assert.Equal(t, 0, c.Removed)
+}
+
+func TestChanges_WhenNotGitRepo_CannotMeasure(t *testing.T) {
+	t.Parallel()
+	ws, tk := newTestManager(t, t.TempDir(), config.GitConfig{})
+
+	req := executor.Request{TaskID: tk.ID}
+	require.NoError(t, ws.Prepare(context.Background(), tk, &req))
+
+	_, ok := ws.Changes(context.Background(), tk)
+	assert.False(t, ok)
+}